@@ -3,20 +3,170 @@ package migration
 import (
 	"owlharbour-api/database"
 	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+
+	"gorm.io/gorm"
 )
 
 var tables = []interface{}{
 	&model.User{},
 	&model.AppSetting{},
+	&model.Harbour{},
+	&model.UserHarbour{},
 	&model.AppGeofence{},
 	&model.PairingRequest{},
 	&model.Ship{},
 	&model.ShipDetail{},
+	&model.ShipDetailHistory{},
 	&model.ShipLocationLog{},
 	&model.ShipDockedLog{},
+	&model.Voyage{},
+	&model.ShipVisit{},
+	&model.Berth{},
+	&model.BerthGeofence{},
+	&model.BerthReservation{},
+	&model.PortTariff{},
+	&model.Invoice{},
+	&model.InvoicePayment{},
+	&model.OccupancyThreshold{},
+	&model.OccupancyAlert{},
+	&model.ShipPositionRollup{},
+	&model.ShipReportingStat{},
+	&model.FraudCase{},
+	&model.FraudCaseActivity{},
+	&model.ReportJob{},
+	&model.ReportJobRun{},
+	&model.InspectionTemplate{},
+	&model.InspectionTemplateItem{},
+	&model.Inspection{},
+	&model.InspectionResult{},
+	&model.InspectionTask{},
+	&model.Attachment{},
+	&model.Landing{},
+	&model.LandingCatch{},
+	&model.CrewMember{},
+	&model.CrewManifest{},
+	&model.CrewManifestMember{},
+	&model.ShipDocument{},
+	&model.ShipImport{},
+}
+
+// indexes backing the (created_at, id) keyset pagination of the log and report lists
+var indexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_ship_location_logs_ship_keyset ON ship_location_logs (ship_id, created_at DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_ship_location_logs_keyset ON ship_location_logs (created_at DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_ship_docked_logs_ship_keyset ON ship_docked_logs (ship_id, created_at DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_ship_docked_logs_keyset ON ship_docked_logs (created_at DESC, id DESC)",
+	// a stay is billed once, a void invoice frees it to be drafted again
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_checkout_billed ON invoices (checkout_log_id) WHERE status <> 'void' AND deleted_at IS NULL",
+	// a threshold has one open alert, duplicates raised by concurrent checks before the index are resolved first
+	"UPDATE occupancy_alerts SET resolved_at = raised_at WHERE resolved_at IS NULL AND deleted_at IS NULL AND id NOT IN " +
+		"(SELECT MIN(id) FROM occupancy_alerts WHERE resolved_at IS NULL AND deleted_at IS NULL GROUP BY harbour_id, threshold_id)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_occupancy_alerts_open ON occupancy_alerts (harbour_id, threshold_id) WHERE resolved_at IS NULL AND deleted_at IS NULL",
+	// a docked log is inspected once, the first of the inspections submitted concurrently before the index is kept
+	"UPDATE inspections SET deleted_at = NOW() WHERE deleted_at IS NULL AND id NOT IN " +
+		"(SELECT MIN(id) FROM inspections WHERE deleted_at IS NULL GROUP BY ship_docked_log_id)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_inspections_docked_log ON inspections (ship_docked_log_id) WHERE deleted_at IS NULL",
+	// a docked log has one landing, the catches of duplicate landings submitted before the index are removed with them
+	"UPDATE landings SET deleted_at = NOW() WHERE deleted_at IS NULL AND id NOT IN " +
+		"(SELECT MIN(id) FROM landings WHERE deleted_at IS NULL GROUP BY ship_docked_log_id)",
+	"UPDATE landing_catches SET deleted_at = NOW() WHERE deleted_at IS NULL AND landing_id IN (SELECT id FROM landings WHERE deleted_at IS NOT NULL)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_landings_docked_log ON landings (ship_docked_log_id) WHERE deleted_at IS NULL",
+}
+
+// money columns first stored as decimal floats, they are converted to minor units (cents)
+var minorUnitColumns = map[string][]string{
+	"port_tariffs":     {"base_fee", "daily_rate", "rate_per_gt"},
+	"invoices":         {"base_fee", "daily_rate", "rate_per_gt", "amount", "paid_amount"},
+	"invoice_payments": {"amount"},
 }
 
 func Migrate() {
 	conn := database.GetConnection() // Get db connection
-	conn.AutoMigrate(tables...)      // migrate the tables
+	migrateMinorUnits(conn)
+	conn.AutoMigrate(tables...) // migrate the tables
+
+	for _, index := range indexes {
+		conn.Exec(index)
+	}
+
+	migrateSingleHarbour(conn)
+
+	// every harbour has a zone its time series are cut in, older harbours get the default one
+	conn.Exec("UPDATE harbours SET timezone = ? WHERE timezone IS NULL OR timezone = ''", helper.DefaultTimezone())
+
+	migrateLocationStatus(conn)
+}
+
+// migrateLocationStatus fills the status of the location logs stored before fixes recorded it.
+// It is approximated from the docked log preceding the fix: after a checkin the ship is counted
+// inside, otherwise out of scope. The fix which checked a ship out is counted out of scope too,
+// the statistics of those older periods only count out of scope ships approximately
+func migrateLocationStatus(conn *gorm.DB) {
+	conn.Exec(`UPDATE ship_location_logs SET status = CASE
+		WHEN (SELECT CAST(ship_docked_logs.status AS TEXT) FROM ship_docked_logs
+			WHERE ship_docked_logs.ship_id = ship_location_logs.ship_id AND ship_docked_logs.created_at <= ship_location_logs.created_at
+			ORDER BY ship_docked_logs.created_at DESC LIMIT 1) = 'checkin' THEN 'checkin'
+		ELSE 'out of scope' END
+		WHERE ship_location_logs.status IS NULL OR ship_location_logs.status = ''`)
+}
+
+// migrateMinorUnits converts the float money columns of earlier migrations to cents, columns
+// already converted or not created yet are left to AutoMigrate
+func migrateMinorUnits(conn *gorm.DB) {
+	for table, columns := range minorUnitColumns {
+		for _, column := range columns {
+			var dataType string
+			conn.Raw("SELECT data_type FROM information_schema.columns WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?",
+				table, column).Scan(&dataType)
+
+			if dataType == "double precision" {
+				conn.Exec("ALTER TABLE " + table + " ALTER COLUMN " + column + " TYPE bigint USING ROUND(" + column + " * 100)::bigint")
+			}
+		}
+	}
+}
+
+// tables of the rows owned by a harbour, rows of the other tables belong to a ship
+var harbourTables = []string{"ships", "pairing_requests", "app_geofences", "report_jobs", "ship_imports"}
+
+// migrateSingleHarbour turns the settings of a single harbour deployment into its first harbour,
+// the existing rows and admins are assigned to it. It does nothing once a harbour exists.
+func migrateSingleHarbour(conn *gorm.DB) {
+	var harbours int64
+	if err := conn.Model(&model.Harbour{}).Count(&harbours).Error; err != nil || harbours > 0 {
+		return
+	}
+
+	var setting model.AppSetting
+	if err := conn.Model(&model.AppSetting{}).Take(&setting).Error; err != nil {
+		return
+	}
+
+	conn.Transaction(func(tx *gorm.DB) error {
+		harbour := model.Harbour{
+			Code:               setting.HarbourCode,
+			Name:               setting.HarbourName,
+			Mode:               setting.Mode,
+			Interval:           setting.Interval,
+			Range:              setting.Range,
+			AdminContact:       setting.AdminContact,
+			IsActive:           1,
+			InspectionSlaHours: setting.InspectionSlaHours,
+			InspectionAssign:   setting.InspectionAssign,
+		}
+
+		if err := tx.Create(&harbour).Error; err != nil {
+			return err
+		}
+
+		for _, table := range harbourTables {
+			if err := tx.Exec("UPDATE "+table+" SET harbour_id = ? WHERE harbour_id IS NULL OR harbour_id = 0", harbour.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec("INSERT INTO user_harbours (user_id, harbour_id) SELECT id, ? FROM users WHERE role = ? AND deleted_at IS NULL ON CONFLICT DO NOTHING",
+			harbour.ID, model.Admin).Error
+	})
 }
@@ -3,6 +3,7 @@ package seeder
 import (
 	"fmt"
 	"owlharbour-api/database"
+	"owlharbour-api/internal/model"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -68,4 +69,57 @@ func Seed() {
 			}
 		}
 	}
+
+	seedInspectionTemplate(db)
+}
+
+// seedInspectionTemplate creates the default harbour checklist once
+func seedInspectionTemplate(db *gorm.DB) {
+	name := "Standard Harbour Inspection"
+
+	template := model.InspectionTemplate{}
+	err := db.Where("name = ?", name).First(&template).Error
+	if err == nil {
+		return
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		fmt.Println(err)
+		return
+	}
+
+	template = model.InspectionTemplate{
+		Name:        name,
+		Description: "Default checklist for ships checking in to the harbour",
+		IsActive:    1,
+	}
+
+	items := []model.InspectionTemplateItem{
+		{Category: model.CategorySafetyGear, Label: "Life jackets for every crew member", IsRequired: 1},
+		{Category: model.CategorySafetyGear, Label: "Fire extinguisher", IsRequired: 1},
+		{Category: model.CategorySafetyGear, Label: "Navigation lights and radio", IsRequired: 1},
+		{Category: model.CategorySafetyGear, Label: "First aid kit", IsRequired: 0},
+		{Category: model.CategoryLicense, Label: "SIUP business license", IsRequired: 1},
+		{Category: model.CategoryLicense, Label: "BKP vessel registration", IsRequired: 1},
+		{Category: model.CategoryLicense, Label: "Sailing approval letter", IsRequired: 1},
+		{Category: model.CategoryCatch, Label: "Catch matches the permitted gear", IsRequired: 1},
+		{Category: model.CategoryCatch, Label: "No protected species on board", IsRequired: 1},
+		{Category: model.CategoryCatch, Label: "Catch stored in proper condition", IsRequired: 0},
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&template).Error; err != nil {
+			return err
+		}
+
+		for i := range items {
+			items[i].InspectionTemplateID = template.ID
+			items[i].Sort = i + 1
+		}
+
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
 }
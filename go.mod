@@ -6,6 +6,7 @@ require (
 	github.com/JGLTechnologies/gin-rate-limit v1.5.4
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gin-contrib/sessions v0.0.5
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/gorilla/websocket v1.5.0
 	github.com/redis/go-redis/v9 v9.0.2
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
@@ -0,0 +1,184 @@
+package attachment
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// attachmentError maps validation failures of uploads and downloads to a client error
+func attachmentError(c *gin.Context, message string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse("invalid attachment id, no attachment data", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.AttachmentTooLarge:
+		response := util.APIResponse(err.Error(), http.StatusRequestEntityTooLarge, "failed", nil)
+		c.JSON(http.StatusRequestEntityTooLarge, response)
+	case constants.InvalidSignature, constants.SignedURLExpired:
+		response := util.APIResponse(err.Error(), http.StatusForbidden, "failed", nil)
+		c.JSON(http.StatusForbidden, response)
+	case constants.StorageObjectNotFound:
+		response := util.APIResponse(err.Error(), http.StatusNotFound, "failed", nil)
+		c.JSON(http.StatusNotFound, response)
+	case constants.InvalidAttachmentOwner, constants.InvalidAttachmentCategory, constants.AttachmentOwnerNotFound,
+		constants.AttachmentTypeNotAllowed, constants.InvalidStorageKey:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func (h *handler) Upload(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.AttachmentUploadRequest
+	if err := c.ShouldBind(&request); err != nil {
+		errorMessage := gin.H{"errors": "please fill data"}
+		if err != io.EOF {
+			errors := util.FormatValidationError(err)
+			errorMessage = gin.H{"errors": errors}
+		}
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": "file is required"})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.Upload(ctx, user, request, file)
+	if err != nil {
+		attachmentError(c, "Failed to upload attachment", err)
+		return
+	}
+
+	response := util.APIResponse("Attachment successfully uploaded", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AttachmentList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	ownerID, err := strconv.Atoi(c.DefaultQuery("owner_id", ""))
+	if err != nil {
+		response := util.APIResponse("Invalid owner_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	param := dto.AttachmentListParam{
+		OwnerType: c.DefaultQuery("owner_type", ""),
+		OwnerID:   ownerID,
+		Category:  c.DefaultQuery("category", ""),
+	}
+
+	res, err := h.service.AttachmentList(ctx, param)
+	if err != nil {
+		attachmentError(c, "Failed to retrieve attachment list", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved attachment list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AttachmentDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid attachment_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.AttachmentDetail(ctx, attachmentID)
+	if err != nil {
+		attachmentError(c, "Failed to retrieve attachment", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved attachment", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) DeleteAttachment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid attachment_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.service.DeleteAttachment(ctx, attachmentID); err != nil {
+		attachmentError(c, "Failed to delete attachment", err)
+		return
+	}
+
+	response := util.APIResponse("Attachment successfully deleted", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// File streams a file of the local storage, the signature in the query stands in for the token
+func (h *handler) File(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	object, err := h.service.File(ctx, c.Query("key"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		attachmentError(c, "Failed to retrieve file", err)
+		return
+	}
+	defer object.Body.Close()
+
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, nil)
+}
@@ -0,0 +1,17 @@
+package attachment
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.GET("/file", h.File)
+
+	g.Use(middleware.Authenticate())
+	g.POST("/upload", h.Upload)
+	g.GET("/list", h.AttachmentList)
+	g.GET("/detail/:attachment_id", h.AttachmentDetail)
+	g.DELETE("/:attachment_id", h.DeleteAttachment)
+}
@@ -0,0 +1,308 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/log"
+	"owlharbour-api/pkg/storage"
+	"owlharbour-api/pkg/util"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxSizeMB = 10
+	signedURLExpiry  = 15 * time.Minute
+)
+
+// ownerCategories lists the categories every owner type accepts
+var ownerCategories = map[model.AttachmentOwner][]model.AttachmentCategory{
+	model.OwnerInspection:     {model.AttachmentPhoto, model.AttachmentDocument},
+	model.OwnerShip:           {model.AttachmentPhoto, model.AttachmentSIUP, model.AttachmentBKP, model.AttachmentDocument},
+	model.OwnerPairingRequest: {model.AttachmentEvidence},
+}
+
+// categoryTypes lists the content types allowed per category, detected from the file itself
+var categoryTypes = map[model.AttachmentCategory][]string{
+	model.AttachmentPhoto:    {"image/jpeg", "image/png"},
+	model.AttachmentSIUP:     {"application/pdf", "image/jpeg", "image/png"},
+	model.AttachmentBKP:      {"application/pdf", "image/jpeg", "image/png"},
+	model.AttachmentDocument: {"application/pdf", "image/jpeg", "image/png"},
+	model.AttachmentEvidence: {"application/pdf", "image/jpeg", "image/png"},
+}
+
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+type service struct {
+	attachmentRepository repository.Attachment
+	storage              storage.Storage
+}
+
+type Service interface {
+	Upload(ctx context.Context, authUser model.User, request dto.AttachmentUploadRequest, file *multipart.FileHeader) (*dto.AttachmentResponse, error)
+	AttachmentList(ctx context.Context, request dto.AttachmentListParam) ([]dto.AttachmentResponse, error)
+	AttachmentDetail(ctx context.Context, ID int) (*dto.AttachmentResponse, error)
+	DeleteAttachment(ctx context.Context, ID int) error
+	File(ctx context.Context, key string, expires string, signature string) (*storage.Object, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		attachmentRepository: f.AttachmentRepository,
+		storage:              f.Storage,
+	}
+}
+
+func maxUploadSize() int64 {
+	sizeMB, err := strconv.ParseInt(util.GetEnv("ATTACHMENT_MAX_SIZE_MB", ""), 10, 64)
+	if err != nil || sizeMB <= 0 {
+		sizeMB = defaultMaxSizeMB
+	}
+
+	return sizeMB << 20
+}
+
+func validCategory(owner model.AttachmentOwner, category model.AttachmentCategory) error {
+	categories, ok := ownerCategories[owner]
+	if !ok {
+		return constants.InvalidAttachmentOwner
+	}
+
+	for _, e := range categories {
+		if e == category {
+			return nil
+		}
+	}
+
+	return constants.InvalidAttachmentCategory
+}
+
+func allowedType(category model.AttachmentCategory, contentType string) bool {
+	for _, e := range categoryTypes[category] {
+		if e == contentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// storageKey groups the files by owner, the random name keeps uploads of the same file apart
+func storageKey(owner model.AttachmentOwner, ownerID int, prefix string, ext string) (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	name := prefix + time.Now().Format("20060102150405") + "-" + hex.EncodeToString(random) + ext
+	return fmt.Sprintf("%s/%d/%s", owner, ownerID, name), nil
+}
+
+func (s *service) Upload(ctx context.Context, authUser model.User, request dto.AttachmentUploadRequest, file *multipart.FileHeader) (*dto.AttachmentResponse, error) {
+	owner := model.AttachmentOwner(request.OwnerType)
+	category := model.AttachmentCategory(request.Category)
+
+	if err := validCategory(owner, category); err != nil {
+		return nil, err
+	}
+
+	maxSize := maxUploadSize()
+	if file.Size > maxSize {
+		return nil, constants.AttachmentTooLarge
+	}
+
+	exists, err := s.attachmentRepository.OwnerExists(ctx, owner, request.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, constants.AttachmentOwnerNotFound
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// the header size comes from the client, the limit is enforced on the bytes actually read
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, constants.AttachmentTooLarge
+	}
+
+	contentType := strings.TrimSpace(strings.Split(http.DetectContentType(data), ";")[0])
+	if !allowedType(category, contentType) {
+		return nil, constants.AttachmentTypeNotAllowed
+	}
+
+	key, err := storageKey(owner, request.OwnerID, "", extensions[contentType])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := model.Attachment{
+		OwnerType:   owner,
+		OwnerID:     request.OwnerID,
+		Category:    category,
+		FileName:    filepath.Base(file.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+		UploadedBy:  authUser.ID,
+	}
+
+	if strings.HasPrefix(contentType, "image/") {
+		attachment.ThumbnailKey = s.storeThumbnail(ctx, owner, request.OwnerID, data)
+	}
+
+	if err := s.attachmentRepository.StoreAttachment(ctx, &attachment); err != nil {
+		s.removeFiles(ctx, attachment)
+		return nil, err
+	}
+
+	return s.response(attachment)
+}
+
+// storeThumbnail returns the key of the thumbnail, an image that can not be scaled is kept without one
+func (s *service) storeThumbnail(ctx context.Context, owner model.AttachmentOwner, ownerID int, data []byte) string {
+	thumb, err := thumbnail(data)
+	if err != nil {
+		log.WriteErrorLog(err)
+		return ""
+	}
+
+	key, err := storageKey(owner, ownerID, "thumb-", ".jpg")
+	if err != nil {
+		log.WriteErrorLog(err)
+		return ""
+	}
+
+	if err := s.storage.Put(ctx, key, bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+		log.WriteErrorLog(err)
+		return ""
+	}
+
+	return key
+}
+
+func (s *service) removeFiles(ctx context.Context, attachment model.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.WriteErrorLog(err)
+		}
+	}
+}
+
+func (s *service) response(attachment model.Attachment) (*dto.AttachmentResponse, error) {
+	url, err := s.storage.SignedURL(attachment.StorageKey, signedURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	var thumbnailURL string
+	if attachment.ThumbnailKey != "" {
+		thumbnailURL, err = s.storage.SignedURL(attachment.ThumbnailKey, signedURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.AttachmentResponse{
+		ID:           attachment.ID,
+		OwnerType:    string(attachment.OwnerType),
+		OwnerID:      attachment.OwnerID,
+		Category:     string(attachment.Category),
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		ExpiresAt:    time.Now().Add(signedURLExpiry).Format("2006-01-02 15:04:05"),
+		UploadedBy:   attachment.UploadedBy,
+		CreatedAt:    attachment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func (s *service) AttachmentList(ctx context.Context, request dto.AttachmentListParam) ([]dto.AttachmentResponse, error) {
+	if _, ok := ownerCategories[model.AttachmentOwner(request.OwnerType)]; !ok {
+		return nil, constants.InvalidAttachmentOwner
+	}
+
+	attachments, err := s.attachmentRepository.AttachmentList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.AttachmentResponse{}
+	for _, e := range attachments {
+		attachment, err := s.response(e)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *attachment)
+	}
+
+	return res, nil
+}
+
+func (s *service) AttachmentDetail(ctx context.Context, ID int) (*dto.AttachmentResponse, error) {
+	attachment, err := s.attachmentRepository.AttachmentByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(*attachment)
+}
+
+func (s *service) DeleteAttachment(ctx context.Context, ID int) error {
+	attachment, err := s.attachmentRepository.AttachmentByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepository.DeleteAttachment(ctx, ID); err != nil {
+		return err
+	}
+
+	s.removeFiles(ctx, *attachment)
+
+	return nil
+}
+
+// File serves a signed link of the local storage, s3 links point at the bucket directly
+func (s *service) File(ctx context.Context, key string, expires string, signature string) (*storage.Object, error) {
+	if err := storage.VerifySignature(key, expires, signature); err != nil {
+		return nil, err
+	}
+
+	return s.storage.Get(ctx, key)
+}
@@ -0,0 +1,355 @@
+package berth
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// mobileShip resolves the ship paired with the signed in account
+func (h *handler) mobileShip(c *gin.Context) (model.User, int, bool) {
+	user, ok := authUser(c)
+	if !ok {
+		return model.User{}, 0, false
+	}
+
+	shipID, err := h.service.MobileShipID(c.Request.Context(), user)
+	if err != nil {
+		berthError(c, "Failed to retrieve ship", "no ship data for this account", err)
+		return model.User{}, 0, false
+	}
+
+	return user, shipID, true
+}
+
+func berthError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidBerthGeofence, constants.BerthCodeTaken, constants.BerthInactive, constants.BerthOtherHarbour,
+		constants.InvalidBerthWindow, constants.BerthShipTooLong, constants.BerthShipTooHeavy, constants.BerthShipSizeUnknown,
+		constants.BerthFull, constants.ReservationOverlap, constants.ReservationNotPending, constants.InvalidHarbour:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func berthParam(c *gin.Context) dto.BerthListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.BerthListParam{
+		Offset:     offset,
+		Limit:      limit,
+		Search:     c.DefaultQuery("search", ""),
+		ActiveOnly: c.DefaultQuery("active_only", "false") == "true",
+	}
+}
+
+func reservationParam(c *gin.Context) dto.ReservationListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+	berthID, _ := strconv.Atoi(c.DefaultQuery("berth_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.ReservationListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		BerthID:   berthID,
+		Status:    c.DefaultQuery("status", ""),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+}
+
+func (h *handler) berthList(c *gin.Context, param dto.BerthListParam) {
+	res, err := h.service.BerthList(c.Request.Context(), param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve berth list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved berth list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) reservationList(c *gin.Context, param dto.ReservationListParam) {
+	res, err := h.service.ReservationList(c.Request.Context(), param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve berth reservation list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved berth reservation list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) cancelReservation(c *gin.Context, request dto.ReservationActionRequest) {
+	if err := h.service.CancelReservation(c.Request.Context(), request); err != nil {
+		berthError(c, "Failed to cancel berth reservation", "invalid reservation id, no reservation data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth reservation successfully cancelled", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) MobileBerthList(c *gin.Context) {
+	if _, _, ok := h.mobileShip(c); !ok {
+		return
+	}
+
+	param := berthParam(c)
+	param.ActiveOnly = true
+
+	h.berthList(c, param)
+}
+
+// MobileRequestReservation asks the staff for a berth during the expected arrival window of the ship
+func (h *handler) MobileRequestReservation(c *gin.Context) {
+	user, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ReservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+	request.ShipID = shipID
+
+	res, err := h.service.RequestReservation(c.Request.Context(), user, request)
+	if err != nil {
+		berthError(c, "Failed to request berth reservation", "invalid berth id, no berth data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth reservation successfully requested", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) MobileReservationList(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	param := reservationParam(c)
+	param.ShipID = shipID
+
+	h.reservationList(c, param)
+}
+
+func (h *handler) MobileCancelReservation(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ReservationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+	request.ShipID = shipID
+
+	h.cancelReservation(c, request)
+}
+
+func (h *handler) BerthList(c *gin.Context) {
+	h.berthList(c, berthParam(c))
+}
+
+func (h *handler) BerthDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	berthID, err := strconv.Atoi(c.Param("berth_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid berth_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.BerthDetail(ctx, berthID)
+	if err != nil {
+		berthError(c, "Failed to retrieve berth", "invalid berth id, no berth data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved berth", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreBerth(c *gin.Context) {
+	var request dto.BerthRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreBerth(c.Request.Context(), request); err != nil {
+		berthError(c, "Failed to store berth", "invalid harbour, no harbour data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateBerth(c *gin.Context) {
+	var request dto.BerthRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateBerth(c.Request.Context(), request); err != nil {
+		berthError(c, "Failed to update berth", "invalid berth id, no berth data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ReservationList(c *gin.Context) {
+	h.reservationList(c, reservationParam(c))
+}
+
+func (h *handler) ReservationDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	reservationID, err := strconv.Atoi(c.Param("reservation_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid reservation_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ReservationDetail(ctx, reservationID)
+	if err != nil {
+		berthError(c, "Failed to retrieve berth reservation", "invalid reservation id, no reservation data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved berth reservation", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+// StoreReservation assigns a berth to a ship without a request of the ship
+func (h *handler) StoreReservation(c *gin.Context) {
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ReservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.StoreReservation(c.Request.Context(), user, request)
+	if err != nil {
+		berthError(c, "Failed to store berth reservation", "invalid ship or berth id, no data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth reservation successfully stored", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AssignReservation(c *gin.Context) {
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ReservationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.AssignReservation(c.Request.Context(), user, request)
+	if err != nil {
+		berthError(c, "Failed to assign berth reservation", "invalid reservation or berth id, no data", err)
+		return
+	}
+
+	response := util.APIResponse("Berth reservation successfully assigned", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) CancelReservation(c *gin.Context) {
+	var request dto.ReservationActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	h.cancelReservation(c, request)
+}
@@ -0,0 +1,27 @@
+package berth
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/mobile/list", h.MobileBerthList)
+	g.POST("/mobile/reservation", h.MobileRequestReservation)
+	g.GET("/mobile/reservation", h.MobileReservationList)
+	g.PUT("/mobile/reservation/cancel", h.MobileCancelReservation)
+
+	g.GET("/list", h.BerthList)
+	g.GET("/detail/:berth_id", h.BerthDetail)
+	g.POST("/store", h.StoreBerth)
+	g.PUT("/update", h.UpdateBerth)
+
+	g.GET("/reservation/list", h.ReservationList)
+	g.GET("/reservation/detail/:reservation_id", h.ReservationDetail)
+	g.POST("/reservation/store", h.StoreReservation)
+	g.PUT("/reservation/assign", h.AssignReservation)
+	g.PUT("/reservation/cancel", h.CancelReservation)
+}
@@ -0,0 +1,511 @@
+package berth
+
+import (
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// arrivalTolerance lets a ship entering its berth early still confirm the reservation
+const arrivalTolerance = 12 * time.Hour
+
+// sizeNumber matches the first number of a free text dimension such as "12 x 4 x 1,5 m",
+// the length of the ship is written first
+var sizeNumber = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+type service struct {
+	appRepository   repository.App
+	shipRepository  repository.Ship
+	berthRepository repository.Berth
+}
+
+type Service interface {
+	MobileShipID(ctx context.Context, authUser model.User) (int, error)
+	StoreBerth(ctx context.Context, request dto.BerthRequest) error
+	UpdateBerth(ctx context.Context, request dto.BerthRequest) error
+	BerthList(ctx context.Context, request dto.BerthListParam) (*dto.BerthResponseList, error)
+	BerthDetail(ctx context.Context, ID int) (*dto.BerthResponse, error)
+	RequestReservation(ctx context.Context, authUser model.User, request dto.ReservationRequest) (*dto.ReservationResponse, error)
+	StoreReservation(ctx context.Context, authUser model.User, request dto.ReservationRequest) (*dto.ReservationResponse, error)
+	AssignReservation(ctx context.Context, authUser model.User, request dto.ReservationActionRequest) (*dto.ReservationResponse, error)
+	CancelReservation(ctx context.Context, request dto.ReservationActionRequest) error
+	ReservationList(ctx context.Context, request dto.ReservationListParam) (*dto.ReservationResponseList, error)
+	ReservationDetail(ctx context.Context, ID int) (*dto.ReservationResponse, error)
+	ConfirmArrival(ctx context.Context, shipID int, lat float64, long float64, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:   f.AppRepository,
+		shipRepository:  f.ShipRepository,
+		berthRepository: f.BerthRepository,
+	}
+}
+
+// MobileShipID returns the ship paired with the signed in account
+func (s *service) MobileShipID(ctx context.Context, authUser model.User) (int, error) {
+	ship, err := s.shipRepository.ShipByAuth(ctx, authUser)
+	if err != nil {
+		return 0, err
+	}
+
+	return ship.ID, nil
+}
+
+func berthGeofences(payload []dto.PayloadAppGeofence) ([]model.BerthGeofence, error) {
+	if len(payload) < 3 {
+		return nil, constants.InvalidBerthGeofence
+	}
+
+	geofences := []model.BerthGeofence{}
+	for _, e := range payload {
+		if _, err := strconv.ParseFloat(e.Lat, 64); err != nil {
+			return nil, constants.InvalidBerthGeofence
+		}
+		if _, err := strconv.ParseFloat(e.Long, 64); err != nil {
+			return nil, constants.InvalidBerthGeofence
+		}
+
+		geofences = append(geofences, model.BerthGeofence{
+			Long: e.Long,
+			Lat:  e.Lat,
+		})
+	}
+
+	return geofences, nil
+}
+
+// StoreBerth adds a berth to the harbour of ctx
+func (s *service) StoreBerth(ctx context.Context, request dto.BerthRequest) error {
+	geofences, err := berthGeofences(request.Geofence)
+	if err != nil {
+		return err
+	}
+
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.InvalidHarbour
+		}
+		return err
+	}
+
+	code := strings.TrimSpace(request.Code)
+	taken, err := s.berthRepository.BerthCodeTaken(ctx, harbour.ID, code, 0)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return constants.BerthCodeTaken
+	}
+
+	berth := model.Berth{
+		HarbourID: harbour.ID,
+		Code:      code,
+		Name:      strings.TrimSpace(request.Name),
+		ZoneType:  strings.ToLower(strings.TrimSpace(request.ZoneType)),
+		Capacity:  request.Capacity,
+		MaxLength: request.MaxLength,
+		MaxGT:     request.MaxGT,
+		IsActive:  1,
+	}
+
+	return s.berthRepository.StoreBerth(ctx, &berth, geofences)
+}
+
+// UpdateBerth changes a berth, the zone is kept when no geofence is sent
+func (s *service) UpdateBerth(ctx context.Context, request dto.BerthRequest) error {
+	current, err := s.berthRepository.BerthByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	var geofences []model.BerthGeofence
+	if request.Geofence != nil {
+		geofences, err = berthGeofences(request.Geofence)
+		if err != nil {
+			return err
+		}
+	}
+
+	code := strings.TrimSpace(request.Code)
+	taken, err := s.berthRepository.BerthCodeTaken(ctx, current.HarbourID, code, current.ID)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return constants.BerthCodeTaken
+	}
+
+	isActive := 0
+	if request.IsActive {
+		isActive = 1
+	}
+
+	berth := model.Berth{
+		Code:      code,
+		Name:      strings.TrimSpace(request.Name),
+		ZoneType:  strings.ToLower(strings.TrimSpace(request.ZoneType)),
+		Capacity:  request.Capacity,
+		MaxLength: request.MaxLength,
+		MaxGT:     request.MaxGT,
+		IsActive:  isActive,
+	}
+	berth.ID = current.ID
+
+	return s.berthRepository.UpdateBerth(ctx, berth, geofences)
+}
+
+func (s *service) BerthList(ctx context.Context, request dto.BerthListParam) (*dto.BerthResponseList, error) {
+	total, err := s.berthRepository.BerthCount(ctx, dto.BerthListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.berthRepository.BerthCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.berthRepository.BerthList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.BerthResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) BerthDetail(ctx context.Context, ID int) (*dto.BerthResponse, error) {
+	berth, err := s.berthRepository.BerthByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	geofences, err := s.berthRepository.BerthGeofences(ctx, berth.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.BerthResponse{
+		ID:        berth.ID,
+		HarbourID: berth.HarbourID,
+		Code:      berth.Code,
+		Name:      berth.Name,
+		ZoneType:  berth.ZoneType,
+		Capacity:  berth.Capacity,
+		MaxLength: berth.MaxLength,
+		MaxGT:     berth.MaxGT,
+		IsActive:  berth.IsActive == 1,
+		CreatedAt: berth.CreatedAt.Format("2006-01-02 15:04:05"),
+		Geofences: geofences,
+	}
+
+	return &res, nil
+}
+
+func reservationWindow(request dto.ReservationRequest) (time.Time, time.Time, error) {
+	arrival, err := time.ParseInLocation("2006-01-02 15:04:05", request.ArrivalAt, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, constants.InvalidBerthWindow
+	}
+
+	departure, err := time.ParseInLocation("2006-01-02 15:04:05", request.DepartureAt, time.Local)
+	if err != nil || !departure.After(arrival) {
+		return time.Time{}, time.Time{}, constants.InvalidBerthWindow
+	}
+
+	return arrival, departure, nil
+}
+
+// sizeValue reads the first number of a ship detail, false when it holds none
+func sizeValue(value string) (float64, bool) {
+	match := sizeNumber.FindString(value)
+	if match == "" {
+		return 0, false
+	}
+
+	res, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", "."), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return res, true
+}
+
+// checkFit compares the dimension and GT of the ship against the limits of the berth
+func (s *service) checkFit(ctx context.Context, berth *model.Berth, shipID int) error {
+	if berth.MaxLength == 0 && berth.MaxGT == 0 {
+		return nil
+	}
+
+	detail, err := s.shipRepository.ShipAddonDetail(ctx, shipID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	if berth.MaxLength > 0 {
+		length, ok := sizeValue(detail.Dimension)
+		if !ok {
+			return constants.BerthShipSizeUnknown
+		}
+
+		if length > berth.MaxLength {
+			return constants.BerthShipTooLong
+		}
+	}
+
+	if berth.MaxGT > 0 {
+		gt, ok := sizeValue(detail.GT)
+		if !ok {
+			return constants.BerthShipSizeUnknown
+		}
+
+		if gt > berth.MaxGT {
+			return constants.BerthShipTooHeavy
+		}
+	}
+
+	return nil
+}
+
+// checkConflict makes sure the ship fits the berth, the bookings of the window are checked by the
+// repository in the transaction storing the reservation
+func (s *service) checkConflict(ctx context.Context, berth *model.Berth, ship *model.Ship) error {
+	if berth.IsActive != 1 {
+		return constants.BerthInactive
+	}
+
+	if berth.HarbourID != ship.HarbourID {
+		return constants.BerthOtherHarbour
+	}
+
+	return s.checkFit(ctx, berth, ship.ID)
+}
+
+func (s *service) reserve(ctx context.Context, authUser model.User, request dto.ReservationRequest, status model.ReservationStatus) (*dto.ReservationResponse, error) {
+	arrival, departure, err := reservationWindow(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, request.ShipID)
+	if err != nil {
+		return nil, err
+	}
+
+	berth, err := s.berthRepository.BerthByID(ctx, request.BerthID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkConflict(ctx, berth, ship); err != nil {
+		return nil, err
+	}
+
+	reservation := model.BerthReservation{
+		BerthID:     berth.ID,
+		ShipID:      ship.ID,
+		Status:      status,
+		ArrivalAt:   arrival,
+		DepartureAt: departure,
+		RequestedBy: authUser.ID,
+		Note:        request.Note,
+	}
+
+	if status == model.ReservationAssigned {
+		reservation.AssignedBy = &authUser.ID
+	}
+
+	if err := s.berthRepository.StoreReservation(ctx, &reservation); err != nil {
+		return nil, err
+	}
+
+	if status == model.ReservationAssigned {
+		notifyAssigned(ship, berth, arrival)
+	}
+
+	return s.berthRepository.ReservationDetail(ctx, reservation.ID)
+}
+
+// RequestReservation asks for a berth on behalf of the ship, it holds no place until the staff assigns it
+func (s *service) RequestReservation(ctx context.Context, authUser model.User, request dto.ReservationRequest) (*dto.ReservationResponse, error) {
+	return s.reserve(ctx, authUser, request, model.ReservationRequested)
+}
+
+// StoreReservation assigns a berth to a ship right away
+func (s *service) StoreReservation(ctx context.Context, authUser model.User, request dto.ReservationRequest) (*dto.ReservationResponse, error) {
+	return s.reserve(ctx, authUser, request, model.ReservationAssigned)
+}
+
+// AssignReservation approves a request of a ship, the conflicts are checked again since the
+// berth may have filled up since the request was made
+func (s *service) AssignReservation(ctx context.Context, authUser model.User, request dto.ReservationActionRequest) (*dto.ReservationResponse, error) {
+	current, err := s.berthRepository.ReservationByID(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status != model.ReservationRequested {
+		return nil, constants.ReservationNotPending
+	}
+
+	berthID := current.BerthID
+	if request.BerthID != 0 {
+		berthID = request.BerthID
+	}
+
+	berth, err := s.berthRepository.BerthByID(ctx, berthID)
+	if err != nil {
+		return nil, err
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, current.ShipID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkConflict(ctx, berth, ship); err != nil {
+		return nil, err
+	}
+
+	if err := s.berthRepository.AssignReservation(ctx, *current, berth.ID, authUser.ID, request.Note); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, constants.ReservationNotPending
+		}
+		return nil, err
+	}
+
+	notifyAssigned(ship, berth, current.ArrivalAt)
+
+	return s.berthRepository.ReservationDetail(ctx, current.ID)
+}
+
+// CancelReservation releases a reservation which is not confirmed yet, request.ShipID limits it
+// to the reservations of that ship
+func (s *service) CancelReservation(ctx context.Context, request dto.ReservationActionRequest) error {
+	current, err := s.berthRepository.ReservationByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	if request.ShipID != 0 && current.ShipID != request.ShipID {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := s.berthRepository.CancelReservation(ctx, current.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.ReservationNotPending
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ReservationList(ctx context.Context, request dto.ReservationListParam) (*dto.ReservationResponseList, error) {
+	total, err := s.berthRepository.ReservationCount(ctx, dto.ReservationListParam{ShipID: request.ShipID})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.berthRepository.ReservationCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.berthRepository.ReservationList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ReservationResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) ReservationDetail(ctx context.Context, ID int) (*dto.ReservationResponse, error) {
+	return s.berthRepository.ReservationDetail(ctx, ID)
+}
+
+// ConfirmArrival confirms the assigned reservations of the ship whose berth contains the position
+func (s *service) ConfirmArrival(ctx context.Context, shipID int, lat float64, long float64, now time.Time) error {
+	reservations, err := s.berthRepository.ArrivingReservations(ctx, shipID, now.Add(arrivalTolerance), now)
+	if err != nil {
+		return err
+	}
+
+	coord := [2]float64{lat, long}
+	for _, e := range reservations {
+		geofences, err := s.berthRepository.BerthGeofences(ctx, e.BerthID)
+		if err != nil {
+			return err
+		}
+
+		var polygon [][2]float64
+		for _, geo := range geofences {
+			lat, err := strconv.ParseFloat(geo.Lat, 64)
+			if err != nil {
+				return err
+			}
+			long, err := strconv.ParseFloat(geo.Long, 64)
+			if err != nil {
+				return err
+			}
+			polygon = append(polygon, [2]float64{lat, long})
+		}
+
+		if len(polygon) == 0 || !helper.StatusCheck(coord, polygon) {
+			continue
+		}
+
+		return s.berthRepository.ConfirmReservation(ctx, e.ID, now)
+	}
+
+	return nil
+}
+
+func notifyAssigned(ship *model.Ship, berth *model.Berth, arrival time.Time) {
+	if ship.FirebaseToken == "" {
+		return
+	}
+
+	notificationData := map[string]interface{}{
+		"title": "OWLHARBOUR - BERTH ASSIGNED",
+		"body":  "Berth " + berth.Code + " - " + berth.Name + " was assigned for the arrival at " + arrival.Format("060102-1504"),
+	}
+	tokens := []string{ship.FirebaseToken}
+
+	if _, err := helper.PushNotification(notificationData, tokens); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,344 @@
+package crew
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// mobileShip resolves the ship paired with the signed in account
+func (h *handler) mobileShip(c *gin.Context) (model.User, int, bool) {
+	user, ok := authUser(c)
+	if !ok {
+		return model.User{}, 0, false
+	}
+
+	shipID, err := h.service.MobileShipID(c.Request.Context(), user)
+	if err != nil {
+		crewError(c, "Failed to retrieve ship", "no ship data for this account", err)
+		return model.User{}, 0, false
+	}
+
+	return user, shipID, true
+}
+
+func crewError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidCrewPosition, constants.CrewNotOnShip, constants.DuplicateCrewMember,
+		constants.ManifestWithoutCaptain, constants.ManifestNotCheckin:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func crewParam(c *gin.Context) dto.CrewListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.CrewListParam{
+		Offset:     offset,
+		Limit:      limit,
+		ShipID:     shipID,
+		Search:     c.DefaultQuery("search", ""),
+		Position:   c.DefaultQuery("position", ""),
+		ActiveOnly: c.DefaultQuery("active_only", "false") == "true",
+	}
+}
+
+func (h *handler) crewList(c *gin.Context, param dto.CrewListParam) {
+	res, err := h.service.CrewList(c.Request.Context(), param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve crew list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved crew list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) storeCrew(c *gin.Context, request dto.CrewRequest) {
+	if err := h.service.StoreCrew(c.Request.Context(), request); err != nil {
+		crewError(c, "Failed to store crew member", "invalid ship id, no ship data", err)
+		return
+	}
+
+	response := util.APIResponse("Crew member successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) updateCrew(c *gin.Context, request dto.CrewRequest) {
+	if err := h.service.UpdateCrew(c.Request.Context(), request); err != nil {
+		crewError(c, "Failed to update crew member", "invalid crew id, no crew data", err)
+		return
+	}
+
+	response := util.APIResponse("Crew member successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) submitManifest(c *gin.Context, user model.User, request dto.ManifestRequest) {
+	res, err := h.service.SubmitManifest(c.Request.Context(), user, request)
+	if err != nil {
+		crewError(c, "Failed to submit crew manifest", "invalid ship id, no ship data", err)
+		return
+	}
+
+	response := util.APIResponse("Crew manifest successfully submitted", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) MobileCrewList(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	param := crewParam(c)
+	param.ShipID = shipID
+
+	h.crewList(c, param)
+}
+
+func (h *handler) MobileStoreCrew(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	var request dto.CrewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+	request.ShipID = shipID
+
+	h.storeCrew(c, request)
+}
+
+func (h *handler) MobileUpdateCrew(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	var request dto.CrewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+	request.ShipID = shipID
+
+	h.updateCrew(c, request)
+}
+
+func (h *handler) MobileSubmitManifest(c *gin.Context) {
+	user, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ManifestRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+	request.ShipID = shipID
+
+	h.submitManifest(c, user, request)
+}
+
+func (h *handler) MobilePendingManifest(c *gin.Context) {
+	_, shipID, ok := h.mobileShip(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.PendingManifest(c.Request.Context(), shipID)
+	if err != nil {
+		crewError(c, "Failed to retrieve crew manifest", "no pending crew manifest", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved crew manifest", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) CrewList(c *gin.Context) {
+	h.crewList(c, crewParam(c))
+}
+
+func (h *handler) StoreCrew(c *gin.Context) {
+	var request dto.CrewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	h.storeCrew(c, request)
+}
+
+func (h *handler) UpdateCrew(c *gin.Context) {
+	var request dto.CrewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	h.updateCrew(c, request)
+}
+
+func (h *handler) SubmitManifest(c *gin.Context) {
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ManifestRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	h.submitManifest(c, user, request)
+}
+
+func (h *handler) ManifestList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ManifestListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Status:    c.DefaultQuery("status", ""),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.ManifestList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve crew manifest list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved crew manifest list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ManifestDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	manifestID, err := strconv.Atoi(c.Param("manifest_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid manifest_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ManifestDetail(ctx, manifestID)
+	if err != nil {
+		crewError(c, "Failed to retrieve crew manifest", "invalid manifest id, no manifest data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved crew manifest", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+// MissingManifests lists the checkouts no crew manifest was submitted for
+func (h *handler) MissingManifests(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.MissingManifestParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.MissingManifests(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve missing crew manifests: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved missing crew manifests", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
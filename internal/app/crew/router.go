@@ -0,0 +1,26 @@
+package crew
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/mobile/list", h.MobileCrewList)
+	g.POST("/mobile/store", h.MobileStoreCrew)
+	g.PUT("/mobile/update", h.MobileUpdateCrew)
+	g.POST("/mobile/manifest", h.MobileSubmitManifest)
+	g.GET("/mobile/manifest", h.MobilePendingManifest)
+
+	g.GET("/list", h.CrewList)
+	g.POST("/store", h.StoreCrew)
+	g.PUT("/update", h.UpdateCrew)
+
+	g.POST("/manifest/store", h.SubmitManifest)
+	g.GET("/manifest/list", h.ManifestList)
+	g.GET("/manifest/detail/:manifest_id", h.ManifestDetail)
+	g.GET("/manifest/missing", h.MissingManifests)
+}
@@ -0,0 +1,360 @@
+package crew
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var positions = []model.CrewPosition{
+	model.CrewCaptain, model.CrewOfficer, model.CrewEngineer, model.CrewDeckhand, model.CrewCook, model.CrewOther,
+}
+
+type service struct {
+	appRepository  repository.App
+	shipRepository repository.Ship
+	userRepository repository.User
+	crewRepository repository.Crew
+}
+
+type Service interface {
+	MobileShipID(ctx context.Context, authUser model.User) (int, error)
+	StoreCrew(ctx context.Context, request dto.CrewRequest) error
+	UpdateCrew(ctx context.Context, request dto.CrewRequest) error
+	CrewList(ctx context.Context, request dto.CrewListParam) (*dto.CrewResponseList, error)
+	SubmitManifest(ctx context.Context, authUser model.User, request dto.ManifestRequest) (*dto.ManifestResponse, error)
+	PendingManifest(ctx context.Context, shipID int) (*dto.ManifestResponse, error)
+	ManifestList(ctx context.Context, request dto.ManifestListParam) (*dto.ManifestResponseList, error)
+	ManifestDetail(ctx context.Context, ID int) (*dto.ManifestResponse, error)
+	MissingManifests(ctx context.Context, request dto.MissingManifestParam) (*dto.MissingManifestResponseList, error)
+	AttachManifest(ctx context.Context, shipID int, checkoutLogID int, departedAt time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:  f.AppRepository,
+		shipRepository: f.ShipRepository,
+		userRepository: f.UserRepository,
+		crewRepository: f.CrewRepository,
+	}
+}
+
+func validPosition(position model.CrewPosition) bool {
+	for _, e := range positions {
+		if e == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MobileShipID returns the ship paired with the signed in account
+func (s *service) MobileShipID(ctx context.Context, authUser model.User) (int, error) {
+	ship, err := s.shipRepository.ShipByAuth(ctx, authUser)
+	if err != nil {
+		return 0, err
+	}
+
+	return ship.ID, nil
+}
+
+func (s *service) StoreCrew(ctx context.Context, request dto.CrewRequest) error {
+	position := model.CrewPosition(request.Position)
+	if !validPosition(position) {
+		return constants.InvalidCrewPosition
+	}
+
+	if _, err := s.shipRepository.ShipByID(ctx, request.ShipID); err != nil {
+		return err
+	}
+
+	crew := model.CrewMember{
+		ShipID:   request.ShipID,
+		Name:     strings.TrimSpace(request.Name),
+		IDNumber: strings.TrimSpace(request.IDNumber),
+		Position: position,
+		Phone:    request.Phone,
+		IsActive: 1,
+	}
+
+	return s.crewRepository.StoreCrew(ctx, &crew)
+}
+
+// UpdateCrew changes a crew member of request.ShipID, members of another ship are reported as not found
+func (s *service) UpdateCrew(ctx context.Context, request dto.CrewRequest) error {
+	position := model.CrewPosition(request.Position)
+	if !validPosition(position) {
+		return constants.InvalidCrewPosition
+	}
+
+	current, err := s.crewRepository.CrewByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	if request.ShipID != 0 && current.ShipID != request.ShipID {
+		return gorm.ErrRecordNotFound
+	}
+
+	isActive := 0
+	if request.IsActive {
+		isActive = 1
+	}
+
+	crew := model.CrewMember{
+		Name:     strings.TrimSpace(request.Name),
+		IDNumber: strings.TrimSpace(request.IDNumber),
+		Position: position,
+		Phone:    request.Phone,
+		IsActive: isActive,
+	}
+	crew.ID = current.ID
+
+	return s.crewRepository.UpdateCrew(ctx, crew)
+}
+
+func (s *service) CrewList(ctx context.Context, request dto.CrewListParam) (*dto.CrewResponseList, error) {
+	total, err := s.crewRepository.CrewCount(ctx, dto.CrewListParam{ShipID: request.ShipID})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.crewRepository.CrewCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.crewRepository.CrewList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.CrewResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// SubmitManifest stores who sails on the next departure of a checked in ship, submitting
+// again before checkout replaces the pending manifest
+func (s *service) SubmitManifest(ctx context.Context, authUser model.User, request dto.ManifestRequest) (*dto.ManifestResponse, error) {
+	lastLog, err := s.shipRepository.GetLastDockedLog(ctx, request.ShipID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if lastLog == nil || lastLog.Status != string(model.Checkin) {
+		return nil, constants.ManifestNotCheckin
+	}
+
+	seen := map[int]bool{}
+	for _, ID := range request.CrewIDs {
+		if seen[ID] {
+			return nil, constants.DuplicateCrewMember
+		}
+		seen[ID] = true
+	}
+
+	crews, err := s.crewRepository.ActiveCrew(ctx, request.ShipID, request.CrewIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(crews) != len(request.CrewIDs) {
+		return nil, constants.CrewNotOnShip
+	}
+
+	hasCaptain := false
+	members := []model.CrewManifestMember{}
+	for _, e := range crews {
+		if e.Position == model.CrewCaptain {
+			hasCaptain = true
+		}
+
+		members = append(members, model.CrewManifestMember{
+			CrewMemberID: e.ID,
+			Name:         e.Name,
+			IDNumber:     e.IDNumber,
+			Position:     e.Position,
+		})
+	}
+
+	if !hasCaptain {
+		return nil, constants.ManifestWithoutCaptain
+	}
+
+	manifest := model.CrewManifest{
+		ShipID:      request.ShipID,
+		SubmittedBy: authUser.ID,
+		SubmittedAt: time.Now(),
+		Note:        request.Note,
+	}
+
+	if err := s.crewRepository.StoreManifest(ctx, &manifest, members); err != nil {
+		return nil, err
+	}
+
+	return s.crewRepository.ManifestDetail(ctx, manifest.ID)
+}
+
+func (s *service) PendingManifest(ctx context.Context, shipID int) (*dto.ManifestResponse, error) {
+	manifest, err := s.crewRepository.PendingManifest(ctx, shipID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.crewRepository.ManifestDetail(ctx, manifest.ID)
+}
+
+func (s *service) ManifestList(ctx context.Context, request dto.ManifestListParam) (*dto.ManifestResponseList, error) {
+	total, err := s.crewRepository.ManifestCount(ctx, dto.ManifestListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.crewRepository.ManifestCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.crewRepository.ManifestList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ManifestResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) ManifestDetail(ctx context.Context, ID int) (*dto.ManifestResponse, error) {
+	return s.crewRepository.ManifestDetail(ctx, ID)
+}
+
+func (s *service) MissingManifests(ctx context.Context, request dto.MissingManifestParam) (*dto.MissingManifestResponseList, error) {
+	total, err := s.crewRepository.MissingManifestCount(ctx, dto.MissingManifestParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.crewRepository.MissingManifestCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.crewRepository.MissingManifests(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.MissingManifestResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// AttachManifest is called on checkout, the pending manifest of the ship is linked to the
+// checkout log and the admins are alerted by email when there is none
+func (s *service) AttachManifest(ctx context.Context, shipID int, checkoutLogID int, departedAt time.Time) error {
+	attached, err := s.crewRepository.AttachManifest(ctx, shipID, checkoutLogID, departedAt)
+	if err != nil {
+		return err
+	}
+
+	if !attached {
+		// the alert must not hold up the location ingestion nor die with its context
+		go s.alertMissingManifest(tenant.Detach(ctx), shipID, departedAt)
+	}
+
+	return nil
+}
+
+func (s *service) alertMissingManifest(ctx context.Context, shipID int, departedAt time.Time) {
+	recipients, err := s.userRepository.AdminEmails(ctx)
+	if err != nil {
+		fmt.Println("Failed to load admin emails:", err.Error())
+		return
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, shipID)
+	if err != nil {
+		fmt.Println("Failed to load ship, Ship ID:", shipID, err.Error())
+		return
+	}
+
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		fmt.Println("Failed to load app info:", err.Error())
+		return
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_crew_manifest_missing.html")
+	if err != nil {
+		fmt.Println("Failed to parse crew manifest template:", err.Error())
+		return
+	}
+
+	title := "Ship departed without crew manifest"
+	data := struct {
+		Title           string
+		HarbourName     string
+		ShipName        string
+		ResponsibleName string
+		Phone           string
+		DepartedAt      string
+	}{
+		Title:           title,
+		HarbourName:     appInfo.HarbourName,
+		ShipName:        ship.Name,
+		ResponsibleName: ship.ResponsibleName,
+		Phone:           ship.Phone,
+		DepartedAt:      departedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		fmt.Println("Failed to render crew manifest template:", err.Error())
+		return
+	}
+
+	if err := helper.SendMail(strings.Join(recipients, ","), title+" - "+ship.Name, tplBuffer.String()); err != nil {
+		fmt.Println("Failed to send crew manifest alert, Ship ID:", shipID, err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package dashboard
 
 import (
 	"net/http"
+	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/pkg/log"
 	"owlharbour-api/pkg/util"
@@ -49,7 +50,7 @@ func (h *handler) ShipMonitorWebsocket(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	token := c.Query("token")
-	if token == "" || token != util.GetEnv("WEBSOCKET_API_KEY", "fallback") {
+	if token == "" || token != util.GetEnv("WEBSOCKET_API_KEY", "") {
 		response := util.APIResponse("token is not valid", http.StatusBadRequest, "failed", nil)
 		c.JSON(http.StatusBadRequest, response)
 		return
@@ -183,3 +184,75 @@ func (h *handler) LogsChart(c *gin.Context) {
 	response := util.APIResponse("Success get data logs chart", http.StatusOK, "success", data)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *handler) InspectionMetrics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	dateStart := c.DefaultQuery("start_date", "")
+	dateEnd := c.DefaultQuery("end_date", "")
+
+	data, err := h.service.InspectionMetrics(ctx, dateStart, dateEnd)
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := util.APIResponse("Success get data inspection metrics", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) OccupancyChart(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	dateStart := c.DefaultQuery("start_date", "")
+	dateEnd := c.DefaultQuery("end_date", "")
+	interval := c.DefaultQuery("interval", "day")
+
+	data, err := h.service.OccupancyChart(ctx, dateStart, dateEnd, interval)
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := util.APIResponse("Success get data occupancy chart", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) PeakHours(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	dateStart := c.DefaultQuery("start_date", "")
+	dateEnd := c.DefaultQuery("end_date", "")
+
+	data, err := h.service.PeakHours(ctx, dateStart, dateEnd)
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := util.APIResponse("Success get data peak hours", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StatisticSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	param := dto.DashboardSeriesParam{
+		StartDate:   c.DefaultQuery("start_date", ""),
+		EndDate:     c.DefaultQuery("end_date", ""),
+		Granularity: c.DefaultQuery("granularity", "day"),
+	}
+
+	data, err := h.service.StatisticSeries(ctx, param)
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := util.APIResponse("Success get data statistic series", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
@@ -11,7 +11,11 @@ func (h *handler) Router(g *gin.RouterGroup) {
 	g.GET("/ship-monitor/websocket", h.ShipMonitorWebsocket)
 	g.Use(middleware.Authenticate())
 	g.GET("/statistic", h.HarbourStatistic)
+	g.GET("/statistic-series", h.StatisticSeries)
 	g.GET("/terrain-chart", h.TerrainChart)
 	g.GET("/logs-chart", h.LogsChart)
 	g.GET("/lastest-dock-ship", h.LastestDockedShip)
+	g.GET("/inspection-metrics", h.InspectionMetrics)
+	g.GET("/occupancy-chart", h.OccupancyChart)
+	g.GET("/peak-hours", h.PeakHours)
 }
@@ -0,0 +1,141 @@
+package dashboard
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	granularityHour  = "hour"
+	granularityDay   = "day"
+	granularityWeek  = "week"
+	granularityMonth = "month"
+)
+
+// seriesMaxDays caps the period of every granularity to keep the number of buckets charted sane
+var seriesMaxDays = map[string]int{
+	granularityHour:  31,
+	granularityDay:   366,
+	granularityWeek:  730,
+	granularityMonth: 1830,
+}
+
+// harbourLocation returns the timezone of the harbour of ctx, see helper.HarbourLocation
+func (s *service) harbourLocation(ctx context.Context) (*time.Location, error) {
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id, timezone")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, constants.InvalidHarbour
+		}
+		return nil, err
+	}
+
+	return helper.HarbourLocation(harbour.Timezone)
+}
+
+// bucketStart truncates t to the start of its bucket the way date_trunc does, weeks start on monday
+func bucketStart(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case granularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case granularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case granularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextBucket steps by wall clock so buckets stay aligned with date_trunc across DST changes
+func nextBucket(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case granularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case granularityWeek:
+		return t.AddDate(0, 0, 7)
+	case granularityMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// StatisticSeries reports checkins, checkouts, out of scope ships, fraud fixes and active ships of
+// the period per bucket of the harbour timezone, buckets without logs are reported with zeros
+func (s *service) StatisticSeries(ctx context.Context, request dto.DashboardSeriesParam) (*dto.DashboardSeriesResponse, error) {
+	granularity := request.Granularity
+	if granularity == "" {
+		granularity = granularityDay
+	}
+
+	maxDays, ok := seriesMaxDays[granularity]
+	if !ok {
+		return nil, constants.InvalidStatisticGranularity
+	}
+
+	loc, err := s.harbourLocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", request.StartDate, loc)
+	if err != nil {
+		return nil, constants.InvalidStatisticRange
+	}
+
+	endDate, err := time.ParseInLocation("2006-01-02", request.EndDate, loc)
+	if err != nil || endDate.Before(start) || endDate.After(start.AddDate(0, 0, maxDays-1)) {
+		return nil, constants.InvalidStatisticRange
+	}
+	end := endDate.AddDate(0, 0, 1)
+
+	filter := dto.DashboardSeriesFilter{
+		Start:       start,
+		End:         end,
+		Granularity: granularity,
+		Timezone:    loc.String(),
+	}
+
+	rows, err := s.shipRepository.StatisticSeries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.DashboardSeriesResponse{
+		Granularity: granularity,
+		Timezone:    loc.String(),
+		StartDate:   request.StartDate,
+		EndDate:     request.EndDate,
+		Data:        []dto.DashboardSeriesBucket{},
+	}
+
+	// the buckets come back as wall clock times of the harbour timezone
+	counts := map[string]dto.DashboardSeriesCounts{}
+	for _, e := range rows {
+		if e.IsTotal == 1 {
+			res.Total = e.DashboardSeriesCounts
+			continue
+		}
+
+		if e.Bucket != nil {
+			counts[e.Bucket.Format("2006-01-02 15:04:05")] = e.DashboardSeriesCounts
+		}
+	}
+
+	for bucket := bucketStart(start, granularity); bucket.Before(end); bucket = nextBucket(bucket, granularity) {
+		key := bucket.Format("2006-01-02 15:04:05")
+		res.Data = append(res.Data, dto.DashboardSeriesBucket{
+			Start:                 key,
+			DashboardSeriesCounts: counts[key],
+		})
+	}
+
+	return &res, nil
+}
@@ -3,15 +3,20 @@ package dashboard
 import (
 	"context"
 	"fmt"
+	"owlharbour-api/internal/app/occupancy"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/internal/repository"
+	"time"
 )
 
 type service struct {
 	appRepository            repository.App
 	shipRepository           repository.Ship
 	pairingRequestRepository repository.PairingRequest
+	fraudCaseRepository      repository.FraudCase
+	inspectionRepository     repository.Inspection
+	occupancyService         occupancy.Service
 }
 
 type Service interface {
@@ -21,6 +26,10 @@ type Service interface {
 	TerrainChart(ctx context.Context) (*dto.ShipTerrainResponse, error)
 	LogsChart(ctx context.Context, startDate string, endDate string) (*dto.LogsStatisticResponse, error)
 	LastestDockedShip(ctx context.Context, limit int) ([]dto.DashboardLastDockedShipResponse, error)
+	InspectionMetrics(ctx context.Context, startDate string, endDate string) (*dto.InspectionMetricsResponse, error)
+	OccupancyChart(ctx context.Context, startDate string, endDate string, interval string) (*dto.OccupancyResponse, error)
+	PeakHours(ctx context.Context, startDate string, endDate string) (*dto.PeakHoursResponse, error)
+	StatisticSeries(ctx context.Context, request dto.DashboardSeriesParam) (*dto.DashboardSeriesResponse, error)
 }
 
 func NewService(f *factory.Factory) Service {
@@ -28,6 +37,9 @@ func NewService(f *factory.Factory) Service {
 		appRepository:            f.AppRepository,
 		shipRepository:           f.ShipRepository,
 		pairingRequestRepository: f.PairingRequestRepository,
+		fraudCaseRepository:      f.FraudCaseRepository,
+		inspectionRepository:     f.InspectionRepository,
+		occupancyService:         occupancy.NewService(f),
 	}
 }
 
@@ -40,6 +52,20 @@ func (s *service) LastestDockedShip(ctx context.Context, limit int) ([]dto.Dashb
 	return res, nil
 }
 
+// InspectionMetrics reports the inspection queue of the check-ins between the dates, all check-ins without dates
+func (s *service) InspectionMetrics(ctx context.Context, startDate string, endDate string) (*dto.InspectionMetricsResponse, error) {
+	return s.inspectionRepository.TaskMetrics(ctx, startDate, endDate, time.Now())
+}
+
+// OccupancyChart reports the ships inside the harbour per hour or day between the dates
+func (s *service) OccupancyChart(ctx context.Context, startDate string, endDate string, interval string) (*dto.OccupancyResponse, error) {
+	return s.occupancyService.Timeline(ctx, dto.OccupancyParam{StartDate: startDate, EndDate: endDate, Interval: interval})
+}
+
+func (s *service) PeakHours(ctx context.Context, startDate string, endDate string) (*dto.PeakHoursResponse, error) {
+	return s.occupancyService.PeakHours(ctx, dto.OccupancyParam{StartDate: startDate, EndDate: endDate})
+}
+
 func (s *service) LogsChart(ctx context.Context, startDate string, endDate string) (*dto.LogsStatisticResponse, error) {
 	checkin, err := s.shipRepository.CountShipByStatus(ctx, startDate, endDate, "checkin")
 	if err != nil {
@@ -135,11 +161,19 @@ func (s *service) GetStatistic(ctx context.Context) (*dto.DashboardStatisticResp
 		return nil, err
 	}
 
+	countCase, err := s.fraudCaseRepository.CountOpenFraudCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	res := dto.DashboardStatisticResponse{
 		TotalShip:     int(countShip),
 		TotalCheckin:  int(countStatistic[0]),
 		TotalCheckout: int(countStatistic[1]),
 		TotalFraud:    int(countStatistic[2]),
+		OpenCase:      int(countCase.Open),
+		ReviewingCase: int(countCase.Reviewing),
+		EscalatedCase: int(countCase.Escalated),
 	}
 
 	return &res, nil
@@ -0,0 +1,177 @@
+package document
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+func documentError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidDocumentType, constants.InvalidDocumentDate, constants.DocumentAttachmentMismatch:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func documentParam(c *gin.Context) dto.ShipDocumentListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+	expiringDays, _ := strconv.Atoi(c.DefaultQuery("expiring_days", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.ShipDocumentListParam{
+		Offset:       offset,
+		Limit:        limit,
+		ShipID:       shipID,
+		Type:         c.DefaultQuery("type", ""),
+		Status:       c.DefaultQuery("status", ""),
+		Search:       c.DefaultQuery("search", ""),
+		ExpiringDays: expiringDays,
+	}
+}
+
+func (h *handler) documentList(c *gin.Context, param dto.ShipDocumentListParam) {
+	res, err := h.service.DocumentList(c.Request.Context(), param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve ship document list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship document list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) MobileDocumentList(c *gin.Context) {
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	shipID, err := h.service.MobileShipID(c.Request.Context(), user)
+	if err != nil {
+		documentError(c, "Failed to retrieve ship", "no ship data for this account", err)
+		return
+	}
+
+	param := documentParam(c)
+	param.ShipID = shipID
+
+	h.documentList(c, param)
+}
+
+func (h *handler) DocumentList(c *gin.Context) {
+	h.documentList(c, documentParam(c))
+}
+
+func (h *handler) StoreDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.ShipDocumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreDocument(ctx, request); err != nil {
+		documentError(c, "Failed to store ship document", "invalid ship or attachment id, no data", err)
+		return
+	}
+
+	response := util.APIResponse("Ship document successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.ShipDocumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateDocument(ctx, request); err != nil {
+		documentError(c, "Failed to update ship document", "invalid document or attachment id, no data", err)
+		return
+	}
+
+	response := util.APIResponse("Ship document successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) DeleteDocument(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	documentID, err := strconv.Atoi(c.Param("document_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid document_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.service.DeleteDocument(ctx, documentID); err != nil {
+		documentError(c, "Failed to delete ship document", "invalid document id, no document data", err)
+		return
+	}
+
+	response := util.APIResponse("Ship document successfully deleted", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
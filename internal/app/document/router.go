@@ -0,0 +1,18 @@
+package document
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/mobile/list", h.MobileDocumentList)
+
+	g.GET("/list", h.DocumentList)
+	g.POST("/store", h.StoreDocument)
+	g.PUT("/update", h.UpdateDocument)
+	g.DELETE("/:document_id", h.DeleteDocument)
+}
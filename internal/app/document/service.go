@@ -0,0 +1,363 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"owlharbour-api/pkg/util"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const defaultReminderDays = "30,7,1"
+
+var documentTypes = []model.DocumentType{
+	model.DocumentSIUP, model.DocumentBKP, model.DocumentSelarMark, model.DocumentSeaworthiness, model.DocumentOther,
+}
+
+// licenseTypes are the documents a ship is not allowed to operate without
+var licenseTypes = []model.DocumentType{model.DocumentSIUP, model.DocumentBKP}
+
+type service struct {
+	appRepository          repository.App
+	shipRepository         repository.Ship
+	userRepository         repository.User
+	attachmentRepository   repository.Attachment
+	shipDocumentRepository repository.ShipDocument
+}
+
+type Service interface {
+	MobileShipID(ctx context.Context, authUser model.User) (int, error)
+	StoreDocument(ctx context.Context, request dto.ShipDocumentRequest) error
+	UpdateDocument(ctx context.Context, request dto.ShipDocumentRequest) error
+	DeleteDocument(ctx context.Context, ID int) error
+	DocumentList(ctx context.Context, request dto.ShipDocumentListParam) (*dto.ShipDocumentResponseList, error)
+	ScanExpiry(ctx context.Context, now time.Time) error
+	CheckinWarning(ctx context.Context, shipID int, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:          f.AppRepository,
+		shipRepository:         f.ShipRepository,
+		userRepository:         f.UserRepository,
+		attachmentRepository:   f.AttachmentRepository,
+		shipDocumentRepository: f.ShipDocumentRepository,
+	}
+}
+
+// reminderDays returns the reminder thresholds in days before expiry from the largest down,
+// the expiry day itself is always the last threshold
+func reminderDays() []int {
+	var days []int
+	for _, value := range strings.Split(util.GetEnv("DOCUMENT_REMINDER_DAYS", defaultReminderDays), ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil && day > 0 {
+			days = append(days, day)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+
+	return append(days, 0)
+}
+
+// reminderStage returns the smallest threshold reached with daysLeft, expired documents are at stage 0
+func reminderStage(thresholds []int, daysLeft int) int {
+	stage := thresholds[0]
+	for _, threshold := range thresholds {
+		if daysLeft <= threshold {
+			stage = threshold
+		}
+	}
+
+	return stage
+}
+
+func validDocumentType(documentType model.DocumentType) bool {
+	for _, e := range documentTypes {
+		if e == documentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *service) MobileShipID(ctx context.Context, authUser model.User) (int, error) {
+	ship, err := s.shipRepository.ShipByAuth(ctx, authUser)
+	if err != nil {
+		return 0, err
+	}
+
+	return ship.ID, nil
+}
+
+// documentData validates the request for a document of shipID
+func (s *service) documentData(ctx context.Context, shipID int, request dto.ShipDocumentRequest) (model.ShipDocument, error) {
+	documentType := model.DocumentType(request.Type)
+	if !validDocumentType(documentType) {
+		return model.ShipDocument{}, constants.InvalidDocumentType
+	}
+
+	issuedAt, err := time.ParseInLocation("2006-01-02", request.IssuedAt, time.Local)
+	if err != nil {
+		return model.ShipDocument{}, constants.InvalidDocumentDate
+	}
+
+	var expiresAt *time.Time
+	if request.ExpiresAt != "" {
+		expiry, err := time.ParseInLocation("2006-01-02", request.ExpiresAt, time.Local)
+		if err != nil || !expiry.After(issuedAt) {
+			return model.ShipDocument{}, constants.InvalidDocumentDate
+		}
+		expiresAt = &expiry
+	}
+
+	if request.AttachmentID != nil {
+		attachment, err := s.attachmentRepository.AttachmentByID(ctx, *request.AttachmentID)
+		if err != nil {
+			return model.ShipDocument{}, err
+		}
+
+		if attachment.OwnerType != model.OwnerShip || attachment.OwnerID != shipID {
+			return model.ShipDocument{}, constants.DocumentAttachmentMismatch
+		}
+	}
+
+	return model.ShipDocument{
+		ShipID:       shipID,
+		Type:         documentType,
+		Number:       strings.TrimSpace(request.Number),
+		Issuer:       strings.TrimSpace(request.Issuer),
+		IssuedAt:     issuedAt,
+		ExpiresAt:    expiresAt,
+		AttachmentID: request.AttachmentID,
+	}, nil
+}
+
+func (s *service) StoreDocument(ctx context.Context, request dto.ShipDocumentRequest) error {
+	if _, err := s.shipRepository.ShipByID(ctx, request.ShipID); err != nil {
+		return err
+	}
+
+	document, err := s.documentData(ctx, request.ShipID, request)
+	if err != nil {
+		return err
+	}
+
+	return s.shipDocumentRepository.StoreDocument(ctx, &document)
+}
+
+// UpdateDocument corrects or renews a document, its reminders start over when the expiry date changes
+func (s *service) UpdateDocument(ctx context.Context, request dto.ShipDocumentRequest) error {
+	current, err := s.shipDocumentRepository.DocumentByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	document, err := s.documentData(ctx, current.ShipID, request)
+	if err != nil {
+		return err
+	}
+
+	document.ID = current.ID
+	document.RemindedDays = current.RemindedDays
+	document.RemindedAt = current.RemindedAt
+
+	if !sameDate(current.ExpiresAt, document.ExpiresAt) {
+		document.RemindedDays = nil
+		document.RemindedAt = nil
+	}
+
+	return s.shipDocumentRepository.UpdateDocument(ctx, document)
+}
+
+func sameDate(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+func (s *service) DeleteDocument(ctx context.Context, ID int) error {
+	return s.shipDocumentRepository.DeleteDocument(ctx, ID)
+}
+
+func (s *service) DocumentList(ctx context.Context, request dto.ShipDocumentListParam) (*dto.ShipDocumentResponseList, error) {
+	now := time.Now()
+
+	if request.ExpiringDays <= 0 {
+		request.ExpiringDays = reminderDays()[0]
+	}
+
+	total, err := s.shipDocumentRepository.DocumentCount(ctx, dto.ShipDocumentListParam{ShipID: request.ShipID}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipDocumentRepository.DocumentCount(ctx, request, now)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.shipDocumentRepository.DocumentList(ctx, request, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ShipDocumentResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// ScanExpiry sends the reminders of documents which reached a new threshold, every document
+// gets one reminder per threshold however often the scan runs
+func (s *service) ScanExpiry(ctx context.Context, now time.Time) error {
+	thresholds := reminderDays()
+
+	documents, err := s.shipDocumentRepository.DueReminders(ctx, thresholds[0], now)
+	if err != nil {
+		return err
+	}
+
+	// the admins of each harbour are mailed the documents of their own ships
+	reminded := map[int][]dto.ShipDocumentResponse{}
+	var harbourIDs []int
+	for _, document := range documents {
+		stage := reminderStage(thresholds, *document.DaysLeft)
+		if document.RemindedDays != nil && *document.RemindedDays <= stage {
+			continue
+		}
+
+		s.notifyShip(document)
+
+		if err := s.shipDocumentRepository.MarkReminded(ctx, document.ID, stage, now); err != nil {
+			return err
+		}
+
+		if _, ok := reminded[document.HarbourID]; !ok {
+			harbourIDs = append(harbourIDs, document.HarbourID)
+		}
+		reminded[document.HarbourID] = append(reminded[document.HarbourID], document)
+	}
+
+	for _, harbourID := range harbourIDs {
+		err := s.mailAdmins(tenant.WithHarbours(ctx, harbourID), "Ship documents expiring",
+			"The ship documents below expire soon or have expired and need to be renewed.", reminded[harbourID])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckinWarning warns the ship and the admins when a ship checks in with expired licenses
+func (s *service) CheckinWarning(ctx context.Context, shipID int, now time.Time) error {
+	expired, err := s.shipDocumentRepository.ExpiredDocuments(ctx, shipID, licenseTypes, now)
+	if err != nil {
+		return err
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, e := range expired {
+		names = append(names, strings.ToUpper(e.Type))
+	}
+
+	if token := expired[0].FirebaseToken; token != "" {
+		notificationData := map[string]interface{}{
+			"title": "OWLHARBOUR - EXPIRED LICENSE",
+			"body":  "Ship checked in with expired " + strings.Join(names, ", ") + ", please renew before the next departure",
+		}
+
+		if _, err := helper.PushNotification(notificationData, []string{token}); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	return s.mailAdmins(ctx, "Ship checked in with expired licenses", "The ship below checked in while its licenses are expired.", expired)
+}
+
+func (s *service) notifyShip(document dto.ShipDocumentResponse) {
+	if document.FirebaseToken == "" {
+		return
+	}
+
+	body := strings.ToUpper(document.Type) + " " + document.Number + " expires on " + document.ExpiresAt
+	if *document.DaysLeft < 0 {
+		body = strings.ToUpper(document.Type) + " " + document.Number + " expired on " + document.ExpiresAt
+	}
+
+	notificationData := map[string]interface{}{
+		"title": "OWLHARBOUR - DOCUMENT EXPIRY",
+		"body":  body,
+	}
+
+	if _, err := helper.PushNotification(notificationData, []string{document.FirebaseToken}); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func (s *service) mailAdmins(ctx context.Context, title string, message string, documents []dto.ShipDocumentResponse) error {
+	recipients, err := s.userRepository.AdminEmails(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_ship_document.html")
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Title       string
+		Message     string
+		HarbourName string
+		Documents   []dto.ShipDocumentResponse
+	}{
+		Title:       title,
+		Message:     message,
+		HarbourName: appInfo.HarbourName,
+		Documents:   documents,
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		return err
+	}
+
+	return helper.SendMail(strings.Join(recipients, ","), title, tplBuffer.String())
+}
@@ -0,0 +1,31 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const scanInterval = 24 * time.Hour
+
+// WorkerExpiry scans the ship documents for expiry reminders on start and then once a day,
+// reminders are tracked per document so a restart does not send them twice
+func (h *handler) WorkerExpiry(ctx context.Context) {
+	fmt.Println("[*] Ship document expiry worker started. To exit press CTRL+C")
+
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.service.ScanExpiry(ctx, time.Now()); err != nil {
+			fmt.Println("[*] Failed to scan ship document expiry:", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Context cancelled, exiting WorkerExpiry")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package fraudcase
+
+import (
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+)
+
+type fraudLogGroup struct {
+	Case   model.FraudCase
+	LogIDs []int
+}
+
+// groupFraudLogs turns every run of consecutive suspicious fixes without a case into one case,
+// a clean fix or a fix already belonging to a case ends the run
+func groupFraudLogs(logs []model.ShipLocationLog) []fraudLogGroup {
+	var groups []fraudLogGroup
+	var current *fraudLogGroup
+
+	for _, log := range logs {
+		suspicious := log.IsMocked == 1 || log.IsFraud == 1
+		if !suspicious || log.FraudCaseID != nil {
+			if current != nil {
+				groups = append(groups, *current)
+				current = nil
+			}
+			continue
+		}
+
+		score := log.FraudScore
+		reason := log.FraudReason
+		// fixes stored before server side scoring only carry the client flag
+		if log.IsMocked == 1 && reason == "" {
+			score = 100
+			reason = string(model.FraudMocked)
+		}
+
+		if current == nil {
+			current = &fraudLogGroup{
+				Case: model.FraudCase{
+					ShipID:     log.ShipID,
+					Status:     model.CaseOpen,
+					FirstLogID: log.ID,
+					StartedAt:  log.CreatedAt,
+				},
+			}
+		}
+
+		current.LogIDs = append(current.LogIDs, log.ID)
+		current.Case.LastLogID = log.ID
+		current.Case.LastSeenAt = log.CreatedAt
+		current.Case.FixCount++
+		if score > current.Case.MaxScore {
+			current.Case.MaxScore = score
+		}
+		current.Case.Reasons = helper.MergeCommaList(current.Case.Reasons, reason)
+	}
+
+	if current != nil {
+		groups = append(groups, *current)
+	}
+
+	return groups
+}
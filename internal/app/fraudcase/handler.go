@@ -0,0 +1,239 @@
+package fraudcase
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// actionError maps validation failures of a case action to a bad request
+func actionError(c *gin.Context, message string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse("invalid case id, no fraud case data", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidFraudCaseStatus, constants.InvalidFraudCaseTransition, constants.InvalidSanctionType,
+		constants.FraudCaseClosed, constants.UserNotFound, constants.InvalidCaseAssignee:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (h *handler) FraudCaseList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+	assigneeID, _ := strconv.Atoi(c.DefaultQuery("assignee_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.FraudCaseListParam{
+		Offset:     offset,
+		Limit:      limit,
+		ShipID:     shipID,
+		AssigneeID: assigneeID,
+		Status:     strings.Split(c.DefaultQuery("status", ""), ","),
+		Search:     c.DefaultQuery("search", ""),
+		StartDate:  c.DefaultQuery("start_date", ""),
+		EndDate:    c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.FraudCaseList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve fraud case list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved fraud case list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) FraudCaseDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	caseID, err := strconv.Atoi(c.Param("case_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid case_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.FraudCaseDetail(ctx, caseID)
+	if err != nil {
+		actionError(c, "Failed to retrieve fraud case data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved fraud case data", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.FraudCaseStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateStatus(ctx, user, request); err != nil {
+		actionError(c, "Failed to update fraud case status", err)
+		return
+	}
+
+	response := util.APIResponse("Fraud case status successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) Assign(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.FraudCaseAssignRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.Assign(ctx, user, request); err != nil {
+		actionError(c, "Failed to assign fraud case", err)
+		return
+	}
+
+	response := util.APIResponse("Fraud case successfully assigned", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AddNote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.FraudCaseNoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.AddNote(ctx, user, request); err != nil {
+		actionError(c, "Failed to add fraud case note", err)
+		return
+	}
+
+	response := util.APIResponse("Fraud case note successfully added", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) Sanction(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.FraudCaseSanctionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.Sanction(ctx, user, request); err != nil {
+		actionError(c, "Failed to sanction fraud case", err)
+		return
+	}
+
+	response := util.APIResponse("Sanction successfully sent", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) RebuildCases(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.FraudCaseRebuildRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		errors := util.FormatValidationError(err)
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": errors})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.RebuildCases(ctx, request)
+	if err != nil {
+		response := util.APIResponse("Failed to rebuild fraud cases: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Fraud cases successfully rebuilt", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
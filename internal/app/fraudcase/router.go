@@ -0,0 +1,19 @@
+package fraudcase
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/list", h.FraudCaseList)
+	g.GET("/detail/:case_id", h.FraudCaseDetail)
+	g.PUT("/status", h.UpdateStatus)
+	g.PUT("/assign", h.Assign)
+	g.POST("/note", h.AddNote)
+	g.POST("/sanction", h.Sanction)
+	g.POST("/rebuild", h.RebuildCases)
+}
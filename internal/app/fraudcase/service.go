@@ -0,0 +1,319 @@
+package fraudcase
+
+import (
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"time"
+)
+
+// caseTransitions lists the statuses a case may move to from its current status,
+// closed cases can only be reopened
+var caseTransitions = map[model.FraudCaseStatus][]model.FraudCaseStatus{
+	model.CaseOpen:      {model.CaseReviewing, model.CaseEscalated, model.CaseDismissed},
+	model.CaseReviewing: {model.CaseEscalated, model.CaseDismissed, model.CaseResolved},
+	model.CaseEscalated: {model.CaseResolved, model.CaseDismissed},
+	model.CaseDismissed: {model.CaseOpen},
+	model.CaseResolved:  {model.CaseOpen},
+}
+
+type service struct {
+	appRepository       repository.App
+	shipRepository      repository.Ship
+	userRepository      repository.User
+	fraudCaseRepository repository.FraudCase
+}
+
+type Service interface {
+	FraudCaseList(ctx context.Context, request dto.FraudCaseListParam) (*dto.FraudCaseResponseList, error)
+	FraudCaseDetail(ctx context.Context, ID int) (*dto.FraudCaseDetailResponse, error)
+	UpdateStatus(ctx context.Context, authUser model.User, request dto.FraudCaseStatusRequest) error
+	Assign(ctx context.Context, authUser model.User, request dto.FraudCaseAssignRequest) error
+	AddNote(ctx context.Context, authUser model.User, request dto.FraudCaseNoteRequest) error
+	Sanction(ctx context.Context, authUser model.User, request dto.FraudCaseSanctionRequest) error
+	RebuildCases(ctx context.Context, request dto.FraudCaseRebuildRequest) (*dto.FraudCaseRebuildResponse, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:       f.AppRepository,
+		shipRepository:      f.ShipRepository,
+		userRepository:      f.UserRepository,
+		fraudCaseRepository: f.FraudCaseRepository,
+	}
+}
+
+func (s *service) FraudCaseList(ctx context.Context, request dto.FraudCaseListParam) (*dto.FraudCaseResponseList, error) {
+	total, err := s.fraudCaseRepository.FraudCaseCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.fraudCaseRepository.FraudCaseList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.FraudCaseResponseList{
+		Total: int(total),
+		Data:  fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) FraudCaseDetail(ctx context.Context, ID int) (*dto.FraudCaseDetailResponse, error) {
+	fraudCase, err := s.fraudCaseRepository.FraudCaseDetail(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	fixes, err := s.fraudCaseRepository.FraudCaseFixes(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.fraudCaseRepository.FraudCaseActivities(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.FraudCaseDetailResponse{
+		FraudCaseResponse: *fraudCase,
+		Fixes:             fixes,
+		Activities:        activities,
+	}
+
+	return &res, nil
+}
+
+func (s *service) UpdateStatus(ctx context.Context, authUser model.User, request dto.FraudCaseStatusRequest) error {
+	next := model.FraudCaseStatus(request.Status)
+	if _, ok := caseTransitions[next]; !ok {
+		return constants.InvalidFraudCaseStatus
+	}
+
+	fraudCase, err := s.fraudCaseRepository.FraudCaseByID(ctx, request.CaseID)
+	if err != nil {
+		return err
+	}
+
+	if !canTransition(fraudCase.Status, next) {
+		return constants.InvalidFraudCaseTransition
+	}
+
+	updateFields := map[string]interface{}{
+		"status": next,
+	}
+
+	if isClosed(next) {
+		updateFields["closed_at"] = time.Now()
+	} else {
+		updateFields["closed_at"] = nil
+	}
+
+	activity := model.FraudCaseActivity{
+		UserID:     &authUser.ID,
+		Action:     model.CaseActionStatus,
+		FromStatus: fraudCase.Status,
+		ToStatus:   next,
+		Note:       request.Note,
+	}
+
+	return s.fraudCaseRepository.UpdateFraudCase(ctx, fraudCase.ID, updateFields, activity)
+}
+
+func (s *service) Assign(ctx context.Context, authUser model.User, request dto.FraudCaseAssignRequest) error {
+	fraudCase, err := s.fraudCaseRepository.FraudCaseByID(ctx, request.CaseID)
+	if err != nil {
+		return err
+	}
+
+	updateFields := map[string]interface{}{
+		"assignee_id": nil,
+	}
+	note := "Unassigned"
+
+	// assignee_id 0 removes the current assignee
+	if request.AssigneeID != 0 {
+		assignee, err := s.userRepository.FindOne(ctx, "id,name,role", "id = ?", request.AssigneeID)
+		if err != nil {
+			return constants.UserNotFound
+		}
+
+		if assignee.Role != model.Admin && assignee.Role != model.SuperAdmin {
+			return constants.InvalidCaseAssignee
+		}
+
+		// admins only review the cases of ships in the harbours they are assigned to
+		if assignee.Role == model.Admin {
+			ship, err := s.shipRepository.ShipByID(ctx, fraudCase.ShipID)
+			if err != nil {
+				return err
+			}
+
+			if _, err := s.userRepository.FindScoped(tenant.WithHarbours(ctx, ship.HarbourID), "id", assignee.ID); err != nil {
+				return constants.InvalidCaseAssignee
+			}
+		}
+
+		updateFields["assignee_id"] = assignee.ID
+		note = "Assigned to " + assignee.Name
+	}
+
+	activity := model.FraudCaseActivity{
+		UserID:     &authUser.ID,
+		Action:     model.CaseActionAssign,
+		FromStatus: fraudCase.Status,
+		ToStatus:   fraudCase.Status,
+		Note:       note,
+	}
+
+	return s.fraudCaseRepository.UpdateFraudCase(ctx, fraudCase.ID, updateFields, activity)
+}
+
+func (s *service) AddNote(ctx context.Context, authUser model.User, request dto.FraudCaseNoteRequest) error {
+	fraudCase, err := s.fraudCaseRepository.FraudCaseByID(ctx, request.CaseID)
+	if err != nil {
+		return err
+	}
+
+	activity := model.FraudCaseActivity{
+		FraudCaseID: fraudCase.ID,
+		UserID:      &authUser.ID,
+		Action:      model.CaseActionNote,
+		FromStatus:  fraudCase.Status,
+		ToStatus:    fraudCase.Status,
+		Note:        request.Note,
+	}
+
+	return s.fraudCaseRepository.StoreFraudCaseActivity(ctx, activity)
+}
+
+// Sanction records the sanction on the case and notifies the ship crew through their device
+func (s *service) Sanction(ctx context.Context, authUser model.User, request dto.FraudCaseSanctionRequest) error {
+	sanction := model.SanctionType(request.Type)
+	if sanction != model.SanctionWarning && sanction != model.SanctionSummon {
+		return constants.InvalidSanctionType
+	}
+
+	fraudCase, err := s.fraudCaseRepository.FraudCaseByID(ctx, request.CaseID)
+	if err != nil {
+		return err
+	}
+
+	if isClosed(fraudCase.Status) {
+		return constants.FraudCaseClosed
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, fraudCase.ShipID)
+	if err != nil {
+		return err
+	}
+
+	appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, ship.HarbourID))
+	if err != nil {
+		return err
+	}
+
+	message := request.Message
+	if message == "" {
+		message = sanctionMessage(sanction, appInfo.HarbourName)
+	}
+
+	notificationData := map[string]interface{}{
+		"title": sanctionTitle(sanction),
+		"body":  message,
+	}
+	tokens := []string{ship.FirebaseToken}
+
+	_, err = helper.PushNotification(notificationData, tokens)
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	now := time.Now()
+	updateFields := map[string]interface{}{
+		"sanction":      sanction,
+		"sanctioned_at": now,
+	}
+
+	activity := model.FraudCaseActivity{
+		UserID:     &authUser.ID,
+		Action:     model.CaseActionSanction,
+		FromStatus: fraudCase.Status,
+		ToStatus:   fraudCase.Status,
+		Note:       string(sanction) + ": " + message,
+	}
+
+	return s.fraudCaseRepository.UpdateFraudCase(ctx, fraudCase.ID, updateFields, activity)
+}
+
+// RebuildCases groups suspicious fixes recorded before case tracking existed (or missed by
+// ingestion) into cases, fixes already belonging to a case are left untouched
+func (s *service) RebuildCases(ctx context.Context, request dto.FraudCaseRebuildRequest) (*dto.FraudCaseRebuildResponse, error) {
+	var err error
+
+	shipIDs := []int{request.ShipID}
+	if request.ShipID == 0 {
+		shipIDs, err = s.fraudCaseRepository.ShipIDsWithUncasedFraud(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := dto.FraudCaseRebuildResponse{}
+	for _, shipID := range shipIDs {
+		logs, err := s.fraudCaseRepository.ShipFraudFlags(ctx, shipID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range groupFraudLogs(logs) {
+			if err := s.fraudCaseRepository.StoreFraudCase(ctx, &group.Case, group.LogIDs); err != nil {
+				return nil, err
+			}
+			res.Cases++
+		}
+
+		res.Ships++
+	}
+
+	return &res, nil
+}
+
+func canTransition(from model.FraudCaseStatus, to model.FraudCaseStatus) bool {
+	for _, allowed := range caseTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isClosed(status model.FraudCaseStatus) bool {
+	return status == model.CaseDismissed || status == model.CaseResolved
+}
+
+func sanctionTitle(sanction model.SanctionType) string {
+	if sanction == model.SanctionSummon {
+		return "OWLHARBOUR - HARBOUR OFFICE SUMMONS"
+	}
+
+	return "OWLHARBOUR - FRAUD WARNING"
+}
+
+func sanctionMessage(sanction model.SanctionType, harbourName string) string {
+	if sanction == model.SanctionSummon {
+		return "Please report to the " + harbourName + " Harbour office regarding suspicious location data sent by this ship"
+	}
+
+	return "Suspicious location data was detected from this ship, falsifying the ship position violates " + harbourName + " Harbour regulations"
+}
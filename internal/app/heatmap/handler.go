@@ -0,0 +1,87 @@
+package heatmap
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func heatmapError(c *gin.Context, message string, err error) {
+	switch err {
+	case constants.InvalidHeatmapRange, constants.InvalidHeatmapPrecision, constants.InvalidHeatmapFilter:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// Density returns the fix counts per geohash cell as a GeoJSON feature collection
+func (h *handler) Density(c *gin.Context) {
+	precision, err := strconv.Atoi(c.DefaultQuery("precision", "0"))
+	if err != nil {
+		heatmapError(c, "Failed to retrieve ship density", constants.InvalidHeatmapPrecision)
+		return
+	}
+
+	param := dto.HeatmapParam{
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+		Precision: precision,
+		ShipType:  c.DefaultQuery("ship_type", ""),
+		OnGround:  c.DefaultQuery("on_ground", ""),
+	}
+
+	res, err := h.service.Density(c.Request.Context(), param)
+	if err != nil {
+		heatmapError(c, "Failed to retrieve ship density", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship density", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) Rollup(c *gin.Context) {
+	var request dto.HeatmapRollupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.Rollup(c.Request.Context(), request)
+	if err != nil {
+		heatmapError(c, "Failed to roll up ship positions", err)
+		return
+	}
+
+	response := util.APIResponse("Ship positions successfully rolled up", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
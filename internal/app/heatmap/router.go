@@ -0,0 +1,14 @@
+package heatmap
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/density", h.Density)
+	g.POST("/rollup", h.Rollup)
+}
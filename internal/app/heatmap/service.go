@@ -0,0 +1,436 @@
+package heatmap
+
+import (
+	"context"
+	"math"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// rollups keep cells of about 150 x 150 metres, coarser cells are prefixes of them
+	rollupPrecision  = 7
+	defaultPrecision = 5
+	minPrecision     = 3
+
+	maxHeatmapDays = 366
+)
+
+type service struct {
+	harbourRepository repository.Harbour
+	heatmapRepository repository.Heatmap
+}
+
+type Service interface {
+	Density(ctx context.Context, request dto.HeatmapParam) (*dto.GeoJSONFeatureCollection, error)
+	Rollup(ctx context.Context, request dto.HeatmapRollupRequest) (*dto.HeatmapRollupResponse, error)
+	RollupPending(ctx context.Context, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		harbourRepository: f.HarbourRepository,
+		heatmapRepository: f.HeatmapRepository,
+	}
+}
+
+type rollupKey struct {
+	HarbourID int
+	ShipID    int
+	OnGround  int
+	Geohash   string
+}
+
+type harbourZone struct {
+	ID       int
+	Location *time.Location
+}
+
+// calendarDate returns the calendar day of t as a midnight in UTC, the way postgres hands dates
+// back. Rollups are kept per calendar day of the zone of their harbour
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayStart returns the midnight of the calendar day in loc
+func dayStart(day time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+}
+
+// dateRange parses inclusive YYYY-MM-DD dates into calendar days, end is exclusive
+func dateRange(startDate string, endDate string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, constants.InvalidHeatmapRange
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.UTC)
+	if err != nil || end.Before(start) || end.After(start.AddDate(0, 0, maxHeatmapDays-1)) {
+		return time.Time{}, time.Time{}, constants.InvalidHeatmapRange
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+func heatmapFilter(request dto.HeatmapParam) (dto.HeatmapFilter, error) {
+	start, end, err := dateRange(request.StartDate, request.EndDate)
+	if err != nil {
+		return dto.HeatmapFilter{}, err
+	}
+
+	precision := request.Precision
+	if precision == 0 {
+		precision = defaultPrecision
+	}
+
+	if precision < minPrecision || precision > rollupPrecision {
+		return dto.HeatmapFilter{}, constants.InvalidHeatmapPrecision
+	}
+
+	filter := dto.HeatmapFilter{
+		Start:     start,
+		End:       end,
+		Precision: precision,
+	}
+
+	if request.ShipType != "" {
+		shipType := model.ShipType(request.ShipType)
+		if shipType != model.KapalAngkut && shipType != model.KapalTangkap {
+			return dto.HeatmapFilter{}, constants.InvalidHeatmapFilter
+		}
+		filter.ShipType = request.ShipType
+	}
+
+	if request.OnGround != "" {
+		onGround, err := strconv.Atoi(request.OnGround)
+		if err != nil || (onGround != 0 && onGround != 1) {
+			return dto.HeatmapFilter{}, constants.InvalidHeatmapFilter
+		}
+		filter.OnGround = &onGround
+	}
+
+	return filter, nil
+}
+
+// harbours returns the active harbours visible to ctx with their zone
+func (s *service) harbours(ctx context.Context) ([]harbourZone, error) {
+	harbours, err := s.harbourRepository.ActiveHarbours(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, scoped := tenant.Harbours(ctx)
+	visible := map[int]bool{}
+	for _, id := range ids {
+		visible[id] = true
+	}
+
+	var res []harbourZone
+	for _, h := range harbours {
+		if scoped && !visible[h.ID] {
+			continue
+		}
+
+		loc, err := helper.HarbourLocation(h.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, harbourZone{ID: h.ID, Location: loc})
+	}
+
+	return res, nil
+}
+
+// fixCell returns the geohash of a fix, ok is false for coordinates which can not be placed
+func fixCell(fix dto.HeatmapFix, precision int) (string, bool) {
+	lat, err := strconv.ParseFloat(fix.Lat, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return "", false
+	}
+
+	long, err := strconv.ParseFloat(fix.Long, 64)
+	if err != nil || long < -180 || long > 180 {
+		return "", false
+	}
+
+	return helper.GeohashEncode(lat, long, precision), true
+}
+
+// Density bins the genuine fixes of the range into geohash cells, the rolled up days are read from
+// the rollups and the days the worker has not reached yet, today included, from the location logs.
+// The days of every harbour are cut in its own zone
+func (s *service) Density(ctx context.Context, request dto.HeatmapParam) (*dto.GeoJSONFeatureCollection, error) {
+	filter, err := heatmapFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	liveFrom := filter.Start
+	last, err := s.heatmapRepository.LastRollupDate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if last != nil {
+		if next := calendarDate(*last).AddDate(0, 0, 1); next.After(liveFrom) {
+			liveFrom = next
+		}
+	}
+
+	if liveFrom.After(filter.End) {
+		liveFrom = filter.End
+	}
+
+	cells := map[string]map[int]int{}
+	add := func(geohash string, shipID int, fixes int) {
+		if cells[geohash] == nil {
+			cells[geohash] = map[int]int{}
+		}
+		cells[geohash][shipID] += fixes
+	}
+
+	if liveFrom.After(filter.Start) {
+		rolled := filter
+		rolled.End = liveFrom
+
+		rollups, err := s.heatmapRepository.RollupCells(ctx, rolled)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range rollups {
+			add(e.Geohash, e.ShipID, e.Fixes)
+		}
+	}
+
+	if filter.End.After(liveFrom) {
+		harbours, err := s.harbours(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range harbours {
+			live := filter
+			live.Start = dayStart(liveFrom, h.Location)
+			live.End = dayStart(filter.End, h.Location)
+
+			err := s.heatmapRepository.EachFix(tenant.WithHarbours(ctx, h.ID), live, func(fix dto.HeatmapFix) error {
+				if geohash, ok := fixCell(fix, filter.Precision); ok {
+					add(geohash, fix.ShipID, 1)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return densityGeoJSON(cells), nil
+}
+
+func densityGeoJSON(cells map[string]map[int]int) *dto.GeoJSONFeatureCollection {
+	geohashes := make([]string, 0, len(cells))
+	fixes := map[string]int{}
+	maxFixes := 0
+	for geohash, ships := range cells {
+		geohashes = append(geohashes, geohash)
+		for _, n := range ships {
+			fixes[geohash] += n
+		}
+
+		if fixes[geohash] > maxFixes {
+			maxFixes = fixes[geohash]
+		}
+	}
+	sort.Strings(geohashes)
+
+	res := dto.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []dto.GeoJSONFeature{},
+	}
+
+	for _, geohash := range geohashes {
+		southWest, northEast, ok := helper.GeohashBounds(geohash)
+		if !ok {
+			continue
+		}
+
+		// GeoJSON positions are [longitude, latitude], the ring is closed on its first corner
+		ring := [][]float64{
+			{southWest.Long, southWest.Lat},
+			{northEast.Long, southWest.Lat},
+			{northEast.Long, northEast.Lat},
+			{southWest.Long, northEast.Lat},
+			{southWest.Long, southWest.Lat},
+		}
+
+		res.Features = append(res.Features, dto.GeoJSONFeature{
+			Type: "Feature",
+			Geometry: dto.GeoJSONGeometry{
+				Type:        "Polygon",
+				Coordinates: [][][]float64{ring},
+			},
+			Properties: map[string]interface{}{
+				"geohash":   geohash,
+				"fixes":     fixes[geohash],
+				"ships":     len(cells[geohash]),
+				"intensity": math.Round(float64(fixes[geohash])/float64(maxFixes)*10000) / 10000,
+			},
+		})
+	}
+
+	return &res
+}
+
+// rollupDay replaces the rollups of the harbour for the calendar day with the fixes of the
+// location logs, the day runs from midnight to midnight in the zone of the harbour
+func (s *service) rollupDay(ctx context.Context, harbour harbourZone, day time.Time) (int, error) {
+	ctx = tenant.WithHarbours(ctx, harbour.ID)
+
+	start := dayStart(day, harbour.Location)
+	filter := dto.HeatmapFilter{
+		Start:     start,
+		End:       start.AddDate(0, 0, 1),
+		Precision: rollupPrecision,
+	}
+
+	counts := map[rollupKey]int{}
+	err := s.heatmapRepository.EachFix(ctx, filter, func(fix dto.HeatmapFix) error {
+		if geohash, ok := fixCell(fix, rollupPrecision); ok {
+			counts[rollupKey{HarbourID: fix.HarbourID, ShipID: fix.ShipID, OnGround: fix.OnGround, Geohash: geohash}]++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	rollups := make([]model.ShipPositionRollup, 0, len(counts))
+	for key, fixes := range counts {
+		rollups = append(rollups, model.ShipPositionRollup{
+			HarbourID: key.HarbourID,
+			ShipID:    key.ShipID,
+			Date:      day,
+			Geohash:   key.Geohash,
+			OnGround:  key.OnGround,
+			Fixes:     fixes,
+		})
+	}
+
+	if err := s.heatmapRepository.ReplaceRollups(ctx, day, rollups); err != nil {
+		return 0, err
+	}
+
+	return len(rollups), nil
+}
+
+// Rollup rolls the days of the range up again, for location logs corrected after their day was
+// rolled up. Days the worker has not reached yet are left to it
+func (s *service) Rollup(ctx context.Context, request dto.HeatmapRollupRequest) (*dto.HeatmapRollupResponse, error) {
+	start, end, err := dateRange(request.StartDate, request.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.HeatmapRollupResponse{}
+
+	last, err := s.heatmapRepository.LastRollupDate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if last == nil {
+		return &res, nil
+	}
+
+	if next := calendarDate(*last).AddDate(0, 0, 1); end.After(next) {
+		end = next
+	}
+
+	harbours, err := s.harbours(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, h := range harbours {
+			cells, err := s.rollupDay(ctx, h, day)
+			if err != nil {
+				return nil, err
+			}
+
+			res.Cells += cells
+		}
+
+		res.Days++
+	}
+
+	return &res, nil
+}
+
+// RollupPending rolls up every completed day from the last rolled up one, starting from the first
+// location log when nothing was rolled up yet. A day is rolled up for every harbour at once when
+// it is over in all their zones, the last day is rolled up again in case a run stopped half way
+func (s *service) RollupPending(ctx context.Context, now time.Time) error {
+	harbours, err := s.harbours(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(harbours) == 0 {
+		return nil
+	}
+
+	var from time.Time
+
+	last, err := s.heatmapRepository.LastRollupDate(ctx)
+	if err != nil {
+		return err
+	}
+
+	if last != nil {
+		from = calendarDate(*last)
+	} else {
+		first, err := s.heatmapRepository.FirstFixDate(ctx)
+		if err != nil {
+			return err
+		}
+
+		if first == nil {
+			return nil
+		}
+
+		for i, h := range harbours {
+			if day := calendarDate(first.In(h.Location)); i == 0 || day.Before(from) {
+				from = day
+			}
+		}
+	}
+
+	var today time.Time
+	for i, h := range harbours {
+		if day := calendarDate(now.In(h.Location)); i == 0 || day.Before(today) {
+			today = day
+		}
+	}
+
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		for _, h := range harbours {
+			if _, err := s.rollupDay(ctx, h, day); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package heatmap
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const rollupInterval = time.Hour
+
+// WorkerRollup rolls up the completed days of location logs on start and then every hour, the
+// rollups pick up from the last rolled up day so a missed run is caught up on the next one
+func (h *handler) WorkerRollup(ctx context.Context) {
+	fmt.Println("[*] Ship position rollup worker started. To exit press CTRL+C")
+
+	ticker := time.NewTicker(rollupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.service.RollupPending(ctx, time.Now()); err != nil {
+			fmt.Println("[*] Failed to roll up ship positions:", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Context cancelled, exiting WorkerRollup")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package inspection
+
+import (
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"strings"
+)
+
+// templateFromRequest validates a template request, items keep the order they are sent in
+func templateFromRequest(request dto.InspectionTemplateRequest) (model.InspectionTemplate, []model.InspectionTemplateItem, error) {
+	template := model.InspectionTemplate{
+		Name:        strings.TrimSpace(request.Name),
+		Description: request.Description,
+		IsActive:    1,
+	}
+
+	if request.IsActive != nil && !*request.IsActive {
+		template.IsActive = 0
+	}
+
+	var items []model.InspectionTemplateItem
+	for i, item := range request.Items {
+		category := model.ChecklistCategory(item.Category)
+		switch category {
+		case model.CategorySafetyGear, model.CategoryLicense, model.CategoryCatch, model.CategoryOther:
+		default:
+			return template, nil, constants.InvalidChecklistCategory
+		}
+
+		isRequired := 0
+		if item.IsRequired {
+			isRequired = 1
+		}
+
+		items = append(items, model.InspectionTemplateItem{
+			Category:    category,
+			Label:       strings.TrimSpace(item.Label),
+			Description: item.Description,
+			IsRequired:  isRequired,
+			Sort:        i + 1,
+		})
+	}
+
+	if len(items) == 0 {
+		return template, nil, constants.InspectionTemplateEmpty
+	}
+
+	return template, items, nil
+}
+
+// checklistResults matches the submitted results to the template items. Every required item needs
+// a result, optional items left out are recorded as not applicable.
+func checklistResults(items []model.InspectionTemplateItem, request []dto.InspectionResultRequest) ([]model.InspectionResult, error) {
+	submitted := make(map[int]dto.InspectionResultRequest)
+	for _, result := range request {
+		submitted[result.ItemID] = result
+	}
+
+	itemIDs := make(map[int]bool)
+	for _, item := range items {
+		itemIDs[item.ID] = true
+	}
+
+	for ID := range submitted {
+		if !itemIDs[ID] {
+			return nil, constants.InvalidChecklistItem
+		}
+	}
+
+	var results []model.InspectionResult
+	for _, item := range items {
+		res := model.InspectionResult{
+			InspectionTemplateItemID: item.ID,
+			Category:                 item.Category,
+			Label:                    item.Label,
+			IsRequired:               item.IsRequired,
+			Result:                   model.ResultNotApplicable,
+		}
+
+		result, ok := submitted[item.ID]
+		if !ok {
+			if item.IsRequired == 1 {
+				return nil, constants.ChecklistIncomplete
+			}
+			results = append(results, res)
+			continue
+		}
+
+		res.Result = model.ChecklistResult(result.Result)
+		res.Note = strings.TrimSpace(result.Note)
+
+		switch res.Result {
+		case model.ResultPass, model.ResultFail:
+		case model.ResultNotApplicable:
+			if item.IsRequired == 1 {
+				return nil, constants.ChecklistIncomplete
+			}
+		default:
+			return nil, constants.InvalidChecklistResult
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// checklistOutcome fails the inspection on a failed required item, a failed optional item only makes it conditional
+func checklistOutcome(results []model.InspectionResult) model.InspectionOutcome {
+	outcome := model.OutcomePassed
+
+	for _, result := range results {
+		if result.Result != model.ResultFail {
+			continue
+		}
+
+		if result.IsRequired == 1 {
+			return model.OutcomeFailed
+		}
+
+		outcome = model.OutcomeConditional
+	}
+
+	return outcome
+}
+
+// outcomeSeverity orders the outcomes from passed to failed
+func outcomeSeverity(outcome model.InspectionOutcome) int {
+	switch outcome {
+	case model.OutcomeConditional:
+		return 1
+	case model.OutcomeFailed:
+		return 2
+	}
+
+	return 0
+}
+
+func validOutcome(outcome model.InspectionOutcome) bool {
+	switch outcome {
+	case model.OutcomePassed, model.OutcomeConditional, model.OutcomeFailed:
+		return true
+	}
+
+	return false
+}
@@ -5,8 +5,11 @@ import (
 	"net/http"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/util"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -28,18 +31,21 @@ func (h *handler) NeedCheckupShip(c *gin.Context) {
 	offsetParam := c.DefaultQuery("offset", "0")
 	limitParam := c.DefaultQuery("limit", "25")
 	searchParam := c.DefaultQuery("search", "")
+	assigneeParam := c.DefaultQuery("assignee_id", "0")
 
 	offset, _ := strconv.Atoi(offsetParam)
 	limit, _ := strconv.Atoi(limitParam)
+	assigneeID, _ := strconv.Atoi(assigneeParam)
 
 	if limit == 0 {
 		limit = 10
 	}
 
 	param := dto.NeedCheckupShipParam{
-		Offset: offset,
-		Limit:  limit,
-		Search: searchParam,
+		Offset:     offset,
+		Limit:      limit,
+		Search:     searchParam,
+		AssigneeID: assigneeID,
 	}
 
 	data, err := h.service.NeedCheckupShip(ctx, param)
@@ -89,3 +95,274 @@ func (h *handler) UpdateShipCheckup(c *gin.Context) {
 	response := util.APIResponse("Successfully updated ship checkup data", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// inspectionError maps validation failures of templates and inspections to a bad request
+func inspectionError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InspectionNotCheckin, constants.InspectionExists, constants.InspectionTemplateInactive,
+		constants.InspectionTemplateEmpty, constants.InvalidChecklistCategory, constants.InvalidChecklistItem,
+		constants.InvalidChecklistResult, constants.ChecklistIncomplete, constants.InvalidInspectionOutcome,
+		constants.InspectionOutcomeLenient, constants.InvalidInspectionTime, constants.InvalidInspector, constants.InspectionTaskDone:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (h *handler) TemplateList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.InspectionTemplateListParam{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.DefaultQuery("search", ""),
+	}
+
+	res, err := h.service.TemplateList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve inspection template list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved inspection template list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) TemplateDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	templateID, err := strconv.Atoi(c.Param("template_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid template_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.TemplateDetail(ctx, templateID)
+	if err != nil {
+		inspectionError(c, "Failed to retrieve inspection template", "invalid template id, no template data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved inspection template", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.InspectionTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreTemplate(ctx, request); err != nil {
+		inspectionError(c, "Failed to store inspection template", "invalid template id, no template data", err)
+		return
+	}
+
+	response := util.APIResponse("Inspection template successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.InspectionTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if request.ID == 0 {
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": "id is required"})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.service.UpdateTemplate(ctx, request); err != nil {
+		inspectionError(c, "Failed to update inspection template", "invalid template id, no template data", err)
+		return
+	}
+
+	response := util.APIResponse("Inspection template successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreInspection(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.InspectionStoreRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreInspection(ctx, user, request); err != nil {
+		inspectionError(c, "Failed to store inspection", "invalid docked log or template id, no data", err)
+		return
+	}
+
+	response := util.APIResponse("Inspection successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) InspectionList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+	inspectorID, _ := strconv.Atoi(c.DefaultQuery("inspector_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.InspectionListParam{
+		Offset:      offset,
+		Limit:       limit,
+		ShipID:      shipID,
+		InspectorID: inspectorID,
+		Outcome:     strings.Split(c.DefaultQuery("outcome", ""), ","),
+		Search:      c.DefaultQuery("search", ""),
+		StartDate:   c.DefaultQuery("start_date", ""),
+		EndDate:     c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.InspectionList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve inspection list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved inspection list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) InspectionDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	inspectionID, err := strconv.Atoi(c.Param("inspection_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid inspection_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.InspectionDetail(ctx, inspectionID)
+	if err != nil {
+		inspectionError(c, "Failed to retrieve inspection data", "invalid inspection id, no inspection data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved inspection data", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) TaskList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	assigneeID, _ := strconv.Atoi(c.DefaultQuery("assignee_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	// mine lists the queue of the signed in inspector
+	if c.DefaultQuery("mine", "false") == "true" {
+		assigneeID = user.ID
+	}
+
+	param := dto.InspectionTaskListParam{
+		Offset:     offset,
+		Limit:      limit,
+		Search:     c.DefaultQuery("search", ""),
+		AssigneeID: assigneeID,
+		Status:     strings.Split(c.DefaultQuery("status", ""), ","),
+		Overdue:    c.DefaultQuery("overdue", "false") == "true",
+	}
+
+	res, err := h.service.TaskList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve inspection task list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved inspection task list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AssignTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.InspectionTaskAssignRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.AssignTask(ctx, request); err != nil {
+		inspectionError(c, "Failed to assign inspection task", "invalid task id, no task data", err)
+		return
+	}
+
+	response := util.APIResponse("Inspection task successfully assigned", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -11,4 +11,16 @@ func (h *handler) Router(g *gin.RouterGroup) {
 
 	g.GET("/", h.NeedCheckupShip)
 	g.PUT("/update-checkup/:log_id", h.UpdateShipCheckup)
+
+	g.GET("/template/list", h.TemplateList)
+	g.GET("/template/detail/:template_id", h.TemplateDetail)
+	g.POST("/template/store", h.StoreTemplate)
+	g.PUT("/template/update", h.UpdateTemplate)
+
+	g.POST("/store", h.StoreInspection)
+	g.GET("/list", h.InspectionList)
+	g.GET("/detail/:inspection_id", h.InspectionDetail)
+
+	g.GET("/task/list", h.TaskList)
+	g.PUT("/task/assign", h.AssignTask)
 }
@@ -5,31 +5,74 @@ import (
 	"fmt"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
 	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/pagination"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type service struct {
-	shipRepository repository.Ship
+	appRepository        repository.App
+	shipRepository       repository.Ship
+	userRepository       repository.User
+	inspectionRepository repository.Inspection
 }
 
 type Service interface {
 	UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupRequest, id int) error
-	NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipParam) ([]dto.NeedCheckupShipResponse, error)
+	NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipParam) (*dto.NeedCheckupShipResponseList, error)
+	TemplateList(ctx context.Context, request dto.InspectionTemplateListParam) (*dto.InspectionTemplateResponseList, error)
+	TemplateDetail(ctx context.Context, ID int) (*dto.InspectionTemplateResponse, error)
+	StoreTemplate(ctx context.Context, request dto.InspectionTemplateRequest) error
+	UpdateTemplate(ctx context.Context, request dto.InspectionTemplateRequest) error
+	StoreInspection(ctx context.Context, authUser model.User, request dto.InspectionStoreRequest) error
+	InspectionList(ctx context.Context, request dto.InspectionListParam) (*dto.InspectionResponseList, error)
+	InspectionDetail(ctx context.Context, ID int) (*dto.InspectionDetailResponse, error)
+	OpenTask(ctx context.Context, dockedLogID int, shipID int, checkinAt time.Time) error
+	AssignTask(ctx context.Context, request dto.InspectionTaskAssignRequest) error
+	TaskList(ctx context.Context, request dto.InspectionTaskListParam) (*dto.InspectionTaskResponseList, error)
+	NotifyOverdue(ctx context.Context, now time.Time) error
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		shipRepository: f.ShipRepository,
+		appRepository:        f.AppRepository,
+		shipRepository:       f.ShipRepository,
+		userRepository:       f.UserRepository,
+		inspectionRepository: f.InspectionRepository,
 	}
 }
 
-func (s *service) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipParam) ([]dto.NeedCheckupShipResponse, error) {
-	res, err := s.shipRepository.NeedCheckupShip(ctx, request)
+func (s *service) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipParam) (*dto.NeedCheckupShipResponseList, error) {
+	total, err := s.shipRepository.NeedCheckupShipCount(ctx, dto.NeedCheckupShipParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipRepository.NeedCheckupShipCount(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	fetch, err := s.shipRepository.NeedCheckupShip(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.NeedCheckupShipResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
 }
 
 func (s *service) UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupRequest, id int) error {
@@ -55,3 +98,206 @@ func (s *service) UpdateShipCheckup(ctx context.Context, request dto.ShipCheckup
 
 	return nil
 }
+
+func (s *service) TemplateList(ctx context.Context, request dto.InspectionTemplateListParam) (*dto.InspectionTemplateResponseList, error) {
+	total, err := s.inspectionRepository.TemplateCount(ctx, dto.InspectionTemplateListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.inspectionRepository.TemplateCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.inspectionRepository.TemplateList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InspectionTemplateResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) TemplateDetail(ctx context.Context, ID int) (*dto.InspectionTemplateResponse, error) {
+	template, err := s.inspectionRepository.TemplateByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.inspectionRepository.TemplateItems(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InspectionTemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		IsActive:    template.IsActive == 1,
+		ItemCount:   len(items),
+		CreatedAt:   template.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, item := range items {
+		res.Items = append(res.Items, dto.InspectionTemplateItemResponse{
+			ID:          item.ID,
+			Category:    string(item.Category),
+			Label:       item.Label,
+			Description: item.Description,
+			IsRequired:  item.IsRequired == 1,
+			Sort:        item.Sort,
+		})
+	}
+
+	return &res, nil
+}
+
+func (s *service) StoreTemplate(ctx context.Context, request dto.InspectionTemplateRequest) error {
+	template, items, err := templateFromRequest(request)
+	if err != nil {
+		return err
+	}
+
+	return s.inspectionRepository.StoreTemplate(ctx, &template, items)
+}
+
+func (s *service) UpdateTemplate(ctx context.Context, request dto.InspectionTemplateRequest) error {
+	template, items, err := templateFromRequest(request)
+	if err != nil {
+		return err
+	}
+
+	template.ID = request.ID
+
+	return s.inspectionRepository.UpdateTemplate(ctx, template, items)
+}
+
+// StoreInspection records the checklist of a check-in docked log, a docked log is inspected once
+func (s *service) StoreInspection(ctx context.Context, authUser model.User, request dto.InspectionStoreRequest) error {
+	log, err := s.shipRepository.FindOneDockedLog(ctx, "id, ship_id, status", "id = ?", request.DockedLogID)
+	if err != nil {
+		return err
+	}
+
+	if log.Status != model.Checkin {
+		return constants.InspectionNotCheckin
+	}
+
+	if _, err := s.inspectionRepository.InspectionByDockedLog(ctx, log.ID); err == nil {
+		return constants.InspectionExists
+	} else if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	template, err := s.inspectionRepository.TemplateByID(ctx, request.TemplateID)
+	if err != nil {
+		return err
+	}
+
+	if template.IsActive == 0 {
+		return constants.InspectionTemplateInactive
+	}
+
+	items, err := s.inspectionRepository.TemplateItems(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+
+	results, err := checklistResults(items, request.Results)
+	if err != nil {
+		return err
+	}
+
+	// the inspector may judge the ship stricter than its checklist, never more leniently
+	outcome := checklistOutcome(results)
+	if request.Outcome != "" {
+		requested := model.InspectionOutcome(request.Outcome)
+		if !validOutcome(requested) {
+			return constants.InvalidInspectionOutcome
+		}
+
+		if outcomeSeverity(requested) < outcomeSeverity(outcome) {
+			return constants.InspectionOutcomeLenient
+		}
+
+		outcome = requested
+	}
+
+	now := time.Now()
+	startedAt := now
+	if request.StartedAt != "" {
+		startedAt, err = time.ParseInLocation("2006-01-02 15:04:05", request.StartedAt, time.Local)
+		if err != nil || startedAt.After(now) {
+			return constants.InvalidInspectionTime
+		}
+	}
+
+	inspection := model.Inspection{
+		ShipDockedLogID:      log.ID,
+		ShipID:               log.ShipID,
+		InspectionTemplateID: template.ID,
+		InspectorID:          authUser.ID,
+		Outcome:              outcome,
+		Findings:             strings.TrimSpace(request.Findings),
+		StartedAt:            startedAt,
+		CompletedAt:          now,
+	}
+
+	return s.inspectionRepository.StoreInspection(ctx, &inspection, results)
+}
+
+func (s *service) InspectionList(ctx context.Context, request dto.InspectionListParam) (*dto.InspectionResponseList, error) {
+	total, err := s.inspectionRepository.InspectionCount(ctx, dto.InspectionListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.inspectionRepository.InspectionCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.inspectionRepository.InspectionList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InspectionResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) InspectionDetail(ctx context.Context, ID int) (*dto.InspectionDetailResponse, error) {
+	inspection, err := s.inspectionRepository.InspectionDetail(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.inspectionRepository.InspectionResults(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InspectionDetailResponse{
+		InspectionResponse: *inspection,
+		Results:            results,
+	}
+
+	return &res, nil
+}
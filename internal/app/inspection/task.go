@@ -0,0 +1,211 @@
+package inspection
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultSlaHours = 24
+
+// taskSettings returns the assignment mode and SLA of the harbour, unset settings fall back to round robin within a day
+func taskSettings(appInfo *dto.AppInfo) (model.InspectionAssignMode, time.Duration) {
+	mode := model.InspectionAssignMode(appInfo.InspectionAssign)
+	if mode != model.AssignManual {
+		mode = model.AssignRoundRobin
+	}
+
+	slaHours := appInfo.InspectionSlaHours
+	if slaHours <= 0 {
+		slaHours = defaultSlaHours
+	}
+
+	return mode, time.Duration(slaHours) * time.Hour
+}
+
+// OpenTask creates the inspection task of a check-in, due the SLA after the check-in time
+func (s *service) OpenTask(ctx context.Context, dockedLogID int, shipID int, checkinAt time.Time) error {
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	mode, sla := taskSettings(appInfo)
+
+	task := model.InspectionTask{
+		ShipDockedLogID: dockedLogID,
+		ShipID:          shipID,
+		AssignMode:      mode,
+		Status:          model.TaskOpen,
+		CheckinAt:       checkinAt,
+		DueAt:           checkinAt.Add(sla),
+	}
+
+	if err := s.inspectionRepository.StoreTask(ctx, &task, mode == model.AssignRoundRobin); err != nil {
+		return err
+	}
+
+	if task.ID != 0 && task.AssigneeID != nil {
+		go s.notifyAssignee(context.Background(), task.ID, "New inspection task", "A ship checked in and the inspection was assigned to you.")
+	}
+
+	return nil
+}
+
+func (s *service) AssignTask(ctx context.Context, request dto.InspectionTaskAssignRequest) error {
+	task, err := s.inspectionRepository.TaskByID(ctx, request.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status == model.TaskDone {
+		return constants.InspectionTaskDone
+	}
+
+	inspector, err := s.userRepository.FindOne(ctx, "id, role", "id = ?", request.AssigneeID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.InvalidInspector
+		}
+		return err
+	}
+
+	if inspector.Role != model.Admin && inspector.Role != model.SuperAdmin {
+		return constants.InvalidInspector
+	}
+
+	// admins only inspect the ships of the harbours they are assigned to
+	if inspector.Role == model.Admin {
+		if _, err := s.userRepository.FindScoped(ctx, "id", inspector.ID); err != nil {
+			return constants.InvalidInspector
+		}
+	}
+
+	if err := s.inspectionRepository.AssignTask(ctx, task.ID, inspector.ID, time.Now()); err != nil {
+		return err
+	}
+
+	go s.notifyAssignee(context.Background(), task.ID, "Inspection task assigned", "An inspection was assigned to you.")
+
+	return nil
+}
+
+func (s *service) TaskList(ctx context.Context, request dto.InspectionTaskListParam) (*dto.InspectionTaskResponseList, error) {
+	now := time.Now()
+
+	total, err := s.inspectionRepository.TaskCount(ctx, dto.InspectionTaskListParam{}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.inspectionRepository.TaskCount(ctx, request, now)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.inspectionRepository.TaskList(ctx, request, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InspectionTaskResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// NotifyOverdue reminds the inspectors of their overdue tasks, once per task and assignee
+func (s *service) NotifyOverdue(ctx context.Context, now time.Time) error {
+	tasks, err := s.inspectionRepository.OverdueTasks(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, task.HarbourID))
+		if err != nil {
+			return err
+		}
+
+		if err := s.sendTaskMail(appInfo, task, "Inspection task overdue", "The inspection below passed its due time and is still open."); err != nil {
+			fmt.Println("Failed to send overdue inspection mail, Task ID:", task.ID, err.Error())
+			continue
+		}
+
+		if err := s.inspectionRepository.MarkOverdueNotified(ctx, task.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) notifyAssignee(ctx context.Context, taskID int, title string, message string) {
+	task, err := s.inspectionRepository.TaskDetail(ctx, taskID, time.Now())
+	if err != nil {
+		fmt.Println("Failed to load inspection task, Task ID:", taskID, err.Error())
+		return
+	}
+
+	appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, task.HarbourID))
+	if err != nil {
+		fmt.Println("Failed to load app info:", err.Error())
+		return
+	}
+
+	if err := s.sendTaskMail(appInfo, *task, title, message); err != nil {
+		fmt.Println("Failed to send inspection task mail, Task ID:", taskID, err.Error())
+	}
+}
+
+func (s *service) sendTaskMail(appInfo *dto.AppInfo, task dto.InspectionTaskResponse, title string, message string) error {
+	if task.AssigneeEmail == "" {
+		return nil
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_inspection_task.html")
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Title        string
+		Message      string
+		HarbourName  string
+		AssigneeName string
+		ShipName     string
+		CheckinAt    string
+		DueAt        string
+	}{
+		Title:        title,
+		Message:      message,
+		HarbourName:  appInfo.HarbourName,
+		AssigneeName: task.AssigneeName,
+		ShipName:     task.ShipName,
+		CheckinAt:    task.CheckinAt,
+		DueAt:        task.DueAt,
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		return err
+	}
+
+	return helper.SendMail(task.AssigneeEmail, title+" - "+task.ShipName, tplBuffer.String())
+}
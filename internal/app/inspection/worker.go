@@ -0,0 +1,30 @@
+package inspection
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const overdueInterval = time.Minute
+
+// WorkerOverdue reminds the inspectors of overdue inspection tasks every minute
+func (h *handler) WorkerOverdue(ctx context.Context) {
+	fmt.Println("[*] Inspection overdue worker started. To exit press CTRL+C")
+
+	ticker := time.NewTicker(overdueInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.service.NotifyOverdue(ctx, time.Now()); err != nil {
+			fmt.Println("[*] Failed to notify overdue inspection tasks:", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Context cancelled, exiting WorkerOverdue")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package invoice
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func invoiceError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.TariffZoneTaken, constants.InvoiceNotDraft, constants.InvoiceNotIssued, constants.InvoiceCannotVoid,
+		constants.InvalidPaymentAmount, constants.InvalidPaymentTime, constants.InvalidAsOfDate, constants.InvalidHarbour:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func invoiceID(c *gin.Context) (int, bool) {
+	ID, err := strconv.Atoi(c.Param("invoice_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid invoice_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return 0, false
+	}
+
+	return ID, true
+}
+
+func (h *handler) TariffList(c *gin.Context) {
+	res, err := h.service.TariffList(c.Request.Context())
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve port tariff list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved port tariff list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreTariff(c *gin.Context) {
+	var request dto.TariffRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreTariff(c.Request.Context(), request); err != nil {
+		invoiceError(c, "Failed to store port tariff", "invalid harbour, no harbour data", err)
+		return
+	}
+
+	response := util.APIResponse("Port tariff successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateTariff(c *gin.Context) {
+	var request dto.TariffRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateTariff(c.Request.Context(), request); err != nil {
+		invoiceError(c, "Failed to update port tariff", "invalid tariff id, no tariff data", err)
+		return
+	}
+
+	response := util.APIResponse("Port tariff successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// GenerateInvoices drafts the missing invoices of the stays which ended in the date range
+func (h *handler) GenerateInvoices(c *gin.Context) {
+	var request dto.InvoiceGenerateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.GenerateInvoices(c.Request.Context(), request)
+	if err != nil {
+		invoiceError(c, "Failed to generate invoices", "no stay data", err)
+		return
+	}
+
+	response := util.APIResponse("Invoices successfully generated", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) InvoiceList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.InvoiceListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Status:    c.DefaultQuery("status", ""),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.InvoiceList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve invoice list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved invoice list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) InvoiceDetail(c *gin.Context) {
+	ID, ok := invoiceID(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.InvoiceDetail(c.Request.Context(), ID)
+	if err != nil {
+		invoiceError(c, "Failed to retrieve invoice", "invalid invoice id, no invoice data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved invoice", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) InvoicePDF(c *gin.Context) {
+	ID, ok := invoiceID(c)
+	if !ok {
+		return
+	}
+
+	// render into memory first so a failure can still be answered with a json error
+	var buf bytes.Buffer
+	filename, err := h.service.InvoicePDF(c.Request.Context(), ID, &buf)
+	if err != nil {
+		invoiceError(c, "Failed to generate invoice", "invalid invoice id, no invoice data", err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (h *handler) IssueInvoice(c *gin.Context) {
+	var request dto.InvoiceActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.IssueInvoice(c.Request.Context(), request)
+	if err != nil {
+		invoiceError(c, "Failed to issue invoice", "invalid invoice id, no invoice data", err)
+		return
+	}
+
+	response := util.APIResponse("Invoice successfully issued", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) VoidInvoice(c *gin.Context) {
+	var request dto.InvoiceActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.VoidInvoice(c.Request.Context(), request); err != nil {
+		invoiceError(c, "Failed to void invoice", "invalid invoice id, no invoice data", err)
+		return
+	}
+
+	response := util.APIResponse("Invoice successfully voided", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) RecordPayment(c *gin.Context) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	var request dto.PaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	res, err := h.service.RecordPayment(c.Request.Context(), authUser, request)
+	if err != nil {
+		invoiceError(c, "Failed to record payment", "invalid invoice id, no invoice data", err)
+		return
+	}
+
+	response := util.APIResponse("Payment successfully recorded", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,183 @@
+package invoice
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin    = 15.0
+	pdfRowHeight = 7.0
+)
+
+// formatAmount writes an amount with thousands separators and two decimals, e.g. 1,250,000.00
+func formatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	cents := int64(math.Round(amount * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var parts []string
+	for len(whole) > 3 {
+		parts = append([]string{whole[len(whole)-3:]}, parts...)
+		whole = whole[:len(whole)-3]
+	}
+	parts = append([]string{whole}, parts...)
+
+	return fmt.Sprintf("%s%s.%02d", sign, strings.Join(parts, ","), cents%100)
+}
+
+func formatQuantity(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// renderInvoice writes the invoice of one stay as A4 PDF, drafts are marked as such since they
+// carry no number yet
+func renderInvoice(w io.Writer, harbourName string, harbourCode int, invoice *dto.InvoiceResponse) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle("Invoice "+invoice.Number, true)
+	pdf.SetCreator("owlharbour-api", true)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	generatedAt := time.Now().Format("2006-01-02 15:04")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-(pdfMargin + 3))
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, translate("Generated "+generatedAt+" - "+harbourName+" Harbour"), "", 0, "L", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(20, 40, 80)
+	pdf.CellFormat(contentWidth/2, 10, translate(strings.ToUpper(harbourName)+" HARBOUR"), "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentWidth/2, 10, "INVOICE", "", 1, "R", false, 0, "")
+
+	number := invoice.Number
+	if number == "" {
+		number = "DRAFT"
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(60, 60, 60)
+	pdf.CellFormat(contentWidth/2, 6, translate(fmt.Sprintf("Harbour code %d", harbourCode)), "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentWidth/2, 6, translate("No. "+number), "", 1, "R", false, 0, "")
+	pdf.CellFormat(contentWidth/2, 6, "", "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentWidth/2, 6, translate("Status "+strings.ToUpper(invoice.Status)), "", 1, "R", false, 0, "")
+	if invoice.IssuedAt != "" {
+		pdf.CellFormat(contentWidth/2, 6, "", "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth/2, 6, translate("Issued "+invoice.IssuedAt), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetDrawColor(20, 40, 80)
+	pdf.SetLineWidth(0.5)
+	pdf.Line(pdfMargin, pdf.GetY()+2, pageWidth-pdfMargin, pdf.GetY()+2)
+	pdf.Ln(6)
+
+	details := [][2]string{
+		{"Ship", invoice.ShipName},
+		{"Owner", invoice.OwnerName},
+		{"Responsible", invoice.ResponsibleName},
+		{"Berth", invoice.BerthName},
+		{"Zone type", invoice.ZoneType},
+		{"Arrived", invoice.ArrivedAt},
+		{"Departed", invoice.DepartedAt},
+		{"Gross tonnage", formatQuantity(invoice.GT)},
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(30, 30, 30)
+	for _, item := range details {
+		if item[1] == "" {
+			continue
+		}
+		pdf.CellFormat(40, 6, translate(item[0]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth-40, 6, translate(item[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	widths := []float64{contentWidth - 105, 30, 35, 40}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(20, 40, 80)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetLineWidth(0.2)
+	for i, title := range []string{"Description", "Quantity", "Rate", "Amount"} {
+		pdf.CellFormat(widths[i], pdfRowHeight, title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	tonnage := invoice.GT * float64(invoice.Days)
+	lines := [][4]string{
+		{"Port entry fee", "1", formatAmount(invoice.BaseFee), formatAmount(invoice.BaseFee)},
+		{"Docking", fmt.Sprintf("%d day(s)", invoice.Days), formatAmount(invoice.DailyRate), formatAmount(float64(invoice.Days) * invoice.DailyRate)},
+		{"Tonnage (GT x days)", formatQuantity(tonnage), formatAmount(invoice.RatePerGT), formatAmount(tonnage * invoice.RatePerGT)},
+	}
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(30, 30, 30)
+	for _, line := range lines {
+		pdf.CellFormat(widths[0], pdfRowHeight, translate(line[0]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], pdfRowHeight, line[1], "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], pdfRowHeight, line[2], "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], pdfRowHeight, line[3], "1", 1, "R", false, 0, "")
+	}
+
+	totals := [][2]string{
+		{"Total", formatAmount(invoice.Amount)},
+		{"Paid", formatAmount(invoice.PaidAmount)},
+		{"Outstanding", formatAmount(invoice.Outstanding)},
+	}
+
+	for i, total := range totals {
+		style := ""
+		if i == 0 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(contentWidth-widths[3], pdfRowHeight, total[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], pdfRowHeight, total[1], "B", 1, "R", false, 0, "")
+	}
+
+	if len(invoice.Payments) > 0 {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(20, 40, 80)
+		pdf.CellFormat(0, 8, "Payments", "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(30, 30, 30)
+		for _, payment := range invoice.Payments {
+			pdf.CellFormat(45, pdfRowHeight, payment.PaidAt, "B", 0, "L", false, 0, "")
+			pdf.CellFormat(contentWidth-85, pdfRowHeight, translate(strings.TrimSpace(payment.Method+" "+payment.Reference)), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(40, pdfRowHeight, formatAmount(payment.Amount), "B", 1, "R", false, 0, "")
+		}
+	}
+
+	if invoice.Status == string(model.InvoiceVoid) {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(180, 30, 30)
+		pdf.MultiCell(0, 6, translate("VOID "+invoice.VoidedAt+" "+invoice.VoidReason), "", "L", false)
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,23 @@
+package invoice
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/tariff/list", h.TariffList)
+	g.POST("/tariff/store", h.StoreTariff)
+	g.PUT("/tariff/update", h.UpdateTariff)
+
+	g.POST("/generate", h.GenerateInvoices)
+	g.GET("/list", h.InvoiceList)
+	g.GET("/detail/:invoice_id", h.InvoiceDetail)
+	g.GET("/pdf/:invoice_id", h.InvoicePDF)
+	g.PUT("/issue", h.IssueInvoice)
+	g.PUT("/void", h.VoidInvoice)
+	g.POST("/payment", h.RecordPayment)
+}
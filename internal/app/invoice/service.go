@@ -0,0 +1,439 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type service struct {
+	appRepository     repository.App
+	shipRepository    repository.Ship
+	invoiceRepository repository.Invoice
+}
+
+type Service interface {
+	TariffList(ctx context.Context) ([]dto.TariffResponse, error)
+	StoreTariff(ctx context.Context, request dto.TariffRequest) error
+	UpdateTariff(ctx context.Context, request dto.TariffRequest) error
+	DraftStay(ctx context.Context, checkoutLogID int) error
+	GenerateInvoices(ctx context.Context, request dto.InvoiceGenerateRequest) (*dto.InvoiceGenerateResponse, error)
+	InvoiceList(ctx context.Context, request dto.InvoiceListParam) (*dto.InvoiceResponseList, error)
+	InvoiceDetail(ctx context.Context, ID int) (*dto.InvoiceResponse, error)
+	IssueInvoice(ctx context.Context, request dto.InvoiceActionRequest) (*dto.InvoiceResponse, error)
+	VoidInvoice(ctx context.Context, request dto.InvoiceActionRequest) error
+	RecordPayment(ctx context.Context, authUser model.User, request dto.PaymentRequest) (*dto.InvoiceResponse, error)
+	InvoicePDF(ctx context.Context, ID int, w io.Writer) (string, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:     f.AppRepository,
+		shipRepository:    f.ShipRepository,
+		invoiceRepository: f.InvoiceRepository,
+	}
+}
+
+// stayDays charges every started day of the stay, a stay is at least one day
+func stayDays(arrivedAt time.Time, departedAt time.Time) int {
+	days := int(math.Ceil(departedAt.Sub(arrivedAt).Hours() / 24))
+	if days < 1 {
+		return 1
+	}
+
+	return days
+}
+
+func tariffResponse(e model.PortTariff) dto.TariffResponse {
+	return dto.TariffResponse{
+		ID:        e.ID,
+		HarbourID: e.HarbourID,
+		ZoneType:  e.ZoneType,
+		Name:      e.Name,
+		BaseFee:   helper.FromMinorUnits(e.BaseFee),
+		DailyRate: helper.FromMinorUnits(e.DailyRate),
+		RatePerGT: helper.FromMinorUnits(e.RatePerGT),
+		IsActive:  e.IsActive == 1,
+		CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (s *service) TariffList(ctx context.Context) ([]dto.TariffResponse, error) {
+	tariffs, err := s.invoiceRepository.TariffList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.TariffResponse{}
+	for _, e := range tariffs {
+		res = append(res, tariffResponse(e))
+	}
+
+	return res, nil
+}
+
+// StoreTariff adds a tariff to the harbour of ctx, every zone type has one active tariff
+func (s *service) StoreTariff(ctx context.Context, request dto.TariffRequest) error {
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.InvalidHarbour
+		}
+		return err
+	}
+
+	zoneType := strings.ToLower(strings.TrimSpace(request.ZoneType))
+	taken, err := s.invoiceRepository.TariffZoneTaken(ctx, harbour.ID, zoneType, 0)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return constants.TariffZoneTaken
+	}
+
+	tariff := model.PortTariff{
+		HarbourID: harbour.ID,
+		ZoneType:  zoneType,
+		Name:      strings.TrimSpace(request.Name),
+		BaseFee:   helper.ToMinorUnits(request.BaseFee),
+		DailyRate: helper.ToMinorUnits(request.DailyRate),
+		RatePerGT: helper.ToMinorUnits(request.RatePerGT),
+		IsActive:  1,
+	}
+
+	return s.invoiceRepository.StoreTariff(ctx, &tariff)
+}
+
+// UpdateTariff changes a tariff, invoices already drafted keep the rates they were drafted with
+func (s *service) UpdateTariff(ctx context.Context, request dto.TariffRequest) error {
+	current, err := s.invoiceRepository.TariffByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	zoneType := strings.ToLower(strings.TrimSpace(request.ZoneType))
+
+	isActive := 0
+	if request.IsActive {
+		isActive = 1
+
+		taken, err := s.invoiceRepository.TariffZoneTaken(ctx, current.HarbourID, zoneType, current.ID)
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			return constants.TariffZoneTaken
+		}
+	}
+
+	tariff := model.PortTariff{
+		ZoneType:  zoneType,
+		Name:      strings.TrimSpace(request.Name),
+		BaseFee:   helper.ToMinorUnits(request.BaseFee),
+		DailyRate: helper.ToMinorUnits(request.DailyRate),
+		RatePerGT: helper.ToMinorUnits(request.RatePerGT),
+		IsActive:  isActive,
+	}
+	tariff.ID = current.ID
+
+	return s.invoiceRepository.UpdateTariff(ctx, tariff)
+}
+
+// stayTariff picks the tariff of the zone type, falling back to the default tariff of the harbour
+func (s *service) stayTariff(ctx context.Context, harbourID int, zoneType string) (*model.PortTariff, error) {
+	tariff, err := s.invoiceRepository.ActiveTariff(ctx, harbourID, zoneType)
+	if err == gorm.ErrRecordNotFound && zoneType != "" {
+		tariff, err = s.invoiceRepository.ActiveTariff(ctx, harbourID, "")
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, constants.TariffNotFound
+	}
+
+	return tariff, err
+}
+
+// DraftStay drafts the invoice of the stay ended by the checkout log, a stay already billed is left alone
+func (s *service) DraftStay(ctx context.Context, checkoutLogID int) error {
+	checkout, err := s.shipRepository.FindOneDockedLog(ctx, "*", "id = ? AND status = ?", checkoutLogID, model.Checkout)
+	if err != nil {
+		return err
+	}
+
+	return s.draftStay(ctx, checkout)
+}
+
+func (s *service) draftStay(ctx context.Context, checkout model.ShipDockedLog) error {
+	exists, err := s.invoiceRepository.InvoiceExists(ctx, checkout.ID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	checkin, err := s.invoiceRepository.StayCheckin(ctx, checkout.ShipID, checkout.ID)
+	if err == gorm.ErrRecordNotFound || (err == nil && checkin.Status != model.Checkin) {
+		return constants.StayNotCompleted
+	}
+	if err != nil {
+		return err
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, checkout.ShipID)
+	if err != nil {
+		return err
+	}
+
+	berth, err := s.invoiceRepository.StayBerth(ctx, ship.ID, checkin.CreatedAt, checkout.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	zoneType := ""
+	var berthID *int
+	if berth != nil {
+		zoneType = berth.ZoneType
+		berthID = &berth.ID
+	}
+
+	tariff, err := s.stayTariff(ctx, ship.HarbourID, zoneType)
+	if err != nil {
+		return err
+	}
+
+	// a ship without a recorded GT only pays the base fee and the daily rate
+	var gt float64
+	detail, err := s.shipRepository.ShipAddonDetail(ctx, ship.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	if value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(detail.GT), ",", "."), 64); err == nil && value > 0 {
+		gt = value
+	}
+
+	days := stayDays(checkin.CreatedAt, checkout.CreatedAt)
+
+	// the tonnage charge is the only fractional part, it is rounded to the cent once
+	tonnage := int64(math.Round(gt * float64(days) * float64(tariff.RatePerGT)))
+
+	invoice := model.Invoice{
+		HarbourID:     ship.HarbourID,
+		ShipID:        ship.ID,
+		CheckinLogID:  checkin.ID,
+		CheckoutLogID: checkout.ID,
+		BerthID:       berthID,
+		TariffID:      tariff.ID,
+		ZoneType:      tariff.ZoneType,
+		ArrivedAt:     checkin.CreatedAt,
+		DepartedAt:    checkout.CreatedAt,
+		Days:          days,
+		GT:            gt,
+		BaseFee:       tariff.BaseFee,
+		DailyRate:     tariff.DailyRate,
+		RatePerGT:     tariff.RatePerGT,
+		Amount:        tariff.BaseFee + int64(days)*tariff.DailyRate + tonnage,
+		Status:        model.InvoiceDraft,
+	}
+
+	// a concurrent run which drafted the stay first leaves it billed, nothing is left to do
+	_, err = s.invoiceRepository.StoreInvoice(ctx, &invoice)
+	return err
+}
+
+// GenerateInvoices drafts the invoices of the stays which ended in the date range, dates are inclusive
+func (s *service) GenerateInvoices(ctx context.Context, request dto.InvoiceGenerateRequest) (*dto.InvoiceGenerateResponse, error) {
+	if _, err := time.Parse("2006-01-02", request.StartDate); err != nil {
+		return nil, constants.InvalidAsOfDate
+	}
+
+	if _, err := time.Parse("2006-01-02", request.EndDate); err != nil {
+		return nil, constants.InvalidAsOfDate
+	}
+
+	checkouts, err := s.invoiceRepository.UninvoicedCheckouts(ctx, request.StartDate, request.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InvoiceGenerateResponse{Stays: len(checkouts)}
+	for _, checkout := range checkouts {
+		err := s.draftStay(ctx, checkout)
+		switch err {
+		case nil:
+			res.Generated++
+		case constants.TariffNotFound, constants.StayNotCompleted:
+			res.Skipped++
+		default:
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+func (s *service) InvoiceList(ctx context.Context, request dto.InvoiceListParam) (*dto.InvoiceResponseList, error) {
+	total, err := s.invoiceRepository.InvoiceCount(ctx, dto.InvoiceListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.invoiceRepository.InvoiceCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.invoiceRepository.InvoiceList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.InvoiceResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) InvoiceDetail(ctx context.Context, ID int) (*dto.InvoiceResponse, error) {
+	return s.invoiceRepository.InvoiceDetail(ctx, ID)
+}
+
+// IssueInvoice numbers a draft invoice with the code of its harbour and the month it is issued in
+func (s *service) IssueInvoice(ctx context.Context, request dto.InvoiceActionRequest) (*dto.InvoiceResponse, error) {
+	current, err := s.invoiceRepository.InvoiceByID(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status != model.InvoiceDraft {
+		return nil, constants.InvoiceNotDraft
+	}
+
+	appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, current.HarbourID))
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := time.Now()
+	number := fmt.Sprintf("INV-%d-%s-%06d", appInfo.HarbourCode, issuedAt.Format("200601"), current.ID)
+
+	ok, err := s.invoiceRepository.IssueInvoice(ctx, current.ID, number, issuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, constants.InvoiceNotDraft
+	}
+
+	return s.invoiceRepository.InvoiceDetail(ctx, current.ID)
+}
+
+func (s *service) VoidInvoice(ctx context.Context, request dto.InvoiceActionRequest) error {
+	current, err := s.invoiceRepository.InvoiceByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.invoiceRepository.VoidInvoice(ctx, current.ID, strings.TrimSpace(request.Reason), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return constants.InvoiceCannotVoid
+	}
+
+	return nil
+}
+
+// RecordPayment books a payment on an issued invoice, the invoice turns paid once nothing is outstanding
+func (s *service) RecordPayment(ctx context.Context, authUser model.User, request dto.PaymentRequest) (*dto.InvoiceResponse, error) {
+	now := time.Now()
+	paidAt := now
+	if request.PaidAt != "" {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04:05", request.PaidAt, time.Local)
+		if err != nil || parsed.After(now) {
+			return nil, constants.InvalidPaymentTime
+		}
+		paidAt = parsed
+	}
+
+	current, err := s.invoiceRepository.InvoiceByID(ctx, request.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Status != model.InvoiceIssued {
+		return nil, constants.InvoiceNotIssued
+	}
+
+	amount := helper.ToMinorUnits(request.Amount)
+	if amount <= 0 || amount > current.Amount-current.PaidAmount {
+		return nil, constants.InvalidPaymentAmount
+	}
+
+	payment := model.InvoicePayment{
+		InvoiceID:  current.ID,
+		Amount:     amount,
+		Method:     strings.TrimSpace(request.Method),
+		Reference:  strings.TrimSpace(request.Reference),
+		PaidAt:     paidAt,
+		RecordedBy: authUser.ID,
+	}
+
+	ok, err := s.invoiceRepository.RecordPayment(ctx, &payment)
+	if err != nil {
+		return nil, err
+	}
+
+	// another payment or a void got in between the check and the update
+	if !ok {
+		return nil, constants.InvalidPaymentAmount
+	}
+
+	return s.invoiceRepository.InvoiceDetail(ctx, current.ID)
+}
+
+// InvoicePDF renders the invoice and returns the file name it should be downloaded as
+func (s *service) InvoicePDF(ctx context.Context, ID int, w io.Writer) (string, error) {
+	invoice, err := s.invoiceRepository.InvoiceDetail(ctx, ID)
+	if err != nil {
+		return "", err
+	}
+
+	appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, invoice.HarbourID))
+	if err != nil {
+		return "", err
+	}
+
+	filename := "invoice-draft-" + strconv.Itoa(invoice.ID) + ".pdf"
+	if invoice.Number != "" {
+		filename = strings.ToLower(invoice.Number) + ".pdf"
+	}
+
+	return filename, renderInvoice(w, appInfo.HarbourName, appInfo.HarbourCode, invoice)
+}
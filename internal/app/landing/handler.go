@@ -0,0 +1,252 @@
+package landing
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/export"
+	"owlharbour-api/pkg/log"
+	"owlharbour-api/pkg/util"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+func landingError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.LandingNotFishingVessel, constants.LandingNotCheckin, constants.LandingExists,
+		constants.InvalidFishingGear, constants.InvalidCatchWeight, constants.InvalidCatchSpecies,
+		constants.DuplicateCatchSpecies, constants.InvalidLandingTime:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func landingParam(c *gin.Context) dto.LandingListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.LandingListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Species:   c.DefaultQuery("species", ""),
+		Gear:      c.DefaultQuery("gear", ""),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+}
+
+func (h *handler) StoreMobileLanding(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.LandingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreMobileLanding(ctx, user, request); err != nil {
+		landingError(c, "Failed to store landing", "no ship or docked log data for this account", err)
+		return
+	}
+
+	response := util.APIResponse("Landing successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreLanding(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.LandingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreLanding(ctx, user, request); err != nil {
+		landingError(c, "Failed to store landing", "invalid docked log id, no log data", err)
+		return
+	}
+
+	response := util.APIResponse("Landing successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateLanding(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.LandingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateLanding(ctx, request); err != nil {
+		landingError(c, "Failed to update landing", "invalid landing id, no landing data", err)
+		return
+	}
+
+	response := util.APIResponse("Landing successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) LandingList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	res, err := h.service.LandingList(ctx, landingParam(c))
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve landing list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved landing list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) LandingDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	landingID, err := strconv.Atoi(c.Param("landing_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid landing_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.LandingDetail(ctx, landingID)
+	if err != nil {
+		landingError(c, "Failed to retrieve landing", "invalid landing id, no landing data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved landing", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AggregateByShip(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	res, err := h.service.AggregateByShip(ctx, landingParam(c))
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve landing per ship: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved landing per ship", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AggregateBySpecies(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	res, err := h.service.AggregateBySpecies(ctx, landingParam(c))
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve landing per species: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved landing per species", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportLandings streams one row per catch for the fisheries statistics, format is csv or xlsx
+func (h *handler) ExportLandings(c *gin.Context) {
+	param := landingParam(c)
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		response := util.APIResponse("Invalid export format, use csv or xlsx", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=\"landing-"+time.Now().Format("20060102150405")+"."+format+"\"")
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, "Landing")
+	if err != nil {
+		log.Logging("Failed create landing export, Err: %s", err.Error()).Error()
+		return
+	}
+
+	if err := h.service.ExportLandings(c.Request.Context(), param, w); err != nil {
+		log.Logging("Failed write landing export, Err: %s", err.Error()).Error()
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		log.Logging("Failed close landing export, Err: %s", err.Error()).Error()
+	}
+}
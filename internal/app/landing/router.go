@@ -0,0 +1,21 @@
+package landing
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.POST("/mobile/store", h.StoreMobileLanding)
+
+	g.POST("/store", h.StoreLanding)
+	g.PUT("/update", h.UpdateLanding)
+	g.GET("/list", h.LandingList)
+	g.GET("/detail/:landing_id", h.LandingDetail)
+	g.GET("/aggregate/ship", h.AggregateByShip)
+	g.GET("/aggregate/species", h.AggregateBySpecies)
+	g.GET("/export", h.ExportLandings)
+}
@@ -0,0 +1,283 @@
+package landing
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/export"
+	"owlharbour-api/pkg/pagination"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxCatchWeightKg = 500000
+
+var gears = []model.FishingGear{
+	model.GearGillnet, model.GearLongline, model.GearPurseSeine, model.GearTrawl,
+	model.GearHandline, model.GearPoleLine, model.GearTrap, model.GearOther,
+}
+
+var landingExportHeader = []string{
+	"Landing ID", "Landed At", "Ship ID", "Ship Name", "SIUP", "BKP", "Fishing Area", "Gear", "Species", "Weight (kg)", "Source",
+}
+
+type service struct {
+	shipRepository    repository.Ship
+	landingRepository repository.Landing
+}
+
+type Service interface {
+	StoreMobileLanding(ctx context.Context, authUser model.User, request dto.LandingRequest) error
+	StoreLanding(ctx context.Context, authUser model.User, request dto.LandingRequest) error
+	UpdateLanding(ctx context.Context, request dto.LandingRequest) error
+	LandingList(ctx context.Context, request dto.LandingListParam) (*dto.LandingResponseList, error)
+	LandingDetail(ctx context.Context, ID int) (*dto.LandingResponse, error)
+	AggregateByShip(ctx context.Context, request dto.LandingListParam) ([]dto.LandingShipAggregate, error)
+	AggregateBySpecies(ctx context.Context, request dto.LandingListParam) ([]dto.LandingSpeciesAggregate, error)
+	ExportLandings(ctx context.Context, request dto.LandingListParam, w export.Writer) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		shipRepository:    f.ShipRepository,
+		landingRepository: f.LandingRepository,
+	}
+}
+
+func validGear(gear model.FishingGear) bool {
+	for _, e := range gears {
+		if e == gear {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StoreMobileLanding records the landing of the ship paired with the signed in account,
+// without docked_log_id the landing belongs to the last docked log of the ship
+func (s *service) StoreMobileLanding(ctx context.Context, authUser model.User, request dto.LandingRequest) error {
+	ship, err := s.shipRepository.ShipByAuth(ctx, authUser)
+	if err != nil {
+		return err
+	}
+
+	dockedLogID := request.DockedLogID
+	if dockedLogID == 0 {
+		lastLog, err := s.shipRepository.GetLastDockedLog(ctx, ship.ID)
+		if err != nil {
+			return err
+		}
+		dockedLogID = lastLog.ID
+	}
+
+	log, err := s.shipRepository.FindOneDockedLog(ctx, "id, ship_id, status, created_at", "id = ?", dockedLogID)
+	if err != nil {
+		return err
+	}
+
+	if log.ShipID != ship.ID {
+		return constants.LandingNotCheckin
+	}
+
+	return s.storeLanding(ctx, authUser, model.LandingMobile, log, request)
+}
+
+// StoreLanding records a landing on behalf of a ship, used by inspectors at the quay
+func (s *service) StoreLanding(ctx context.Context, authUser model.User, request dto.LandingRequest) error {
+	log, err := s.shipRepository.FindOneDockedLog(ctx, "id, ship_id, status, created_at", "id = ?", request.DockedLogID)
+	if err != nil {
+		return err
+	}
+
+	return s.storeLanding(ctx, authUser, model.LandingInspector, log, request)
+}
+
+func (s *service) storeLanding(ctx context.Context, authUser model.User, source model.LandingSource, log model.ShipDockedLog, request dto.LandingRequest) error {
+	if log.Status != model.Checkin {
+		return constants.LandingNotCheckin
+	}
+
+	if err := s.fishingVessel(ctx, log.ShipID); err != nil {
+		return err
+	}
+
+	if _, err := s.landingRepository.LandingByDockedLog(ctx, log.ID); err == nil {
+		return constants.LandingExists
+	} else if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	landing, catches, err := landingData(request, log.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	landing.ShipDockedLogID = log.ID
+	landing.ShipID = log.ShipID
+	landing.Source = source
+	landing.SubmittedBy = authUser.ID
+
+	return s.landingRepository.StoreLanding(ctx, &landing, catches)
+}
+
+// UpdateLanding corrects a recorded landing, the docked log, source and submitter are kept
+func (s *service) UpdateLanding(ctx context.Context, request dto.LandingRequest) error {
+	current, err := s.landingRepository.LandingByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	log, err := s.shipRepository.FindOneDockedLog(ctx, "id, ship_id, status, created_at", "id = ?", current.ShipDockedLogID)
+	if err != nil {
+		return err
+	}
+
+	landing, catches, err := landingData(request, log.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	landing.ID = current.ID
+	landing.Source = current.Source
+	landing.SubmittedBy = current.SubmittedBy
+
+	return s.landingRepository.UpdateLanding(ctx, landing, catches)
+}
+
+// fishingVessel rejects ships which are not registered as kapal tangkap
+func (s *service) fishingVessel(ctx context.Context, shipID int) error {
+	detail, err := s.shipRepository.ShipAddonDetail(ctx, shipID)
+	if err == gorm.ErrRecordNotFound {
+		return constants.LandingNotFishingVessel
+	}
+	if err != nil {
+		return err
+	}
+
+	if model.ShipType(detail.Type) != model.KapalTangkap {
+		return constants.LandingNotFishingVessel
+	}
+
+	return nil
+}
+
+// landingData validates the request, species are stored trimmed and lower case
+// so aggregates do not split a species over its spellings
+func landingData(request dto.LandingRequest, checkinAt time.Time) (model.Landing, []model.LandingCatch, error) {
+	gear := model.FishingGear(request.Gear)
+	if !validGear(gear) {
+		return model.Landing{}, nil, constants.InvalidFishingGear
+	}
+
+	now := time.Now()
+	landedAt := now
+	if request.LandedAt != "" {
+		var err error
+		landedAt, err = time.ParseInLocation("2006-01-02 15:04:05", request.LandedAt, time.Local)
+		if err != nil || landedAt.Before(checkinAt) || landedAt.After(now) {
+			return model.Landing{}, nil, constants.InvalidLandingTime
+		}
+	}
+
+	seen := map[string]bool{}
+	catches := []model.LandingCatch{}
+	for _, e := range request.Catches {
+		species := strings.ToLower(strings.TrimSpace(e.Species))
+		if species == "" {
+			return model.Landing{}, nil, constants.InvalidCatchSpecies
+		}
+
+		if seen[species] {
+			return model.Landing{}, nil, constants.DuplicateCatchSpecies
+		}
+		seen[species] = true
+
+		if e.WeightKg <= 0 || e.WeightKg > maxCatchWeightKg {
+			return model.Landing{}, nil, constants.InvalidCatchWeight
+		}
+
+		catches = append(catches, model.LandingCatch{
+			Species:  species,
+			WeightKg: e.WeightKg,
+		})
+	}
+
+	landing := model.Landing{
+		FishingArea: strings.TrimSpace(request.FishingArea),
+		Gear:        gear,
+		LandedAt:    landedAt,
+		Note:        request.Note,
+	}
+
+	return landing, catches, nil
+}
+
+func (s *service) LandingList(ctx context.Context, request dto.LandingListParam) (*dto.LandingResponseList, error) {
+	total, err := s.landingRepository.LandingCount(ctx, dto.LandingListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.landingRepository.LandingCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.landingRepository.LandingList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.LandingResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) LandingDetail(ctx context.Context, ID int) (*dto.LandingResponse, error) {
+	return s.landingRepository.LandingDetail(ctx, ID)
+}
+
+func (s *service) AggregateByShip(ctx context.Context, request dto.LandingListParam) ([]dto.LandingShipAggregate, error) {
+	return s.landingRepository.AggregateByShip(ctx, request)
+}
+
+func (s *service) AggregateBySpecies(ctx context.Context, request dto.LandingListParam) ([]dto.LandingSpeciesAggregate, error) {
+	return s.landingRepository.AggregateBySpecies(ctx, request)
+}
+
+// ExportLandings writes one row per catch of the filtered landings to w
+func (s *service) ExportLandings(ctx context.Context, request dto.LandingListParam, w export.Writer) error {
+	if err := w.Write(landingExportHeader); err != nil {
+		return err
+	}
+
+	return s.landingRepository.ExportLandings(ctx, request, func(row dto.LandingExportRow) error {
+		return w.Write([]string{
+			strconv.Itoa(row.LandingID),
+			row.LandedAt.Format("2006-01-02 15:04:05"),
+			strconv.Itoa(row.ShipID),
+			row.ShipName,
+			row.SIUP,
+			row.BKP,
+			row.FishingArea,
+			row.Gear,
+			row.Species,
+			strconv.FormatFloat(row.WeightKg, 'f', 2, 64),
+			row.Source,
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package occupancy
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func occupancyError(c *gin.Context, message string, notFound string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse(notFound, http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidOccupancyInterval, constants.InvalidOccupancyRange, constants.InvalidHarbour, constants.HarbourRequired:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func occupancyParam(c *gin.Context) dto.OccupancyParam {
+	return dto.OccupancyParam{
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+		Interval:  c.DefaultQuery("interval", "day"),
+	}
+}
+
+func (h *handler) Timeline(c *gin.Context) {
+	res, err := h.service.Timeline(c.Request.Context(), occupancyParam(c))
+	if err != nil {
+		occupancyError(c, "Failed to retrieve occupancy timeline", "no occupancy data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved occupancy timeline", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) PeakHours(c *gin.Context) {
+	res, err := h.service.PeakHours(c.Request.Context(), occupancyParam(c))
+	if err != nil {
+		occupancyError(c, "Failed to retrieve peak hours", "no occupancy data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved peak hours", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ThresholdList(c *gin.Context) {
+	res, err := h.service.ThresholdList(c.Request.Context())
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve capacity threshold list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved capacity threshold list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreThreshold(c *gin.Context) {
+	var request dto.ThresholdRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreThreshold(c.Request.Context(), request); err != nil {
+		occupancyError(c, "Failed to store capacity threshold", "invalid harbour, no harbour data", err)
+		return
+	}
+
+	response := util.APIResponse("Capacity threshold successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateThreshold(c *gin.Context) {
+	var request dto.ThresholdRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.UpdateThreshold(c.Request.Context(), request); err != nil {
+		occupancyError(c, "Failed to update capacity threshold", "invalid threshold id, no threshold data", err)
+		return
+	}
+
+	response := util.APIResponse("Capacity threshold successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) AlertList(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.OccupancyAlertListParam{
+		Offset:    offset,
+		Limit:     limit,
+		Status:    c.DefaultQuery("status", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.AlertList(c.Request.Context(), param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve capacity alert list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved capacity alert list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
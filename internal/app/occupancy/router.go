@@ -0,0 +1,20 @@
+package occupancy
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/timeline", h.Timeline)
+	g.GET("/peak-hours", h.PeakHours)
+
+	g.GET("/threshold/list", h.ThresholdList)
+	g.POST("/threshold/store", h.StoreThreshold)
+	g.PUT("/threshold/update", h.UpdateThreshold)
+
+	g.GET("/alert/list", h.AlertList)
+}
@@ -0,0 +1,481 @@
+package occupancy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	intervalHour = "hour"
+	intervalDay  = "day"
+
+	maxHourDays = 31
+	maxDayDays  = 366
+)
+
+type service struct {
+	appRepository       repository.App
+	userRepository      repository.User
+	occupancyRepository repository.Occupancy
+}
+
+type Service interface {
+	Timeline(ctx context.Context, request dto.OccupancyParam) (*dto.OccupancyResponse, error)
+	PeakHours(ctx context.Context, request dto.OccupancyParam) (*dto.PeakHoursResponse, error)
+	ThresholdList(ctx context.Context) ([]dto.ThresholdResponse, error)
+	StoreThreshold(ctx context.Context, request dto.ThresholdRequest) error
+	UpdateThreshold(ctx context.Context, request dto.ThresholdRequest) error
+	AlertList(ctx context.Context, request dto.OccupancyAlertListParam) (*dto.OccupancyAlertResponseList, error)
+	CheckCapacity(ctx context.Context, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:       f.AppRepository,
+		userRepository:      f.UserRepository,
+		occupancyRepository: f.OccupancyRepository,
+	}
+}
+
+// harbourLocation returns the timezone of the harbour of ctx, the days and hours of the occupancy
+// are cut in it like the other statistics of the harbour
+func (s *service) harbourLocation(ctx context.Context) (*time.Location, error) {
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id, timezone")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, constants.InvalidHarbour
+		}
+		return nil, err
+	}
+
+	return helper.HarbourLocation(harbour.Timezone)
+}
+
+// occupancyRange turns the inclusive dates of the harbour zone into [start, end), the current
+// day is charted up to now
+func occupancyRange(request dto.OccupancyParam, maxDays int, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", request.StartDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, constants.InvalidOccupancyRange
+	}
+
+	endDate, err := time.ParseInLocation("2006-01-02", request.EndDate, loc)
+	if err != nil || endDate.Before(start) {
+		return time.Time{}, time.Time{}, constants.InvalidOccupancyRange
+	}
+
+	end := endDate.AddDate(0, 0, 1)
+	if end.Sub(start) > time.Duration(maxDays)*24*time.Hour+time.Hour {
+		return time.Time{}, time.Time{}, constants.InvalidOccupancyRange
+	}
+
+	if end.After(now) {
+		end = now
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, constants.InvalidOccupancyRange
+	}
+
+	return start, end, nil
+}
+
+func (s *service) stays(ctx context.Context, start time.Time, end time.Time) ([]stay, error) {
+	opening, err := s.occupancyRepository.OpeningLogs(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := s.occupancyRepository.DockedLogsBetween(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStays(opening, logs, start, end), nil
+}
+
+func thresholdResponse(e model.OccupancyThreshold) dto.ThresholdResponse {
+	return dto.ThresholdResponse{
+		ID:        e.ID,
+		Name:      e.Name,
+		Ships:     e.Ships,
+		IsActive:  e.IsActive == 1,
+		CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// Timeline charts the ships inside the harbour per hour or day of the range
+func (s *service) Timeline(ctx context.Context, request dto.OccupancyParam) (*dto.OccupancyResponse, error) {
+	interval := request.Interval
+	if interval == "" {
+		interval = intervalDay
+	}
+
+	maxDays := maxDayDays
+	switch interval {
+	case intervalHour:
+		maxDays = maxHourDays
+	case intervalDay:
+	default:
+		return nil, constants.InvalidOccupancyInterval
+	}
+
+	loc, err := s.harbourLocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := occupancyRange(request, maxDays, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	stays, err := s.stays(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.occupancyRepository.CurrentOccupancy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds, err := s.occupancyRepository.ThresholdList(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.OccupancyResponse{
+		Interval:   interval,
+		Current:    current,
+		Average:    math.Round(averageOccupancy(stays, start, end)*100) / 100,
+		Thresholds: []dto.ThresholdResponse{},
+		Data:       []dto.OccupancyBucket{},
+	}
+
+	for _, e := range thresholds {
+		res.Thresholds = append(res.Thresholds, thresholdResponse(e))
+	}
+
+	for _, e := range countBuckets(stays, bucketStarts(start, end, interval), end) {
+		if e.Peak > res.Peak || res.PeakAt == "" {
+			res.Peak = e.Peak
+			res.PeakAt = e.Start.Format("2006-01-02 15:04:05")
+		}
+
+		res.Data = append(res.Data, dto.OccupancyBucket{
+			Start: e.Start.Format("2006-01-02 15:04:05"),
+			Ships: e.Ships,
+			Peak:  e.Peak,
+		})
+	}
+
+	return &res, nil
+}
+
+// PeakHours averages the hourly occupancy of the range per hour of the day
+func (s *service) PeakHours(ctx context.Context, request dto.OccupancyParam) (*dto.PeakHoursResponse, error) {
+	loc, err := s.harbourLocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := occupancyRange(request, maxDayDays, time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	stays, err := s.stays(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var sum [24]int
+	var samples [24]int
+	var peak [24]int
+	for _, e := range countBuckets(stays, bucketStarts(start, end, intervalHour), end) {
+		hour := e.Start.Hour()
+		sum[hour] += e.Ships
+		samples[hour]++
+		if e.Peak > peak[hour] {
+			peak[hour] = e.Peak
+		}
+	}
+
+	res := dto.PeakHoursResponse{
+		Days:    int(math.Ceil(end.Sub(start).Hours() / 24)),
+		Busiest: []int{},
+	}
+
+	for hour := 0; hour < 24; hour++ {
+		var average float64
+		if samples[hour] > 0 {
+			average = math.Round(float64(sum[hour])/float64(samples[hour])*100) / 100
+		}
+
+		res.Hours = append(res.Hours, dto.PeakHour{
+			Hour:    hour,
+			Average: average,
+			Peak:    peak[hour],
+		})
+	}
+
+	busiest := make([]dto.PeakHour, len(res.Hours))
+	copy(busiest, res.Hours)
+	sort.SliceStable(busiest, func(i, j int) bool {
+		return busiest[i].Average > busiest[j].Average
+	})
+
+	for _, e := range busiest {
+		if e.Average == 0 || len(res.Busiest) == 3 {
+			break
+		}
+		res.Busiest = append(res.Busiest, e.Hour)
+	}
+
+	return &res, nil
+}
+
+func (s *service) ThresholdList(ctx context.Context) ([]dto.ThresholdResponse, error) {
+	thresholds, err := s.occupancyRepository.ThresholdList(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []dto.ThresholdResponse{}
+	for _, e := range thresholds {
+		res = append(res, thresholdResponse(e))
+	}
+
+	return res, nil
+}
+
+// StoreThreshold adds a capacity threshold to the harbour of ctx
+func (s *service) StoreThreshold(ctx context.Context, request dto.ThresholdRequest) error {
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.InvalidHarbour
+		}
+		return err
+	}
+
+	threshold := model.OccupancyThreshold{
+		HarbourID: harbour.ID,
+		Name:      strings.TrimSpace(request.Name),
+		Ships:     request.Ships,
+		IsActive:  1,
+	}
+
+	return s.occupancyRepository.StoreThreshold(ctx, &threshold)
+}
+
+// UpdateThreshold changes a threshold, an open alert of the threshold is settled by the next check
+func (s *service) UpdateThreshold(ctx context.Context, request dto.ThresholdRequest) error {
+	current, err := s.occupancyRepository.ThresholdByID(ctx, request.ID)
+	if err != nil {
+		return err
+	}
+
+	isActive := 0
+	if request.IsActive {
+		isActive = 1
+	}
+
+	threshold := model.OccupancyThreshold{
+		Name:     strings.TrimSpace(request.Name),
+		Ships:    request.Ships,
+		IsActive: isActive,
+	}
+	threshold.ID = current.ID
+
+	return s.occupancyRepository.UpdateThreshold(ctx, threshold)
+}
+
+func (s *service) AlertList(ctx context.Context, request dto.OccupancyAlertListParam) (*dto.OccupancyAlertResponseList, error) {
+	total, err := s.occupancyRepository.AlertCount(ctx, dto.OccupancyAlertListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.occupancyRepository.AlertCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.occupancyRepository.AlertList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.OccupancyAlertResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// CheckCapacity compares the current occupancy of the harbour of ctx with its thresholds, an alert
+// is raised once when a threshold is exceeded and resolved when the occupancy drops back
+func (s *service) CheckCapacity(ctx context.Context, now time.Time) error {
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	thresholds, err := s.occupancyRepository.ThresholdList(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	openAlerts, err := s.occupancyRepository.OpenAlerts(ctx, harbour.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(thresholds) == 0 && len(openAlerts) == 0 {
+		return nil
+	}
+
+	current, err := s.occupancyRepository.CurrentOccupancy(ctx)
+	if err != nil {
+		return err
+	}
+	occupancy := int(current)
+
+	exceeded := map[int]bool{}
+	for _, e := range thresholds {
+		if occupancy > e.Ships {
+			exceeded[e.ID] = true
+		}
+	}
+
+	open := map[int]bool{}
+	for _, e := range openAlerts {
+		open[e.ThresholdID] = true
+
+		if !exceeded[e.ThresholdID] {
+			if err := s.occupancyRepository.ResolveAlert(ctx, e.ID, now); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.occupancyRepository.RaiseAlertPeak(ctx, e.ID, occupancy); err != nil {
+			return err
+		}
+	}
+
+	var raised []model.OccupancyAlert
+	for _, e := range thresholds {
+		if !exceeded[e.ID] || open[e.ID] {
+			continue
+		}
+
+		alert := model.OccupancyAlert{
+			HarbourID:     harbour.ID,
+			ThresholdID:   e.ID,
+			ThresholdName: e.Name,
+			Threshold:     e.Ships,
+			Occupancy:     occupancy,
+			PeakOccupancy: occupancy,
+			RaisedAt:      now,
+		}
+
+		stored, err := s.occupancyRepository.StoreAlert(ctx, &alert)
+		if err != nil {
+			return err
+		}
+
+		// the check of another fix raised it first and notified the admins
+		if stored {
+			raised = append(raised, alert)
+		}
+	}
+
+	if len(raised) > 0 {
+		s.notifyCapacity(ctx, raised, occupancy)
+	}
+
+	return nil
+}
+
+func (s *service) notifyCapacity(ctx context.Context, alerts []model.OccupancyAlert, occupancy int) {
+	recipients, err := s.userRepository.AdminEmails(ctx)
+	if err != nil {
+		fmt.Println("Failed to load admin emails:", err.Error())
+		return
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		fmt.Println("Failed to load app info:", err.Error())
+		return
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_capacity_alert.html")
+	if err != nil {
+		fmt.Println("Failed to parse capacity alert template:", err.Error())
+		return
+	}
+
+	type thresholdRow struct {
+		Name  string
+		Ships int
+	}
+
+	var thresholds []thresholdRow
+	for _, e := range alerts {
+		thresholds = append(thresholds, thresholdRow{Name: e.ThresholdName, Ships: e.Threshold})
+	}
+
+	title := "Harbour capacity exceeded"
+	data := struct {
+		Title       string
+		HarbourName string
+		Occupancy   int
+		RaisedAt    string
+		Thresholds  []thresholdRow
+	}{
+		Title:       title,
+		HarbourName: appInfo.HarbourName,
+		Occupancy:   occupancy,
+		RaisedAt:    alerts[0].RaisedAt.Format("2006-01-02 15:04:05"),
+		Thresholds:  thresholds,
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		fmt.Println("Failed to render capacity alert template:", err.Error())
+		return
+	}
+
+	if err := helper.SendMail(strings.Join(recipients, ","), title+" - "+strconv.Itoa(occupancy)+" ships", tplBuffer.String()); err != nil {
+		fmt.Println("Failed to send capacity alert:", err.Error())
+	}
+}
@@ -0,0 +1,143 @@
+package occupancy
+
+import (
+	"owlharbour-api/internal/model"
+	"sort"
+	"time"
+)
+
+// stay is the time a ship spent inside the harbour, clipped to the range being charted
+type stay struct {
+	ShipID int
+	From   time.Time
+	To     time.Time
+}
+
+type occupancyEvent struct {
+	At    time.Time
+	Delta int
+}
+
+// buildStays pairs the docked logs into stays, opening holds the last log of every ship before
+// start and logs the logs of the range ordered by ship then time. A stay still open at end is
+// closed at end
+func buildStays(opening []model.ShipDockedLog, logs []model.ShipDockedLog, start time.Time, end time.Time) []stay {
+	open := map[int]time.Time{}
+	for _, e := range opening {
+		if e.Status == model.Checkin {
+			open[e.ShipID] = start
+		}
+	}
+
+	var res []stay
+	for _, e := range logs {
+		from, inside := open[e.ShipID]
+
+		switch e.Status {
+		case model.Checkin:
+			if !inside {
+				open[e.ShipID] = e.CreatedAt
+			}
+		case model.Checkout:
+			if inside {
+				res = append(res, stay{ShipID: e.ShipID, From: from, To: e.CreatedAt})
+				delete(open, e.ShipID)
+			}
+		}
+	}
+
+	for shipID, from := range open {
+		res = append(res, stay{ShipID: shipID, From: from, To: end})
+	}
+
+	return res
+}
+
+// bucketStarts splits [start, end) into hours or calendar days of the zone of start
+func bucketStarts(start time.Time, end time.Time, interval string) []time.Time {
+	var res []time.Time
+	for at := start; at.Before(end); {
+		res = append(res, at)
+		if interval == intervalHour {
+			at = at.Add(time.Hour)
+		} else {
+			at = at.AddDate(0, 0, 1)
+		}
+	}
+
+	return res
+}
+
+type bucketCount struct {
+	Start time.Time
+	Ships int
+	Peak  int
+}
+
+// countBuckets sweeps the arrivals and departures of the stays through the buckets. Ships counts
+// every ship inside at some point of the bucket, Peak the most ships inside at the same time
+func countBuckets(stays []stay, starts []time.Time, end time.Time) []bucketCount {
+	events := make([]occupancyEvent, 0, len(stays)*2)
+	for _, e := range stays {
+		if !e.To.After(e.From) {
+			continue
+		}
+		events = append(events, occupancyEvent{At: e.From, Delta: 1}, occupancyEvent{At: e.To, Delta: -1})
+	}
+
+	// a departure and an arrival at the same moment do not overlap
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].At.Equal(events[j].At) {
+			return events[i].Delta < events[j].Delta
+		}
+		return events[i].At.Before(events[j].At)
+	})
+
+	res := make([]bucketCount, 0, len(starts))
+	current, i := 0, 0
+	for n, bucketStart := range starts {
+		bucketEnd := end
+		if n+1 < len(starts) {
+			bucketEnd = starts[n+1]
+		}
+
+		// the events before the bucket set the ships inside when it opens
+		for i < len(events) && events[i].At.Before(bucketStart) {
+			current += events[i].Delta
+			i++
+		}
+
+		ships, peak := current, current
+		for i < len(events) && events[i].At.Before(bucketEnd) {
+			current += events[i].Delta
+			if events[i].Delta > 0 {
+				ships++
+			}
+			if current > peak {
+				peak = current
+			}
+			i++
+		}
+
+		res = append(res, bucketCount{Start: bucketStart, Ships: ships, Peak: peak})
+	}
+
+	return res
+}
+
+// averageOccupancy weighs every stay by its duration over the whole range
+func averageOccupancy(stays []stay, start time.Time, end time.Time) float64 {
+	total := end.Sub(start).Seconds()
+	if total <= 0 {
+		return 0
+	}
+
+	var inside float64
+	for _, e := range stays {
+		if e.To.After(e.From) {
+			inside += e.To.Sub(e.From).Seconds()
+		}
+	}
+
+	return inside / total
+}
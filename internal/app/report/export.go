@@ -0,0 +1,50 @@
+package report
+
+import (
+	"owlharbour-api/internal/dto"
+	"strconv"
+)
+
+var (
+	shipDetailHeader = []string{"Type", "Dimension", "Harbour", "SIUP", "BKP", "Selar Mark", "GT", "Owner Name"}
+
+	dockingExportHeader = append([]string{"Log ID", "Log Date", "Ship ID", "Ship Name", "Status", "Latitude", "Longitude"}, shipDetailHeader...)
+
+	fraudExportHeader = append([]string{"Log ID", "Log Date", "Ship ID", "Ship Name", "Latitude", "Longitude", "Is Mocked",
+		"On Ground", "Fraud Score", "Fraud Reason"}, shipDetailHeader...)
+)
+
+func shipDetailCells(d dto.ReportShipDetailColumns) []string {
+	return []string{d.Type, d.Dimension, d.Harbour, d.SIUP, d.BKP, d.SelarMark, d.GT, d.OwnerName}
+}
+
+func dockingExportCells(row dto.ReportShipDockingExportRow) []string {
+	cells := []string{
+		strconv.Itoa(row.LogID),
+		row.LogDate.Format("2006-01-02 15:04:05"),
+		strconv.Itoa(row.ShipID),
+		row.ShipName,
+		row.Status,
+		row.Lat,
+		row.Long,
+	}
+
+	return append(cells, shipDetailCells(row.ReportShipDetailColumns)...)
+}
+
+func fraudExportCells(row dto.ReportShipFraudExportRow) []string {
+	cells := []string{
+		strconv.Itoa(row.LogID),
+		row.LogDate.Format("2006-01-02 15:04:05"),
+		strconv.Itoa(row.ShipID),
+		row.ShipName,
+		row.Lat,
+		row.Long,
+		strconv.Itoa(row.IsMocked),
+		strconv.Itoa(row.OnGround),
+		strconv.Itoa(row.FraudScore),
+		row.FraudReason,
+	}
+
+	return append(cells, shipDetailCells(row.ReportShipDetailColumns)...)
+}
@@ -1,12 +1,16 @@
 package report
 
 import (
+	"bytes"
 	"net/http"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/export"
+	"owlharbour-api/pkg/log"
 	"owlharbour-api/pkg/util"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,9 +25,7 @@ func NewHandler(f *factory.Factory) *handler {
 	}
 }
 
-func (h *handler) ShipDocking(c *gin.Context) {
-	ctx := c.Request.Context()
-
+func dockingParam(c *gin.Context) dto.ReportShipDockedParam {
 	offsetParam := c.DefaultQuery("offset", "0")
 	limitParam := c.DefaultQuery("limit", "25")
 	logType := c.DefaultQuery("type", "")
@@ -40,34 +42,24 @@ func (h *handler) ShipDocking(c *gin.Context) {
 
 	typeArray := strings.Split(logType, ",")
 
-	param := dto.ReportShipDockedParam{
+	return dto.ReportShipDockedParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		LogType:   typeArray,
 		Search:    searchParam,
 		StartDate: dateStart,
 		EndDate:   dateEnd,
 	}
-
-	data, err := h.service.ShipDocking(ctx, param)
-	if err != nil {
-		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
-		c.JSON(http.StatusBadRequest, response)
-		return
-	}
-
-	response := util.APIResponse("Success get data docking", http.StatusOK, "success", data)
-	c.JSON(http.StatusOK, response)
 }
 
-func (h *handler) ShipFraud(c *gin.Context) {
-	ctx := c.Request.Context()
-
+func fraudParam(c *gin.Context) dto.ReportShipLocationParam {
 	offsetParam := c.DefaultQuery("offset", "0")
 	limitParam := c.DefaultQuery("limit", "25")
 	dateStart := c.DefaultQuery("start_date", "")
 	dateEnd := c.DefaultQuery("end_date", "")
 	searchParam := c.DefaultQuery("search", "")
+	reasonParam := c.DefaultQuery("reason", "")
 
 	offset, _ := strconv.Atoi(offsetParam)
 	limit, _ := strconv.Atoi(limitParam)
@@ -76,15 +68,35 @@ func (h *handler) ShipFraud(c *gin.Context) {
 		limit = 10
 	}
 
-	param := dto.ReportShipLocationParam{
+	return dto.ReportShipLocationParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		Search:    searchParam,
 		StartDate: dateStart,
 		EndDate:   dateEnd,
+		Reason:    reasonParam,
+	}
+}
+
+func (h *handler) ShipDocking(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := h.service.ShipDocking(ctx, dockingParam(c))
+	if err != nil {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
 	}
 
-	data, err := h.service.ShipFraud(ctx, param)
+	response := util.APIResponse("Success get data docking", http.StatusOK, "success", data)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ShipFraud(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := h.service.ShipFraud(ctx, fraudParam(c))
 	if err != nil {
 		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
 		c.JSON(http.StatusBadRequest, response)
@@ -94,3 +106,78 @@ func (h *handler) ShipFraud(c *gin.Context) {
 	response := util.APIResponse("Success get data fraud", http.StatusOK, "success", data)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *handler) ExportShipDocking(c *gin.Context) {
+	param := dockingParam(c)
+
+	h.export(c, "ship-docking", "Ship Docking", func(w export.Writer) error {
+		return h.service.ExportShipDocking(c.Request.Context(), param, w)
+	})
+}
+
+func (h *handler) ExportShipFraud(c *gin.Context) {
+	param := fraudParam(c)
+
+	h.export(c, "ship-fraud", "Ship Fraud", func(w export.Writer) error {
+		return h.service.ExportShipFraud(c.Request.Context(), param, w)
+	})
+}
+
+func (h *handler) ActivityReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	startDate, endDate, err := activityRange(c.DefaultQuery("start_date", ""), c.DefaultQuery("end_date", ""))
+	if err != nil {
+		response := util.APIResponse("Invalid date format, use YYYY-MM-DD", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	param := dto.ReportActivityParam{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	// render into memory first so a failure can still be answered with a json error
+	var buf bytes.Buffer
+	if err := h.service.ActivityReport(ctx, param, &buf); err != nil {
+		response := util.APIResponse("Failed to generate activity report: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\"harbour-activity-"+startDate+"-"+endDate+".pdf\"")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// export streams a report as attachment in the format of the format query (csv or xlsx),
+// once the first bytes are sent a failure can only be logged and ends the download early
+func (h *handler) export(c *gin.Context, filename string, sheet string, write func(w export.Writer) error) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+
+	contentType, ok := export.ContentType(format)
+	if !ok {
+		response := util.APIResponse("Invalid export format, use csv or xlsx", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"-"+time.Now().Format("20060102150405")+"."+format+"\"")
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, sheet)
+	if err != nil {
+		log.Logging("Failed create %s export, Err: %s", filename, err.Error()).Error()
+		return
+	}
+
+	if err := write(w); err != nil {
+		log.Logging("Failed write %s export, Err: %s", filename, err.Error()).Error()
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		log.Logging("Failed close %s export, Err: %s", filename, err.Error()).Error()
+	}
+}
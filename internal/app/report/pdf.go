@@ -0,0 +1,171 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin    = 10.0
+	pdfRowHeight = 6.0
+)
+
+type pdfColumn struct {
+	Title string
+	Width float64
+	Align string
+}
+
+// activityPDF renders the harbour activity report, tables break across pages and repeat their header
+type activityPDF struct {
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	columns   []pdfColumn
+}
+
+func newActivityPDF(harbourName string, harbourCode int, startDate string, endDate string) *activityPDF {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.SetTitle("Harbour Activity Report "+harbourName, true)
+	pdf.SetCreator("owlharbour-api", true)
+	pdf.AliasNbPages("")
+
+	r := &activityPDF{
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+
+	generatedAt := time.Now().Format("2006-01-02 15:04")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-(pdfMargin + 3))
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, r.translate("Generated "+generatedAt+" - "+harbourName+" Harbour"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(20, 40, 80)
+	pdf.CellFormat(0, 10, r.translate(strings.ToUpper(harbourName)+" HARBOUR"), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(60, 60, 60)
+	pdf.CellFormat(0, 6, r.translate(fmt.Sprintf("Harbour code %d", harbourCode)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, r.translate("Activity report "+startDate+" to "+endDate), "", 1, "L", false, 0, "")
+
+	pdf.SetDrawColor(20, 40, 80)
+	pdf.SetLineWidth(0.5)
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.Line(pdfMargin, pdf.GetY()+2, pageWidth-pdfMargin, pdf.GetY()+2)
+	pdf.Ln(6)
+
+	return r
+}
+
+func (r *activityPDF) Section(title string) {
+	_, pageHeight := r.pdf.GetPageSize()
+	// keep the title together with the table header and a first row
+	if r.pdf.GetY()+10+3*pdfRowHeight > pageHeight-pdfMargin-5 {
+		r.pdf.AddPage()
+	}
+
+	r.pdf.Ln(4)
+	r.pdf.SetFont("Helvetica", "B", 13)
+	r.pdf.SetTextColor(20, 40, 80)
+	r.pdf.CellFormat(0, 8, r.translate(title), "", 1, "L", false, 0, "")
+}
+
+// Summary prints label / value pairs in two columns
+func (r *activityPDF) Summary(items [][2]string) {
+	r.pdf.SetFont("Helvetica", "", 10)
+	r.pdf.SetTextColor(30, 30, 30)
+
+	for i, item := range items {
+		r.pdf.CellFormat(60, pdfRowHeight, r.translate(item[0]), "B", 0, "L", false, 0, "")
+		r.pdf.CellFormat(70, pdfRowHeight, r.translate(item[1]), "B", 0, "R", false, 0, "")
+		if i%2 == 0 {
+			r.pdf.CellFormat(10, pdfRowHeight, "", "", 0, "L", false, 0, "")
+		} else {
+			r.pdf.Ln(-1)
+		}
+	}
+
+	if len(items)%2 == 1 {
+		r.pdf.Ln(-1)
+	}
+}
+
+func (r *activityPDF) Table(columns []pdfColumn) {
+	r.columns = columns
+	r.tableHeader()
+}
+
+func (r *activityPDF) tableHeader() {
+	r.pdf.SetFont("Helvetica", "B", 9)
+	r.pdf.SetFillColor(20, 40, 80)
+	r.pdf.SetTextColor(255, 255, 255)
+	r.pdf.SetDrawColor(200, 200, 200)
+	r.pdf.SetLineWidth(0.2)
+
+	for _, column := range r.columns {
+		r.pdf.CellFormat(column.Width, pdfRowHeight+1, r.translate(column.Title), "1", 0, "C", true, 0, "")
+	}
+	r.pdf.Ln(-1)
+}
+
+// Row adds a row to the current table, values wider than their column are shortened
+func (r *activityPDF) Row(values ...string) {
+	_, pageHeight := r.pdf.GetPageSize()
+	if r.pdf.GetY()+pdfRowHeight > pageHeight-pdfMargin-5 {
+		r.pdf.AddPage()
+		r.tableHeader()
+	}
+
+	r.pdf.SetFont("Helvetica", "", 8)
+	r.pdf.SetTextColor(30, 30, 30)
+
+	for i, column := range r.columns {
+		value := ""
+		if i < len(values) {
+			value = r.fit(r.translate(values[i]), column.Width-2)
+		}
+		r.pdf.CellFormat(column.Width, pdfRowHeight, value, "1", 0, column.Align, false, 0, "")
+	}
+	r.pdf.Ln(-1)
+}
+
+// Empty prints a placeholder row spanning the whole table
+func (r *activityPDF) Empty(message string) {
+	var width float64
+	for _, column := range r.columns {
+		width += column.Width
+	}
+
+	r.pdf.SetFont("Helvetica", "I", 8)
+	r.pdf.SetTextColor(120, 120, 120)
+	r.pdf.CellFormat(width, pdfRowHeight, r.translate(message), "1", 1, "C", false, 0, "")
+}
+
+func (r *activityPDF) fit(value string, width float64) string {
+	if r.pdf.GetStringWidth(value) <= width {
+		return value
+	}
+
+	// translated text is single byte cp1252, shortening per byte is safe
+	for len(value) > 0 && r.pdf.GetStringWidth(value+"...") > width {
+		value = value[:len(value)-1]
+	}
+
+	return value + "..."
+}
+
+func (r *activityPDF) Output(w io.Writer) error {
+	return r.pdf.Output(w)
+}
@@ -11,4 +11,7 @@ func (h *handler) Router(g *gin.RouterGroup) {
 	g.Use(middleware.Authenticate())
 	g.GET("/ship-docking", h.ShipDocking)
 	g.GET("/ship-fraud", h.ShipFraud)
+	g.GET("/ship-docking/export", h.ExportShipDocking)
+	g.GET("/ship-fraud/export", h.ExportShipFraud)
+	g.GET("/activity/pdf", h.ActivityReport)
 }
@@ -2,40 +2,318 @@ package report
 
 import (
 	"context"
+	"io"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
 	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/export"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type service struct {
-	shipRepository repository.Ship
+	appRepository       repository.App
+	shipRepository      repository.Ship
+	fraudCaseRepository repository.FraudCase
 }
 
 type Service interface {
-	ShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, error)
-	ShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, error)
+	ShipDocking(ctx context.Context, request dto.ReportShipDockedParam) (*dto.ReportShipDockingResponseList, error)
+	ShipFraud(ctx context.Context, request dto.ReportShipLocationParam) (*dto.ReportShipLocationResponseList, error)
+	ExportShipDocking(ctx context.Context, request dto.ReportShipDockedParam, w export.Writer) error
+	ExportShipFraud(ctx context.Context, request dto.ReportShipLocationParam, w export.Writer) error
+	ActivityReport(ctx context.Context, request dto.ReportActivityParam, w io.Writer) error
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		shipRepository: f.ShipRepository,
+		appRepository:       f.AppRepository,
+		shipRepository:      f.ShipRepository,
+		fraudCaseRepository: f.FraudCaseRepository,
 	}
 }
 
-func (s *service) ShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, error) {
-	res, err := s.shipRepository.ReportShipDocking(ctx, request)
+// dockingFiltered reports whether the request narrows the docking report, the unfiltered total is
+// only counted apart then
+func dockingFiltered(request dto.ReportShipDockedParam) bool {
+	return (len(request.LogType) > 0 && request.LogType[0] != "") || request.Search != "" ||
+		(request.StartDate != "" && request.EndDate != "")
+}
+
+// fraudFiltered reports whether the request narrows the fraud report
+func fraudFiltered(request dto.ReportShipLocationParam) bool {
+	return request.Search != "" || request.Reason != "" || (request.StartDate != "" && request.EndDate != "")
+}
+
+func (s *service) ShipDocking(ctx context.Context, request dto.ReportShipDockedParam) (*dto.ReportShipDockingResponseList, error) {
+	fetch, nextCursor, err := s.shipRepository.ReportShipDocking(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipRepository.ReportShipDockingCount(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	total := filtered
+	if dockingFiltered(request) {
+		total, err = s.shipRepository.ReportShipDockingCount(ctx, dto.ReportShipDockedParam{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := dto.ReportShipDockingResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			NextCursor:    nextCursor,
+			HasMore:       nextCursor != "",
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
 }
 
-func (s *service) ShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, error) {
-	res, err := s.shipRepository.ReportShipFraud(ctx, request)
+func (s *service) ShipFraud(ctx context.Context, request dto.ReportShipLocationParam) (*dto.ReportShipLocationResponseList, error) {
+	fetch, nextCursor, err := s.shipRepository.ReportShipFraud(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	filtered, err := s.shipRepository.ReportShipFraudCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	total := filtered
+	if fraudFiltered(request) {
+		total, err = s.shipRepository.ReportShipFraudCount(ctx, dto.ReportShipLocationParam{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := dto.ReportShipLocationResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			NextCursor:    nextCursor,
+			HasMore:       nextCursor != "",
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// ExportShipDocking writes the header and every row of the filtered docking report to w
+func (s *service) ExportShipDocking(ctx context.Context, request dto.ReportShipDockedParam, w export.Writer) error {
+	if err := w.Write(dockingExportHeader); err != nil {
+		return err
+	}
+
+	return s.shipRepository.ExportShipDocking(ctx, request, func(row dto.ReportShipDockingExportRow) error {
+		return w.Write(dockingExportCells(row))
+	})
+}
+
+// ExportShipFraud writes the header and every row of the filtered fraud report to w
+func (s *service) ExportShipFraud(ctx context.Context, request dto.ReportShipLocationParam, w export.Writer) error {
+	if err := w.Write(fraudExportHeader); err != nil {
+		return err
+	}
+
+	return s.shipRepository.ExportShipFraud(ctx, request, func(row dto.ReportShipFraudExportRow) error {
+		return w.Write(fraudExportCells(row))
+	})
+}
+
+// ActivityReport renders the harbour activity of a date range as PDF, dates are inclusive
+func (s *service) ActivityReport(ctx context.Context, request dto.ReportActivityParam, w io.Writer) error {
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the counters compare created_at with plain strings, extend the end date to its last second
+	rangeStart := request.StartDate + " 00:00:00"
+	rangeEnd := request.EndDate + " 23:59:59"
+
+	totalShip, err := s.shipRepository.CountShip(ctx)
+	if err != nil {
+		return err
+	}
+
+	allTime, err := s.shipRepository.CountStatistic(ctx)
+	if err != nil {
+		return err
+	}
+
+	rangeCounts := make(map[string]int64)
+	for _, status := range []string{string(model.Checkin), string(model.Checkout), string(model.OutOfScope)} {
+		count, err := s.shipRepository.CountShipByStatus(ctx, rangeStart, rangeEnd, status)
+		if err != nil {
+			return err
+		}
+		rangeCounts[status] = count
+	}
+
+	fraud, err := s.shipRepository.CountShipFraud(ctx, rangeStart, rangeEnd)
+	if err != nil {
+		return err
+	}
+
+	openCases, err := s.fraudCaseRepository.CountOpenFraudCase(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := newActivityPDF(appInfo.HarbourName, appInfo.HarbourCode, request.StartDate, request.EndDate)
+
+	report.Section("Summary")
+	report.Summary([][2]string{
+		{"Registered ships", formatCount(totalShip)},
+		{"Fraud fixes in period", formatCount(fraud)},
+		{"Check-ins in period", formatCount(rangeCounts[string(model.Checkin)])},
+		{"Check-ins all time", formatCount(allTime[0])},
+		{"Check-outs in period", formatCount(rangeCounts[string(model.Checkout)])},
+		{"Check-outs all time", formatCount(allTime[1])},
+		{"Out of scope in period", formatCount(rangeCounts[string(model.OutOfScope)])},
+		{"Fraud fixes all time", formatCount(allTime[2])},
+		{"Open fraud cases", formatCount(openCases.Open + openCases.Reviewing)},
+		{"Escalated fraud cases", formatCount(openCases.Escalated)},
+	})
+
+	report.Section("Check-ins and check-outs")
+	report.Table([]pdfColumn{
+		{Title: "Log ID", Width: 18, Align: "R"},
+		{Title: "Date", Width: 34, Align: "L"},
+		{Title: "Ship", Width: 55, Align: "L"},
+		{Title: "Status", Width: 22, Align: "C"},
+		{Title: "SIUP", Width: 40, Align: "L"},
+		{Title: "BKP", Width: 40, Align: "L"},
+		{Title: "GT", Width: 15, Align: "R"},
+		{Title: "Latitude", Width: 26, Align: "R"},
+		{Title: "Longitude", Width: 27, Align: "R"},
+	})
+
+	dockingParam := dto.ReportShipDockedParam{
+		LogType:   []string{string(model.Checkin), string(model.Checkout)},
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+	}
+
+	dockingRows := 0
+	err = s.shipRepository.ExportShipDocking(ctx, dockingParam, func(row dto.ReportShipDockingExportRow) error {
+		dockingRows++
+		report.Row(strconv.Itoa(row.LogID), row.LogDate.Format("2006-01-02 15:04:05"), row.ShipName, row.Status,
+			row.SIUP, row.BKP, row.GT, row.Lat, row.Long)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if dockingRows == 0 {
+		report.Empty("No check-in or check-out in this period")
+	}
+
+	report.Section("Fraud incidents")
+	report.Table([]pdfColumn{
+		{Title: "Case", Width: 15, Align: "R"},
+		{Title: "Ship", Width: 50, Align: "L"},
+		{Title: "Status", Width: 22, Align: "C"},
+		{Title: "Fixes", Width: 15, Align: "R"},
+		{Title: "Score", Width: 15, Align: "R"},
+		{Title: "Reasons", Width: 60, Align: "L"},
+		{Title: "Started", Width: 34, Align: "L"},
+		{Title: "Last seen", Width: 34, Align: "L"},
+		{Title: "Assignee", Width: 32, Align: "L"},
+	})
+
+	cases, err := s.fraudCaseRepository.FraudCaseList(ctx, dto.FraudCaseListParam{
+		Limit:     -1,
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cases {
+		report.Row(strconv.Itoa(c.ID), c.ShipName, c.Status, strconv.Itoa(c.FixCount), strconv.Itoa(c.MaxScore),
+			strings.Join(c.Reasons, ", "), c.StartedAt, c.LastSeenAt, c.AssigneeName)
+	}
+	if len(cases) == 0 {
+		report.Empty("No fraud incident in this period")
+	}
+
+	report.Section("Pending inspections")
+	report.Table([]pdfColumn{
+		{Title: "Log ID", Width: 18, Align: "R"},
+		{Title: "Ship", Width: 80, Align: "L"},
+		{Title: "Check-in date", Width: 40, Align: "L"},
+		{Title: "Latitude", Width: 30, Align: "R"},
+		{Title: "Longitude", Width: 30, Align: "R"},
+		{Title: "Inspected", Width: 32, Align: "C"},
+		{Title: "Reported", Width: 27, Align: "C"},
+	})
+
+	pending, err := s.shipRepository.NeedCheckupShip(ctx, dto.NeedCheckupShipParam{Limit: -1})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		report.Row(strconv.Itoa(p.LogID), p.ShipName, p.CheckinDate, p.Lat, p.Long, yesNo(p.IsInspected), yesNo(p.IsReported))
+	}
+	if len(pending) == 0 {
+		report.Empty("No ship waiting for inspection")
+	}
+
+	return report.Output(w)
+}
+
+// activityRange validates the requested period and defaults it to the current month up to today
+func activityRange(startDate string, endDate string) (string, string, error) {
+	now := time.Now()
+
+	if startDate == "" {
+		startDate = now.Format("2006-01") + "-01"
+	}
+	if endDate == "" {
+		endDate = now.Format("2006-01-02")
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return "", "", err
+	}
+
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return "", "", err
+	}
+
+	if end.Before(start) {
+		return endDate, startDate, nil
+	}
+
+	return startDate, endDate, nil
+}
+
+func formatCount(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func yesNo(value int) string {
+	if value == 1 {
+		return "Yes"
+	}
+
+	return "No"
 }
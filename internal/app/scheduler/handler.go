@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func authUser(c *gin.Context) (model.User, bool) {
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return model.User{}, false
+	}
+
+	return authUser, true
+}
+
+// jobError maps validation failures of a report job to a bad request
+func jobError(c *gin.Context, message string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse("invalid job id, no report job data", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidReportKind, constants.InvalidReportFormat, constants.InvalidJobFrequency,
+		constants.InvalidJobRunTime, constants.InvalidJobRecipients, constants.InvalidJobSchedule,
+		constants.InvalidJobMaxRetry, constants.HarbourRequired, constants.InvalidHarbour:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func bindingError(c *gin.Context, err error) {
+	errorMessage := gin.H{"errors": "please fill data"}
+	if err != io.EOF {
+		errors := util.FormatValidationError(err)
+		errorMessage = gin.H{"errors": errors}
+	}
+	response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", errorMessage)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (h *handler) ReportJobList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ReportJobListParam{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.DefaultQuery("search", ""),
+		Report: c.DefaultQuery("report", ""),
+	}
+
+	res, err := h.service.ReportJobList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve report job list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved report job list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ReportJobDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.Atoi(c.Param("job_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid job_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ReportJobDetail(ctx, jobID)
+	if err != nil {
+		jobError(c, "Failed to retrieve report job data", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved report job data", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreReportJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := authUser(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ReportJobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.StoreReportJob(ctx, user, request); err != nil {
+		jobError(c, "Failed to store report job", err)
+		return
+	}
+
+	response := util.APIResponse("Report job successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) UpdateReportJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.ReportJobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if request.ID == 0 {
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": "id is required"})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.service.UpdateReportJob(ctx, request); err != nil {
+		jobError(c, "Failed to update report job", err)
+		return
+	}
+
+	response := util.APIResponse("Report job successfully updated", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) DeleteReportJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.Atoi(c.Param("job_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid job_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.service.DeleteReportJob(ctx, jobID); err != nil {
+		jobError(c, "Failed to delete report job", err)
+		return
+	}
+
+	response := util.APIResponse("Report job successfully deleted", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) RunNow(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.ReportJobRunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bindingError(c, err)
+		return
+	}
+
+	if err := h.service.RunNow(ctx, request); err != nil {
+		jobError(c, "Failed to queue report job", err)
+		return
+	}
+
+	response := util.APIResponse("Report job queued, it runs within a minute", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ReportJobRuns(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	jobID, err := strconv.Atoi(c.Param("job_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid job_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ReportJobRunListParam{
+		Offset: offset,
+		Limit:  limit,
+		JobID:  jobID,
+		Status: strings.Split(c.DefaultQuery("status", ""), ","),
+	}
+
+	res, err := h.service.ReportJobRuns(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve report job runs: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved report job runs", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
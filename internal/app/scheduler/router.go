@@ -0,0 +1,19 @@
+package scheduler
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/job/list", h.ReportJobList)
+	g.GET("/job/detail/:job_id", h.ReportJobDetail)
+	g.POST("/job/store", h.StoreReportJob)
+	g.PUT("/job/update", h.UpdateReportJob)
+	g.DELETE("/job/:job_id", h.DeleteReportJob)
+	g.POST("/job/run", h.RunNow)
+	g.GET("/job/:job_id/runs", h.ReportJobRuns)
+}
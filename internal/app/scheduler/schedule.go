@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"owlharbour-api/internal/model"
+	"time"
+)
+
+const (
+	defaultRunTime  = "06:00"
+	defaultMaxRetry = 3
+	retryDelay      = 5 * time.Minute
+	maxMonthDay     = 28 // every month has it
+)
+
+// nextRun returns the first scheduled time of the job strictly after the given time
+func nextRun(job model.ReportJob, after time.Time) time.Time {
+	runTime, err := time.Parse("15:04", job.RunTime)
+	if err != nil {
+		runTime, _ = time.Parse("15:04", defaultRunTime)
+	}
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, runTime.Hour(), runTime.Minute(), 0, 0, after.Location())
+	}
+
+	switch job.Frequency {
+	case model.Weekly:
+		days := (job.Weekday - int(after.Weekday()) + 7) % 7
+		candidate := at(after.Year(), after.Month(), after.Day()+days)
+		if !candidate.After(after) {
+			candidate = candidate.AddDate(0, 0, 7)
+		}
+		return candidate
+	case model.Monthly:
+		candidate := at(after.Year(), after.Month(), job.MonthDay)
+		if !candidate.After(after) {
+			candidate = at(after.Year(), after.Month()+1, job.MonthDay)
+		}
+		return candidate
+	default:
+		candidate := at(after.Year(), after.Month(), after.Day())
+		if !candidate.After(after) {
+			candidate = candidate.AddDate(0, 0, 1)
+		}
+		return candidate
+	}
+}
+
+// reportPeriod is the inclusive date range covered by a run scheduled at the given time:
+// the previous day, the previous seven days or the previous calendar month
+func reportPeriod(frequency model.JobFrequency, scheduledAt time.Time) (string, string) {
+	day := time.Date(scheduledAt.Year(), scheduledAt.Month(), scheduledAt.Day(), 0, 0, 0, 0, scheduledAt.Location())
+
+	switch frequency {
+	case model.Weekly:
+		return day.AddDate(0, 0, -7).Format("2006-01-02"), day.AddDate(0, 0, -1).Format("2006-01-02")
+	case model.Monthly:
+		firstOfMonth := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		return firstOfMonth.AddDate(0, -1, 0).Format("2006-01-02"), firstOfMonth.AddDate(0, 0, -1).Format("2006-01-02")
+	default:
+		yesterday := day.AddDate(0, 0, -1).Format("2006-01-02")
+		return yesterday, yesterday
+	}
+}
+
+// retryAt spaces the attempts of a failed run further apart each time
+func retryAt(attempt int, now time.Time) time.Time {
+	return now.Add(time.Duration(attempt) * retryDelay)
+}
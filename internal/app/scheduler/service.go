@@ -0,0 +1,463 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"owlharbour-api/internal/app/report"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/export"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const formatPDF = "pdf"
+
+// reportFormats lists the attachment formats every report can be delivered in
+var reportFormats = map[model.ReportKind][]string{
+	model.ReportDocking:  {export.FormatCSV, export.FormatXLSX},
+	model.ReportFraud:    {export.FormatCSV, export.FormatXLSX},
+	model.ReportActivity: {formatPDF},
+}
+
+var reportTitles = map[model.ReportKind]string{
+	model.ReportDocking:  "Docking Summary",
+	model.ReportFraud:    "Fraud Report",
+	model.ReportActivity: "Ship Activity Report",
+}
+
+type service struct {
+	appRepository       repository.App
+	reportJobRepository repository.ReportJob
+	reportService       report.Service
+}
+
+type Service interface {
+	ReportJobList(ctx context.Context, request dto.ReportJobListParam) (*dto.ReportJobResponseList, error)
+	ReportJobDetail(ctx context.Context, ID int) (*dto.ReportJobResponse, error)
+	StoreReportJob(ctx context.Context, authUser model.User, request dto.ReportJobRequest) error
+	UpdateReportJob(ctx context.Context, request dto.ReportJobRequest) error
+	DeleteReportJob(ctx context.Context, ID int) error
+	RunNow(ctx context.Context, request dto.ReportJobRunRequest) error
+	ReportJobRuns(ctx context.Context, request dto.ReportJobRunListParam) (*dto.ReportJobRunResponseList, error)
+	RunDueJobs(ctx context.Context, now time.Time) error
+	RetryRuns(ctx context.Context, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:       f.AppRepository,
+		reportJobRepository: f.ReportJobRepository,
+		reportService:       report.NewService(f),
+	}
+}
+
+func (s *service) ReportJobList(ctx context.Context, request dto.ReportJobListParam) (*dto.ReportJobResponseList, error) {
+	total, err := s.reportJobRepository.ReportJobCount(ctx, dto.ReportJobListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.reportJobRepository.ReportJobCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.reportJobRepository.ReportJobList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ReportJobResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) ReportJobDetail(ctx context.Context, ID int) (*dto.ReportJobResponse, error) {
+	return s.reportJobRepository.ReportJobDetail(ctx, ID)
+}
+
+func (s *service) StoreReportJob(ctx context.Context, authUser model.User, request dto.ReportJobRequest) error {
+	job, err := jobFromRequest(request)
+	if err != nil {
+		return err
+	}
+
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return constants.InvalidHarbour
+		}
+		return err
+	}
+
+	job.CreatedBy = authUser.ID
+	job.HarbourID = harbour.ID
+	job.NextRunAt = nextRun(job, time.Now())
+
+	return s.reportJobRepository.StoreReportJob(ctx, &job)
+}
+
+func (s *service) UpdateReportJob(ctx context.Context, request dto.ReportJobRequest) error {
+	job, err := jobFromRequest(request)
+	if err != nil {
+		return err
+	}
+
+	// the schedule may have changed, the next run is always planned again from now
+	fields := map[string]interface{}{
+		"name":        job.Name,
+		"report":      job.Report,
+		"frequency":   job.Frequency,
+		"format":      job.Format,
+		"recipients":  job.Recipients,
+		"run_time":    job.RunTime,
+		"weekday":     job.Weekday,
+		"month_day":   job.MonthDay,
+		"next_run_at": nextRun(job, time.Now()),
+	}
+
+	// the retries and the active flag are optional, left out they keep their current value
+	if request.MaxRetry != nil {
+		fields["max_retry"] = job.MaxRetry
+	}
+
+	if request.IsActive != nil {
+		fields["is_active"] = job.IsActive
+	}
+
+	return s.reportJobRepository.UpdateReportJob(ctx, request.ID, fields)
+}
+
+func (s *service) DeleteReportJob(ctx context.Context, ID int) error {
+	return s.reportJobRepository.DeleteReportJob(ctx, ID)
+}
+
+// RunNow makes the job due immediately, the scheduler picks it up on its next tick
+func (s *service) RunNow(ctx context.Context, request dto.ReportJobRunRequest) error {
+	return s.reportJobRepository.UpdateReportJob(ctx, request.JobID, map[string]interface{}{
+		"next_run_at": time.Now(),
+	})
+}
+
+func (s *service) ReportJobRuns(ctx context.Context, request dto.ReportJobRunListParam) (*dto.ReportJobRunResponseList, error) {
+	total, err := s.reportJobRepository.ReportJobRunCount(ctx, dto.ReportJobRunListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.reportJobRepository.ReportJobRunCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.reportJobRepository.ReportJobRunList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ReportJobRunResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// RunDueJobs executes every active job whose next run has passed, a job missed while the
+// scheduler was down runs once and then continues on its regular schedule
+func (s *service) RunDueJobs(ctx context.Context, now time.Time) error {
+	jobs, err := s.reportJobRepository.DueReportJobs(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		periodStart, periodEnd := reportPeriod(job.Frequency, job.NextRunAt)
+
+		run := model.ReportJobRun{
+			ReportJobID: job.ID,
+			Status:      model.JobRunRunning,
+			Attempt:     1,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			ScheduledAt: job.NextRunAt,
+		}
+
+		claimed, err := s.reportJobRepository.ClaimReportJob(ctx, job, nextRun(job, now), &run)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		s.execute(ctx, job, run)
+	}
+
+	return nil
+}
+
+func (s *service) RetryRuns(ctx context.Context, now time.Time) error {
+	runs, err := s.reportJobRepository.DueRetryRuns(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		claimed, err := s.reportJobRepository.ClaimRetryRun(ctx, &run)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		job, err := s.reportJobRepository.ReportJobByID(ctx, run.ReportJobID)
+		if err != nil {
+			// the job was removed while the run was waiting
+			s.finish(ctx, run, 0, err)
+			continue
+		}
+
+		s.execute(ctx, *job, run)
+	}
+
+	return nil
+}
+
+// execute builds the report attachment, mails it and records the outcome of the run
+func (s *service) execute(ctx context.Context, job model.ReportJob, run model.ReportJobRun) {
+	// a job reports on and is branded with the harbour it was created for
+	ctx = tenant.WithHarbours(ctx, job.HarbourID)
+
+	attachment, err := s.render(ctx, job, run)
+	if err == nil {
+		err = s.deliver(ctx, job, run, attachment)
+	}
+
+	s.finish(ctx, run, job.MaxRetry, err)
+}
+
+func (s *service) finish(ctx context.Context, run model.ReportJobRun, maxRetry int, runErr error) {
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":      model.JobRunSuccess,
+		"finished_at": now,
+		"error":       "",
+	}
+
+	if runErr != nil {
+		fields["error"] = runErr.Error()
+		fields["status"] = model.JobRunFailed
+		if run.Attempt <= maxRetry {
+			fields["status"] = model.JobRunRetrying
+			fields["next_retry_at"] = retryAt(run.Attempt, now)
+		}
+		fmt.Printf("[*] Report job %d run %d attempt %d failed: %s\n", run.ReportJobID, run.ID, run.Attempt, runErr.Error())
+	} else {
+		fmt.Printf("[*] Report job %d run %d delivered\n", run.ReportJobID, run.ID)
+	}
+
+	if err := s.reportJobRepository.FinishReportJobRun(ctx, run.ID, fields); err != nil {
+		fmt.Printf("[*] Failed to record report job run %d: %s\n", run.ID, err.Error())
+	}
+}
+
+func (s *service) render(ctx context.Context, job model.ReportJob, run model.ReportJobRun) (helper.MailAttachment, error) {
+	attachment := helper.MailAttachment{
+		Filename: fmt.Sprintf("%s-report_%s_%s.%s", job.Report, run.PeriodStart, run.PeriodEnd, job.Format),
+	}
+
+	var buf bytes.Buffer
+
+	if job.Report == model.ReportActivity {
+		request := dto.ReportActivityParam{
+			StartDate: run.PeriodStart,
+			EndDate:   run.PeriodEnd,
+		}
+		if err := s.reportService.ActivityReport(ctx, request, &buf); err != nil {
+			return attachment, err
+		}
+
+		attachment.ContentType = "application/pdf"
+		attachment.Data = buf.Bytes()
+
+		return attachment, nil
+	}
+
+	contentType, ok := export.ContentType(job.Format)
+	if !ok {
+		return attachment, constants.InvalidReportFormat
+	}
+
+	w, err := export.NewWriter(job.Format, &buf, reportTitles[job.Report])
+	if err != nil {
+		return attachment, err
+	}
+
+	switch job.Report {
+	case model.ReportDocking:
+		err = s.reportService.ExportShipDocking(ctx, dto.ReportShipDockedParam{
+			StartDate: run.PeriodStart,
+			EndDate:   run.PeriodEnd,
+		}, w)
+	case model.ReportFraud:
+		err = s.reportService.ExportShipFraud(ctx, dto.ReportShipLocationParam{
+			StartDate: run.PeriodStart,
+			EndDate:   run.PeriodEnd,
+		}, w)
+	default:
+		err = constants.InvalidReportKind
+	}
+	if err != nil {
+		return attachment, err
+	}
+
+	if err := w.Close(); err != nil {
+		return attachment, err
+	}
+
+	attachment.ContentType = contentType
+	attachment.Data = buf.Bytes()
+
+	return attachment, nil
+}
+
+func (s *service) deliver(ctx context.Context, job model.ReportJob, run model.ReportJobRun, attachment helper.MailAttachment) error {
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_report.html")
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Title       string
+		HarbourName string
+		Report      string
+		PeriodStart string
+		PeriodEnd   string
+		Filename    string
+	}{
+		Title:       job.Name,
+		HarbourName: appInfo.HarbourName,
+		Report:      reportTitles[job.Report],
+		PeriodStart: run.PeriodStart,
+		PeriodEnd:   run.PeriodEnd,
+		Filename:    attachment.Filename,
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("%s %s - %s", job.Name, run.PeriodStart, run.PeriodEnd)
+
+	return helper.SendMail(job.Recipients, subject, tplBuffer.String(), attachment)
+}
+
+// jobFromRequest validates the request and fills the defaults of a report job
+func jobFromRequest(request dto.ReportJobRequest) (model.ReportJob, error) {
+	job := model.ReportJob{
+		Name:      strings.TrimSpace(request.Name),
+		Report:    model.ReportKind(request.Report),
+		Frequency: model.JobFrequency(request.Frequency),
+		Format:    strings.ToLower(request.Format),
+		RunTime:   request.RunTime,
+		Weekday:   request.Weekday,
+		MonthDay:  request.MonthDay,
+		MaxRetry:  defaultMaxRetry,
+		IsActive:  1,
+	}
+
+	formats, ok := reportFormats[job.Report]
+	if !ok {
+		return job, constants.InvalidReportKind
+	}
+
+	validFormat := false
+	for _, format := range formats {
+		if format == job.Format {
+			validFormat = true
+		}
+	}
+	if !validFormat {
+		return job, constants.InvalidReportFormat
+	}
+
+	switch job.Frequency {
+	case model.Daily, model.Weekly, model.Monthly:
+	default:
+		return job, constants.InvalidJobFrequency
+	}
+
+	if job.RunTime == "" {
+		job.RunTime = defaultRunTime
+	}
+	if _, err := time.Parse("15:04", job.RunTime); err != nil {
+		return job, constants.InvalidJobRunTime
+	}
+
+	if job.MonthDay == 0 {
+		job.MonthDay = 1
+	}
+	if job.Weekday < 0 || job.Weekday > 6 || job.MonthDay < 1 || job.MonthDay > maxMonthDay {
+		return job, constants.InvalidJobSchedule
+	}
+
+	var recipients []string
+	for _, recipient := range request.Recipients {
+		address, err := mail.ParseAddress(strings.TrimSpace(recipient))
+		if err != nil {
+			return job, constants.InvalidJobRecipients
+		}
+		recipients = append(recipients, address.Address)
+	}
+	if len(recipients) == 0 {
+		return job, constants.InvalidJobRecipients
+	}
+	job.Recipients = strings.Join(recipients, ",")
+
+	// 0 never retries a failed run
+	if request.MaxRetry != nil {
+		if *request.MaxRetry < 0 {
+			return job, constants.InvalidJobMaxRetry
+		}
+		job.MaxRetry = *request.MaxRetry
+	}
+
+	if request.IsActive != nil && !*request.IsActive {
+		job.IsActive = 0
+	}
+
+	return job, nil
+}
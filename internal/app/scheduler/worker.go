@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const tickInterval = time.Minute
+
+// WorkerSchedule checks for due report jobs and failed runs waiting for a retry every minute
+func (h *handler) WorkerSchedule(ctx context.Context) {
+	fmt.Println("[*] Report scheduler started. To exit press CTRL+C")
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+
+		if err := h.service.RunDueJobs(ctx, now); err != nil {
+			fmt.Println("[*] Failed to run due report jobs:", err.Error())
+		}
+
+		if err := h.service.RetryRuns(ctx, now); err != nil {
+			fmt.Println("[*] Failed to retry report job runs:", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Context cancelled, exiting WorkerSchedule")
+			return
+		case <-ticker.C:
+		}
+	}
+}
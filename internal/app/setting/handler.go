@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
 	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/util"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,7 +25,15 @@ func NewHandler(f *factory.Factory) *handler {
 }
 
 func (h *handler) GetDataSetting(c *gin.Context) {
-	data, err := h.service.GetSetting(c)
+	harbourCode, _ := strconv.Atoi(c.DefaultQuery("harbour_code", "0"))
+
+	data, err := h.service.GetSetting(c, harbourCode)
+
+	if err == constants.InvalidHarbour || err == constants.HarbourRequired {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 
 	if err == constants.NotFoundDataAppSetting {
 		response := util.APIResponse(fmt.Sprintf("%s", constants.NotFoundDataAppSetting), http.StatusBadRequest, "failed", nil)
@@ -38,6 +48,12 @@ func (h *handler) GetDataSetting(c *gin.Context) {
 func (h *handler) GetDataSettingWeb(c *gin.Context) {
 	data, err := h.service.GetSettingWeb(c)
 
+	if err == constants.HarbourRequired {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err == constants.NotFoundDataAppSetting {
 		response := util.APIResponse(fmt.Sprintf("%s", constants.NotFoundDataAppSetting), http.StatusBadRequest, "failed", nil)
 		c.JSON(http.StatusBadRequest, response)
@@ -69,6 +85,101 @@ func (h *handler) Store(c *gin.Context) {
 		return
 	}
 
+	if err == constants.InvalidInspectionSla || err == constants.InvalidAssignMode || err == constants.InvalidTimezone ||
+		err == constants.HarbourRequired || err == constants.HarbourCodeTaken {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err == constants.HarbourForbidden {
+		response := util.APIResponse(err.Error(), http.StatusForbidden, "failed", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if err != nil {
+		response := util.APIResponse("Failed create or update setting: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
 	response := util.APIResponse("Success create or update setting", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *handler) HarbourList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.HarbourListParam{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.DefaultQuery("search", ""),
+	}
+
+	res, err := h.service.HarbourList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve harbour list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved harbour list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) StoreHarbour(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	var payload dto.PayloadStoreSetting
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		errorMessage := gin.H{"errors": "Please fill data"}
+		if err != io.EOF {
+			errors := util.FormatValidationError(err)
+			errorMessage = gin.H{"errors": errors}
+		}
+		response := util.APIResponse("Error validation", http.StatusUnprocessableEntity, "failed", errorMessage)
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err := h.service.StoreHarbour(ctx, authUser, payload)
+	if err != nil {
+		switch err {
+		case constants.HarbourForbidden:
+			response := util.APIResponse(err.Error(), http.StatusForbidden, "failed", nil)
+			c.JSON(http.StatusForbidden, response)
+		case constants.InvalidInspectionSla, constants.InvalidAssignMode, constants.InvalidTimezone, constants.HarbourCodeTaken:
+			response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := util.APIResponse("Failed to store harbour: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := util.APIResponse("Harbour successfully stored", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
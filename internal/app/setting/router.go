@@ -13,4 +13,6 @@ func (h *handler) Router(g *gin.RouterGroup) {
 	g.Use(middleware.Authenticate())
 	g.GET("/web", h.GetDataSettingWeb)
 	g.POST("/create-or-update", h.Store)
+	g.GET("/harbour/list", h.HarbourList)
+	g.POST("/harbour/store", h.StoreHarbour)
 }
@@ -7,26 +7,50 @@ import (
 	"owlharbour-api/internal/model"
 	"owlharbour-api/internal/repository"
 	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type service struct {
-	AppRepository repository.App
+	AppRepository     repository.App
+	HarbourRepository repository.Harbour
 }
 
 type Service interface {
 	CreateOrUpdate(ctx context.Context, payload dto.PayloadStoreSetting) error
-	GetSetting(ctx context.Context) (dto.GetDataSetting, error)
+	GetSetting(ctx context.Context, harbourCode int) (dto.GetDataSetting, error)
 	GetSettingWeb(ctx context.Context) (dto.GetDataSettingWeb, error)
+	HarbourList(ctx context.Context, request dto.HarbourListParam) (*dto.HarbourResponseList, error)
+	StoreHarbour(ctx context.Context, authUser model.User, payload dto.PayloadStoreSetting) error
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		AppRepository: f.AppRepository,
+		AppRepository:     f.AppRepository,
+		HarbourRepository: f.HarbourRepository,
 	}
 }
 
-func (s *service) GetSetting(ctx context.Context) (dto.GetDataSetting, error) {
-	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "harbour_code, harbour_name, mode, interval, range, admin_contact")
+// GetSetting returns the settings for the mobile app, harbourCode selects the harbour
+// of the device while the request carries no harbour yet
+func (s *service) GetSetting(ctx context.Context, harbourCode int) (dto.GetDataSetting, error) {
+	if harbourCode != 0 {
+		harbour, err := s.HarbourRepository.HarbourByCode(ctx, harbourCode)
+		if err != nil {
+			return dto.GetDataSetting{}, constants.InvalidHarbour
+		}
+
+		ctx = tenant.WithHarbours(ctx, harbour.ID)
+	}
+
+	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "id, code, name, mode, interval, range, admin_contact, inspection_sla_hours, inspection_assign, timezone")
+	if err == constants.HarbourRequired {
+		return dto.GetDataSetting{}, err
+	}
 	if err != nil {
 		return dto.GetDataSetting{}, constants.NotFoundDataAppSetting
 	}
@@ -34,13 +58,16 @@ func (s *service) GetSetting(ctx context.Context) (dto.GetDataSetting, error) {
 	getGeofance, err := s.AppRepository.GetPolygon(ctx)
 	if err != nil {
 		data := dto.GetDataSetting{
-			HarbourCode:  appsetting.HarbourCode,
-			HarbourName:  appsetting.HarbourName,
-			Mode:         appsetting.Mode.String(),
-			Interval:     appsetting.Interval,
-			Range:        appsetting.Range,
-			AdminContact: appsetting.AdminContact,
-			Geofences:    nil,
+			HarbourCode:        appsetting.Code,
+			HarbourName:        appsetting.Name,
+			Mode:               appsetting.Mode.String(),
+			Interval:           appsetting.Interval,
+			Range:              appsetting.Range,
+			AdminContact:       appsetting.AdminContact,
+			InspectionSlaHours: appsetting.InspectionSlaHours,
+			InspectionAssign:   string(appsetting.InspectionAssign),
+			Timezone:           appsetting.Timezone,
+			Geofences:          nil,
 		}
 		return data, nil
 	}
@@ -55,34 +82,43 @@ func (s *service) GetSetting(ctx context.Context) (dto.GetDataSetting, error) {
 	}
 
 	data := dto.GetDataSetting{
-		HarbourCode:  appsetting.HarbourCode,
-		HarbourName:  appsetting.HarbourName,
-		Mode:         appsetting.Mode.String(),
-		Interval:     appsetting.Interval,
-		Range:        appsetting.Range,
-		AdminContact: appsetting.AdminContact,
-		Geofences:    geofences,
+		HarbourCode:        appsetting.Code,
+		HarbourName:        appsetting.Name,
+		Mode:               appsetting.Mode.String(),
+		Interval:           appsetting.Interval,
+		Range:              appsetting.Range,
+		AdminContact:       appsetting.AdminContact,
+		InspectionSlaHours: appsetting.InspectionSlaHours,
+		InspectionAssign:   string(appsetting.InspectionAssign),
+		Timezone:           appsetting.Timezone,
+		Geofences:          geofences,
 	}
 
 	return data, nil
 }
 
 func (s *service) GetSettingWeb(ctx context.Context) (dto.GetDataSettingWeb, error) {
-	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "harbour_code, harbour_name, mode, interval, range, admin_contact")
-	if err != nil {
+	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "id, code, name, mode, interval, range, admin_contact, inspection_sla_hours, inspection_assign, timezone")
+	if err == constants.HarbourRequired {
 		return dto.GetDataSettingWeb{}, err
 	}
+	if err != nil {
+		return dto.GetDataSettingWeb{}, constants.NotFoundDataAppSetting
+	}
 
 	getGeofance, err := s.AppRepository.GetPolygon(ctx)
 	if err != nil {
 		data := dto.GetDataSettingWeb{
-			HarbourCode:  appsetting.HarbourCode,
-			HarbourName:  appsetting.HarbourName,
-			Mode:         appsetting.Mode.String(),
-			Interval:     appsetting.Interval,
-			Range:        appsetting.Range,
-			AdminContact: appsetting.AdminContact,
-			Geofences:    nil,
+			HarbourCode:        appsetting.Code,
+			HarbourName:        appsetting.Name,
+			Mode:               appsetting.Mode.String(),
+			Interval:           appsetting.Interval,
+			Range:              appsetting.Range,
+			AdminContact:       appsetting.AdminContact,
+			InspectionSlaHours: appsetting.InspectionSlaHours,
+			InspectionAssign:   string(appsetting.InspectionAssign),
+			Timezone:           appsetting.Timezone,
+			Geofences:          nil,
 		}
 		return data, nil
 	}
@@ -96,54 +132,180 @@ func (s *service) GetSettingWeb(ctx context.Context) (dto.GetDataSettingWeb, err
 		geofences = append(geofences, dataGeofance)
 	}
 	data := dto.GetDataSettingWeb{
-		HarbourCode:  appsetting.HarbourCode,
-		HarbourName:  appsetting.HarbourName,
-		Mode:         appsetting.Mode.String(),
-		Interval:     appsetting.Interval,
-		Range:        appsetting.Range,
-		AdminContact: appsetting.AdminContact,
-		Geofences:    geofences,
+		HarbourCode:        appsetting.Code,
+		HarbourName:        appsetting.Name,
+		Mode:               appsetting.Mode.String(),
+		Interval:           appsetting.Interval,
+		Range:              appsetting.Range,
+		AdminContact:       appsetting.AdminContact,
+		InspectionSlaHours: appsetting.InspectionSlaHours,
+		InspectionAssign:   string(appsetting.InspectionAssign),
+		Timezone:           appsetting.Timezone,
+		Geofences:          geofences,
 	}
 
 	return data, nil
 }
+
+func validateSetting(payload dto.PayloadStoreSetting) error {
+	if payload.InspectionSlaHours < 0 {
+		return constants.InvalidInspectionSla
+	}
+
+	assign := model.InspectionAssignMode(payload.InspectionAssign)
+	if assign != "" && assign != model.AssignRoundRobin && assign != model.AssignManual {
+		return constants.InvalidAssignMode
+	}
+
+	if payload.Timezone != "" {
+		if _, err := time.LoadLocation(payload.Timezone); err != nil {
+			return constants.InvalidTimezone
+		}
+	}
+
+	return nil
+}
+
+// settingHarbour maps the payload to a harbour, every harbour has a zone its time series are cut in
+func settingHarbour(payload dto.PayloadStoreSetting) model.Harbour {
+	timezone := payload.Timezone
+	if timezone == "" {
+		timezone = helper.DefaultTimezone()
+	}
+
+	return model.Harbour{
+		Code:               payload.HarbourCode,
+		Name:               payload.HarbourName,
+		Mode:               model.ModeType(payload.Mode),
+		Interval:           payload.Interval,
+		Range:              payload.Range,
+		AdminContact:       payload.AdminContact,
+		IsActive:           1,
+		InspectionSlaHours: payload.InspectionSlaHours,
+		InspectionAssign:   model.InspectionAssignMode(payload.InspectionAssign),
+		Timezone:           timezone,
+	}
+}
+
+// CreateOrUpdate saves the settings of the harbour of ctx, the first harbour of a new
+// deployment is created by a superadmin through it
 func (s *service) CreateOrUpdate(ctx context.Context, payload dto.PayloadStoreSetting) error {
-	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "harbour_code")
-	if err != nil {
-		dataStore := model.AppSetting{
-			HarbourCode:  payload.HarbourCode,
-			HarbourName:  payload.HarbourName,
-			Mode:         model.ModeType(payload.Mode),
-			Interval:     payload.Interval,
-			Range:        payload.Range,
-			AdminContact: payload.AdminContact,
+	if err := validateSetting(payload); err != nil {
+		return err
+	}
+
+	var harbourID int
+
+	appsetting, err := s.AppRepository.FindLatestSetting(ctx, "id, code, timezone")
+	if err == gorm.ErrRecordNotFound {
+		if _, scoped := tenant.Harbours(ctx); scoped {
+			return constants.HarbourForbidden
+		}
+
+		dataStore := settingHarbour(payload)
+		if err := s.HarbourRepository.StoreHarbour(ctx, &dataStore); err != nil {
+			return err
 		}
 
-		s.AppRepository.StoreSetting(ctx, dataStore)
+		harbourID = dataStore.ID
+	} else if err != nil {
+		return err
 	} else {
-		update := model.AppSetting{
-			HarbourCode:  payload.HarbourCode,
-			HarbourName:  payload.HarbourName,
-			Mode:         model.ModeType(payload.Mode),
-			Interval:     payload.Interval,
-			Range:        payload.Range,
-			AdminContact: payload.AdminContact,
+		taken, err := s.HarbourRepository.HarbourCodeExists(ctx, payload.HarbourCode, appsetting.ID)
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			return constants.HarbourCodeTaken
 		}
 
-		s.AppRepository.UpsertSetting(ctx, &update, "harbour_code,harbour_name,mode,interval,range,admin_contact,updated_at", "harbour_code = ?", appsetting.HarbourCode)
+		// settings saved without a zone keep the one of the harbour
+		update := settingHarbour(payload)
+		if payload.Timezone == "" && appsetting.Timezone != "" {
+			update.Timezone = appsetting.Timezone
+		}
+
+		if err := s.AppRepository.UpsertSetting(ctx, &update, "code,name,mode,interval,range,admin_contact,inspection_sla_hours,inspection_assign,timezone,updated_at", "id = ?", appsetting.ID); err != nil {
+			return constants.ErrorUpdateAppSetting
+		}
+
+		harbourID = appsetting.ID
 	}
 
-	if payload.Geofence != nil {
-		s.AppRepository.DeleteAllGeofence(ctx)
-		for _, geofence := range payload.Geofence {
-			store := model.AppGeofence{
-				Long: geofence.Long,
-				Lat:  geofence.Lat,
-			}
+	return s.storeGeofence(ctx, harbourID, payload.Geofence)
+}
 
-			s.AppRepository.StoreGeofence(ctx, store)
+func (s *service) storeGeofence(ctx context.Context, harbourID int, geofences []dto.PayloadAppGeofence) error {
+	if geofences == nil {
+		return nil
+	}
+
+	s.AppRepository.DeleteAllGeofence(ctx, harbourID)
+	for _, geofence := range geofences {
+		store := model.AppGeofence{
+			HarbourID: harbourID,
+			Long:      geofence.Long,
+			Lat:       geofence.Lat,
 		}
+
+		s.AppRepository.StoreGeofence(ctx, store)
 	}
 
 	return nil
 }
+
+func (s *service) HarbourList(ctx context.Context, request dto.HarbourListParam) (*dto.HarbourResponseList, error) {
+	total, err := s.HarbourRepository.HarbourCount(ctx, dto.HarbourListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.HarbourRepository.HarbourCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.HarbourRepository.HarbourList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.HarbourResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// StoreHarbour adds another harbour to the instance, only superadmins manage harbours
+func (s *service) StoreHarbour(ctx context.Context, authUser model.User, payload dto.PayloadStoreSetting) error {
+	if authUser.Role != model.SuperAdmin {
+		return constants.HarbourForbidden
+	}
+
+	if err := validateSetting(payload); err != nil {
+		return err
+	}
+
+	taken, err := s.HarbourRepository.HarbourCodeExists(ctx, payload.HarbourCode, 0)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return constants.HarbourCodeTaken
+	}
+
+	harbour := settingHarbour(payload)
+	if err := s.HarbourRepository.StoreHarbour(ctx, &harbour); err != nil {
+		return err
+	}
+
+	return s.storeGeofence(ctx, harbour.ID, payload.Geofence)
+}
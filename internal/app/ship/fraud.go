@@ -0,0 +1,99 @@
+package ship
+
+import (
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fraudThreshold = 50 // score from which a fix is reported as fraud
+
+	maxShipSpeed     = 40.0             // knots, nothing in the fleet sails faster
+	minSpeedMovement = 50.0             // metres, shorter hops are treated as gps jitter
+	teleportDistance = 5000.0           // metres covered at an impossible speed
+	inlandDistance   = 2000.0           // metres from the harbour while on ground
+	frozenPeriod     = 30 * time.Minute // identical coordinates outside the harbour
+	maxClockAhead    = 5 * time.Minute  // device clock running ahead of the server
+	maxFixAge        = time.Hour        // fix taken long before it was received
+)
+
+var fraudWeights = map[model.FraudReason]int{
+	model.FraudMocked:          100,
+	model.FraudTeleport:        80,
+	model.FraudImpossibleSpeed: 50,
+	model.FraudInland:          50,
+	model.FraudFrozenPosition:  40,
+	model.FraudTimestamp:       30,
+}
+
+// fixAnalysis is everything the ingestion path knows about a fix when it is scored
+type fixAnalysis struct {
+	IsMocked        int
+	Fix             helper.GeoPoint
+	LastFix         *model.ShipLocationLog
+	Speed           float64
+	OnGround        bool
+	HarbourDistance float64
+	FrozenSince     *time.Time
+	DeviceTime      *time.Time
+	Now             time.Time
+}
+
+// detectFraud scores a fix with server side checks so spoofing is caught even when the
+// client does not raise is_mocked, it returns a score capped at 100 and the matched reasons
+func detectFraud(a fixAnalysis) (int, []model.FraudReason) {
+	var reasons []model.FraudReason
+
+	if a.IsMocked == 1 {
+		reasons = append(reasons, model.FraudMocked)
+	}
+
+	if a.LastFix != nil && a.Speed > maxShipSpeed {
+		lastLat, errLat := strconv.ParseFloat(a.LastFix.Lat, 64)
+		lastLong, errLong := strconv.ParseFloat(a.LastFix.Long, 64)
+		if errLat == nil && errLong == nil {
+			distance := helper.Haversine(helper.GeoPoint{Lat: lastLat, Long: lastLong}, a.Fix)
+			if distance >= teleportDistance {
+				reasons = append(reasons, model.FraudTeleport)
+			} else if distance >= minSpeedMovement {
+				reasons = append(reasons, model.FraudImpossibleSpeed)
+			}
+		}
+	}
+
+	if a.OnGround && a.HarbourDistance > inlandDistance {
+		reasons = append(reasons, model.FraudInland)
+	}
+
+	if a.FrozenSince != nil && a.Now.Sub(*a.FrozenSince) >= frozenPeriod {
+		reasons = append(reasons, model.FraudFrozenPosition)
+	}
+
+	if a.DeviceTime != nil {
+		if a.DeviceTime.Sub(a.Now) > maxClockAhead || a.Now.Sub(*a.DeviceTime) > maxFixAge {
+			reasons = append(reasons, model.FraudTimestamp)
+		}
+	}
+
+	score := 0
+	for _, reason := range reasons {
+		score += fraudWeights[reason]
+	}
+	if score > 100 {
+		score = 100
+	}
+
+	return score, reasons
+}
+
+func fraudReasonString(reasons []model.FraudReason) string {
+	values := make([]string, len(reasons))
+	for i, reason := range reasons {
+		values[i] = string(reason)
+	}
+
+	return strings.Join(values, ",")
+}
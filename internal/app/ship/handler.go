@@ -7,14 +7,24 @@ import (
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/internal/model"
 	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/log"
 	"owlharbour-api/pkg/util"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"gorm.io/gorm"
 )
 
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
 type handler struct {
 	service            Service
 	rabbitMqRepository repository.RabbitMq
@@ -262,6 +272,12 @@ func (h *handler) UpdateShipDetail(c *gin.Context) {
 		return
 	}
 
+	if user, ok := c.Get("user"); ok {
+		if authUser, ok := user.(model.User); ok {
+			request.ChangedBy = authUser.ID
+		}
+	}
+
 	err := h.service.UpdateShipDetail(ctx, request)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -310,6 +326,75 @@ func (h *handler) ShipDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *handler) ShipDetailHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	shipID, err := strconv.Atoi(c.Param("ship_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid ship_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ShipDetailHistoryParam{
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	res, err := h.service.ShipDetailHistory(ctx, shipID, param)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response := util.APIResponse("invalid ship id, no ship data", http.StatusBadRequest, "failed", nil)
+			c.JSON(http.StatusBadRequest, response)
+		} else {
+			response := util.APIResponse("Failed to retrieve ship detail history: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship detail history", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+// ShipDetailAsOf returns the ship details as they were at the date query param
+func (h *handler) ShipDetailAsOf(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	shipID, err := strconv.Atoi(c.Param("ship_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid ship_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ShipDetailAsOf(ctx, shipID, c.Query("date"))
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			response := util.APIResponse("no ship detail data at this date", http.StatusNotFound, "failed", nil)
+			c.JSON(http.StatusNotFound, response)
+		case constants.InvalidAsOfDate:
+			response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := util.APIResponse("Failed to retrieve ship detail: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship detail", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *handler) PairingDetailByUsername(c *gin.Context) {
 	ctx := c.Request.Context()
 	username := c.Query("username")
@@ -355,11 +440,18 @@ func (h *handler) ShipDockLog(c *gin.Context) {
 	param := dto.ShipLogParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		StartDate: dateStart,
 		EndDate:   dateEnd,
 	}
 
 	res, err := h.service.ShipDockLog(ctx, param, shipID)
+	if err == constants.InvalidCursor {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err != nil {
 		response := util.APIResponse("Failed to retrieve ship list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
 		c.JSON(http.StatusInternalServerError, response)
@@ -390,11 +482,18 @@ func (h *handler) ShipLocationLog(c *gin.Context) {
 	param := dto.ShipLogParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		StartDate: dateStart,
 		EndDate:   dateEnd,
 	}
 
 	res, err := h.service.ShipLocationLog(ctx, param, shipID)
+	if err == constants.InvalidCursor {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err != nil {
 		response := util.APIResponse("Failed to retrieve ship list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
 		c.JSON(http.StatusInternalServerError, response)
@@ -424,11 +523,18 @@ func (h *handler) ShipDockLogByDevice(c *gin.Context) {
 	param := dto.ShipLogParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		StartDate: dateStart,
 		EndDate:   dateEnd,
 	}
 
 	res, err := h.service.ShipDockLog(ctx, param, deviceID)
+	if err == constants.InvalidCursor {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err != nil {
 		response := util.APIResponse("Failed to retrieve ship list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
 		c.JSON(http.StatusInternalServerError, response)
@@ -458,11 +564,18 @@ func (h *handler) ShipLocationLogByDevice(c *gin.Context) {
 	param := dto.ShipLogParam{
 		Offset:    offset,
 		Limit:     limit,
+		Cursor:    c.DefaultQuery("cursor", ""),
 		StartDate: dateStart,
 		EndDate:   dateEnd,
 	}
 
 	res, err := h.service.ShipLocationLog(ctx, param, deviceID)
+	if err == constants.InvalidCursor {
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	if err != nil {
 		response := util.APIResponse("Failed to retrieve ship list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
 		c.JSON(http.StatusInternalServerError, response)
@@ -486,3 +599,285 @@ func (h *handler) PairingRequestCount(c *gin.Context) {
 	response := util.APIResponse("Successfully retrieved pairing ship count", http.StatusOK, "success", res)
 	c.JSON(http.StatusOK, response)
 }
+
+func trackParam(c *gin.Context) dto.ShipTrackParam {
+	tolerance, _ := strconv.ParseFloat(c.DefaultQuery("tolerance", "0"), 64)
+	bucket, _ := strconv.Atoi(c.DefaultQuery("bucket", "0"))
+
+	return dto.ShipTrackParam{
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+		Simplify:  c.DefaultQuery("simplify", TrackSimplifyDouglasPeucker),
+		Tolerance: tolerance,
+		Bucket:    bucket,
+	}
+}
+
+func trackError(c *gin.Context, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse("invalid ship id, no ship data", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.InvalidTrackRange:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse("Failed to retrieve ship track: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+func (h *handler) ShipTrack(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	shipID, err := strconv.Atoi(c.Param("ship_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid ship_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ShipTrack(ctx, shipID, trackParam(c))
+	if err != nil {
+		trackError(c, err)
+		return
+	}
+
+	if c.DefaultQuery("format", "json") == "geojson" {
+		response := util.APIResponse("Successfully retrieved ship track", http.StatusOK, "success", trackGeoJSON(res))
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship track", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ShipTrackReplay(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	shipID, err := strconv.Atoi(c.Param("ship_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid ship_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// speed is the replay acceleration, 60 means one minute of track is played in one second
+	speed, _ := strconv.ParseFloat(c.DefaultQuery("speed", "60"), 64)
+	if speed <= 0 {
+		speed = 60
+	}
+
+	track, err := h.service.ShipTrack(ctx, shipID, trackParam(c))
+	if err != nil {
+		trackError(c, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Logging("Failed on ws handshake: %s", err.Error()).Error()
+		return
+	}
+
+	defer conn.Close()
+
+	// read from the connection so a client close frame stops the replay
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	maxDelay := 5 * time.Second
+	total := len(track.Points)
+
+	for i := range track.Points {
+		if i > 0 {
+			delay := time.Duration(float64(track.Points[i].RecordedAt.Sub(track.Points[i-1].RecordedAt)) / speed)
+			if delay > maxDelay {
+				delay = maxDelay
+			}
+
+			select {
+			case <-closed:
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		message := dto.ShipTrackReplayMessage{
+			Event: "point",
+			Index: i,
+			Total: total,
+			Point: &track.Points[i],
+		}
+
+		if err := conn.WriteJSON(message); err != nil {
+			log.Logging("Error sending track replay ship %d, Err: %s", shipID, err.Error()).Error()
+			return
+		}
+	}
+
+	conn.WriteJSON(dto.ShipTrackReplayMessage{
+		Event: "finished",
+		Index: total,
+		Total: total,
+	})
+}
+
+func (h *handler) ShipReportingCompliance(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ShipReportingComplianceParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.ShipReportingCompliance(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve reporting compliance: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved reporting compliance", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func importError(c *gin.Context, message string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		response := util.APIResponse("invalid import id, no import data", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	case constants.AttachmentTooLarge:
+		response := util.APIResponse(err.Error(), http.StatusRequestEntityTooLarge, "failed", nil)
+		c.JSON(http.StatusRequestEntityTooLarge, response)
+	case constants.InvalidImportFormat, constants.ImportMissingColumn, constants.ImportTooManyRows, constants.ImportNotValidated,
+		constants.HarbourRequired, constants.InvalidHarbour:
+		response := util.APIResponse(err.Error(), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := util.APIResponse(message+": "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+// ImportShips uploads a csv or xlsx vessel registry and starts its dry run
+func (h *handler) ImportShips(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := c.Get("user")
+	if !ok {
+		response := util.APIResponse("User information not found", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	authUser, ok := user.(model.User)
+	if !ok {
+		response := util.APIResponse("Invalid user type", http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": "file is required"})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ImportShips(ctx, authUser, file)
+	if err != nil {
+		importError(c, "Failed to import ships", err)
+		return
+	}
+
+	response := util.APIResponse("Ship import successfully uploaded", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) CommitImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	importID, err := strconv.Atoi(c.Param("import_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid import_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.CommitImport(ctx, importID)
+	if err != nil {
+		importError(c, "Failed to commit ship import", err)
+		return
+	}
+
+	response := util.APIResponse("Ship import successfully committed", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ImportDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	importID, err := strconv.Atoi(c.Param("import_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid import_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.ImportDetail(ctx, importID)
+	if err != nil {
+		importError(c, "Failed to retrieve ship import", err)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship import", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ImportList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ShipImportListParam{
+		Offset: offset,
+		Limit:  limit,
+		Status: c.DefaultQuery("status", ""),
+	}
+
+	res, err := h.service.ImportList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve ship import list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship import list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,507 @@
+package ship
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/export"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"owlharbour-api/pkg/util"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportSize     = 5 << 20
+	defaultMaxRows    = 5000
+	defaultSyncRows   = 200
+	maxImportErrors   = 500
+	progressBatchRows = 25
+
+	// a running import saves its progress every progressBatchRows rows, one silent for this long
+	// was left by a crashed worker
+	staleImportTimeout = 15 * time.Minute
+)
+
+// importColumns maps the normalized header names of an import file to the row fields
+var importColumns = map[string]string{
+	"name":             "name",
+	"ship_name":        "name",
+	"nama_kapal":       "name",
+	"responsible_name": "responsible_name",
+	"phone":            "phone",
+	"type":             "type",
+	"ship_type":        "type",
+	"dimension":        "dimension",
+	"harbour":          "harbour",
+	"siup":             "siup",
+	"bkp":              "bkp",
+	"selar_mark":       "selar_mark",
+	"gt":               "gt",
+	"owner_name":       "owner_name",
+}
+
+func importLimit(key string, fallback int) int {
+	value, err := strconv.Atoi(util.GetEnv(key, ""))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+func importFormat(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return export.FormatCSV, nil
+	case ".xlsx":
+		return export.FormatXLSX, nil
+	}
+
+	return "", constants.InvalidImportFormat
+}
+
+func normalizeHeader(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(value)
+}
+
+// parseImport reads the header and turns every following non empty line into a row,
+// row numbers are the line numbers of the file so errors point at the right line
+func parseImport(table [][]string) ([]dto.ShipImportRow, error) {
+	if len(table) == 0 {
+		return nil, constants.ImportMissingColumn
+	}
+
+	columns := map[int]string{}
+	hasName := false
+	for i, header := range table[0] {
+		if field, ok := importColumns[normalizeHeader(header)]; ok {
+			columns[i] = field
+			hasName = hasName || field == "name"
+		}
+	}
+
+	if !hasName {
+		return nil, constants.ImportMissingColumn
+	}
+
+	var rows []dto.ShipImportRow
+	for i, line := range table[1:] {
+		row := dto.ShipImportRow{Row: i + 2}
+		empty := true
+
+		for j, value := range line {
+			field, ok := columns[j]
+			value = strings.TrimSpace(value)
+			if !ok || value == "" {
+				continue
+			}
+			empty = false
+
+			switch field {
+			case "name":
+				row.Name = value
+			case "responsible_name":
+				row.ResponsibleName = value
+			case "phone":
+				row.Phone = value
+			case "type":
+				row.Type = strings.ToLower(value)
+			case "dimension":
+				row.Dimension = value
+			case "harbour":
+				row.Harbour = value
+			case "siup":
+				row.SIUP = value
+			case "bkp":
+				row.BKP = value
+			case "selar_mark":
+				row.SelarMark = value
+			case "gt":
+				row.GT = value
+			case "owner_name":
+				row.OwnerName = value
+			}
+		}
+
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// validateImportRow checks the values of a row on their own, duplicates are checked by the caller
+func validateImportRow(row dto.ShipImportRow) []dto.ShipImportRowError {
+	var errs []dto.ShipImportRowError
+	add := func(field string, message string) {
+		errs = append(errs, dto.ShipImportRowError{Row: row.Row, Field: field, Message: message})
+	}
+
+	if row.Name == "" {
+		add("name", "name is required")
+	} else if len(row.Name) > 100 {
+		add("name", "name must be at most 100 characters")
+	}
+
+	if row.Type != "" && model.ShipType(row.Type) != model.KapalAngkut && model.ShipType(row.Type) != model.KapalTangkap {
+		add("type", "type must be kapal angkut or kapal tangkap")
+	}
+
+	if row.Phone != "" {
+		digits := strings.TrimPrefix(row.Phone, "+")
+		if strings.Trim(digits, "0123456789 -") != "" || len(digits) < 6 || len(digits) > 20 {
+			add("phone", "phone must be 6 to 20 digits")
+		}
+	}
+
+	if row.GT != "" {
+		if gt, err := strconv.ParseFloat(strings.ReplaceAll(row.GT, ",", "."), 64); err != nil || gt < 0 {
+			add("gt", "gt must be a positive number")
+		}
+	}
+
+	return errs
+}
+
+// ImportShips stores an uploaded vessel registry and runs its dry run, small files are checked
+// right away while larger ones are queued for the import worker
+func (s *service) ImportShips(ctx context.Context, authUser model.User, file *multipart.FileHeader) (*dto.ShipImportResponse, error) {
+	format, err := importFormat(file.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size > maxImportSize {
+		return nil, constants.AttachmentTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxImportSize {
+		return nil, constants.AttachmentTooLarge
+	}
+
+	table, err := export.ReadAll(format, data)
+	if err != nil {
+		return nil, constants.InvalidImportFormat
+	}
+
+	rows, err := parseImport(table)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > importLimit("IMPORT_MAX_ROWS", defaultMaxRows) {
+		return nil, constants.ImportTooManyRows
+	}
+
+	harbour, err := s.appRepository.FindLatestSetting(ctx, "id")
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, constants.InvalidHarbour
+		}
+		return nil, err
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	key := "imports/" + time.Now().Format("20060102150405") + "-" + hex.EncodeToString(random) + "." + format
+
+	contentType, _ := export.ContentType(format)
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	shipImport := model.ShipImport{
+		FileName:   filepath.Base(file.Filename),
+		Format:     format,
+		StorageKey: key,
+		DryRun:     1,
+		Status:     model.ImportQueued,
+		TotalRows:  len(rows),
+		CreatedBy:  authUser.ID,
+		HarbourID:  harbour.ID,
+	}
+
+	// small files are validated within the request, they are stored running so the worker skips them
+	inline := len(rows) <= importLimit("IMPORT_SYNC_ROWS", defaultSyncRows)
+	if inline {
+		shipImport.Status = model.ImportRunning
+	}
+
+	if err := s.shipImportRepository.StoreImport(ctx, &shipImport); err != nil {
+		return nil, err
+	}
+
+	if inline {
+		if err := s.runImport(ctx, shipImport, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.shipImportRepository.ImportDetail(ctx, shipImport.ID)
+}
+
+// CommitImport applies a validated dry run, rows which failed validation are skipped again
+func (s *service) CommitImport(ctx context.Context, ID int) (*dto.ShipImportResponse, error) {
+	queued, err := s.shipImportRepository.QueueCommit(ctx, ID, importLimit("IMPORT_SYNC_ROWS", defaultSyncRows))
+	if err != nil {
+		return nil, err
+	}
+
+	if !queued {
+		if _, err := s.shipImportRepository.ImportByID(ctx, ID); err != nil {
+			return nil, err
+		}
+		return nil, constants.ImportNotValidated
+	}
+
+	shipImport, err := s.shipImportRepository.ImportByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shipImport.Status == model.ImportRunning {
+		if err := s.processImport(ctx, *shipImport); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.shipImportRepository.ImportDetail(ctx, ID)
+}
+
+func (s *service) ImportDetail(ctx context.Context, ID int) (*dto.ShipImportResponse, error) {
+	return s.shipImportRepository.ImportDetail(ctx, ID)
+}
+
+func (s *service) ImportList(ctx context.Context, request dto.ShipImportListParam) (*dto.ShipImportResponseList, error) {
+	total, err := s.shipImportRepository.ImportCount(ctx, dto.ShipImportListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipImportRepository.ImportCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.shipImportRepository.ImportList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ShipImportResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// ProcessQueuedImports runs the queued imports one after another until none is left
+func (s *service) ProcessQueuedImports(ctx context.Context) error {
+	for {
+		shipImport, err := s.shipImportRepository.ClaimImport(ctx, time.Now().Add(-staleImportTimeout))
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.processImport(ctx, *shipImport); err != nil {
+			return err
+		}
+	}
+}
+
+// processImport reads the stored file of an import again and runs it
+func (s *service) processImport(ctx context.Context, shipImport model.ShipImport) error {
+	rows, err := s.importRows(ctx, shipImport)
+	if err != nil {
+		now := time.Now()
+		shipImport.Status = model.ImportFailed
+		shipImport.Error = err.Error()
+		shipImport.FinishedAt = &now
+
+		return s.shipImportRepository.UpdateImport(ctx, shipImport)
+	}
+
+	return s.runImport(ctx, shipImport, rows)
+}
+
+func (s *service) importRows(ctx context.Context, shipImport model.ShipImport) ([]dto.ShipImportRow, error) {
+	object, err := s.storage.Get(ctx, shipImport.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	data, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := export.ReadAll(shipImport.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseImport(table)
+}
+
+// runImport validates every row and matches it to an existing ship by SIUP or name, a dry run
+// only counts what would be created or updated while a commit writes the ships
+func (s *service) runImport(ctx context.Context, shipImport model.ShipImport, rows []dto.ShipImportRow) error {
+	// queued imports run in the worker, the rows are matched against the ships of the import harbour
+	ctx = tenant.WithHarbours(ctx, shipImport.HarbourID)
+
+	now := time.Now()
+	shipImport.Status = model.ImportRunning
+	shipImport.StartedAt = &now
+	shipImport.TotalRows = len(rows)
+	shipImport.ProcessedRows = 0
+	shipImport.CreatedRows = 0
+	shipImport.UpdatedRows = 0
+	shipImport.FailedRows = 0
+	shipImport.Error = ""
+
+	if err := s.shipImportRepository.UpdateImport(ctx, shipImport); err != nil {
+		return err
+	}
+
+	index, err := s.shipRepository.ShipImportIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]int{}
+	bySIUP := map[string]int{}
+	for _, e := range index {
+		byName[strings.ToLower(strings.TrimSpace(e.Name))] = e.ID
+		if e.SIUP != "" {
+			bySIUP[strings.ToLower(strings.TrimSpace(e.SIUP))] = e.ID
+		}
+	}
+
+	seenName := map[string]int{}
+	seenSIUP := map[string]int{}
+	seenShip := map[int]int{}
+	var rowErrors []dto.ShipImportRowError
+
+	for i, row := range rows {
+		errs := validateImportRow(row)
+
+		name := strings.ToLower(row.Name)
+		siup := strings.ToLower(row.SIUP)
+
+		if first, ok := seenName[name]; ok && name != "" {
+			errs = append(errs, dto.ShipImportRowError{Row: row.Row, Field: "name", Message: fmt.Sprintf("duplicate of row %d", first)})
+		}
+		if first, ok := seenSIUP[siup]; ok && siup != "" {
+			errs = append(errs, dto.ShipImportRowError{Row: row.Row, Field: "siup", Message: fmt.Sprintf("duplicate of row %d", first)})
+		}
+		if _, ok := seenName[name]; !ok {
+			seenName[name] = row.Row
+		}
+		if _, ok := seenSIUP[siup]; !ok {
+			seenSIUP[siup] = row.Row
+		}
+
+		nameID, nameMatch := byName[name]
+		siupID, siupMatch := bySIUP[siup]
+		if siup == "" {
+			siupMatch = false
+		}
+
+		switch {
+		case siupMatch && nameMatch && siupID != nameID:
+			errs = append(errs, dto.ShipImportRowError{Row: row.Row, Field: "siup", Message: "siup belongs to another ship than the name"})
+		case siupMatch:
+			row.ShipID = siupID
+		case nameMatch:
+			row.ShipID = nameID
+		}
+
+		if first, ok := seenShip[row.ShipID]; ok && row.ShipID != 0 {
+			errs = append(errs, dto.ShipImportRowError{Row: row.Row, Field: "name", Message: fmt.Sprintf("matches the same ship as row %d", first)})
+		} else if row.ShipID != 0 {
+			seenShip[row.ShipID] = row.Row
+		}
+
+		if len(errs) == 0 && shipImport.DryRun == 0 {
+			row.ChangedBy = shipImport.CreatedBy
+			row.HarbourID = shipImport.HarbourID
+			if _, err := s.shipRepository.ImportShip(ctx, row); err != nil {
+				errs = append(errs, dto.ShipImportRowError{Row: row.Row, Message: "failed to save ship: " + err.Error()})
+			}
+		}
+
+		if len(errs) > 0 {
+			shipImport.FailedRows++
+			if len(rowErrors) < maxImportErrors {
+				rowErrors = append(rowErrors, errs...)
+			}
+		} else if row.ShipID == 0 {
+			shipImport.CreatedRows++
+		} else {
+			shipImport.UpdatedRows++
+		}
+
+		shipImport.ProcessedRows = i + 1
+		if shipImport.ProcessedRows%progressBatchRows == 0 && shipImport.ProcessedRows < len(rows) {
+			if err := s.shipImportRepository.UpdateImport(ctx, shipImport); err != nil {
+				return err
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(rowErrors)
+	if err != nil {
+		return err
+	}
+	shipImport.Errors = string(encoded)
+	if len(rowErrors) == 0 {
+		shipImport.Errors = ""
+	}
+
+	finishedAt := time.Now()
+	shipImport.FinishedAt = &finishedAt
+	shipImport.Status = model.ImportCompleted
+	if shipImport.DryRun == 1 {
+		shipImport.Status = model.ImportValidated
+	}
+
+	return s.shipImportRepository.UpdateImport(ctx, shipImport)
+}
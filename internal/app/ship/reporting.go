@@ -0,0 +1,63 @@
+package ship
+
+import (
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"strconv"
+	"time"
+)
+
+// gapFactor is how many configured intervals may pass between two fixes before it counts as a missed report
+const gapFactor = 2
+
+// keepFix applies the harbour reporting mode to an incoming fix, a fix arriving faster than the
+// interval or closer than the range to the last stored fix is merged into the ship position only.
+// State changing fixes (checkin, checkout, terrain or mocked flag change) are always stored.
+func keepFix(appInfo *dto.AppInfo, lastFix *model.ShipLocationLog, fix helper.GeoPoint, now time.Time, stateChanged bool) bool {
+	if stateChanged || lastFix == nil {
+		return true
+	}
+
+	switch model.ModeType(appInfo.Mode) {
+	case model.Interval:
+		if appInfo.Interval > 0 && now.Sub(lastFix.CreatedAt) < time.Duration(appInfo.Interval)*time.Second {
+			return false
+		}
+	case model.Range:
+		lastLat, errLat := strconv.ParseFloat(lastFix.Lat, 64)
+		lastLong, errLong := strconv.ParseFloat(lastFix.Long, 64)
+		if errLat != nil || errLong != nil {
+			return true
+		}
+
+		if appInfo.Range > 0 && helper.Haversine(helper.GeoPoint{Lat: lastLat, Long: lastLong}, fix) < float64(appInfo.Range) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reportingGap tells whether the time since the previous report exceeds the allowed interval
+func reportingGap(appInfo *dto.AppInfo, elapsed time.Duration) bool {
+	if appInfo.Interval <= 0 || elapsed <= 0 {
+		return false
+	}
+
+	return elapsed > gapFactor*time.Duration(appInfo.Interval)*time.Second
+}
+
+// complianceRate is the share of received fixes that were neither redundant nor late, in percent
+func complianceRate(received int, merged int, gaps int) float64 {
+	if received == 0 {
+		return 0
+	}
+
+	compliant := received - merged - gaps
+	if compliant < 0 {
+		compliant = 0
+	}
+
+	return float64(compliant) / float64(received) * 100
+}
@@ -12,7 +12,6 @@ func (h *handler) Router(g *gin.RouterGroup) {
 
 	g.POST("/pairing", h.PairingShip)
 	g.GET("/pairing/detail", h.PairingDetailByUsername)
-
 	g.Use(middleware.Authenticate())
 	g.GET("/mobile/profile", h.ShipByAuth)
 	g.GET("/mobile/dock-log/:device_id", h.ShipDockLogByDevice)
@@ -26,5 +25,15 @@ func (h *handler) Router(g *gin.RouterGroup) {
 	g.GET("/detail/:ship_id", h.ShipDetail)
 	g.GET("/dock-log/:ship_id", h.ShipDockLog)
 	g.GET("/location-log/:ship_id", h.ShipLocationLog)
+	g.GET("/track/:ship_id", h.ShipTrack)
+	g.GET("/track/:ship_id/replay", h.ShipTrackReplay)
+	g.GET("/reporting-compliance", h.ShipReportingCompliance)
 	g.PUT("/update-detail", h.UpdateShipDetail)
+	g.GET("/detail-history/:ship_id", h.ShipDetailHistory)
+	g.GET("/detail-as-of/:ship_id", h.ShipDetailAsOf)
+
+	g.POST("/import", h.ImportShips)
+	g.POST("/import/commit/:import_id", h.CommitImport)
+	g.GET("/import/list", h.ImportList)
+	g.GET("/import/detail/:import_id", h.ImportDetail)
 }
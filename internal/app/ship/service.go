@@ -3,12 +3,24 @@ package ship
 import (
 	"context"
 	"fmt"
+	"mime/multipart"
+	"owlharbour-api/internal/app/berth"
+	"owlharbour-api/internal/app/crew"
+	"owlharbour-api/internal/app/document"
+	"owlharbour-api/internal/app/inspection"
+	"owlharbour-api/internal/app/invoice"
+	"owlharbour-api/internal/app/occupancy"
+	"owlharbour-api/internal/app/visit"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/internal/model"
 	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/helper"
 	"owlharbour-api/pkg/log"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/storage"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"strconv"
 	"strings"
@@ -20,11 +32,24 @@ import (
 )
 
 type service struct {
-	appRepository            repository.App
-	shipRepository           repository.Ship
-	pairingRequestRepository repository.PairingRequest
-	userRepository           repository.User
-	RabbitMqRepository       repository.RabbitMq
+	appRepository               repository.App
+	shipRepository              repository.Ship
+	pairingRequestRepository    repository.PairingRequest
+	userRepository              repository.User
+	RabbitMqRepository          repository.RabbitMq
+	voyageRepository            repository.Voyage
+	fraudCaseRepository         repository.FraudCase
+	inspectionService           inspection.Service
+	crewService                 crew.Service
+	documentService             document.Service
+	visitService                visit.Service
+	berthService                berth.Service
+	invoiceService              invoice.Service
+	occupancyService            occupancy.Service
+	shipImportRepository        repository.ShipImport
+	shipDetailHistoryRepository repository.ShipDetailHistory
+	harbourRepository           repository.Harbour
+	storage                     storage.Storage
 }
 
 type Service interface {
@@ -38,18 +63,40 @@ type Service interface {
 	RecordLocationShip(ctx context.Context, request dto.ShipRecordRequest) error
 	UpdateShipDetail(ctx context.Context, request dto.ShipAddonDetailRequest) error
 	ShipDetail(ctx context.Context, ShipID int) (*dto.ShipDetailResponse, error)
+	ShipDetailHistory(ctx context.Context, ShipID int, request dto.ShipDetailHistoryParam) (*dto.ShipDetailHistoryResponseList, error)
+	ShipDetailAsOf(ctx context.Context, ShipID int, date string) (*dto.ShipDetailAsOfResponse, error)
 	ShipDockLog(ctx context.Context, request dto.ShipLogParam, shipOrDeviceID any) (*dto.ShipDockLogResponse, error)
 	ShipLocationLog(ctx context.Context, request dto.ShipLogParam, shipOrDeviceID any) (*dto.ShipLocationLogResponse, error)
 	RecordShipRabbit(ctx context.Context, request dto.ShipRecordRequest) error
+	ShipTrack(ctx context.Context, ShipID int, request dto.ShipTrackParam) (*dto.ShipTrackResponse, error)
+	ShipReportingCompliance(ctx context.Context, request dto.ShipReportingComplianceParam) ([]dto.ShipReportingComplianceResponse, error)
+	ImportShips(ctx context.Context, authUser model.User, file *multipart.FileHeader) (*dto.ShipImportResponse, error)
+	CommitImport(ctx context.Context, ID int) (*dto.ShipImportResponse, error)
+	ImportDetail(ctx context.Context, ID int) (*dto.ShipImportResponse, error)
+	ImportList(ctx context.Context, request dto.ShipImportListParam) (*dto.ShipImportResponseList, error)
+	ProcessQueuedImports(ctx context.Context) error
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		appRepository:            f.AppRepository,
-		shipRepository:           f.ShipRepository,
-		pairingRequestRepository: f.PairingRequestRepository,
-		userRepository:           f.UserRepository,
-		RabbitMqRepository:       f.RabbitMqRepository,
+		appRepository:               f.AppRepository,
+		shipRepository:              f.ShipRepository,
+		pairingRequestRepository:    f.PairingRequestRepository,
+		userRepository:              f.UserRepository,
+		RabbitMqRepository:          f.RabbitMqRepository,
+		voyageRepository:            f.VoyageRepository,
+		fraudCaseRepository:         f.FraudCaseRepository,
+		inspectionService:           inspection.NewService(f),
+		crewService:                 crew.NewService(f),
+		documentService:             document.NewService(f),
+		visitService:                visit.NewService(f),
+		berthService:                berth.NewService(f),
+		invoiceService:              invoice.NewService(f),
+		occupancyService:            occupancy.NewService(f),
+		shipImportRepository:        f.ShipImportRepository,
+		shipDetailHistoryRepository: f.ShipDetailHistoryRepository,
+		harbourRepository:           f.HarbourRepository,
+		storage:                     f.Storage,
 	}
 }
 
@@ -79,15 +126,13 @@ func (s *service) PairingRequestCount(ctx context.Context) (int64, error) {
 }
 
 func (s *service) PairingShip(ctx context.Context, request dto.PairingRequest) error {
-	appInfo, err := s.appRepository.AppInfo(ctx)
+	harbour, err := s.harbourRepository.HarbourByCode(ctx, request.HarbourCode)
 	if err != nil {
-		return err
-	}
-
-	if request.HarbourCode != appInfo.HarbourCode {
 		return fmt.Errorf("unable to pair a device, invalid harbour code")
 	}
 
+	request.HarbourID = harbour.ID
+
 	err = s.pairingRequestRepository.StorePairingRequests(ctx, request)
 	if err != nil {
 		return err
@@ -101,7 +146,12 @@ func (s *service) PairingRequestList(ctx context.Context, request dto.PairingLis
 		res dto.PairingRequestResponseList
 	)
 
-	totalPairing, err := s.pairingRequestRepository.PairingRequestCount(ctx, request.Status)
+	totalPairing, err := s.pairingRequestRepository.PairingRequestListCount(ctx, dto.PairingListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filteredPairing, err := s.pairingRequestRepository.PairingRequestListCount(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +162,12 @@ func (s *service) PairingRequestList(ctx context.Context, request dto.PairingLis
 	}
 
 	res = dto.PairingRequestResponseList{
-		Total: int(totalPairing),
-		Data:  fetch,
+		PageInfo: dto.PageInfo{
+			Total:         int(totalPairing),
+			FilteredTotal: int(filteredPairing),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filteredPairing),
+		},
+		Data: fetch,
 	}
 
 	return &res, nil
@@ -147,7 +201,7 @@ func (s *service) PairingAction(ctx context.Context, request dto.PairingActionRe
 				return err
 			}
 
-			appInfo, err := s.appRepository.AppInfo(ctx)
+			appInfo, err := s.appRepository.AppInfo(tenant.WithHarbours(ctx, res.HarbourID))
 			if err != nil {
 				return err
 			}
@@ -184,6 +238,7 @@ func (s *service) PairingAction(ctx context.Context, request dto.PairingActionRe
 					FirebaseToken:   res.FirebaseToken,
 					UserID:          user.ID,
 					Phone:           res.Phone,
+					HarbourID:       res.HarbourID,
 				}
 
 				err = s.shipRepository.StoreNewShip(ctx, pairingToShip)
@@ -232,6 +287,14 @@ func (s *service) ShipList(ctx context.Context, request dto.ShipListParam) (*dto
 	)
 
 	totalShip, err := s.shipRepository.CountShip(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredShip, err := s.shipRepository.ShipListCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 
 	fetch, err := s.shipRepository.ShipList(ctx, request)
 	if err != nil {
@@ -239,8 +302,12 @@ func (s *service) ShipList(ctx context.Context, request dto.ShipListParam) (*dto
 	}
 
 	res = dto.ShipResponseList{
-		Total: int(totalShip),
-		Data:  fetch,
+		PageInfo: dto.PageInfo{
+			Total:         int(totalShip),
+			FilteredTotal: int(filteredShip),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filteredShip),
+		},
+		Data: fetch,
 	}
 
 	return &res, nil
@@ -268,6 +335,7 @@ func (s *service) ShipByAuth(ctx context.Context, authUser model.User) (*dto.Shi
 		Status:          string(ship.Status),
 		OnGround:        ship.OnGround,
 		CreatedAt:       ship.CreatedAt,
+		HarbourID:       ship.HarbourID,
 		HitMode:         appInfo.Mode,
 		Range:           appInfo.Range,
 		Interval:        appInfo.Interval,
@@ -276,12 +344,23 @@ func (s *service) ShipByAuth(ctx context.Context, authUser model.User) (*dto.Shi
 	return res, nil
 }
 
+// checkCapacity settles the capacity alerts after a ship entered or left, the alert mail must not
+// hold up the location ingestion
+func (s *service) checkCapacity(ctx context.Context, shipID int, now time.Time) {
+	if err := s.occupancyService.CheckCapacity(ctx, now); err != nil {
+		log.Logging("Failed check harbour capacity, Ship ID: %d, Err: %s", shipID, err.Error()).Error()
+	}
+}
+
 func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecordRequest) error {
 	ship, err := s.shipRepository.ShipByDevice(ctx, request.DeviceID)
 	if err != nil {
 		return err
 	}
 
+	// the location is checked against the zone of the harbour the ship is paired to
+	ctx = tenant.WithHarbours(ctx, ship.HarbourID)
+
 	appInfo, err := s.appRepository.AppInfo(ctx)
 	if err != nil {
 		return err
@@ -321,12 +400,53 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 
 	isInside := helper.StatusCheck(coord, polygon2D)
 
+	fix := helper.GeoPoint{Lat: lat, Long: long}
+	harbourDistance := helper.DistanceFromHarbour(fix, polygon2D)
+
+	var deviceTime *time.Time
+	if request.Timestamp > 0 {
+		fixedAt := time.Unix(request.Timestamp, 0)
+		deviceTime = &fixedAt
+	}
+
+	var speed float64
+	lastFix, err := s.shipRepository.GetLastLocationLog(ctx, ship.ID)
+	if err != nil {
+		lastFix = nil
+	} else {
+		lastLat, errLat := strconv.ParseFloat(lastFix.Lat, 64)
+		lastLong, errLong := strconv.ParseFloat(lastFix.Long, 64)
+		if errLat == nil && errLong == nil {
+			// the device clocks time both fixes when it can, the receive times also count the
+			// network delay and the retries of the client
+			elapsed := time.Since(lastFix.CreatedAt)
+			if deviceTime != nil && lastFix.FixedAt != nil {
+				elapsed = deviceTime.Sub(*lastFix.FixedAt)
+			}
+			speed = helper.SpeedOverGround(helper.GeoPoint{Lat: lastLat, Long: lastLong}, fix, elapsed)
+		}
+	}
+
+	voyageProgress := dto.VoyageProgress{
+		ShipID:          ship.ID,
+		Lat:             request.Lat,
+		Long:            request.Long,
+		HarbourDistance: harbourDistance,
+	}
+	voyageStarted := false
+	checkinLogID := 0
+
 	var isWater bool
 	var status string
 	currentTime := time.Now()
 	formattedTimeNotification := currentTime.Format("060102-1504")
 
 	if isInside {
+		// the berth is entered after the harbour zone, every fix inside the harbour is checked
+		if err := s.berthService.ConfirmArrival(ctx, ship.ID, lat, long, currentTime); err != nil {
+			log.Logging("Failed confirm berth reservation, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+		}
+
 		lastLogs, _ := s.shipRepository.GetLastDockedLog(ctx, ship.ID)
 
 		if lastLogs == nil || (lastLogs != nil && lastLogs.Status != "checkin") {
@@ -337,17 +457,31 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 				Status: "checkin",
 			}
 
-			if err := s.shipRepository.StoreDockedLog(ctx, dockedLog); err != nil {
+			checkinLogID, err = s.shipRepository.StoreDockedLog(ctx, dockedLog)
+			if err != nil {
 				return err
 			}
 
+			if err := s.inspectionService.OpenTask(ctx, checkinLogID, ship.ID, currentTime); err != nil {
+				log.Logging("Failed open inspection task, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+			}
+
+			// the warning mails the admins, it must not hold up the location ingestion
+			go func(ctx context.Context, shipID int) {
+				if err := s.documentService.CheckinWarning(ctx, shipID, currentTime); err != nil {
+					log.Logging("Failed check expired licenses, Ship ID: %d, Err: %s", shipID, err.Error()).Error()
+				}
+			}(tenant.Detach(ctx), ship.ID)
+
+			go s.checkCapacity(tenant.Detach(ctx), ship.ID, currentTime)
+
 			notificationData := map[string]interface{}{
 				"title": "OWLHARBOUR - CHECK IN SUCCESS",
 				"body":  "Ship was checkin-in into " + appInfo.HarbourName + " Harbour at " + formattedTimeNotification,
 			}
 			tokens := []string{ship.FirebaseToken}
 
-			_, err := helper.PushNotification(notificationData, tokens)
+			_, err = helper.PushNotification(notificationData, tokens)
 			if err != nil {
 				fmt.Println(err)
 			}
@@ -380,17 +514,34 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 						Status: "checkout",
 					}
 
-					if err := s.shipRepository.StoreDockedLog(ctx, dockedLog); err != nil {
+					checkoutLogID, err := s.shipRepository.StoreDockedLog(ctx, dockedLog)
+					if err != nil {
 						return err
 					}
 
+					if err := s.voyageRepository.StartVoyage(ctx, ship.ID, checkoutLogID, request.Lat, request.Long); err != nil {
+						log.Logging("Failed start voyage, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+					}
+					voyageStarted = true
+
+					if err := s.crewService.AttachManifest(ctx, ship.ID, checkoutLogID, currentTime); err != nil {
+						log.Logging("Failed attach crew manifest, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+					}
+
+					// harbours without a port tariff do not bill their stays
+					if err := s.invoiceService.DraftStay(ctx, checkoutLogID); err != nil && err != constants.TariffNotFound {
+						log.Logging("Failed draft stay invoice, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+					}
+
+					go s.checkCapacity(tenant.Detach(ctx), ship.ID, currentTime)
+
 					notificationData := map[string]interface{}{
 						"title": "OWLHARBOUR - CHECK OUT SUCCESS",
 						"body":  "Ship was checkin-out from " + appInfo.HarbourName + " Harbour at " + formattedTimeNotification,
 					}
 					tokens := []string{ship.FirebaseToken}
 
-					_, err := helper.PushNotification(notificationData, tokens)
+					_, err = helper.PushNotification(notificationData, tokens)
 					if err != nil {
 						fmt.Println(err)
 					}
@@ -412,6 +563,56 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 		}
 	}
 
+	now := time.Now()
+
+	analysis := fixAnalysis{
+		IsMocked:        request.IsMocked,
+		Fix:             fix,
+		LastFix:         lastFix,
+		Speed:           speed,
+		OnGround:        !isWater,
+		HarbourDistance: harbourDistance,
+		Now:             now,
+		DeviceTime:      deviceTime,
+	}
+
+	// a phone lying still in the harbour legitimately repeats its position
+	if !isInside && lastFix != nil && lastFix.Lat == request.Lat && lastFix.Long == request.Long {
+		analysis.FrozenSince, err = s.shipRepository.FrozenPositionSince(ctx, ship.ID, request.Lat, request.Long)
+		if err != nil {
+			log.Logging("Failed check frozen position, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+		}
+	}
+
+	fraudScore, fraudReasons := detectFraud(analysis)
+	isFraud := 0
+	if fraudScore >= fraudThreshold {
+		isFraud = 1
+	}
+	voyageProgress.IsFraud = isFraud
+
+	// the check-in fix ends the voyage, its last leg only counts once the fix is known to be genuine
+	if checkinLogID != 0 {
+		if isFraud == 0 && request.IsMocked == 0 {
+			voyageProgress.HarbourDistance = 0
+			if err := s.voyageRepository.UpdateVoyageProgress(ctx, voyageProgress); err != nil {
+				log.Logging("Failed update voyage progress, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+			}
+		}
+
+		if err := s.voyageRepository.CompleteVoyage(ctx, ship.ID, checkinLogID); err != nil {
+			log.Logging("Failed complete voyage, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+		}
+	}
+
+	// outside its home zone the ship may be checking in at another harbour of the deployment,
+	// a mocked or fraudulent fix must not open or end a visit
+	if isFraud == 0 && request.IsMocked == 0 {
+		if err := s.visitService.TrackVisit(ctx, ship, lat, long, isInside, now); err != nil {
+			log.Logging("Failed track harbour visit, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+		}
+	}
+
 	sll := dto.ShipLocationLogStore{
 		ShipID:   ship.ID,
 		Lat:      request.Lat,
@@ -424,10 +625,64 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 			}
 			return 1
 		}(),
+		Speed:           speed,
+		HarbourDistance: harbourDistance,
+		IsFraud:         isFraud,
+		FraudScore:      fraudScore,
+		FraudReason:     fraudReasonString(fraudReasons),
+		Status:          status,
+		FixedAt:         deviceTime,
+	}
+
+	// suspicious fixes are always kept as evidence even when the reporting mode would merge them
+	stateChanged := string(status) != ship.Status || sll.OnGround != ship.OnGround ||
+		(lastFix != nil && lastFix.IsMocked != request.IsMocked) || isFraud == 1
+	stored := keepFix(appInfo, lastFix, fix, now, stateChanged)
+
+	if stored {
+		logID, err := s.shipRepository.StoreLocationLog(ctx, sll)
+		if err != nil {
+			return err
+		}
+
+		if isFraud == 1 {
+			fraudFix := dto.FraudFixStore{
+				ShipID: ship.ID,
+				LogID:  logID,
+				Score:  fraudScore,
+				Reason: sll.FraudReason,
+			}
+			if lastFix != nil {
+				fraudFix.PreviousLogID = lastFix.ID
+			}
+
+			if _, err := s.fraudCaseRepository.AttachFraudFix(ctx, fraudFix); err != nil {
+				log.Logging("Failed attach fraud fix to case, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+			}
+		}
 	}
 
-	if err := s.shipRepository.StoreLocationLog(ctx, sll); err != nil {
-		return err
+	var elapsed time.Duration
+	if ship.LastReportedAt != nil {
+		elapsed = now.Sub(*ship.LastReportedAt)
+	}
+
+	reportingStat := dto.ShipReportingStatStore{
+		ShipID:   ship.ID,
+		Stored:   stored,
+		Gap:      reportingGap(appInfo, elapsed),
+		Interval: int64(elapsed.Seconds()),
+	}
+
+	if err := s.shipRepository.RecordReportingStat(ctx, reportingStat); err != nil {
+		log.Logging("Failed record reporting stat, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+	}
+
+	// the checkout fix is the starting point of a new voyage, every later fix outside the harbour extends it
+	if !isInside && !voyageStarted {
+		if err := s.voyageRepository.UpdateVoyageProgress(ctx, voyageProgress); err != nil {
+			log.Logging("Failed update voyage progress, Ship ID: %d, Err: %s", ship.ID, err.Error()).Error()
+		}
 	}
 
 	setID := model.Common{
@@ -446,6 +701,7 @@ func (s *service) RecordLocationShip(ctx context.Context, request dto.ShipRecord
 			}
 			return 1
 		}(),
+		LastReportedAt: &now,
 	}
 
 	if err := s.shipRepository.UpdateShip(ctx, shipUpdate); err != nil {
@@ -472,6 +728,51 @@ func (s *service) UpdateShipDetail(ctx context.Context, request dto.ShipAddonDet
 	return nil
 }
 
+func (s *service) ShipDetailHistory(ctx context.Context, ShipID int, request dto.ShipDetailHistoryParam) (*dto.ShipDetailHistoryResponseList, error) {
+	if _, err := s.shipRepository.ShipByID(ctx, ShipID); err != nil {
+		return nil, err
+	}
+
+	total, err := s.shipDetailHistoryRepository.HistoryCount(ctx, ShipID)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.shipDetailHistoryRepository.HistoryList(ctx, ShipID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ShipDetailHistoryResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(total),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), total),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+// ShipDetailAsOf returns the ship details valid at date, a date without time covers the whole day
+func (s *service) ShipDetailAsOf(ctx context.Context, ShipID int, date string) (*dto.ShipDetailAsOfResponse, error) {
+	at, err := time.ParseInLocation("2006-01-02 15:04:05", date, time.Local)
+	if err != nil {
+		day, dayErr := time.ParseInLocation("2006-01-02", date, time.Local)
+		if dayErr != nil {
+			return nil, constants.InvalidAsOfDate
+		}
+		at = day.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	if _, err := s.shipRepository.ShipByID(ctx, ShipID); err != nil {
+		return nil, err
+	}
+
+	return s.shipDetailHistoryRepository.DetailAsOf(ctx, ShipID, at)
+}
+
 func (s *service) ShipDetail(ctx context.Context, ShipID int) (*dto.ShipDetailResponse, error) {
 	ship, err := s.shipRepository.ShipByID(ctx, ShipID)
 	if err != nil {
@@ -526,7 +827,17 @@ func (s *service) ShipDockLog(ctx context.Context, request dto.ShipLogParam, shi
 		id = ship.ID
 	}
 
-	dockLogs, err := s.shipRepository.ShipDockedLogs(ctx, id, &request)
+	dockLogs, nextCursor, err := s.shipRepository.ShipDockedLogs(ctx, id, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.shipRepository.CountShipDockedLogs(ctx, id, &dto.ShipLogParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipRepository.CountShipDockedLogs(ctx, id, &request)
 	if err != nil {
 		return nil, err
 	}
@@ -534,6 +845,12 @@ func (s *service) ShipDockLog(ctx context.Context, request dto.ShipLogParam, shi
 	res := &dto.ShipDockLogResponse{
 		ID:          id,
 		DockingLogs: dockLogs,
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			NextCursor:    nextCursor,
+			HasMore:       nextCursor != "",
+		},
 	}
 
 	return res, nil
@@ -554,7 +871,17 @@ func (s *service) ShipLocationLog(ctx context.Context, request dto.ShipLogParam,
 		id = ship.ID
 	}
 
-	locationLogs, err := s.shipRepository.ShipLocationLogs(ctx, id, &request)
+	locationLogs, nextCursor, err := s.shipRepository.ShipLocationLogs(ctx, id, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.shipRepository.CountShipLocationLogs(ctx, id, &dto.ShipLogParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipRepository.CountShipLocationLogs(ctx, id, &request)
 	if err != nil {
 		return nil, err
 	}
@@ -562,6 +889,87 @@ func (s *service) ShipLocationLog(ctx context.Context, request dto.ShipLogParam,
 	res := &dto.ShipLocationLogResponse{
 		ID:           id,
 		LocationLogs: locationLogs,
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			NextCursor:    nextCursor,
+			HasMore:       nextCursor != "",
+		},
+	}
+
+	return res, nil
+}
+
+func (s *service) ShipTrack(ctx context.Context, ShipID int, request dto.ShipTrackParam) (*dto.ShipTrackResponse, error) {
+	ship, err := s.shipRepository.ShipByID(ctx, ShipID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.StartDate == "" || request.EndDate == "" {
+		today := time.Now().Format("2006-01-02")
+		request.StartDate = today
+		request.EndDate = today
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", request.StartDate, time.Local)
+	if err != nil {
+		return nil, constants.InvalidTrackRange
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", request.EndDate, time.Local)
+	if err != nil || end.Before(start) || end.After(start.AddDate(0, 0, maxTrackDays-1)) {
+		return nil, constants.InvalidTrackRange
+	}
+
+	logs, err := s.shipRepository.ShipTrackLogs(ctx, ShipID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	fixes := trackFixes(logs)
+
+	switch request.Simplify {
+	case TrackSimplifyNone:
+	case TrackSimplifyBucket:
+		fixes = bucketTrack(fixes, time.Duration(request.Bucket)*time.Second)
+	default:
+		request.Simplify = TrackSimplifyDouglasPeucker
+		fixes = simplifyTrack(fixes, request.Tolerance)
+	}
+
+	points, distance := computeTrackKinematics(fixes)
+
+	res := &dto.ShipTrackResponse{
+		ID:          ship.ID,
+		ShipName:    ship.Name,
+		StartDate:   request.StartDate,
+		EndDate:     request.EndDate,
+		Simplify:    request.Simplify,
+		TotalFixes:  len(logs),
+		TotalPoints: len(points),
+		Distance:    distance,
+		Points:      points,
+	}
+
+	return res, nil
+}
+
+func (s *service) ShipReportingCompliance(ctx context.Context, request dto.ShipReportingComplianceParam) ([]dto.ShipReportingComplianceResponse, error) {
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.shipRepository.ShipReportingCompliance(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		res[i].Mode = appInfo.Mode
+		res[i].Expected = appInfo.Interval
+		res[i].ComplianceRate = complianceRate(res[i].Received, res[i].Merged, res[i].Gaps)
 	}
 
 	return res, nil
@@ -0,0 +1,146 @@
+package ship
+
+import (
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"strconv"
+	"time"
+)
+
+const (
+	TrackSimplifyNone           = "none"
+	TrackSimplifyDouglasPeucker = "douglas-peucker"
+	TrackSimplifyBucket         = "bucket"
+
+	defaultTrackTolerance = 10.0 // metres
+	defaultTrackBucket    = time.Minute
+
+	// a track keeps every fix of the range, the range is capped to keep a request bounded
+	maxTrackDays = 31
+)
+
+// trackFixes converts location logs into track points, rows with unparsable coordinates are skipped
+func trackFixes(logs []model.ShipLocationLog) []dto.ShipTrackPoint {
+	var points []dto.ShipTrackPoint
+	for _, log := range logs {
+		lat, err := strconv.ParseFloat(log.Lat, 64)
+		if err != nil {
+			continue
+		}
+
+		long, err := strconv.ParseFloat(log.Long, 64)
+		if err != nil {
+			continue
+		}
+
+		points = append(points, dto.ShipTrackPoint{
+			LogID:      log.ID,
+			Lat:        lat,
+			Long:       long,
+			IsMocked:   log.IsMocked,
+			OnGround:   log.OnGround,
+			CreatedAt:  log.CreatedAt.Format("2006-01-02 15:04:05"),
+			RecordedAt: log.CreatedAt,
+		})
+	}
+
+	return points
+}
+
+func simplifyTrack(points []dto.ShipTrackPoint, tolerance float64) []dto.ShipTrackPoint {
+	if tolerance <= 0 {
+		tolerance = defaultTrackTolerance
+	}
+
+	geo := make([]helper.GeoPoint, len(points))
+	for i, p := range points {
+		geo[i] = helper.GeoPoint{Lat: p.Lat, Long: p.Long}
+	}
+
+	var simplified []dto.ShipTrackPoint
+	for _, i := range helper.DouglasPeucker(geo, tolerance) {
+		simplified = append(simplified, points[i])
+	}
+
+	return simplified
+}
+
+// bucketTrack keeps the first fix of every time bucket and always the last fix of the track
+func bucketTrack(points []dto.ShipTrackPoint, bucket time.Duration) []dto.ShipTrackPoint {
+	if bucket <= 0 {
+		bucket = defaultTrackBucket
+	}
+
+	var bucketed []dto.ShipTrackPoint
+	var lastBucket int64 = -1
+	for i, p := range points {
+		current := p.RecordedAt.UnixNano() / int64(bucket)
+		if current != lastBucket || i == len(points)-1 {
+			bucketed = append(bucketed, p)
+			lastBucket = current
+		}
+	}
+
+	return bucketed
+}
+
+// computeTrackKinematics fills distance (metres), speed (knots) and course (degrees) of every point
+// relative to the previous one and returns the total distance travelled
+func computeTrackKinematics(points []dto.ShipTrackPoint) ([]dto.ShipTrackPoint, float64) {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		prev := helper.GeoPoint{Lat: points[i-1].Lat, Long: points[i-1].Long}
+		cur := helper.GeoPoint{Lat: points[i].Lat, Long: points[i].Long}
+
+		distance := helper.Haversine(prev, cur)
+		elapsed := points[i].RecordedAt.Sub(points[i-1].RecordedAt)
+
+		points[i].Distance = distance
+		if distance > 0 {
+			points[i].Course = helper.Bearing(prev, cur)
+		} else {
+			points[i].Course = points[i-1].Course
+		}
+		points[i].Speed = helper.SpeedOverGround(prev, cur, elapsed)
+
+		total += distance
+	}
+
+	return points, total
+}
+
+func trackGeoJSON(track *dto.ShipTrackResponse) dto.GeoJSONFeature {
+	coordinates := make([][]float64, len(track.Points))
+	times := make([]string, len(track.Points))
+	speeds := make([]float64, len(track.Points))
+	courses := make([]float64, len(track.Points))
+	for i, p := range track.Points {
+		// GeoJSON positions are [longitude, latitude]
+		coordinates[i] = []float64{p.Long, p.Lat}
+		times[i] = p.CreatedAt
+		speeds[i] = p.Speed
+		courses[i] = p.Course
+	}
+
+	return dto.GeoJSONFeature{
+		Type: "Feature",
+		Geometry: dto.GeoJSONGeometry{
+			Type:        "LineString",
+			Coordinates: coordinates,
+		},
+		Properties: map[string]interface{}{
+			"ship_id":      track.ID,
+			"ship_name":    track.ShipName,
+			"start_date":   track.StartDate,
+			"end_date":     track.EndDate,
+			"simplify":     track.Simplify,
+			"total_fixes":  track.TotalFixes,
+			"total_points": track.TotalPoints,
+			"distance":     track.Distance,
+			"times":        times,
+			"speeds":       speeds,
+			"courses":      courses,
+		},
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/pkg/util"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -65,3 +66,26 @@ func (h *handler) WorkerRecordLog(ctx context.Context) {
 	fmt.Println("[*] Waiting for messages. To exit press CTRL+C")
 	<-forever
 }
+
+const importInterval = 5 * time.Second
+
+// WorkerImport runs the ship imports too large to be handled within the upload request
+func (h *handler) WorkerImport(ctx context.Context) {
+	fmt.Println("[*] Ship import worker started. To exit press CTRL+C")
+
+	ticker := time.NewTicker(importInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.service.ProcessQueuedImports(ctx); err != nil {
+			fmt.Println("[*] Failed to process ship imports:", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Context cancelled, exiting WorkerImport")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -153,11 +153,15 @@ func (h *handler) GetAllUsers(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	search := c.Query("search")
-	strLimit := c.Query("limit")
-	strOffset := c.Query("offset")
+	strLimit := c.DefaultQuery("limit", "25")
+	strOffset := c.DefaultQuery("offset", "0")
 	limit, _ := strconv.Atoi(strLimit)
 	offset, _ := strconv.Atoi(strOffset)
 
+	if limit == 0 {
+		limit = 10
+	}
+
 	request := dto.UserListParam{
 		Search: search,
 		Limit:  limit,
@@ -202,6 +206,18 @@ func (h *handler) StoreUser(c *gin.Context) {
 		return
 	}
 
+	if err == constants.HarbourForbidden {
+		response := util.APIResponse(fmt.Sprintf("%s", constants.HarbourForbidden), http.StatusForbidden, "failed", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if err == constants.InvalidHarbour {
+		response := util.APIResponse(fmt.Sprintf("%s", constants.InvalidHarbour), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	response := util.APIResponse("Success Store User", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
@@ -271,6 +287,18 @@ func (h *handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if err == constants.HarbourForbidden {
+		response := util.APIResponse(fmt.Sprintf("%s", constants.HarbourForbidden), http.StatusForbidden, "failed", nil)
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	if err == constants.InvalidHarbour {
+		response := util.APIResponse(fmt.Sprintf("%s", constants.InvalidHarbour), http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
 	response := util.APIResponse("Success Update User", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
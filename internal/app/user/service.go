@@ -12,6 +12,8 @@ import (
 	"owlharbour-api/internal/repository"
 	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"strconv"
 	"text/template"
@@ -23,14 +25,15 @@ import (
 )
 
 type service struct {
-	UserRepository repository.User
-	ShipRepository repository.Ship
+	UserRepository    repository.User
+	ShipRepository    repository.Ship
+	HarbourRepository repository.Harbour
 }
 
 type Service interface {
 	LoginService(ctx context.Context, payload dto.PayloadLogin, is_mobile bool) (dto.ReturnJwt, error)
 	GetProfile(ctx context.Context, userSess any) dto.ProfileUser
-	GetAllUsers(ctx context.Context, request dto.UserListParam) ([]dto.AllUser, error)
+	GetAllUsers(ctx context.Context, request dto.UserListParam) (*dto.UserResponseList, error)
 	DetailUser(ctx context.Context, userID int) (dto.DetailUser, error)
 	StoreUser(ctx context.Context, payload dto.PayloadStoreUser) error
 	UpdateUser(ctx context.Context, payload dto.PayloadUpdateUser) error
@@ -42,8 +45,9 @@ type Service interface {
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		UserRepository: f.UserRepository,
-		ShipRepository: f.ShipRepository,
+		UserRepository:    f.UserRepository,
+		ShipRepository:    f.ShipRepository,
+		HarbourRepository: f.HarbourRepository,
 	}
 }
 
@@ -168,9 +172,19 @@ func (s *service) LoginService(ctx context.Context, payload dto.PayloadLogin, is
 	}, nil
 }
 
-func (s *service) GetAllUsers(ctx context.Context, request dto.UserListParam) ([]dto.AllUser, error) {
+func (s *service) GetAllUsers(ctx context.Context, request dto.UserListParam) (*dto.UserResponseList, error) {
 	var AllUser []dto.AllUser
 
+	total, err := s.UserRepository.Count(ctx, dto.UserListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.UserRepository.Count(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
 	users, err := s.UserRepository.GetAll(ctx, request)
 	if err != nil {
 		return nil, err
@@ -189,7 +203,16 @@ func (s *service) GetAllUsers(ctx context.Context, request dto.UserListParam) ([
 		AllUser = append(AllUser, user)
 	}
 
-	return AllUser, nil
+	res := dto.UserResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(AllUser), filtered),
+		},
+		Data: AllUser,
+	}
+
+	return &res, nil
 }
 
 func (s *service) GetProfile(ctx context.Context, userSess any) dto.ProfileUser {
@@ -217,23 +240,29 @@ func (s *service) LogoutService(ctx context.Context, userSess any) error {
 }
 
 func (s *service) DetailUser(ctx context.Context, userID int) (dto.DetailUser, error) {
-	user, err := s.UserRepository.FindOne(ctx, "id, email, name, role, created_at, updated_at", "id = ?", userID)
+	user, err := s.UserRepository.FindScoped(ctx, "id, email, name, role, created_at, updated_at", userID)
 	if err != nil {
 		return dto.DetailUser{}, constants.NotFoundDataUser
 	}
 
+	harbourIDs, err := s.HarbourRepository.UserHarbours(ctx, user.ID)
+	if err != nil {
+		return dto.DetailUser{}, err
+	}
+
 	tCreatedAt := user.CreatedAt
 	tUpdatedAt := user.UpdatedAt
 	formatCreatedAt := tCreatedAt.Format("2006-01-02 15:04:05")
 	formatUpdateddAt := tUpdatedAt.Format("2006-01-02 15:04:05")
 
 	data := dto.DetailUser{
-		ID:        userID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      string(user.Role),
-		CreatedAt: formatCreatedAt,
-		UpdatedAt: formatUpdateddAt,
+		ID:         userID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       string(user.Role),
+		CreatedAt:  formatCreatedAt,
+		UpdatedAt:  formatUpdateddAt,
+		HarbourIDs: harbourIDs,
 	}
 
 	return data, nil
@@ -243,6 +272,11 @@ func (s *service) StoreUser(ctx context.Context, payload dto.PayloadStoreUser) e
 	_, err := s.UserRepository.FindOne(ctx, "id,email,name,password", "email = ?", payload.Email)
 
 	if err != nil {
+		harbourIDs, err := s.userHarbours(ctx, payload.HarbourIDs)
+		if err != nil {
+			return err
+		}
+
 		password := []byte(payload.Password)
 		hashedPassword, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
 		if err != nil {
@@ -278,6 +312,16 @@ func (s *service) StoreUser(ctx context.Context, payload dto.PayloadStoreUser) e
 			Role:     model.RoleType(payload.Role),
 		}
 		s.UserRepository.Store(ctx, dataStore)
+
+		if dataStore.Role == model.Admin {
+			user, err := s.UserRepository.FindOne(ctx, "id", "email = ?", payload.Email)
+			if err != nil {
+				return err
+			}
+
+			return s.HarbourRepository.SetUserHarbours(ctx, user.ID, harbourIDs)
+		}
+
 		return nil
 	}
 
@@ -285,11 +329,19 @@ func (s *service) StoreUser(ctx context.Context, payload dto.PayloadStoreUser) e
 }
 
 func (s *service) UpdateUser(ctx context.Context, payload dto.PayloadUpdateUser) error {
-	user, err := s.UserRepository.FindOne(ctx, "id", "id = ?", payload.ID)
+	user, err := s.UserRepository.FindScoped(ctx, "id", payload.ID)
 	if err != nil {
 		return constants.NotFoundDataUser
 	}
 
+	var harbourIDs []int
+	if payload.HarbourIDs != nil {
+		harbourIDs, err = s.userHarbours(ctx, payload.HarbourIDs)
+		if err != nil {
+			return err
+		}
+	}
+
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		return constants.ErrorLoadLocationTime
@@ -315,18 +367,55 @@ func (s *service) UpdateUser(ctx context.Context, payload dto.PayloadUpdateUser)
 			log.Println("Error updating user:", err)
 			return constants.FailedUpdateUser
 		}
-		return nil
+	} else {
+		err = s.UserRepository.UpdateOne(ctx, &updateUser, "name,email,role,updated_at", "id = ?", user.ID)
+		if err != nil {
+			log.Println("Error updating user:", err)
+			return constants.FailedUpdateUser
+		}
 	}
 
-	err = s.UserRepository.UpdateOne(ctx, &updateUser, "name,email,role,updated_at", "id = ?", user.ID)
-	if err != nil {
-		log.Println("Error updating user:", err)
-		return constants.FailedUpdateUser
+	if payload.HarbourIDs != nil {
+		if err := s.HarbourRepository.SetUserHarbours(ctx, user.ID, harbourIDs); err != nil {
+			log.Println("Error updating user harbours:", err)
+			return constants.FailedUpdateUser
+		}
 	}
 
 	return nil
 }
 
+// userHarbours checks the harbours assigned to an admin, a scoped admin can only hand out
+// its own harbours and assigns them all when none are given
+func (s *service) userHarbours(ctx context.Context, harbourIDs []int) ([]int, error) {
+	scoped, ok := tenant.Harbours(ctx)
+	if ok && len(harbourIDs) == 0 {
+		return scoped, nil
+	}
+
+	for _, harbourID := range harbourIDs {
+		if ok && !containsInt(scoped, harbourID) {
+			return nil, constants.HarbourForbidden
+		}
+
+		if _, err := s.HarbourRepository.HarbourByID(ctx, harbourID); err != nil {
+			return nil, constants.InvalidHarbour
+		}
+	}
+
+	return harbourIDs, nil
+}
+
+func containsInt(ids []int, id int) bool {
+	for _, e := range ids {
+		if e == id {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *service) ChangePassword(ctx context.Context, userID int, payload dto.PayloadChangePassword) error {
 	user, err := s.UserRepository.FindOne(ctx, "id,password", "id = ?", userID)
 	if err != nil {
@@ -406,7 +495,7 @@ func (s *service) VerifyEmail(ctx context.Context, base64String string) error {
 }
 
 func (s *service) DeleteUser(ctx context.Context, userID int) error {
-	user, err := s.UserRepository.FindOne(ctx, "id", "id = ?", userID)
+	user, err := s.UserRepository.FindScoped(ctx, "id", userID)
 	if err != nil {
 		return constants.NotFoundDataUser
 	}
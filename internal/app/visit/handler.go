@@ -0,0 +1,83 @@
+package visit
+
+import (
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+// VisitList lists the ships visiting our harbours as well as our ships visiting another harbour
+func (h *handler) VisitList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	param := dto.ShipVisitListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Status:    c.DefaultQuery("status", ""),
+		Direction: c.DefaultQuery("direction", ""),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+
+	res, err := h.service.VisitList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve ship visit list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship visit list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) VisitDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	visitID, err := strconv.Atoi(c.Param("visit_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid visit_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.VisitDetail(ctx, visitID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response := util.APIResponse("invalid visit id, no visit data", http.StatusBadRequest, "failed", nil)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
+		response := util.APIResponse("Failed to retrieve ship visit: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved ship visit", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,14 @@
+package visit
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/list", h.VisitList)
+	g.GET("/detail/:visit_id", h.VisitDetail)
+}
@@ -0,0 +1,242 @@
+package visit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type service struct {
+	appRepository       repository.App
+	shipRepository      repository.Ship
+	userRepository      repository.User
+	harbourRepository   repository.Harbour
+	shipVisitRepository repository.ShipVisit
+}
+
+type Service interface {
+	VisitList(ctx context.Context, request dto.ShipVisitListParam) (*dto.ShipVisitResponseList, error)
+	VisitDetail(ctx context.Context, ID int) (*dto.ShipVisitResponse, error)
+	TrackVisit(ctx context.Context, ship *dto.ShipMobileDetailResponse, lat, long float64, atHome bool, now time.Time) error
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:       f.AppRepository,
+		shipRepository:      f.ShipRepository,
+		userRepository:      f.UserRepository,
+		harbourRepository:   f.HarbourRepository,
+		shipVisitRepository: f.ShipVisitRepository,
+	}
+}
+
+func (s *service) VisitList(ctx context.Context, request dto.ShipVisitListParam) (*dto.ShipVisitResponseList, error) {
+	total, err := s.shipVisitRepository.VisitCount(ctx, dto.ShipVisitListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.shipVisitRepository.VisitCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.shipVisitRepository.VisitList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ShipVisitResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) VisitDetail(ctx context.Context, ID int) (*dto.ShipVisitResponse, error) {
+	return s.shipVisitRepository.VisitDetail(ctx, ID)
+}
+
+// TrackVisit follows a ship outside of its home harbour, entering the zone of another harbour
+// checks it in there as a visitor and leaving it, or coming back home, ends the visit.
+// ctx is scoped to the home harbour of the ship
+func (s *service) TrackVisit(ctx context.Context, ship *dto.ShipMobileDetailResponse, lat, long float64, atHome bool, now time.Time) error {
+	ongoing, err := s.shipVisitRepository.OngoingVisit(ctx, ship.ID)
+	if err != nil {
+		return err
+	}
+
+	var visited *model.Harbour
+	if !atHome {
+		visited, err = s.visitedHarbour(ctx, ship.HarbourID, lat, long)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ongoing != nil {
+		if visited != nil && visited.ID == ongoing.HarbourID {
+			return nil
+		}
+
+		if err := s.shipVisitRepository.DepartVisit(ctx, ongoing.ID, now); err != nil {
+			return err
+		}
+	}
+
+	if visited == nil {
+		return nil
+	}
+
+	visit := model.ShipVisit{
+		ShipID:        ship.ID,
+		HomeHarbourID: ship.HarbourID,
+		HarbourID:     visited.ID,
+		Status:        model.VisitOngoing,
+		ArrivedAt:     now,
+		Lat:           strconv.FormatFloat(lat, 'f', -1, 64),
+		Long:          strconv.FormatFloat(long, 'f', -1, 64),
+	}
+
+	if err := s.shipVisitRepository.StoreVisit(ctx, &visit); err != nil {
+		return err
+	}
+
+	// the mail to the home admins must not hold up the location ingestion
+	go s.notifyHomeHarbour(tenant.Detach(ctx), ship.ID, visited.Name, now)
+
+	notificationData := map[string]interface{}{
+		"title": "OWLHARBOUR - VISITOR CHECK IN",
+		"body":  "Ship was checkin-in as a visitor into " + visited.Name + " Harbour at " + now.Format("060102-1504"),
+	}
+	tokens := []string{ship.FirebaseToken}
+
+	if _, err := helper.PushNotification(notificationData, tokens); err != nil {
+		fmt.Println(err)
+	}
+
+	return nil
+}
+
+// visitedHarbour returns the harbour other than the home harbour the position lies in, nil when none
+func (s *service) visitedHarbour(ctx context.Context, homeHarbourID int, lat, long float64) (*model.Harbour, error) {
+	harbours, err := s.harbourRepository.ActiveHarbours(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	coord := [2]float64{lat, long}
+	for i, h := range harbours {
+		if h.ID == homeHarbourID {
+			continue
+		}
+
+		polygon, err := s.harbourPolygon(tenant.WithHarbours(ctx, h.ID))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(polygon) > 0 && helper.StatusCheck(coord, polygon) {
+			return &harbours[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *service) harbourPolygon(ctx context.Context) ([][2]float64, error) {
+	polygonData, err := s.appRepository.GetPolygon(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var polygon [][2]float64
+	for _, geo := range polygonData {
+		lat, err := strconv.ParseFloat(geo.Lat, 64)
+		if err != nil {
+			return nil, err
+		}
+		long, err := strconv.ParseFloat(geo.Long, 64)
+		if err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, [2]float64{lat, long})
+	}
+
+	return polygon, nil
+}
+
+func (s *service) notifyHomeHarbour(ctx context.Context, shipID int, visitedName string, arrivedAt time.Time) {
+	recipients, err := s.userRepository.AdminEmails(ctx)
+	if err != nil {
+		fmt.Println("Failed to load admin emails:", err.Error())
+		return
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	ship, err := s.shipRepository.ShipByID(ctx, shipID)
+	if err != nil {
+		fmt.Println("Failed to load ship, Ship ID:", shipID, err.Error())
+		return
+	}
+
+	appInfo, err := s.appRepository.AppInfo(ctx)
+	if err != nil {
+		fmt.Println("Failed to load app info:", err.Error())
+		return
+	}
+
+	tmpl, err := template.ParseFiles("pkg/resource/email_ship_visit.html")
+	if err != nil {
+		fmt.Println("Failed to parse ship visit template:", err.Error())
+		return
+	}
+
+	title := "Ship checked in at another harbour"
+	data := struct {
+		Title              string
+		HarbourName        string
+		VisitedHarbourName string
+		ShipName           string
+		ResponsibleName    string
+		Phone              string
+		ArrivedAt          string
+	}{
+		Title:              title,
+		HarbourName:        appInfo.HarbourName,
+		VisitedHarbourName: visitedName,
+		ShipName:           ship.Name,
+		ResponsibleName:    ship.ResponsibleName,
+		Phone:              ship.Phone,
+		ArrivedAt:          arrivedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	var tplBuffer = new(bytes.Buffer)
+	if err := tmpl.Execute(tplBuffer, data); err != nil {
+		fmt.Println("Failed to render ship visit template:", err.Error())
+		return
+	}
+
+	if err := helper.SendMail(strings.Join(recipients, ","), title+" - "+ship.Name, tplBuffer.String()); err != nil {
+		fmt.Println("Failed to send ship visit notification, Ship ID:", shipID, err.Error())
+	}
+}
@@ -0,0 +1,131 @@
+package voyage
+
+import (
+	"io"
+	"net/http"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/util"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func voyageListParam(c *gin.Context) dto.VoyageListParam {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	shipID, _ := strconv.Atoi(c.DefaultQuery("ship_id", "0"))
+
+	if limit == 0 {
+		limit = 10
+	}
+
+	return dto.VoyageListParam{
+		Offset:    offset,
+		Limit:     limit,
+		ShipID:    shipID,
+		Status:    strings.Split(c.DefaultQuery("status", ""), ","),
+		Search:    c.DefaultQuery("search", ""),
+		StartDate: c.DefaultQuery("start_date", ""),
+		EndDate:   c.DefaultQuery("end_date", ""),
+	}
+}
+
+func (h *handler) VoyageList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	res, err := h.service.VoyageList(ctx, voyageListParam(c))
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve voyage list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved voyage list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) ShipVoyageList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	shipID, err := strconv.Atoi(c.Param("ship_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid ship_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	param := voyageListParam(c)
+	param.ShipID = shipID
+
+	res, err := h.service.VoyageList(ctx, param)
+	if err != nil {
+		response := util.APIResponse("Failed to retrieve voyage list: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved voyage list", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) VoyageDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	voyageID, err := strconv.Atoi(c.Param("voyage_id"))
+	if err != nil {
+		response := util.APIResponse("Invalid voyage_id format", http.StatusBadRequest, "failed", nil)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.VoyageDetail(ctx, voyageID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response := util.APIResponse("invalid voyage id, no voyage data", http.StatusBadRequest, "failed", nil)
+			c.JSON(http.StatusBadRequest, response)
+		} else {
+			response := util.APIResponse("Failed to retrieve voyage data: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := util.APIResponse("Successfully retrieved voyage data", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *handler) RebuildVoyages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request dto.VoyageRebuildRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		errors := util.FormatValidationError(err)
+		response := util.APIResponse("Invalid request payload", http.StatusBadRequest, "failed", gin.H{"errors": errors})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	res, err := h.service.RebuildVoyages(ctx, request)
+	if err != nil {
+		response := util.APIResponse("Failed to rebuild voyages: "+err.Error(), http.StatusInternalServerError, "failed", nil)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := util.APIResponse("Voyages successfully rebuilt", http.StatusOK, "success", res)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,16 @@
+package voyage
+
+import (
+	"owlharbour-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) Router(g *gin.RouterGroup) {
+	g.Use(middleware.Authenticate())
+
+	g.GET("/list", h.VoyageList)
+	g.GET("/ship/:ship_id", h.ShipVoyageList)
+	g.GET("/detail/:voyage_id", h.VoyageDetail)
+	g.POST("/rebuild", h.RebuildVoyages)
+}
@@ -0,0 +1,233 @@
+package voyage
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
+	"strconv"
+	"time"
+)
+
+type service struct {
+	appRepository    repository.App
+	voyageRepository repository.Voyage
+	shipRepository   repository.Ship
+}
+
+type Service interface {
+	VoyageList(ctx context.Context, request dto.VoyageListParam) (*dto.VoyageResponseList, error)
+	VoyageDetail(ctx context.Context, ID int) (*dto.VoyageResponse, error)
+	RebuildVoyages(ctx context.Context, request dto.VoyageRebuildRequest) (*dto.VoyageRebuildResponse, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		appRepository:    f.AppRepository,
+		voyageRepository: f.VoyageRepository,
+		shipRepository:   f.ShipRepository,
+	}
+}
+
+func (s *service) VoyageList(ctx context.Context, request dto.VoyageListParam) (*dto.VoyageResponseList, error) {
+	total, err := s.voyageRepository.VoyageCount(ctx, dto.VoyageListParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered, err := s.voyageRepository.VoyageCount(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch, err := s.voyageRepository.VoyageList(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.VoyageResponseList{
+		PageInfo: dto.PageInfo{
+			Total:         int(total),
+			FilteredTotal: int(filtered),
+			HasMore:       pagination.HasMore(request.Offset, len(fetch), filtered),
+		},
+		Data: fetch,
+	}
+
+	return &res, nil
+}
+
+func (s *service) VoyageDetail(ctx context.Context, ID int) (*dto.VoyageResponse, error) {
+	res, err := s.voyageRepository.VoyageByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RebuildVoyages recreates the voyages of one ship (or every ship when ship_id is empty)
+// from the checkout -> checkin pairs in ship_docked_logs
+func (s *service) RebuildVoyages(ctx context.Context, request dto.VoyageRebuildRequest) (*dto.VoyageRebuildResponse, error) {
+	var err error
+
+	shipIDs := []int{request.ShipID}
+	if request.ShipID == 0 {
+		shipIDs, err = s.voyageRepository.ShipIDsWithDockedLogs(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// every ship is measured against the zone of its own harbour
+	polygons := map[int][][2]float64{}
+
+	res := dto.VoyageRebuildResponse{}
+	for _, shipID := range shipIDs {
+		ship, err := s.shipRepository.ShipByID(ctx, shipID)
+		if err != nil {
+			return nil, err
+		}
+
+		harbour, ok := polygons[ship.HarbourID]
+		if !ok {
+			harbour, err = s.harbourPolygon(tenant.WithHarbours(ctx, ship.HarbourID))
+			if err != nil {
+				return nil, err
+			}
+			polygons[ship.HarbourID] = harbour
+		}
+
+		dockedLogs, err := s.voyageRepository.DockedLogsByShip(ctx, shipID)
+		if err != nil {
+			return nil, err
+		}
+
+		voyages := pairDockedLogs(shipID, dockedLogs)
+		for i := range voyages {
+			end := time.Now()
+			if voyages[i].ArrivedAt != nil {
+				end = *voyages[i].ArrivedAt
+			}
+
+			logs, err := s.voyageRepository.LocationLogsBetween(ctx, shipID, voyages[i].DepartedAt, end)
+			if err != nil {
+				return nil, err
+			}
+
+			applyVoyageLogs(&voyages[i], logs, harbour)
+		}
+
+		if err := s.voyageRepository.ReplaceShipVoyages(ctx, shipID, voyages); err != nil {
+			return nil, err
+		}
+
+		res.Ships++
+		res.Voyages += len(voyages)
+	}
+
+	return &res, nil
+}
+
+func (s *service) harbourPolygon(ctx context.Context) ([][2]float64, error) {
+	polygonData, err := s.appRepository.GetPolygon(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var polygon [][2]float64
+	for _, geo := range polygonData {
+		lat, err := strconv.ParseFloat(geo.Lat, 64)
+		if err != nil {
+			return nil, err
+		}
+		long, err := strconv.ParseFloat(geo.Long, 64)
+		if err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, [2]float64{lat, long})
+	}
+
+	return polygon, nil
+}
+
+// pairDockedLogs opens a voyage on every checkout and closes it on the following checkin,
+// a trailing checkout without checkin stays ongoing
+func pairDockedLogs(shipID int, logs []model.ShipDockedLog) []model.Voyage {
+	var voyages []model.Voyage
+	var open *model.Voyage
+
+	for _, log := range logs {
+		switch log.Status {
+		case model.Checkout:
+			if open != nil {
+				continue
+			}
+
+			open = &model.Voyage{
+				ShipID:        shipID,
+				CheckoutLogID: log.ID,
+				Status:        model.VoyageOngoing,
+				DepartedAt:    log.CreatedAt,
+				LastLat:       log.Lat,
+				LastLong:      log.Long,
+			}
+		case model.Checkin:
+			if open == nil {
+				continue
+			}
+
+			checkinLogID := log.ID
+			arrivedAt := log.CreatedAt
+			open.CheckinLogID = &checkinLogID
+			open.ArrivedAt = &arrivedAt
+			open.Status = model.VoyageCompleted
+			open.Duration = int64(arrivedAt.Sub(open.DepartedAt).Seconds())
+
+			voyages = append(voyages, *open)
+			open = nil
+		}
+	}
+
+	if open != nil {
+		voyages = append(voyages, *open)
+	}
+
+	return voyages
+}
+
+func applyVoyageLogs(voyage *model.Voyage, logs []model.ShipLocationLog, harbour [][2]float64) {
+	var last *helper.GeoPoint
+
+	for _, log := range logs {
+		lat, err := strconv.ParseFloat(log.Lat, 64)
+		if err != nil {
+			continue
+		}
+		long, err := strconv.ParseFloat(log.Long, 64)
+		if err != nil {
+			continue
+		}
+
+		point := helper.GeoPoint{Lat: lat, Long: long}
+		if last != nil {
+			voyage.Distance += helper.Haversine(*last, point)
+		}
+		last = &point
+
+		if d := helper.DistanceFromHarbour(point, harbour); d > voyage.MaxDistance {
+			voyage.MaxDistance = d
+		}
+
+		if log.IsMocked == 1 || log.IsFraud == 1 {
+			voyage.FraudCount++
+		}
+
+		voyage.LastLat = log.Lat
+		voyage.LastLong = log.Long
+	}
+}
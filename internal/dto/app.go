@@ -2,13 +2,17 @@ package dto
 
 type (
 	AppInfo struct {
-		HarbourCode     int    `json:"harbour_code"`
-		HarbourName     string `json:"harbour_name"`
-		Mode            string `json:"mode"`
-		Interval        int    `json:"interval"`
-		Range           int    `json:"range"`
-		ApkDownloadLink string `json:"apk_download_link"`
-		Geofence        []AppGeofence
+		HarbourID          int    `json:"harbour_id"`
+		HarbourCode        int    `json:"harbour_code"`
+		HarbourName        string `json:"harbour_name"`
+		Mode               string `json:"mode"`
+		Interval           int    `json:"interval"`
+		Range              int    `json:"range"`
+		ApkDownloadLink    string `json:"apk_download_link"`
+		InspectionSlaHours int    `json:"inspection_sla_hours"`
+		InspectionAssign   string `json:"inspection_assign"`
+		Timezone           string `json:"timezone"`
+		Geofence           []AppGeofence
 	}
 
 	AppGeofence struct {
@@ -17,33 +21,42 @@ type (
 	}
 
 	GetDataSetting struct {
-		HarbourCode  int           `json:"harbour_code"`
-		HarbourName  string        `json:"harbour_name"`
-		Mode         string        `json:"mode"`
-		Interval     int           `json:"interval"`
-		Range        int           `json:"range"`
-		AdminContact string        `json:"admin_contact"`
-		Geofences    []AppGeofence `json:"geofences"`
+		HarbourCode        int           `json:"harbour_code"`
+		HarbourName        string        `json:"harbour_name"`
+		Mode               string        `json:"mode"`
+		Interval           int           `json:"interval"`
+		Range              int           `json:"range"`
+		AdminContact       string        `json:"admin_contact"`
+		InspectionSlaHours int           `json:"inspection_sla_hours"`
+		InspectionAssign   string        `json:"inspection_assign"`
+		Timezone           string        `json:"timezone"`
+		Geofences          []AppGeofence `json:"geofences"`
 	}
 
 	GetDataSettingWeb struct {
-		HarbourCode  int           `json:"harbour_code"`
-		HarbourName  string        `json:"harbour_name"`
-		Mode         string        `json:"mode"`
-		Interval     int           `json:"interval"`
-		Range        int           `json:"range"`
-		AdminContact string        `json:"admin_contact"`
-		Geofences    []AppGeofence `json:"geofences"`
+		HarbourCode        int           `json:"harbour_code"`
+		HarbourName        string        `json:"harbour_name"`
+		Mode               string        `json:"mode"`
+		Interval           int           `json:"interval"`
+		Range              int           `json:"range"`
+		AdminContact       string        `json:"admin_contact"`
+		InspectionSlaHours int           `json:"inspection_sla_hours"`
+		InspectionAssign   string        `json:"inspection_assign"`
+		Timezone           string        `json:"timezone"`
+		Geofences          []AppGeofence `json:"geofences"`
 	}
 
 	PayloadStoreSetting struct {
-		HarbourCode  int                  `json:"harbour_code" binding:"required"`
-		HarbourName  string               `json:"harbour_name" binding:"required"`
-		Mode         string               `json:"mode" binding:"required"`
-		Interval     int                  `json:"interval" binding:"required"`
-		Range        int                  `json:"range" binding:"required"`
-		AdminContact string               `json:"admin_contact" binding:"required"`
-		Geofence     []PayloadAppGeofence `json:"geofence"`
+		HarbourCode        int                  `json:"harbour_code" binding:"required"`
+		HarbourName        string               `json:"harbour_name" binding:"required"`
+		Mode               string               `json:"mode" binding:"required"`
+		Interval           int                  `json:"interval" binding:"required"`
+		Range              int                  `json:"range" binding:"required"`
+		AdminContact       string               `json:"admin_contact" binding:"required"`
+		InspectionSlaHours int                  `json:"inspection_sla_hours"`
+		InspectionAssign   string               `json:"inspection_assign"`
+		Timezone           string               `json:"timezone"`
+		Geofence           []PayloadAppGeofence `json:"geofence"`
 	}
 
 	PayloadAppGeofence struct {
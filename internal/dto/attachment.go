@@ -0,0 +1,30 @@
+package dto
+
+type (
+	AttachmentUploadRequest struct {
+		OwnerType string `form:"owner_type" binding:"required"`
+		OwnerID   int    `form:"owner_id" binding:"required"`
+		Category  string `form:"category" binding:"required"`
+	}
+
+	AttachmentListParam struct {
+		OwnerType string `json:"owner_type"`
+		OwnerID   int    `json:"owner_id"`
+		Category  string `json:"category"`
+	}
+
+	AttachmentResponse struct {
+		ID           int    `json:"id"`
+		OwnerType    string `json:"owner_type"`
+		OwnerID      int    `json:"owner_id"`
+		Category     string `json:"category"`
+		FileName     string `json:"file_name"`
+		ContentType  string `json:"content_type"`
+		Size         int64  `json:"size"`
+		URL          string `json:"url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		ExpiresAt    string `json:"expires_at"`
+		UploadedBy   int    `json:"uploaded_by"`
+		CreatedAt    string `json:"created_at"`
+	}
+)
@@ -0,0 +1,90 @@
+package dto
+
+type (
+	BerthRequest struct {
+		ID        int                  `json:"id"`
+		Code      string               `json:"code" binding:"required"`
+		Name      string               `json:"name" binding:"required"`
+		ZoneType  string               `json:"zone_type"`
+		Capacity  int                  `json:"capacity" binding:"required,min=1"`
+		MaxLength float64              `json:"max_length" binding:"min=0"`
+		MaxGT     float64              `json:"max_gt" binding:"min=0"`
+		IsActive  bool                 `json:"is_active"`
+		Geofence  []PayloadAppGeofence `json:"geofence"`
+	}
+
+	BerthListParam struct {
+		Offset     int    `json:"offset"`
+		Limit      int    `json:"limit"`
+		Search     string `json:"search"`
+		ActiveOnly bool   `json:"active_only"`
+	}
+
+	BerthResponseList struct {
+		PageInfo
+		Data []BerthResponse `json:"data"`
+	}
+
+	BerthResponse struct {
+		ID        int           `json:"id"`
+		HarbourID int           `json:"harbour_id"`
+		Code      string        `json:"code"`
+		Name      string        `json:"name"`
+		ZoneType  string        `json:"zone_type"`
+		Capacity  int           `json:"capacity"`
+		MaxLength float64       `json:"max_length"`
+		MaxGT     float64       `json:"max_gt"`
+		IsActive  bool          `json:"is_active"`
+		CreatedAt string        `json:"created_at"`
+		Geofences []AppGeofence `json:"geofences"`
+	}
+
+	ReservationRequest struct {
+		BerthID     int    `json:"berth_id" binding:"required"`
+		ShipID      int    `json:"ship_id"`
+		ArrivalAt   string `json:"arrival_at" binding:"required"`
+		DepartureAt string `json:"departure_at" binding:"required"`
+		Note        string `json:"note"`
+	}
+
+	// ReservationActionRequest assigns a requested reservation, a BerthID moves it to another berth
+	ReservationActionRequest struct {
+		ID      int    `json:"id" binding:"required"`
+		BerthID int    `json:"berth_id"`
+		ShipID  int    `json:"-"`
+		Note    string `json:"note"`
+	}
+
+	ReservationListParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		BerthID   int    `json:"berth_id"`
+		Status    string `json:"status"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	ReservationResponseList struct {
+		PageInfo
+		Data []ReservationResponse `json:"data"`
+	}
+
+	ReservationResponse struct {
+		ID          int    `json:"id"`
+		BerthID     int    `json:"berth_id"`
+		BerthCode   string `json:"berth_code"`
+		BerthName   string `json:"berth_name"`
+		ShipID      int    `json:"ship_id"`
+		ShipName    string `json:"ship_name"`
+		Status      string `json:"status"`
+		ArrivalAt   string `json:"arrival_at"`
+		DepartureAt string `json:"departure_at"`
+		RequestedBy int    `json:"requested_by"`
+		AssignedBy  *int   `json:"assigned_by"`
+		ConfirmedAt string `json:"confirmed_at"`
+		Note        string `json:"note"`
+		CreatedAt   string `json:"created_at"`
+	}
+)
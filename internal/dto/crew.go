@@ -0,0 +1,103 @@
+package dto
+
+type (
+	CrewRequest struct {
+		ID       int    `json:"id"`
+		ShipID   int    `json:"ship_id"`
+		Name     string `json:"name" binding:"required"`
+		IDNumber string `json:"id_number" binding:"required"`
+		Position string `json:"position" binding:"required"`
+		Phone    string `json:"phone"`
+		IsActive bool   `json:"is_active"`
+	}
+
+	CrewListParam struct {
+		Offset     int    `json:"offset"`
+		Limit      int    `json:"limit"`
+		ShipID     int    `json:"ship_id"`
+		Search     string `json:"search"`
+		Position   string `json:"position"`
+		ActiveOnly bool   `json:"active_only"`
+	}
+
+	CrewResponseList struct {
+		PageInfo
+		Data []CrewResponse `json:"data"`
+	}
+
+	CrewResponse struct {
+		ID        int    `json:"id"`
+		ShipID    int    `json:"ship_id"`
+		ShipName  string `json:"ship_name"`
+		Name      string `json:"name"`
+		IDNumber  string `json:"id_number"`
+		Position  string `json:"position"`
+		Phone     string `json:"phone"`
+		IsActive  bool   `json:"is_active"`
+		CreatedAt string `json:"created_at"`
+	}
+
+	ManifestRequest struct {
+		ShipID  int    `json:"ship_id"`
+		CrewIDs []int  `json:"crew_ids" binding:"required,min=1"`
+		Note    string `json:"note"`
+	}
+
+	ManifestListParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		Status    string `json:"status"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	ManifestResponseList struct {
+		PageInfo
+		Data []ManifestResponse `json:"data"`
+	}
+
+	ManifestResponse struct {
+		ID            int                      `json:"id"`
+		ShipID        int                      `json:"ship_id"`
+		ShipName      string                   `json:"ship_name"`
+		CheckoutLogID *int                     `json:"checkout_log_id"`
+		Status        string                   `json:"status"`
+		SubmittedBy   int                      `json:"submitted_by"`
+		CrewCount     int                      `json:"crew_count"`
+		SubmittedAt   string                   `json:"submitted_at"`
+		DepartedAt    string                   `json:"departed_at"`
+		Note          string                   `json:"note"`
+		Members       []ManifestMemberResponse `json:"members"`
+	}
+
+	ManifestMemberResponse struct {
+		CrewMemberID int    `json:"crew_member_id"`
+		Name         string `json:"name"`
+		IDNumber     string `json:"id_number"`
+		Position     string `json:"position"`
+	}
+
+	MissingManifestParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	MissingManifestResponseList struct {
+		PageInfo
+		Data []MissingManifestResponse `json:"data"`
+	}
+
+	MissingManifestResponse struct {
+		LogID           int    `json:"log_id"`
+		ShipID          int    `json:"ship_id"`
+		ShipName        string `json:"ship_name"`
+		ResponsibleName string `json:"responsible_name"`
+		Phone           string `json:"phone"`
+		DepartedAt      string `json:"departed_at"`
+	}
+)
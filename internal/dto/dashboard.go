@@ -1,11 +1,16 @@
 package dto
 
+import "time"
+
 type (
 	DashboardStatisticResponse struct {
 		TotalCheckin  int `json:"total_checkin"`
 		TotalCheckout int `json:"total_checkout"`
 		TotalShip     int `json:"total_ship"`
 		TotalFraud    int `json:"total_fraud"`
+		OpenCase      int `json:"open_case"`
+		ReviewingCase int `json:"reviewing_case"`
+		EscalatedCase int `json:"escalated_case"`
 	}
 
 	ShipTerrainResponse struct {
@@ -34,4 +39,48 @@ type (
 		IsInspected     int    `json:"is_inspected"`
 		IsReported      int    `json:"is_reported"`
 	}
+
+	DashboardSeriesParam struct {
+		StartDate   string `json:"start_date"`
+		EndDate     string `json:"end_date"`
+		Granularity string `json:"granularity"`
+	}
+
+	// DashboardSeriesFilter is the validated period in the harbour timezone, End is exclusive
+	DashboardSeriesFilter struct {
+		Start       time.Time
+		End         time.Time
+		Granularity string
+		Timezone    string
+	}
+
+	DashboardSeriesCounts struct {
+		Checkin     int64 `json:"checkin"`
+		Checkout    int64 `json:"checkout"`
+		OutOfScope  int64 `json:"out_of_scope"`
+		Fraud       int64 `json:"fraud"`
+		ActiveShips int64 `json:"active_ships"`
+	}
+
+	// DashboardSeriesRow is a bucket of the grouped statistic query, Bucket is the wall clock
+	// start in the harbour timezone and IsTotal marks the row of the whole period
+	DashboardSeriesRow struct {
+		Bucket  *time.Time
+		IsTotal int
+		DashboardSeriesCounts
+	}
+
+	DashboardSeriesBucket struct {
+		Start string `json:"start"`
+		DashboardSeriesCounts
+	}
+
+	DashboardSeriesResponse struct {
+		Granularity string                  `json:"granularity"`
+		Timezone    string                  `json:"timezone"`
+		StartDate   string                  `json:"start_date"`
+		EndDate     string                  `json:"end_date"`
+		Total       DashboardSeriesCounts   `json:"total"`
+		Data        []DashboardSeriesBucket `json:"data"`
+	}
 )
@@ -0,0 +1,98 @@
+package dto
+
+type (
+	FraudCaseListParam struct {
+		Offset     int      `json:"offset"`
+		Limit      int      `json:"limit"`
+		ShipID     int      `json:"ship_id"`
+		AssigneeID int      `json:"assignee_id"`
+		Status     []string `json:"status"`
+		Search     string   `json:"search"`
+		StartDate  string   `json:"start_date"`
+		EndDate    string   `json:"end_date"`
+	}
+
+	FraudCaseResponseList struct {
+		Total int                 `json:"total"`
+		Data  []FraudCaseResponse `json:"data"`
+	}
+
+	FraudCaseResponse struct {
+		ID           int      `json:"id"`
+		ShipID       int      `json:"ship_id"`
+		ShipName     string   `json:"ship_name"`
+		Status       string   `json:"status"`
+		AssigneeID   *int     `json:"assignee_id"`
+		AssigneeName string   `json:"assignee_name"`
+		FixCount     int      `json:"fix_count"`
+		MaxScore     int      `json:"max_score"`
+		Reasons      []string `json:"reasons"`
+		StartedAt    string   `json:"started_at"`
+		LastSeenAt   string   `json:"last_seen_at"`
+		Sanction     string   `json:"sanction"`
+		SanctionedAt string   `json:"sanctioned_at"`
+		ClosedAt     string   `json:"closed_at"`
+	}
+
+	FraudCaseDetailResponse struct {
+		FraudCaseResponse
+		Fixes      []ReportShipLocationResponse `json:"fixes"`
+		Activities []FraudCaseActivityResponse  `json:"activities"`
+	}
+
+	FraudCaseActivityResponse struct {
+		ID         int    `json:"id"`
+		UserID     *int   `json:"user_id"`
+		UserName   string `json:"user_name"`
+		Action     string `json:"action"`
+		FromStatus string `json:"from_status"`
+		ToStatus   string `json:"to_status"`
+		Note       string `json:"note"`
+		CreatedAt  string `json:"created_at"`
+	}
+
+	FraudCaseStatusRequest struct {
+		CaseID int    `json:"case_id" binding:"required"`
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+
+	FraudCaseAssignRequest struct {
+		CaseID     int `json:"case_id" binding:"required"`
+		AssigneeID int `json:"assignee_id"`
+	}
+
+	FraudCaseNoteRequest struct {
+		CaseID int    `json:"case_id" binding:"required"`
+		Note   string `json:"note" binding:"required"`
+	}
+
+	FraudCaseSanctionRequest struct {
+		CaseID  int    `json:"case_id" binding:"required"`
+		Type    string `json:"type" binding:"required"`
+		Message string `json:"message"`
+	}
+
+	FraudFixStore struct {
+		ShipID        int    `json:"ship_id"`
+		LogID         int    `json:"log_id"`
+		PreviousLogID int    `json:"previous_log_id"`
+		Score         int    `json:"score"`
+		Reason        string `json:"reason"`
+	}
+
+	FraudCaseRebuildRequest struct {
+		ShipID int `json:"ship_id"`
+	}
+
+	FraudCaseRebuildResponse struct {
+		Ships int `json:"ships"`
+		Cases int `json:"cases"`
+	}
+
+	FraudCaseCount struct {
+		Open      int64 `json:"open"`
+		Reviewing int64 `json:"reviewing"`
+		Escalated int64 `json:"escalated"`
+	}
+)
@@ -0,0 +1,19 @@
+package dto
+
+type (
+	GeoJSONFeatureCollection struct {
+		Type     string           `json:"type"`
+		Features []GeoJSONFeature `json:"features"`
+	}
+
+	GeoJSONFeature struct {
+		Type       string                 `json:"type"`
+		Geometry   GeoJSONGeometry        `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	GeoJSONGeometry struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}
+)
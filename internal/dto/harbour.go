@@ -0,0 +1,24 @@
+package dto
+
+type (
+	HarbourListParam struct {
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		Search string `json:"search"`
+	}
+
+	HarbourResponseList struct {
+		PageInfo
+		Data []HarbourResponse `json:"data"`
+	}
+
+	HarbourResponse struct {
+		ID           int    `json:"id"`
+		Code         int    `json:"code"`
+		Name         string `json:"name"`
+		AdminContact string `json:"admin_contact"`
+		Timezone     string `json:"timezone"`
+		IsActive     bool   `json:"is_active"`
+		CreatedAt    string `json:"created_at"`
+	}
+)
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+type (
+	HeatmapParam struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Precision int    `json:"precision"`
+		ShipType  string `json:"ship_type"`
+		OnGround  string `json:"on_ground"`
+	}
+
+	// HeatmapFilter is the validated HeatmapParam, Start and End are local midnights and End is exclusive
+	HeatmapFilter struct {
+		Start     time.Time
+		End       time.Time
+		Precision int
+		ShipType  string
+		OnGround  *int
+	}
+
+	HeatmapFix struct {
+		HarbourID int
+		ShipID    int
+		Lat       string
+		Long      string
+		OnGround  int
+		CreatedAt time.Time
+	}
+
+	HeatmapCell struct {
+		Geohash string
+		ShipID  int
+		Fixes   int
+	}
+
+	HeatmapRollupRequest struct {
+		StartDate string `json:"start_date" binding:"required"`
+		EndDate   string `json:"end_date" binding:"required"`
+	}
+
+	HeatmapRollupResponse struct {
+		Days  int `json:"days"`
+		Cells int `json:"cells"`
+	}
+)
@@ -2,23 +2,188 @@ package dto
 
 type (
 	NeedCheckupShipParam struct {
-		Offset int    `json:"offset"`
-		Limit  int    `json:"limit"`
-		Search string `json:"search"`
+		Offset     int    `json:"offset"`
+		Limit      int    `json:"limit"`
+		Search     string `json:"search"`
+		AssigneeID int    `json:"assignee_id"`
+	}
+
+	NeedCheckupShipResponseList struct {
+		PageInfo
+		Data []NeedCheckupShipResponse `json:"data"`
 	}
 
 	NeedCheckupShipResponse struct {
-		LogID       int    `json:"log_id"`
-		ShipName    string `json:"ship_name"`
-		Lat         string `json:"lat"`
-		Long        string `json:"long"`
-		IsInspected int    `json:"is_inspected"`
-		IsReported  int    `json:"is_reported"`
-		CheckinDate string `json:"checkin_date"`
+		LogID        int    `json:"log_id"`
+		ShipName     string `json:"ship_name"`
+		Lat          string `json:"lat"`
+		Long         string `json:"long"`
+		IsInspected  int    `json:"is_inspected"`
+		IsReported   int    `json:"is_reported"`
+		CheckinDate  string `json:"checkin_date"`
+		TaskID       int    `json:"task_id"`
+		AssigneeName string `json:"assignee_name"`
+		DueAt        string `json:"due_at"`
+		IsOverdue    bool   `json:"is_overdue"`
 	}
 
 	ShipCheckupRequest struct {
 		IsInspected bool `json:"is_inspected"`
 		IsReported  bool `json:"is_reported"`
 	}
+
+	InspectionTemplateListParam struct {
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		Search string `json:"search"`
+	}
+
+	InspectionTemplateRequest struct {
+		ID          int                             `json:"id"`
+		Name        string                          `json:"name" binding:"required"`
+		Description string                          `json:"description"`
+		IsActive    *bool                           `json:"is_active"`
+		Items       []InspectionTemplateItemRequest `json:"items" binding:"required,dive"`
+	}
+
+	InspectionTemplateItemRequest struct {
+		Category    string `json:"category" binding:"required"`
+		Label       string `json:"label" binding:"required"`
+		Description string `json:"description"`
+		IsRequired  bool   `json:"is_required"`
+	}
+
+	InspectionTemplateResponseList struct {
+		PageInfo
+		Data []InspectionTemplateResponse `json:"data"`
+	}
+
+	InspectionTemplateResponse struct {
+		ID          int                              `json:"id"`
+		Name        string                           `json:"name"`
+		Description string                           `json:"description"`
+		IsActive    bool                             `json:"is_active"`
+		ItemCount   int                              `json:"item_count"`
+		CreatedAt   string                           `json:"created_at"`
+		Items       []InspectionTemplateItemResponse `json:"items,omitempty"`
+	}
+
+	InspectionTemplateItemResponse struct {
+		ID          int    `json:"id"`
+		Category    string `json:"category"`
+		Label       string `json:"label"`
+		Description string `json:"description"`
+		IsRequired  bool   `json:"is_required"`
+		Sort        int    `json:"sort"`
+	}
+
+	InspectionStoreRequest struct {
+		DockedLogID int                       `json:"docked_log_id" binding:"required"`
+		TemplateID  int                       `json:"template_id" binding:"required"`
+		Outcome     string                    `json:"outcome"`
+		Findings    string                    `json:"findings"`
+		StartedAt   string                    `json:"started_at"`
+		Results     []InspectionResultRequest `json:"results" binding:"required,dive"`
+	}
+
+	InspectionResultRequest struct {
+		ItemID int    `json:"item_id" binding:"required"`
+		Result string `json:"result" binding:"required"`
+		Note   string `json:"note"`
+	}
+
+	InspectionListParam struct {
+		Offset      int      `json:"offset"`
+		Limit       int      `json:"limit"`
+		ShipID      int      `json:"ship_id"`
+		InspectorID int      `json:"inspector_id"`
+		Outcome     []string `json:"outcome"`
+		Search      string   `json:"search"`
+		StartDate   string   `json:"start_date"`
+		EndDate     string   `json:"end_date"`
+	}
+
+	InspectionResponseList struct {
+		PageInfo
+		Data []InspectionResponse `json:"data"`
+	}
+
+	InspectionResponse struct {
+		ID            int    `json:"id"`
+		DockedLogID   int    `json:"docked_log_id"`
+		ShipID        int    `json:"ship_id"`
+		ShipName      string `json:"ship_name"`
+		TemplateID    int    `json:"template_id"`
+		TemplateName  string `json:"template_name"`
+		InspectorID   int    `json:"inspector_id"`
+		InspectorName string `json:"inspector_name"`
+		Outcome       string `json:"outcome"`
+		Findings      string `json:"findings"`
+		FailedItems   int    `json:"failed_items"`
+		CheckinDate   string `json:"checkin_date"`
+		StartedAt     string `json:"started_at"`
+		CompletedAt   string `json:"completed_at"`
+	}
+
+	InspectionDetailResponse struct {
+		InspectionResponse
+		Results []InspectionResultResponse `json:"results"`
+	}
+
+	InspectionResultResponse struct {
+		ID         int    `json:"id"`
+		ItemID     int    `json:"item_id"`
+		Category   string `json:"category"`
+		Label      string `json:"label"`
+		IsRequired bool   `json:"is_required"`
+		Result     string `json:"result"`
+		Note       string `json:"note"`
+	}
+
+	InspectionTaskListParam struct {
+		Offset     int      `json:"offset"`
+		Limit      int      `json:"limit"`
+		Search     string   `json:"search"`
+		AssigneeID int      `json:"assignee_id"`
+		Status     []string `json:"status"`
+		Overdue    bool     `json:"overdue"`
+	}
+
+	InspectionTaskAssignRequest struct {
+		TaskID     int `json:"task_id" binding:"required"`
+		AssigneeID int `json:"assignee_id" binding:"required"`
+	}
+
+	InspectionTaskResponseList struct {
+		PageInfo
+		Data []InspectionTaskResponse `json:"data"`
+	}
+
+	InspectionTaskResponse struct {
+		ID            int    `json:"id"`
+		DockedLogID   int    `json:"docked_log_id"`
+		ShipID        int    `json:"ship_id"`
+		ShipName      string `json:"ship_name"`
+		HarbourID     int    `json:"harbour_id"`
+		AssigneeID    int    `json:"assignee_id"`
+		AssigneeName  string `json:"assignee_name"`
+		AssigneeEmail string `json:"-"`
+		AssignMode    string `json:"assign_mode"`
+		Status        string `json:"status"`
+		CheckinAt     string `json:"checkin_at"`
+		DueAt         string `json:"due_at"`
+		AssignedAt    string `json:"assigned_at"`
+		CompletedAt   string `json:"completed_at"`
+		InspectionID  int    `json:"inspection_id"`
+		IsOverdue     bool   `json:"is_overdue"`
+	}
+
+	InspectionMetricsResponse struct {
+		Completed           int64   `json:"completed"`
+		CompletedOnTime     int64   `json:"completed_on_time"`
+		Open                int64   `json:"open"`
+		Overdue             int64   `json:"overdue"`
+		OnTimeRate          float64 `json:"on_time_rate"`
+		AvgTimeToInspectMin float64 `json:"avg_time_to_inspect_minutes"`
+	}
 )
@@ -0,0 +1,105 @@
+package dto
+
+type (
+	TariffRequest struct {
+		ID        int     `json:"id"`
+		ZoneType  string  `json:"zone_type"`
+		Name      string  `json:"name" binding:"required"`
+		BaseFee   float64 `json:"base_fee" binding:"min=0"`
+		DailyRate float64 `json:"daily_rate" binding:"min=0"`
+		RatePerGT float64 `json:"rate_per_gt" binding:"min=0"`
+		IsActive  bool    `json:"is_active"`
+	}
+
+	TariffResponse struct {
+		ID        int     `json:"id"`
+		HarbourID int     `json:"harbour_id"`
+		ZoneType  string  `json:"zone_type"`
+		Name      string  `json:"name"`
+		BaseFee   float64 `json:"base_fee"`
+		DailyRate float64 `json:"daily_rate"`
+		RatePerGT float64 `json:"rate_per_gt"`
+		IsActive  bool    `json:"is_active"`
+		CreatedAt string  `json:"created_at"`
+	}
+
+	InvoiceGenerateRequest struct {
+		StartDate string `json:"start_date" binding:"required"`
+		EndDate   string `json:"end_date" binding:"required"`
+	}
+
+	// InvoiceGenerateResponse counts the completed stays without invoice, stays without
+	// a matching tariff are skipped
+	InvoiceGenerateResponse struct {
+		Stays     int `json:"stays"`
+		Generated int `json:"generated"`
+		Skipped   int `json:"skipped"`
+	}
+
+	InvoiceListParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		Status    string `json:"status"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	InvoiceResponseList struct {
+		PageInfo
+		Data []InvoiceResponse `json:"data"`
+	}
+
+	InvoiceResponse struct {
+		ID              int                      `json:"id"`
+		Number          string                   `json:"number"`
+		HarbourID       int                      `json:"harbour_id"`
+		ShipID          int                      `json:"ship_id"`
+		ShipName        string                   `json:"ship_name"`
+		ResponsibleName string                   `json:"responsible_name"`
+		OwnerName       string                   `json:"owner_name"`
+		BerthID         *int                     `json:"berth_id"`
+		BerthName       string                   `json:"berth_name"`
+		ZoneType        string                   `json:"zone_type"`
+		ArrivedAt       string                   `json:"arrived_at"`
+		DepartedAt      string                   `json:"departed_at"`
+		Days            int                      `json:"days"`
+		GT              float64                  `json:"gt"`
+		BaseFee         float64                  `json:"base_fee"`
+		DailyRate       float64                  `json:"daily_rate"`
+		RatePerGT       float64                  `json:"rate_per_gt"`
+		Amount          float64                  `json:"amount"`
+		PaidAmount      float64                  `json:"paid_amount"`
+		Outstanding     float64                  `json:"outstanding"`
+		Status          string                   `json:"status"`
+		IssuedAt        string                   `json:"issued_at"`
+		PaidAt          string                   `json:"paid_at"`
+		VoidedAt        string                   `json:"voided_at"`
+		VoidReason      string                   `json:"void_reason"`
+		CreatedAt       string                   `json:"created_at"`
+		Payments        []InvoicePaymentResponse `json:"payments"`
+	}
+
+	InvoiceActionRequest struct {
+		ID     int    `json:"id" binding:"required"`
+		Reason string `json:"reason"`
+	}
+
+	PaymentRequest struct {
+		InvoiceID int     `json:"invoice_id" binding:"required"`
+		Amount    float64 `json:"amount" binding:"required"`
+		Method    string  `json:"method" binding:"required"`
+		Reference string  `json:"reference"`
+		PaidAt    string  `json:"paid_at"`
+	}
+
+	InvoicePaymentResponse struct {
+		ID         int     `json:"id"`
+		Amount     float64 `json:"amount"`
+		Method     string  `json:"method"`
+		Reference  string  `json:"reference"`
+		PaidAt     string  `json:"paid_at"`
+		RecordedBy int     `json:"recorded_by"`
+	}
+)
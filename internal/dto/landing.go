@@ -0,0 +1,86 @@
+package dto
+
+import "time"
+
+type (
+	LandingRequest struct {
+		ID          int                   `json:"id"`
+		DockedLogID int                   `json:"docked_log_id"`
+		FishingArea string                `json:"fishing_area" binding:"required"`
+		Gear        string                `json:"gear" binding:"required"`
+		LandedAt    string                `json:"landed_at"`
+		Note        string                `json:"note"`
+		Catches     []LandingCatchRequest `json:"catches" binding:"required,min=1,dive"`
+	}
+
+	LandingCatchRequest struct {
+		Species  string  `json:"species" binding:"required"`
+		WeightKg float64 `json:"weight_kg" binding:"required"`
+	}
+
+	LandingListParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		Species   string `json:"species"`
+		Gear      string `json:"gear"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	LandingResponseList struct {
+		PageInfo
+		Data []LandingResponse `json:"data"`
+	}
+
+	LandingResponse struct {
+		ID            int                    `json:"id"`
+		DockedLogID   int                    `json:"docked_log_id"`
+		ShipID        int                    `json:"ship_id"`
+		ShipName      string                 `json:"ship_name"`
+		FishingArea   string                 `json:"fishing_area"`
+		Gear          string                 `json:"gear"`
+		Source        string                 `json:"source"`
+		SubmittedBy   int                    `json:"submitted_by"`
+		TotalWeightKg float64                `json:"total_weight_kg"`
+		LandedAt      string                 `json:"landed_at"`
+		Note          string                 `json:"note"`
+		Catches       []LandingCatchResponse `json:"catches"`
+	}
+
+	LandingCatchResponse struct {
+		ID       int     `json:"id"`
+		Species  string  `json:"species"`
+		WeightKg float64 `json:"weight_kg"`
+	}
+
+	LandingShipAggregate struct {
+		ShipID        int     `json:"ship_id"`
+		ShipName      string  `json:"ship_name"`
+		Landings      int     `json:"landings"`
+		Species       int     `json:"species"`
+		TotalWeightKg float64 `json:"total_weight_kg"`
+	}
+
+	LandingSpeciesAggregate struct {
+		Species       string  `json:"species"`
+		Landings      int     `json:"landings"`
+		Ships         int     `json:"ships"`
+		TotalWeightKg float64 `json:"total_weight_kg"`
+	}
+
+	LandingExportRow struct {
+		LandingID   int
+		LandedAt    time.Time
+		ShipID      int
+		ShipName    string
+		SIUP        string
+		BKP         string
+		FishingArea string
+		Gear        string
+		Species     string
+		WeightKg    float64
+		Source      string
+	}
+)
@@ -0,0 +1,81 @@
+package dto
+
+type (
+	OccupancyParam struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Interval  string `json:"interval"`
+	}
+
+	// OccupancyBucket counts the ships inside the harbour at some point of the bucket and the
+	// most ships inside at the same time
+	OccupancyBucket struct {
+		Start string `json:"start"`
+		Ships int    `json:"ships"`
+		Peak  int    `json:"peak"`
+	}
+
+	OccupancyResponse struct {
+		Interval   string              `json:"interval"`
+		Current    int64               `json:"current"`
+		Peak       int                 `json:"peak"`
+		PeakAt     string              `json:"peak_at"`
+		Average    float64             `json:"average"`
+		Thresholds []ThresholdResponse `json:"thresholds"`
+		Data       []OccupancyBucket   `json:"data"`
+	}
+
+	PeakHour struct {
+		Hour    int     `json:"hour"`
+		Average float64 `json:"average"`
+		Peak    int     `json:"peak"`
+	}
+
+	// PeakHoursResponse averages the hourly occupancy per hour of the day, Busiest lists the
+	// hours by descending average
+	PeakHoursResponse struct {
+		Days    int        `json:"days"`
+		Busiest []int      `json:"busiest"`
+		Hours   []PeakHour `json:"hours"`
+	}
+
+	ThresholdRequest struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name" binding:"required"`
+		Ships    int    `json:"ships" binding:"required,min=1"`
+		IsActive bool   `json:"is_active"`
+	}
+
+	ThresholdResponse struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		Ships     int    `json:"ships"`
+		IsActive  bool   `json:"is_active"`
+		CreatedAt string `json:"created_at"`
+	}
+
+	OccupancyAlertListParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		Status    string `json:"status"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	OccupancyAlertResponseList struct {
+		PageInfo
+		Data []OccupancyAlertResponse `json:"data"`
+	}
+
+	OccupancyAlertResponse struct {
+		ID            int    `json:"id"`
+		ThresholdID   int    `json:"threshold_id"`
+		ThresholdName string `json:"threshold_name"`
+		Threshold     int    `json:"threshold"`
+		Occupancy     int    `json:"occupancy"`
+		PeakOccupancy int    `json:"peak_occupancy"`
+		Status        string `json:"status"`
+		RaisedAt      string `json:"raised_at"`
+		ResolvedAt    string `json:"resolved_at"`
+	}
+)
@@ -0,0 +1,13 @@
+package dto
+
+type (
+	// PageInfo is embedded in list responses. Total counts every row of the list, FilteredTotal
+	// only the rows matching the request filters. NextCursor is set on keyset paginated lists
+	// and is passed back as cursor to fetch the following page.
+	PageInfo struct {
+		Total         int    `json:"total"`
+		FilteredTotal int    `json:"filtered_total"`
+		NextCursor    string `json:"next_cursor"`
+		HasMore       bool   `json:"has_more"`
+	}
+)
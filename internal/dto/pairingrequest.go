@@ -10,6 +10,7 @@ type (
 		ResponsibleName string `json:"responsible_name" binding:"required"`
 		DeviceID        string `json:"device_id" binding:"required"`
 		FirebaseToken   string `json:"firebase_token" binding:"required"`
+		HarbourID       int    `json:"-"`
 	}
 
 	PairingActionRequest struct {
@@ -18,8 +19,8 @@ type (
 	}
 
 	PairingRequestResponseList struct {
-		Total int                      `json:"total"`
-		Data  []PairingRequestResponse `json:"data"`
+		PageInfo
+		Data []PairingRequestResponse `json:"data"`
 	}
 
 	PairingRequestResponse struct {
@@ -32,6 +33,7 @@ type (
 		DeviceID        string `json:"device_id"`
 		FirebaseToken   string `json:"firebase_token"`
 		Status          string `json:"status"`
+		HarbourID       int    `json:"harbour_id"`
 		CreatedAt       string `json:"created_at"`
 	}
 
@@ -42,6 +44,7 @@ type (
 		DeviceID        string `json:"device_id"`
 		FirebaseToken   string `json:"firebase_token"`
 		UserID          int    `json:"user_id"`
+		HarbourID       int    `json:"harbour_id"`
 	}
 
 	PairingListParam struct {
@@ -0,0 +1,75 @@
+package dto
+
+type (
+	ReportJobListParam struct {
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		Search string `json:"search"`
+		Report string `json:"report"`
+	}
+
+	ReportJobRequest struct {
+		ID         int      `json:"id"`
+		Name       string   `json:"name" binding:"required"`
+		Report     string   `json:"report" binding:"required"`
+		Frequency  string   `json:"frequency" binding:"required"`
+		Format     string   `json:"format" binding:"required"`
+		Recipients []string `json:"recipients" binding:"required"`
+		RunTime    string   `json:"run_time"`
+		Weekday    int      `json:"weekday"`
+		MonthDay   int      `json:"month_day"`
+		MaxRetry   *int     `json:"max_retry"`
+		IsActive   *bool    `json:"is_active"`
+	}
+
+	ReportJobRunRequest struct {
+		JobID int `json:"job_id" binding:"required"`
+	}
+
+	ReportJobResponseList struct {
+		PageInfo
+		Data []ReportJobResponse `json:"data"`
+	}
+
+	ReportJobResponse struct {
+		ID         int      `json:"id"`
+		Name       string   `json:"name"`
+		Report     string   `json:"report"`
+		Frequency  string   `json:"frequency"`
+		Format     string   `json:"format"`
+		Recipients []string `json:"recipients"`
+		RunTime    string   `json:"run_time"`
+		Weekday    int      `json:"weekday"`
+		MonthDay   int      `json:"month_day"`
+		MaxRetry   int      `json:"max_retry"`
+		IsActive   bool     `json:"is_active"`
+		NextRunAt  string   `json:"next_run_at"`
+		LastRunAt  string   `json:"last_run_at"`
+		LastStatus string   `json:"last_status"`
+	}
+
+	ReportJobRunListParam struct {
+		Offset int      `json:"offset"`
+		Limit  int      `json:"limit"`
+		JobID  int      `json:"job_id"`
+		Status []string `json:"status"`
+	}
+
+	ReportJobRunResponseList struct {
+		PageInfo
+		Data []ReportJobRunResponse `json:"data"`
+	}
+
+	ReportJobRunResponse struct {
+		ID          int    `json:"id"`
+		JobID       int    `json:"job_id"`
+		Status      string `json:"status"`
+		Attempt     int    `json:"attempt"`
+		PeriodStart string `json:"period_start"`
+		PeriodEnd   string `json:"period_end"`
+		ScheduledAt string `json:"scheduled_at"`
+		FinishedAt  string `json:"finished_at"`
+		NextRetryAt string `json:"next_retry_at"`
+		Error       string `json:"error"`
+	}
+)
@@ -1,9 +1,52 @@
 package dto
 
+import "time"
+
 type (
+	ShipTrackParam struct {
+		StartDate string  `json:"start_date"`
+		EndDate   string  `json:"end_date"`
+		Simplify  string  `json:"simplify"`
+		Tolerance float64 `json:"tolerance"`
+		Bucket    int     `json:"bucket"`
+	}
+
+	ShipTrackResponse struct {
+		ID          int              `json:"id"`
+		ShipName    string           `json:"ship_name"`
+		StartDate   string           `json:"start_date"`
+		EndDate     string           `json:"end_date"`
+		Simplify    string           `json:"simplify"`
+		TotalFixes  int              `json:"total_fixes"`
+		TotalPoints int              `json:"total_points"`
+		Distance    float64          `json:"distance"`
+		Points      []ShipTrackPoint `json:"points"`
+	}
+
+	ShipTrackPoint struct {
+		LogID      int       `json:"log_id"`
+		Lat        float64   `json:"lat"`
+		Long       float64   `json:"long"`
+		Speed      float64   `json:"speed"`
+		Course     float64   `json:"course"`
+		Distance   float64   `json:"distance"`
+		IsMocked   int       `json:"is_mocked"`
+		OnGround   int       `json:"on_ground"`
+		CreatedAt  string    `json:"created_at"`
+		RecordedAt time.Time `json:"-"`
+	}
+
+	ShipTrackReplayMessage struct {
+		Event string          `json:"event"`
+		Index int             `json:"index"`
+		Total int             `json:"total"`
+		Point *ShipTrackPoint `json:"point"`
+	}
+
 	ShipLogParam struct {
 		Offset    int    `json:"offset"`
 		Limit     int    `json:"limit"`
+		Cursor    string `json:"cursor"`
 		StartDate string `json:"start_date"`
 		EndDate   string `json:"end_date"`
 	}
@@ -11,10 +54,12 @@ type (
 	ShipDockLogResponse struct {
 		ID          any            `json:"id"`
 		DockingLogs []DockLogsShip `json:"docking_logs"`
+		PageInfo
 	}
 	ShipLocationLogResponse struct {
 		ID           any                `json:"id"`
 		LocationLogs []LocationLogsShip `json:"location_logs"`
+		PageInfo
 	}
 
 	ShipListParam struct {
@@ -27,6 +72,7 @@ type (
 	ReportShipDockedParam struct {
 		Offset    int      `json:"offset"`
 		Limit     int      `json:"limit"`
+		Cursor    string   `json:"cursor"`
 		LogType   []string `json:"status"`
 		Search    string   `json:"search"`
 		StartDate string   `json:"start_date"`
@@ -36,9 +82,21 @@ type (
 	ReportShipLocationParam struct {
 		Offset    int    `json:"offset"`
 		Limit     int    `json:"limit"`
+		Cursor    string `json:"cursor"`
 		Search    string `json:"search"`
 		StartDate string `json:"start_date"`
 		EndDate   string `json:"end_date"`
+		Reason    string `json:"reason"`
+	}
+
+	ReportActivityParam struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	ReportShipDockingResponseList struct {
+		PageInfo
+		Data []ReportShipDockingResponse `json:"data"`
 	}
 
 	ReportShipDockingResponse struct {
@@ -51,20 +109,63 @@ type (
 		Status   string `json:"status"`
 	}
 
+	ReportShipLocationResponseList struct {
+		PageInfo
+		Data []ReportShipLocationResponse `json:"data"`
+	}
+
 	ReportShipLocationResponse struct {
-		LogID    int    `json:"log_id"`
-		LogDate  string `json:"log_date"`
-		ShipID   int    `json:"ship_id"`
-		ShipName string `json:"ship_name"`
-		Long     string `json:"long"`
-		Lat      string `json:"lat"`
-		IsMocked int    `json:"is_mocked"`
-		OnGround int    `json:"on_ground"`
+		LogID       int    `json:"log_id"`
+		LogDate     string `json:"log_date"`
+		ShipID      int    `json:"ship_id"`
+		ShipName    string `json:"ship_name"`
+		Long        string `json:"long"`
+		Lat         string `json:"lat"`
+		IsMocked    int    `json:"is_mocked"`
+		OnGround    int    `json:"on_ground"`
+		FraudScore  int    `json:"fraud_score"`
+		FraudReason string `json:"fraud_reason"`
+	}
+
+	ReportShipDetailColumns struct {
+		Type      string `json:"type"`
+		Dimension string `json:"dimension"`
+		Harbour   string `json:"harbour"`
+		SIUP      string `json:"siup"`
+		BKP       string `json:"bkp"`
+		SelarMark string `json:"selar_mark"`
+		GT        string `json:"gt"`
+		OwnerName string `json:"owner_name"`
+	}
+
+	ReportShipDockingExportRow struct {
+		LogID    int       `json:"log_id"`
+		LogDate  time.Time `json:"log_date"`
+		ShipID   int       `json:"ship_id"`
+		ShipName string    `json:"ship_name"`
+		Long     string    `json:"long"`
+		Lat      string    `json:"lat"`
+		Status   string    `json:"status"`
+		ReportShipDetailColumns
+	}
+
+	ReportShipFraudExportRow struct {
+		LogID       int       `json:"log_id"`
+		LogDate     time.Time `json:"log_date"`
+		ShipID      int       `json:"ship_id"`
+		ShipName    string    `json:"ship_name"`
+		Long        string    `json:"long"`
+		Lat         string    `json:"lat"`
+		IsMocked    int       `json:"is_mocked"`
+		OnGround    int       `json:"on_ground"`
+		FraudScore  int       `json:"fraud_score"`
+		FraudReason string    `json:"fraud_reason"`
+		ReportShipDetailColumns
 	}
 
 	ShipResponseList struct {
-		Total int            `json:"total"`
-		Data  []ShipResponse `json:"data"`
+		PageInfo
+		Data []ShipResponse `json:"data"`
 	}
 
 	ShipResponse struct {
@@ -78,19 +179,21 @@ type (
 	}
 
 	ShipMobileDetailResponse struct {
-		ID              int    `json:"id"`
-		ShipName        string `json:"ship_name"`
-		ResponsibleName string `json:"responsible_name"`
-		DeviceID        string `json:"device_id"`
-		CurrentLong     string `json:"current_long"`
-		CurrentLat      string `json:"current_lat"`
-		FirebaseToken   string `json:"firebase_token"`
-		Status          string `json:"status"`
-		OnGround        int    `json:"on_ground"`
-		CreatedAt       string `json:"created_at"`
-		HitMode         string `json:"hit_mode"`
-		Range           int    `json:"range"`
-		Interval        int    `json:"interval"`
+		ID              int        `json:"id"`
+		ShipName        string     `json:"ship_name"`
+		ResponsibleName string     `json:"responsible_name"`
+		DeviceID        string     `json:"device_id"`
+		CurrentLong     string     `json:"current_long"`
+		CurrentLat      string     `json:"current_lat"`
+		FirebaseToken   string     `json:"firebase_token"`
+		Status          string     `json:"status"`
+		OnGround        int        `json:"on_ground"`
+		CreatedAt       string     `json:"created_at"`
+		LastReportedAt  *time.Time `json:"last_reported_at"`
+		HitMode         string     `json:"hit_mode"`
+		Range           int        `json:"range"`
+		Interval        int        `json:"interval"`
+		HarbourID       int        `json:"harbour_id"`
 	}
 
 	ShipDetailResponse struct {
@@ -129,13 +232,17 @@ type (
 	}
 
 	LocationLogsShip struct {
-		LogID     int    `json:"log_id"`
-		Long      string `json:"long"`
-		Lat       string `json:"lat"`
-		IsMocked  int    `json:"is_mocked"`
-		OnGround  int    `json:"on_ground"`
-		DegNorth  string `json:"deg_north"`
-		CreatedAt string `json:"created_at"`
+		LogID           int     `json:"log_id"`
+		Long            string  `json:"long"`
+		Lat             string  `json:"lat"`
+		IsMocked        int     `json:"is_mocked"`
+		OnGround        int     `json:"on_ground"`
+		DegNorth        string  `json:"deg_north"`
+		Speed           float64 `json:"speed"`
+		HarbourDistance float64 `json:"harbour_distance"`
+		FraudScore      int     `json:"fraud_score"`
+		FraudReason     string  `json:"fraud_reason"`
+		CreatedAt       string  `json:"created_at"`
 	}
 
 	ShipAddonDetailRequest struct {
@@ -148,6 +255,8 @@ type (
 		SelarMark string `json:"selar_mark"`
 		GT        string `json:"gt"`
 		OwnerName string `json:"owner_name"`
+		// ChangedBy is the user making the update, recorded in the detail history
+		ChangedBy int `json:"-"`
 	}
 
 	ShipRecordRequest struct {
@@ -156,6 +265,8 @@ type (
 		Lat      string `json:"lat" binding:"required"`
 		DegNorth string `json:"deg_north" binding:"required"`
 		IsMocked int    `json:"is_mocked"`
+		// Timestamp is the unix time the fix was taken on the device, optional for older clients
+		Timestamp int64 `json:"timestamp"`
 	}
 
 	ShipDockedLog struct {
@@ -173,12 +284,49 @@ type (
 		Status string `json:"status"`
 	}
 	ShipLocationLogStore struct {
-		ShipID   int    `json:"ship_id"`
-		Long     string `json:"long"`
-		Lat      string `json:"lat"`
-		DegNorth string `json:"deg_north"`
-		IsMocked int    `json:"is_mocked"`
-		OnGround int    `json:"on_ground"`
+		ShipID          int        `json:"ship_id"`
+		Long            string     `json:"long"`
+		Lat             string     `json:"lat"`
+		DegNorth        string     `json:"deg_north"`
+		IsMocked        int        `json:"is_mocked"`
+		OnGround        int        `json:"on_ground"`
+		Speed           float64    `json:"speed"`
+		HarbourDistance float64    `json:"harbour_distance"`
+		IsFraud         int        `json:"is_fraud"`
+		FraudScore      int        `json:"fraud_score"`
+		FraudReason     string     `json:"fraud_reason"`
+		Status          string     `json:"status"`
+		FixedAt         *time.Time `json:"fixed_at"`
+	}
+
+	ShipReportingStatStore struct {
+		ShipID   int   `json:"ship_id"`
+		Stored   bool  `json:"stored"`
+		Gap      bool  `json:"gap"`
+		Interval int64 `json:"interval"`
+	}
+
+	ShipReportingComplianceParam struct {
+		Offset    int    `json:"offset"`
+		Limit     int    `json:"limit"`
+		ShipID    int    `json:"ship_id"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	ShipReportingComplianceResponse struct {
+		ShipID          int     `json:"ship_id"`
+		ShipName        string  `json:"ship_name"`
+		Mode            string  `json:"mode"`
+		Expected        int     `json:"expected_interval"`
+		Received        int     `json:"received"`
+		Stored          int     `json:"stored"`
+		Merged          int     `json:"merged"`
+		Gaps            int     `json:"gaps"`
+		MaxGap          int64   `json:"max_gap"`
+		AverageInterval float64 `json:"average_interval"`
+		ComplianceRate  float64 `json:"compliance_rate"`
 	}
 
 	ShipWebsocketResponse struct {
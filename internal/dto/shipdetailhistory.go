@@ -0,0 +1,38 @@
+package dto
+
+type (
+	ShipDetailChange struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	ShipDetailHistoryParam struct {
+		Offset int `json:"offset"`
+		Limit  int `json:"limit"`
+	}
+
+	ShipDetailHistoryResponseList struct {
+		PageInfo
+		Data []ShipDetailHistoryResponse `json:"data"`
+	}
+
+	ShipDetailHistoryResponse struct {
+		ID            int                         `json:"id"`
+		ShipID        int                         `json:"ship_id"`
+		Version       int                         `json:"version"`
+		Detail        ShipAddonDetailResponse     `json:"detail"`
+		Changes       map[string]ShipDetailChange `json:"changes"`
+		Source        string                      `json:"source"`
+		ChangedBy     *int                        `json:"changed_by"`
+		ChangedByName string                      `json:"changed_by_name"`
+		ValidFrom     string                      `json:"valid_from"`
+		CreatedAt     string                      `json:"created_at"`
+	}
+
+	ShipDetailAsOfResponse struct {
+		ShipID  int                     `json:"ship_id"`
+		AsOf    string                  `json:"as_of"`
+		Version int                     `json:"version"`
+		Detail  ShipAddonDetailResponse `json:"detail"`
+	}
+)
@@ -0,0 +1,47 @@
+package dto
+
+type (
+	ShipDocumentRequest struct {
+		ID           int    `json:"id"`
+		ShipID       int    `json:"ship_id"`
+		Type         string `json:"type" binding:"required"`
+		Number       string `json:"number" binding:"required"`
+		Issuer       string `json:"issuer"`
+		IssuedAt     string `json:"issued_at" binding:"required"`
+		ExpiresAt    string `json:"expires_at"`
+		AttachmentID *int   `json:"attachment_id"`
+	}
+
+	ShipDocumentListParam struct {
+		Offset       int    `json:"offset"`
+		Limit        int    `json:"limit"`
+		ShipID       int    `json:"ship_id"`
+		Type         string `json:"type"`
+		Status       string `json:"status"`
+		Search       string `json:"search"`
+		ExpiringDays int    `json:"expiring_days"`
+	}
+
+	ShipDocumentResponseList struct {
+		PageInfo
+		Data []ShipDocumentResponse `json:"data"`
+	}
+
+	ShipDocumentResponse struct {
+		ID            int    `json:"id"`
+		ShipID        int    `json:"ship_id"`
+		ShipName      string `json:"ship_name"`
+		Type          string `json:"type"`
+		Number        string `json:"number"`
+		Issuer        string `json:"issuer"`
+		IssuedAt      string `json:"issued_at"`
+		ExpiresAt     string `json:"expires_at"`
+		DaysLeft      *int   `json:"days_left"`
+		Status        string `json:"status"`
+		AttachmentID  *int   `json:"attachment_id"`
+		RemindedDays  *int   `json:"reminded_days"`
+		CreatedAt     string `json:"created_at"`
+		FirebaseToken string `json:"-"`
+		HarbourID     int    `json:"-"`
+	}
+)
@@ -0,0 +1,63 @@
+package dto
+
+type (
+	ShipImportRow struct {
+		Row             int    `json:"row"`
+		ShipID          int    `json:"ship_id"`
+		Name            string `json:"name"`
+		ResponsibleName string `json:"responsible_name"`
+		Phone           string `json:"phone"`
+		Type            string `json:"type"`
+		Dimension       string `json:"dimension"`
+		Harbour         string `json:"harbour"`
+		SIUP            string `json:"siup"`
+		BKP             string `json:"bkp"`
+		SelarMark       string `json:"selar_mark"`
+		GT              string `json:"gt"`
+		OwnerName       string `json:"owner_name"`
+		ChangedBy       int    `json:"-"`
+		HarbourID       int    `json:"-"`
+	}
+
+	ShipImportIndex struct {
+		ID   int
+		Name string
+		SIUP string
+	}
+
+	ShipImportRowError struct {
+		Row     int    `json:"row"`
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+
+	ShipImportListParam struct {
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		Status string `json:"status"`
+	}
+
+	ShipImportResponseList struct {
+		PageInfo
+		Data []ShipImportResponse `json:"data"`
+	}
+
+	ShipImportResponse struct {
+		ID            int                  `json:"id"`
+		FileName      string               `json:"file_name"`
+		Status        string               `json:"status"`
+		DryRun        bool                 `json:"dry_run"`
+		TotalRows     int                  `json:"total_rows"`
+		ProcessedRows int                  `json:"processed_rows"`
+		Progress      float64              `json:"progress"`
+		CreatedRows   int                  `json:"created_rows"`
+		UpdatedRows   int                  `json:"updated_rows"`
+		FailedRows    int                  `json:"failed_rows"`
+		Error         string               `json:"error"`
+		Errors        []ShipImportRowError `json:"errors,omitempty"`
+		CreatedBy     int                  `json:"created_by"`
+		StartedAt     string               `json:"started_at"`
+		FinishedAt    string               `json:"finished_at"`
+		CreatedAt     string               `json:"created_at"`
+	}
+)
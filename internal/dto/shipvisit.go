@@ -0,0 +1,38 @@
+package dto
+
+type (
+	ShipVisitListParam struct {
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		ShipID int    `json:"ship_id"`
+		Status string `json:"status"`
+		// Direction narrows the visits to the ships visiting our harbours (incoming)
+		// or to our ships visiting another harbour (outgoing)
+		Direction string `json:"direction"`
+		Search    string `json:"search"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	ShipVisitResponseList struct {
+		PageInfo
+		Data []ShipVisitResponse `json:"data"`
+	}
+
+	ShipVisitResponse struct {
+		ID              int    `json:"id"`
+		ShipID          int    `json:"ship_id"`
+		ShipName        string `json:"ship_name"`
+		ResponsibleName string `json:"responsible_name"`
+		Phone           string `json:"phone"`
+		HomeHarbourID   int    `json:"home_harbour_id"`
+		HomeHarbourName string `json:"home_harbour_name"`
+		HarbourID       int    `json:"harbour_id"`
+		HarbourName     string `json:"harbour_name"`
+		Status          string `json:"status"`
+		Lat             string `json:"lat"`
+		Long            string `json:"long"`
+		ArrivedAt       string `json:"arrived_at"`
+		DepartedAt      string `json:"departed_at"`
+	}
+)
@@ -35,6 +35,8 @@ type (
 		Role      string    `json:"role" binding:"required"`
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
+		// HarbourIDs are the harbours an admin manages, defaults to the harbours of the creating admin
+		HarbourIDs []int `json:"harbour_ids" gorm:"-"`
 	}
 
 	PayloadUpdateUser struct {
@@ -45,6 +47,7 @@ type (
 		Role            string    `json:"role" binding:"required"`
 		EmailVerifiedAt time.Time `json:"email_verified_at"`
 		UpdatedAt       time.Time `json:"updated_at"`
+		HarbourIDs      []int     `json:"harbour_ids" gorm:"-"`
 	}
 
 	PayloadUpdateJwtToken struct {
@@ -80,6 +83,13 @@ type (
 		Role      string `json:"role"`
 		CreatedAt string `json:"created_at"`
 		UpdatedAt string `json:"updated_at"`
+		// HarbourIDs are the harbours assigned to an admin
+		HarbourIDs []int `json:"harbour_ids"`
+	}
+
+	UserResponseList struct {
+		PageInfo
+		Data []AllUser `json:"data"`
 	}
 
 	AllUser struct {
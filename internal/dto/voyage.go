@@ -0,0 +1,50 @@
+package dto
+
+type (
+	VoyageListParam struct {
+		Offset    int      `json:"offset"`
+		Limit     int      `json:"limit"`
+		ShipID    int      `json:"ship_id"`
+		Status    []string `json:"status"`
+		Search    string   `json:"search"`
+		StartDate string   `json:"start_date"`
+		EndDate   string   `json:"end_date"`
+	}
+
+	VoyageResponseList struct {
+		PageInfo
+		Data []VoyageResponse `json:"data"`
+	}
+
+	VoyageResponse struct {
+		ID            int     `json:"id"`
+		ShipID        int     `json:"ship_id"`
+		ShipName      string  `json:"ship_name"`
+		Status        string  `json:"status"`
+		CheckoutLogID int     `json:"checkout_log_id"`
+		CheckinLogID  *int    `json:"checkin_log_id"`
+		DepartedAt    string  `json:"departed_at"`
+		ArrivedAt     string  `json:"arrived_at"`
+		Duration      int64   `json:"duration"`
+		Distance      float64 `json:"distance"`
+		MaxDistance   float64 `json:"max_distance"`
+		FraudCount    int     `json:"fraud_count"`
+	}
+
+	VoyageProgress struct {
+		ShipID          int     `json:"ship_id"`
+		Lat             string  `json:"lat"`
+		Long            string  `json:"long"`
+		HarbourDistance float64 `json:"harbour_distance"`
+		IsFraud         int     `json:"is_fraud"`
+	}
+
+	VoyageRebuildRequest struct {
+		ShipID int `json:"ship_id"`
+	}
+
+	VoyageRebuildResponse struct {
+		Ships   int `json:"ships"`
+		Voyages int `json:"voyages"`
+	}
+)
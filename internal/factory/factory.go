@@ -4,17 +4,35 @@ import (
 	"owlharbour-api/database"
 	"owlharbour-api/internal/rabbitmq"
 	"owlharbour-api/internal/repository"
+	"owlharbour-api/pkg/storage"
 	"owlharbour-api/pkg/util"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Factory struct {
-	AppRepository            repository.App
-	ShipRepository           repository.Ship
-	PairingRequestRepository repository.PairingRequest
-	UserRepository           repository.User
-	RabbitMqRepository       repository.RabbitMq
+	AppRepository               repository.App
+	ShipRepository              repository.Ship
+	PairingRequestRepository    repository.PairingRequest
+	UserRepository              repository.User
+	RabbitMqRepository          repository.RabbitMq
+	VoyageRepository            repository.Voyage
+	FraudCaseRepository         repository.FraudCase
+	ReportJobRepository         repository.ReportJob
+	InspectionRepository        repository.Inspection
+	AttachmentRepository        repository.Attachment
+	LandingRepository           repository.Landing
+	CrewRepository              repository.Crew
+	ShipDocumentRepository      repository.ShipDocument
+	ShipImportRepository        repository.ShipImport
+	ShipDetailHistoryRepository repository.ShipDetailHistory
+	HarbourRepository           repository.Harbour
+	ShipVisitRepository         repository.ShipVisit
+	BerthRepository             repository.Berth
+	InvoiceRepository           repository.Invoice
+	OccupancyRepository         repository.Occupancy
+	HeatmapRepository           repository.Heatmap
+	Storage                     storage.Storage
 }
 
 func NewFactory() *Factory {
@@ -28,11 +46,28 @@ func NewFactory() *Factory {
 
 	return &Factory{
 		// Pass the db connection to the repository package for database query calling
-		AppRepository:            repository.NewAppRepository(db, redisClient),
-		ShipRepository:           repository.NewShipRepository(db, redisClient),
-		PairingRequestRepository: repository.NewPairingRequestRepository(db, redisClient),
-		UserRepository:           repository.NewUserRepository(db, redisClient),
-		RabbitMqRepository:       repository.NewRabbitMqRepository(conn, ch),
+		AppRepository:               repository.NewAppRepository(db, redisClient),
+		ShipRepository:              repository.NewShipRepository(db, redisClient),
+		PairingRequestRepository:    repository.NewPairingRequestRepository(db, redisClient),
+		UserRepository:              repository.NewUserRepository(db, redisClient),
+		RabbitMqRepository:          repository.NewRabbitMqRepository(conn, ch),
+		VoyageRepository:            repository.NewVoyageRepository(db, redisClient),
+		FraudCaseRepository:         repository.NewFraudCaseRepository(db, redisClient),
+		ReportJobRepository:         repository.NewReportJobRepository(db, redisClient),
+		InspectionRepository:        repository.NewInspectionRepository(db, redisClient),
+		AttachmentRepository:        repository.NewAttachmentRepository(db, redisClient),
+		LandingRepository:           repository.NewLandingRepository(db, redisClient),
+		CrewRepository:              repository.NewCrewRepository(db, redisClient),
+		ShipDocumentRepository:      repository.NewShipDocumentRepository(db, redisClient),
+		ShipImportRepository:        repository.NewShipImportRepository(db, redisClient),
+		ShipDetailHistoryRepository: repository.NewShipDetailHistoryRepository(db, redisClient),
+		HarbourRepository:           repository.NewHarbourRepository(db, redisClient),
+		ShipVisitRepository:         repository.NewShipVisitRepository(db, redisClient),
+		BerthRepository:             repository.NewBerthRepository(db, redisClient),
+		InvoiceRepository:           repository.NewInvoiceRepository(db, redisClient),
+		OccupancyRepository:         repository.NewOccupancyRepository(db, redisClient),
+		HeatmapRepository:           repository.NewHeatmapRepository(db, redisClient),
+		Storage:                     storage.NewStorage(),
 		// Assign the appropriate implementation of the ReturInsightRepository
 	}
 }
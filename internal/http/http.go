@@ -1,12 +1,25 @@
 package http
 
 import (
+	"log"
+	Attachment "owlharbour-api/internal/app/attachment"
+	Berth "owlharbour-api/internal/app/berth"
+	Crew "owlharbour-api/internal/app/crew"
 	Dashboard "owlharbour-api/internal/app/dashboard"
+	Document "owlharbour-api/internal/app/document"
+	FraudCase "owlharbour-api/internal/app/fraudcase"
+	Heatmap "owlharbour-api/internal/app/heatmap"
 	Inspection "owlharbour-api/internal/app/inspection"
+	Invoice "owlharbour-api/internal/app/invoice"
+	Landing "owlharbour-api/internal/app/landing"
+	Occupancy "owlharbour-api/internal/app/occupancy"
 	Report "owlharbour-api/internal/app/report"
+	Scheduler "owlharbour-api/internal/app/scheduler"
 	Setting "owlharbour-api/internal/app/setting"
 	Ship "owlharbour-api/internal/app/ship"
 	User "owlharbour-api/internal/app/user"
+	Visit "owlharbour-api/internal/app/visit"
+	Voyage "owlharbour-api/internal/app/voyage"
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/internal/middleware"
 	"owlharbour-api/pkg/util"
@@ -26,6 +39,13 @@ func errorHandler(c *gin.Context, info ratelimit.Info) {
 
 // Here we define route function for user Handlers that accepts gin.Engine and factory parameters
 func NewHttp(g *gin.Engine, f *factory.Factory) {
+	// handlers passing the gin context as context.Context still see the harbour scope set on the request
+	g.ContextWithFallback = true
+
+	// the dashboard monitor websocket trusts the api key alone, it must never fall back to a known one
+	if util.GetEnv("WEBSOCKET_API_KEY", "") == "" {
+		log.Fatal("WEBSOCKET_API_KEY is required to authenticate the websocket routes")
+	}
 
 	Index(g)
 	// Here we use logger middleware before the actual API to catch any api call from clients
@@ -56,6 +76,18 @@ func NewHttp(g *gin.Engine, f *factory.Factory) {
 	Ship.NewHandler(f).Router(v1.Group("/ship"))
 	User.NewHandler(f).Router(v1.Group("/user"))
 	Inspection.NewHandler(f).Router(v1.Group("/inspection"))
+	Voyage.NewHandler(f).Router(v1.Group("/voyage"))
+	Visit.NewHandler(f).Router(v1.Group("/visit"))
+	Berth.NewHandler(f).Router(v1.Group("/berth"))
+	Invoice.NewHandler(f).Router(v1.Group("/invoice"))
+	Occupancy.NewHandler(f).Router(v1.Group("/occupancy"))
+	Heatmap.NewHandler(f).Router(v1.Group("/heatmap"))
+	FraudCase.NewHandler(f).Router(v1.Group("/fraud-case"))
+	Scheduler.NewHandler(f).Router(v1.Group("/scheduler"))
+	Attachment.NewHandler(f).Router(v1.Group("/attachment"))
+	Landing.NewHandler(f).Router(v1.Group("/landing"))
+	Crew.NewHandler(f).Router(v1.Group("/crew"))
+	Document.NewHandler(f).Router(v1.Group("/document"))
 }
 
 func Index(g *gin.Engine) {
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"owlharbour-api/internal/factory"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveHarbours returns the harbours the requests of user are scoped to, nil when every harbour
+// is visible. Superadmins see every harbour, admins the harbours they are assigned to and ship
+// accounts the harbour of their ship. The X-Harbour-Code header narrows the scope to one of them.
+func resolveHarbours(c *gin.Context, f *factory.Factory, user model.User) ([]int, error) {
+	var allowed []int
+
+	switch user.Role {
+	case model.SuperAdmin:
+		allowed = nil
+	case model.Admin:
+		ids, err := f.HarbourRepository.UserHarbours(c, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = append([]int{}, ids...)
+	default:
+		harbourID, err := f.HarbourRepository.ShipUserHarbour(c, user.ID)
+		if err != nil {
+			return []int{}, nil
+		}
+
+		allowed = []int{harbourID}
+	}
+
+	header := c.GetHeader("X-Harbour-Code")
+	if header == "" {
+		return allowed, nil
+	}
+
+	code, err := strconv.Atoi(header)
+	if err != nil {
+		return nil, constants.InvalidHarbour
+	}
+
+	harbour, err := f.HarbourRepository.HarbourByCode(c, code)
+	if err != nil {
+		return nil, constants.InvalidHarbour
+	}
+
+	if allowed != nil && !containsHarbour(allowed, harbour.ID) {
+		return nil, constants.HarbourForbidden
+	}
+
+	return []int{harbour.ID}, nil
+}
+
+func containsHarbour(ids []int, harbourID int) bool {
+	for _, id := range ids {
+		if id == harbourID {
+			return true
+		}
+	}
+
+	return false
+}
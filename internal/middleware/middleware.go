@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"owlharbour-api/internal/factory"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"regexp"
 	"strconv"
@@ -54,6 +56,23 @@ func Authenticate() gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("bearer", bearerStr)
 
+		harbours, err := resolveHarbours(c, f, user)
+		if err != nil {
+			status := http.StatusBadRequest
+			if err == constants.HarbourForbidden {
+				status = http.StatusForbidden
+			}
+
+			response := util.APIResponse(err.Error(), status, "failed", nil)
+			c.JSON(status, response)
+			c.Abort()
+			return
+		}
+
+		if harbours != nil {
+			c.Request = c.Request.WithContext(tenant.WithHarbours(c.Request.Context(), harbours...))
+		}
+
 		c.Next()
 	}
 }
@@ -1,8 +1,9 @@
 package model
 
 type AppGeofence struct {
-	Long string `gorm:"varchar"`
-	Lat  string `gorm:"varchar"`
+	HarbourID int    `gorm:"index"`
+	Long      string `gorm:"varchar"`
+	Lat       string `gorm:"varchar"`
 }
 
 func (AppGeofence) TableName() string {
@@ -1,5 +1,7 @@
 package model
 
+// AppSetting is the settings table of single harbour deployments, it is only read to create
+// the first harbour when such a database is migrated
 type AppSetting struct {
 	HarbourCode  int      `gorm:"integer"`
 	HarbourName  string   `gorm:"varchar"`
@@ -7,6 +9,9 @@ type AppSetting struct {
 	Interval     int      `gorm:"integer"`
 	Range        int      `gorm:"integer"`
 	AdminContact string   `gorm:"varchar"`
+
+	InspectionSlaHours int                  `gorm:"integer"`
+	InspectionAssign   InspectionAssignMode `gorm:"varchar"`
 }
 
 func (AppSetting) TableName() string {
@@ -0,0 +1,18 @@
+package model
+
+type Attachment struct {
+	Common
+	OwnerType    AttachmentOwner `gorm:"varchar"`
+	OwnerID      int
+	Category     AttachmentCategory `gorm:"varchar"`
+	FileName     string             `gorm:"varchar"`
+	ContentType  string             `gorm:"varchar"`
+	Size         int64
+	StorageKey   string `gorm:"varchar"`
+	ThumbnailKey string `gorm:"varchar"`
+	UploadedBy   int
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Berth is a mooring zone of a harbour, its zone is stored in berth_geofences. A limit of 0
+// leaves the ship size unchecked, the zone type picks the port tariff of a stay at the berth
+type Berth struct {
+	Common
+	HarbourID int    `gorm:"index"`
+	Code      string `gorm:"varchar"`
+	Name      string `gorm:"varchar"`
+	ZoneType  string `gorm:"varchar"`
+	Capacity  int    `gorm:"integer"`
+	MaxLength float64
+	MaxGT     float64
+	IsActive  int
+}
+
+func (Berth) TableName() string {
+	return "berths"
+}
+
+type BerthGeofence struct {
+	BerthID int    `gorm:"index"`
+	Long    string `gorm:"varchar"`
+	Lat     string `gorm:"varchar"`
+}
+
+func (BerthGeofence) TableName() string {
+	return "berth_geofences"
+}
+
+// BerthReservation holds a berth for a ship during its expected arrival window, a request of
+// the ship waits for the staff to assign it and it is confirmed once the ship enters the berth
+type BerthReservation struct {
+	Common
+	BerthID     int               `gorm:"index"`
+	ShipID      int               `gorm:"index"`
+	Status      ReservationStatus `gorm:"varchar"`
+	ArrivalAt   time.Time         `gorm:"timestamp"`
+	DepartureAt time.Time         `gorm:"timestamp"`
+	RequestedBy int
+	AssignedBy  *int
+	ConfirmedAt *time.Time `gorm:"timestamp"`
+	Note        string     `gorm:"text"`
+}
+
+func (BerthReservation) TableName() string {
+	return "berth_reservations"
+}
@@ -33,6 +33,30 @@ type PairingStatus string
 type ModeType string
 type RoleType string
 type ShipType string
+type VoyageStatus string
+type FraudReason string
+type FraudCaseStatus string
+type FraudCaseAction string
+type SanctionType string
+type ReportKind string
+type JobFrequency string
+type JobRunStatus string
+type ChecklistCategory string
+type ChecklistResult string
+type InspectionOutcome string
+type AttachmentOwner string
+type AttachmentCategory string
+type InspectionAssignMode string
+type InspectionTaskStatus string
+type FishingGear string
+type LandingSource string
+type CrewPosition string
+type DocumentType string
+type ImportStatus string
+type ShipDetailSource string
+type VisitStatus string
+type ReservationStatus string
+type InvoiceStatus string
 
 const (
 	KapalAngkut    ShipType = "kapal angkut"
@@ -45,6 +69,170 @@ const (
 	OutOfScope ShipStatus = "out of scope"
 )
 
+const (
+	VoyageOngoing   VoyageStatus = "ongoing"
+	VoyageCompleted VoyageStatus = "completed"
+)
+
+const (
+	FraudMocked          FraudReason = "mocked"
+	FraudImpossibleSpeed FraudReason = "impossible_speed"
+	FraudTeleport        FraudReason = "teleport"
+	FraudInland          FraudReason = "inland"
+	FraudFrozenPosition  FraudReason = "frozen_position"
+	FraudTimestamp       FraudReason = "timestamp_anomaly"
+)
+
+const (
+	CaseOpen      FraudCaseStatus = "open"
+	CaseReviewing FraudCaseStatus = "reviewing"
+	CaseEscalated FraudCaseStatus = "escalated"
+	CaseDismissed FraudCaseStatus = "dismissed"
+	CaseResolved  FraudCaseStatus = "resolved"
+)
+
+const (
+	CaseActionOpened   FraudCaseAction = "opened"
+	CaseActionStatus   FraudCaseAction = "status"
+	CaseActionAssign   FraudCaseAction = "assign"
+	CaseActionNote     FraudCaseAction = "note"
+	CaseActionSanction FraudCaseAction = "sanction"
+)
+
+const (
+	SanctionWarning SanctionType = "warning"
+	SanctionSummon  SanctionType = "summon"
+)
+
+const (
+	ReportDocking  ReportKind = "docking"
+	ReportFraud    ReportKind = "fraud"
+	ReportActivity ReportKind = "activity"
+)
+
+const (
+	Daily   JobFrequency = "daily"
+	Weekly  JobFrequency = "weekly"
+	Monthly JobFrequency = "monthly"
+)
+
+const (
+	JobRunRunning  JobRunStatus = "running"
+	JobRunSuccess  JobRunStatus = "success"
+	JobRunRetrying JobRunStatus = "retrying"
+	JobRunFailed   JobRunStatus = "failed"
+)
+
+const (
+	CategorySafetyGear ChecklistCategory = "safety_gear"
+	CategoryLicense    ChecklistCategory = "license"
+	CategoryCatch      ChecklistCategory = "catch"
+	CategoryOther      ChecklistCategory = "other"
+)
+
+const (
+	ResultPass          ChecklistResult = "pass"
+	ResultFail          ChecklistResult = "fail"
+	ResultNotApplicable ChecklistResult = "na"
+)
+
+const (
+	OutcomePassed      InspectionOutcome = "passed"
+	OutcomeConditional InspectionOutcome = "conditional"
+	OutcomeFailed      InspectionOutcome = "failed"
+)
+
+const (
+	AssignRoundRobin InspectionAssignMode = "round_robin"
+	AssignManual     InspectionAssignMode = "manual"
+)
+
+const (
+	TaskOpen InspectionTaskStatus = "open"
+	TaskDone InspectionTaskStatus = "done"
+)
+
+const (
+	GearGillnet    FishingGear = "gillnet"
+	GearLongline   FishingGear = "longline"
+	GearPurseSeine FishingGear = "purse_seine"
+	GearTrawl      FishingGear = "trawl"
+	GearHandline   FishingGear = "handline"
+	GearPoleLine   FishingGear = "pole_and_line"
+	GearTrap       FishingGear = "trap"
+	GearOther      FishingGear = "other"
+)
+
+const (
+	LandingMobile    LandingSource = "mobile"
+	LandingInspector LandingSource = "inspector"
+)
+
+const (
+	CrewCaptain  CrewPosition = "captain"
+	CrewOfficer  CrewPosition = "officer"
+	CrewEngineer CrewPosition = "engineer"
+	CrewDeckhand CrewPosition = "deckhand"
+	CrewCook     CrewPosition = "cook"
+	CrewOther    CrewPosition = "other"
+)
+
+const (
+	DocumentSIUP          DocumentType = "siup"
+	DocumentBKP           DocumentType = "bkp"
+	DocumentSelarMark     DocumentType = "selar_mark"
+	DocumentSeaworthiness DocumentType = "seaworthiness"
+	DocumentOther         DocumentType = "other"
+)
+
+const (
+	ImportQueued    ImportStatus = "queued"
+	ImportRunning   ImportStatus = "running"
+	ImportValidated ImportStatus = "validated"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+const (
+	// DetailSourceBaseline is the version recorded for details stored before history was kept
+	DetailSourceBaseline ShipDetailSource = "baseline"
+	DetailSourceManual   ShipDetailSource = "manual"
+	DetailSourceImport   ShipDetailSource = "import"
+)
+
+const (
+	VisitOngoing  VisitStatus = "visiting"
+	VisitDeparted VisitStatus = "departed"
+)
+
+const (
+	ReservationRequested ReservationStatus = "requested"
+	ReservationAssigned  ReservationStatus = "assigned"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationCancelled ReservationStatus = "cancelled"
+)
+
+const (
+	InvoiceDraft  InvoiceStatus = "draft"
+	InvoiceIssued InvoiceStatus = "issued"
+	InvoicePaid   InvoiceStatus = "paid"
+	InvoiceVoid   InvoiceStatus = "void"
+)
+
+const (
+	OwnerInspection     AttachmentOwner = "inspection"
+	OwnerShip           AttachmentOwner = "ship"
+	OwnerPairingRequest AttachmentOwner = "pairing_request"
+)
+
+const (
+	AttachmentPhoto    AttachmentCategory = "photo"
+	AttachmentSIUP     AttachmentCategory = "siup"
+	AttachmentBKP      AttachmentCategory = "bkp"
+	AttachmentDocument AttachmentCategory = "document"
+	AttachmentEvidence AttachmentCategory = "evidence"
+)
+
 const (
 	Pending  PairingStatus = "pending"
 	Approved PairingStatus = "approved"
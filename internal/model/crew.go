@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+type CrewMember struct {
+	Common
+	ShipID   int
+	Name     string       `gorm:"varchar"`
+	IDNumber string       `gorm:"varchar"`
+	Position CrewPosition `gorm:"varchar"`
+	Phone    string       `gorm:"varchar"`
+	IsActive int
+}
+
+func (CrewMember) TableName() string {
+	return "crew_members"
+}
+
+// CrewManifest lists who is on board for one departure, it stays pending until the
+// checkout log of the ship is attached
+type CrewManifest struct {
+	Common
+	ShipID        int
+	CheckoutLogID *int
+	SubmittedBy   int
+	SubmittedAt   time.Time  `gorm:"timestamp"`
+	DepartedAt    *time.Time `gorm:"timestamp"`
+	Note          string     `gorm:"text"`
+}
+
+func (CrewManifest) TableName() string {
+	return "crew_manifests"
+}
+
+// CrewManifestMember copies the crew details at submission so later edits of a crew
+// member do not rewrite past manifests
+type CrewManifestMember struct {
+	Common
+	CrewManifestID int
+	CrewMemberID   int
+	Name           string       `gorm:"varchar"`
+	IDNumber       string       `gorm:"varchar"`
+	Position       CrewPosition `gorm:"varchar"`
+}
+
+func (CrewManifestMember) TableName() string {
+	return "crew_manifest_members"
+}
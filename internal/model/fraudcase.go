@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type FraudCase struct {
+	Common
+	ShipID       int
+	Status       FraudCaseStatus `gorm:"enum:open,reviewing,escalated,dismissed,resolved"`
+	AssigneeID   *int
+	FirstLogID   int
+	LastLogID    int
+	StartedAt    time.Time `gorm:"timestamp"`
+	LastSeenAt   time.Time `gorm:"timestamp"`
+	FixCount     int
+	MaxScore     int
+	Reasons      string       `gorm:"varchar"`
+	Sanction     SanctionType `gorm:"varchar"`
+	SanctionedAt *time.Time   `gorm:"timestamp"`
+	ClosedAt     *time.Time   `gorm:"timestamp"`
+}
+
+func (FraudCase) TableName() string {
+	return "fraud_cases"
+}
+
+type FraudCaseActivity struct {
+	Common
+	FraudCaseID int
+	UserID      *int
+	Action      FraudCaseAction `gorm:"varchar"`
+	FromStatus  FraudCaseStatus `gorm:"varchar"`
+	ToStatus    FraudCaseStatus `gorm:"varchar"`
+	Note        string          `gorm:"text"`
+}
+
+func (FraudCaseActivity) TableName() string {
+	return "fraud_case_activities"
+}
@@ -0,0 +1,35 @@
+package model
+
+// Harbour is a port served by the instance together with its settings, the zone of a
+// harbour is stored in app_geofences
+type Harbour struct {
+	Common
+	Code         int      `gorm:"integer;uniqueIndex"`
+	Name         string   `gorm:"varchar"`
+	Mode         ModeType `gorm:"enum:interval,range"`
+	Interval     int      `gorm:"integer"`
+	Range        int      `gorm:"integer"`
+	AdminContact string   `gorm:"varchar"`
+	IsActive     int
+
+	InspectionSlaHours int                  `gorm:"integer"`
+	InspectionAssign   InspectionAssignMode `gorm:"varchar"`
+
+	// Timezone is the IANA zone the statistics, occupancy and heatmap days of the harbour are cut
+	// in, harbours saved without one get APP_TIMEZONE
+	Timezone string `gorm:"varchar"`
+}
+
+func (Harbour) TableName() string {
+	return "harbours"
+}
+
+// UserHarbour grants an admin access to a harbour, superadmins see every harbour
+type UserHarbour struct {
+	UserID    int `gorm:"primaryKey"`
+	HarbourID int `gorm:"primaryKey"`
+}
+
+func (UserHarbour) TableName() string {
+	return "user_harbours"
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ShipPositionRollup holds the fixes a ship sent from one geohash cell during a day, the heatmap
+// aggregates these instead of the raw location logs
+type ShipPositionRollup struct {
+	Common
+	HarbourID int       `gorm:"index"`
+	ShipID    int       `gorm:"uniqueIndex:idx_ship_position_rollup_cell"`
+	Date      time.Time `gorm:"type:date;index;uniqueIndex:idx_ship_position_rollup_cell"`
+	Geohash   string    `gorm:"varchar;uniqueIndex:idx_ship_position_rollup_cell"`
+	OnGround  int       `gorm:"uniqueIndex:idx_ship_position_rollup_cell"`
+	Fixes     int
+}
+
+func (ShipPositionRollup) TableName() string {
+	return "ship_position_rollups"
+}
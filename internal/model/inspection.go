@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+type InspectionTemplate struct {
+	Common
+	Name        string `gorm:"varchar"`
+	Description string `gorm:"text"`
+	IsActive    int
+}
+
+func (InspectionTemplate) TableName() string {
+	return "inspection_templates"
+}
+
+type InspectionTemplateItem struct {
+	Common
+	InspectionTemplateID int
+	Category             ChecklistCategory `gorm:"varchar"`
+	Label                string            `gorm:"varchar"`
+	Description          string            `gorm:"text"`
+	IsRequired           int
+	Sort                 int
+}
+
+func (InspectionTemplateItem) TableName() string {
+	return "inspection_template_items"
+}
+
+type Inspection struct {
+	Common
+	ShipDockedLogID      int
+	ShipID               int
+	InspectionTemplateID int
+	InspectorID          int
+	Outcome              InspectionOutcome `gorm:"enum:passed,conditional,failed"`
+	Findings             string            `gorm:"text"`
+	StartedAt            time.Time         `gorm:"timestamp"`
+	CompletedAt          time.Time         `gorm:"timestamp"`
+}
+
+func (Inspection) TableName() string {
+	return "inspections"
+}
+
+// InspectionResult keeps a copy of the checklist item so past inspections read the same after the template changes
+type InspectionResult struct {
+	Common
+	InspectionID             int
+	InspectionTemplateItemID int
+	Category                 ChecklistCategory `gorm:"varchar"`
+	Label                    string            `gorm:"varchar"`
+	IsRequired               int
+	Result                   ChecklistResult `gorm:"varchar"`
+	Note                     string          `gorm:"text"`
+}
+
+func (InspectionResult) TableName() string {
+	return "inspection_results"
+}
+
+type InspectionTask struct {
+	Common
+	ShipDockedLogID   int
+	ShipID            int
+	AssigneeID        *int
+	AssignMode        InspectionAssignMode `gorm:"varchar"`
+	Status            InspectionTaskStatus `gorm:"enum:open,done"`
+	CheckinAt         time.Time            `gorm:"timestamp"`
+	DueAt             time.Time            `gorm:"timestamp"`
+	AssignedAt        *time.Time           `gorm:"timestamp"`
+	CompletedAt       *time.Time           `gorm:"timestamp"`
+	InspectionID      *int
+	OverdueNotifiedAt *time.Time `gorm:"timestamp"`
+}
+
+func (InspectionTask) TableName() string {
+	return "inspection_tasks"
+}
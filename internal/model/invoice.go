@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// PortTariff prices a stay in a zone type of a harbour, a stay is charged
+// BaseFee + Days * (DailyRate + GT * RatePerGT). An empty zone type is the default of the
+// harbour for stays outside any berth. Money is stored in minor units (cents)
+type PortTariff struct {
+	Common
+	HarbourID int    `gorm:"index"`
+	ZoneType  string `gorm:"varchar"`
+	Name      string `gorm:"varchar"`
+	BaseFee   int64
+	DailyRate int64
+	RatePerGT int64
+	IsActive  int
+}
+
+func (PortTariff) TableName() string {
+	return "port_tariffs"
+}
+
+// Invoice bills one stay, the check-in to check-out of a ship. The tariff is copied so later
+// changes of the tariff do not rewrite issued invoices. A stay has at most one invoice which is
+// not void, see idx_invoices_checkout_billed. Money is stored in minor units (cents)
+type Invoice struct {
+	Common
+	HarbourID     int    `gorm:"index"`
+	ShipID        int    `gorm:"index"`
+	Number        string `gorm:"varchar"`
+	CheckinLogID  int
+	CheckoutLogID int
+	BerthID       *int
+	TariffID      int
+	ZoneType      string    `gorm:"varchar"`
+	ArrivedAt     time.Time `gorm:"timestamp"`
+	DepartedAt    time.Time `gorm:"timestamp"`
+	Days          int
+	GT            float64
+	BaseFee       int64
+	DailyRate     int64
+	RatePerGT     int64
+	Amount        int64
+	PaidAmount    int64
+	Status        InvoiceStatus `gorm:"varchar"`
+	IssuedAt      *time.Time    `gorm:"timestamp"`
+	PaidAt        *time.Time    `gorm:"timestamp"`
+	VoidedAt      *time.Time    `gorm:"timestamp"`
+	VoidReason    string        `gorm:"text"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+type InvoicePayment struct {
+	Common
+	InvoiceID  int `gorm:"index"`
+	Amount     int64
+	Method     string    `gorm:"varchar"`
+	Reference  string    `gorm:"varchar"`
+	PaidAt     time.Time `gorm:"timestamp"`
+	RecordedBy int
+}
+
+func (InvoicePayment) TableName() string {
+	return "invoice_payments"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type Landing struct {
+	Common
+	ShipDockedLogID int
+	ShipID          int
+	FishingArea     string        `gorm:"varchar"`
+	Gear            FishingGear   `gorm:"varchar"`
+	Source          LandingSource `gorm:"varchar"`
+	SubmittedBy     int
+	LandedAt        time.Time `gorm:"timestamp"`
+	Note            string    `gorm:"text"`
+}
+
+func (Landing) TableName() string {
+	return "landings"
+}
+
+type LandingCatch struct {
+	Common
+	LandingID int
+	Species   string `gorm:"varchar"`
+	WeightKg  float64
+}
+
+func (LandingCatch) TableName() string {
+	return "landing_catches"
+}
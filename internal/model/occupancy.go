@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// OccupancyThreshold raises an alert once more ships than Ships are inside the harbour
+type OccupancyThreshold struct {
+	Common
+	HarbourID int    `gorm:"index"`
+	Name      string `gorm:"varchar"`
+	Ships     int    `gorm:"integer"`
+	IsActive  int
+}
+
+func (OccupancyThreshold) TableName() string {
+	return "occupancy_thresholds"
+}
+
+// OccupancyAlert stays open while the occupancy exceeds its threshold, the threshold is copied
+// so later edits do not rewrite past alerts
+type OccupancyAlert struct {
+	Common
+	HarbourID     int        `gorm:"index"`
+	ThresholdID   int        `gorm:"index"`
+	ThresholdName string     `gorm:"varchar"`
+	Threshold     int        `gorm:"integer"`
+	Occupancy     int        `gorm:"integer"`
+	PeakOccupancy int        `gorm:"integer"`
+	RaisedAt      time.Time  `gorm:"timestamp"`
+	ResolvedAt    *time.Time `gorm:"timestamp"`
+}
+
+func (OccupancyAlert) TableName() string {
+	return "occupancy_alerts"
+}
@@ -10,6 +10,7 @@ type PairingRequest struct {
 	DeviceID        string        `gorm:"varchar"`
 	FirebaseToken   string        `gorm:"varchar"`
 	Status          PairingStatus `gorm:"enum:pending,approved,rejected"`
+	HarbourID       int
 }
 
 func (PairingRequest) TableName() string {
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type ReportJob struct {
+	Common
+	Name       string       `gorm:"varchar"`
+	Report     ReportKind   `gorm:"varchar"`
+	Frequency  JobFrequency `gorm:"enum:daily,weekly,monthly"`
+	Format     string       `gorm:"varchar"`
+	Recipients string       `gorm:"text"`
+	RunTime    string       `gorm:"varchar"`
+	Weekday    int
+	MonthDay   int
+	MaxRetry   int
+	IsActive   int
+	NextRunAt  time.Time  `gorm:"timestamp"`
+	LastRunAt  *time.Time `gorm:"timestamp"`
+	CreatedBy  int
+	HarbourID  int
+}
+
+func (ReportJob) TableName() string {
+	return "report_jobs"
+}
+
+type ReportJobRun struct {
+	Common
+	ReportJobID int
+	Status      JobRunStatus `gorm:"varchar"`
+	Attempt     int
+	PeriodStart string     `gorm:"varchar"`
+	PeriodEnd   string     `gorm:"varchar"`
+	ScheduledAt time.Time  `gorm:"timestamp"`
+	FinishedAt  *time.Time `gorm:"timestamp"`
+	NextRetryAt *time.Time `gorm:"timestamp"`
+	Error       string     `gorm:"text"`
+}
+
+func (ReportJobRun) TableName() string {
+	return "report_job_runs"
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Ship struct {
 	Common
 	Name            string     `gorm:"varchar"`
@@ -12,7 +14,9 @@ type Ship struct {
 	CurrentLong     string     `gorm:"varchar"`
 	DegNorth        string     `gorm:"varchar"`
 	UserID          int
+	HarbourID       int `gorm:"index"`
 	OnGround        int
+	LastReportedAt  *time.Time `gorm:"timestamp"`
 }
 
 func (Ship) TableName() string {
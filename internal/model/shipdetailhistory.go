@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// ShipDetailHistory is a version of a ship's ship_details row, every update of the details
+// stores the full snapshot together with the changed fields and the actor
+type ShipDetailHistory struct {
+	Common
+	ShipID    int
+	Version   int
+	Type      ShipType `gorm:"enum:kapal angkut,kapal tangkap"`
+	Dimension string   `gorm:"varchar"`
+	Harbour   string   `gorm:"varchar"`
+	SIUP      string   `gorm:"varchar"`
+	BKP       string   `gorm:"varchar"`
+	SelarMark string   `gorm:"varchar"`
+	GT        string   `gorm:"varchar"`
+	OwnerName string   `gorm:"varchar"`
+	// Changes is the json encoded diff against the previous version
+	Changes   string           `gorm:"text"`
+	Source    ShipDetailSource `gorm:"varchar"`
+	ChangedBy *int
+	ValidFrom time.Time `gorm:"timestamp"`
+}
+
+func (ShipDetailHistory) TableName() string {
+	return "ship_detail_histories"
+}
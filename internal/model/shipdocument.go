@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type ShipDocument struct {
+	Common
+	ShipID       int
+	Type         DocumentType `gorm:"varchar"`
+	Number       string       `gorm:"varchar"`
+	Issuer       string       `gorm:"varchar"`
+	IssuedAt     time.Time    `gorm:"date"`
+	ExpiresAt    *time.Time   `gorm:"date"`
+	AttachmentID *int
+	RemindedDays *int       // smallest reminder threshold already sent, reset when the expiry changes
+	RemindedAt   *time.Time `gorm:"timestamp"`
+}
+
+func (ShipDocument) TableName() string {
+	return "ship_documents"
+}
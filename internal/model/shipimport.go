@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// ShipImport is a vessel registry import, the uploaded file is kept in storage so the
+// dry run and the commit read the same rows
+type ShipImport struct {
+	Common
+	FileName      string `gorm:"varchar"`
+	Format        string `gorm:"varchar"`
+	StorageKey    string `gorm:"varchar"`
+	DryRun        int
+	Status        ImportStatus `gorm:"varchar"`
+	TotalRows     int
+	ProcessedRows int
+	CreatedRows   int
+	UpdatedRows   int
+	FailedRows    int
+	Errors        string `gorm:"text"`
+	Error         string `gorm:"text"`
+	CreatedBy     int
+	HarbourID     int
+	StartedAt     *time.Time `gorm:"timestamp"`
+	FinishedAt    *time.Time `gorm:"timestamp"`
+}
+
+func (ShipImport) TableName() string {
+	return "ship_imports"
+}
@@ -1,13 +1,24 @@
 package model
 
+import "time"
+
 type ShipLocationLog struct {
 	Common
-	ShipID   int
-	Long     string `gorm:"varchar"`
-	Lat      string `gorm:"varchar"`
-	DegNorth string `gorm:"varchar"`
-	IsMocked int
-	OnGround int
+	ShipID          int
+	Long            string `gorm:"varchar"`
+	Lat             string `gorm:"varchar"`
+	DegNorth        string `gorm:"varchar"`
+	IsMocked        int
+	OnGround        int
+	Speed           float64
+	HarbourDistance float64
+	IsFraud         int
+	FraudScore      int
+	FraudReason     string `gorm:"varchar"`
+	FraudCaseID     *int
+	Status          ShipStatus `gorm:"enum:checkin,checkout,out of scope"`
+	// FixedAt is the time the fix was taken on the device, nil for older clients
+	FixedAt *time.Time
 }
 
 func (ShipLocationLog) TableName() string {
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ShipReportingStat holds the daily counters of fixes sent by a ship against the configured reporting mode
+type ShipReportingStat struct {
+	Common
+	ShipID      int       `gorm:"uniqueIndex:idx_ship_reporting_stat_date"`
+	Date        time.Time `gorm:"type:date;uniqueIndex:idx_ship_reporting_stat_date"`
+	Received    int
+	Stored      int
+	Merged      int
+	Gaps        int
+	MaxGap      int64
+	SumInterval int64
+}
+
+func (ShipReportingStat) TableName() string {
+	return "ship_reporting_stats"
+}
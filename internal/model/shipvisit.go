@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ShipVisit is a ship checked in at a harbour of the deployment other than its home harbour,
+// it is visible to the admins of both harbours
+type ShipVisit struct {
+	Common
+	ShipID        int         `gorm:"index"`
+	HomeHarbourID int         `gorm:"index"`
+	HarbourID     int         `gorm:"index"`
+	Status        VisitStatus `gorm:"enum:visiting,departed"`
+	ArrivedAt     time.Time   `gorm:"timestamp"`
+	DepartedAt    *time.Time  `gorm:"timestamp"`
+	Lat           string      `gorm:"varchar"`
+	Long          string      `gorm:"varchar"`
+}
+
+func (ShipVisit) TableName() string {
+	return "ship_visits"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type Voyage struct {
+	Common
+	ShipID        int
+	CheckoutLogID int
+	CheckinLogID  *int
+	Status        VoyageStatus `gorm:"enum:ongoing,completed"`
+	DepartedAt    time.Time    `gorm:"timestamp"`
+	ArrivedAt     *time.Time   `gorm:"timestamp"`
+	Duration      int64
+	Distance      float64
+	MaxDistance   float64
+	FraudCount    int
+	LastLat       string `gorm:"varchar"`
+	LastLong      string `gorm:"varchar"`
+}
+
+func (Voyage) TableName() string {
+	return "voyages"
+}
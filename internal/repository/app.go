@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"time"
 
@@ -17,11 +19,10 @@ import (
 type App interface {
 	AppInfo(ctx context.Context) (*dto.AppInfo, error)
 	GetPolygon(ctx context.Context) ([]dto.HarbourGeofences, error)
-	StoreSetting(ctx context.Context, data model.AppSetting) error
-	FindLatestSetting(ctx context.Context, selectedFields string) (model.AppSetting, error)
-	UpsertSetting(ctx context.Context, updatedModels *model.AppSetting, updatedField string, query string, args ...interface{}) error
+	FindLatestSetting(ctx context.Context, selectedFields string) (model.Harbour, error)
+	UpsertSetting(ctx context.Context, updatedModels *model.Harbour, updatedField string, query string, args ...interface{}) error
 	StoreGeofence(ctx context.Context, data model.AppGeofence) error
-	DeleteAllGeofence(ctx context.Context) error
+	DeleteAllGeofence(ctx context.Context, harbourID int) error
 }
 
 type app struct {
@@ -38,8 +39,38 @@ func NewAppRepository(db *gorm.DB, redisClient *redis.Client) App {
 	}
 }
 
+// currentHarbour returns the harbour whose settings apply to ctx, the harbour ctx is scoped to
+// or the only harbour of single harbour deployments
+func (r *app) currentHarbour(ctx context.Context, selectedFields string) (model.Harbour, error) {
+	query := util.SetSelectFields(r.Db.WithContext(ctx).Model(&model.Harbour{}), selectedFields)
+
+	if harbourID, ok := tenant.Harbour(ctx); ok {
+		var res model.Harbour
+		if err := query.Where("id = ?", harbourID).Take(&res).Error; err != nil {
+			return model.Harbour{}, err
+		}
+
+		return res, nil
+	}
+
+	var harbours []model.Harbour
+	if err := query.Scopes(tenant.Scope(ctx, "id")).Order("id ASC").Limit(2).Find(&harbours).Error; err != nil {
+		return model.Harbour{}, err
+	}
+
+	if len(harbours) == 0 {
+		return model.Harbour{}, gorm.ErrRecordNotFound
+	}
+
+	if len(harbours) > 1 {
+		return model.Harbour{}, constants.HarbourRequired
+	}
+
+	return harbours[0], nil
+}
+
 func (r *app) AppInfo(ctx context.Context) (*dto.AppInfo, error) {
-	cacheKey := "app_info"
+	cacheKey := tenant.CacheKey(ctx, "app_info")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -51,17 +82,16 @@ func (r *app) AppInfo(ctx context.Context) (*dto.AppInfo, error) {
 		}
 	}
 
-	querySetting := r.Db.Model(&model.AppSetting{})
-	queryGeofence := r.Db.Model(&model.AppGeofence{})
-
-	var setting model.AppSetting
-	var geofence []model.AppGeofence
-
-	if err := querySetting.First(&setting).Error; err != nil {
+	setting, err := r.currentHarbour(ctx, "")
+	if err != nil {
 		return nil, err
 	}
 
-	if err := queryGeofence.Find(&geofence).Error; err != nil {
+	queryGeofence := r.Db.WithContext(ctx).Model(&model.AppGeofence{})
+
+	var geofence []model.AppGeofence
+
+	if err := queryGeofence.Where("harbour_id = ?", setting.ID).Find(&geofence).Error; err != nil {
 		return nil, err
 	}
 
@@ -74,12 +104,16 @@ func (r *app) AppInfo(ctx context.Context) (*dto.AppInfo, error) {
 	}
 
 	res := &dto.AppInfo{
-		HarbourCode: setting.HarbourCode,
-		HarbourName: setting.HarbourName,
-		Mode:        setting.Mode.String(),
-		Interval:    setting.Interval,
-		Range:       setting.Range,
-		Geofence:    geofences,
+		HarbourID:          setting.ID,
+		HarbourCode:        setting.Code,
+		HarbourName:        setting.Name,
+		Mode:               setting.Mode.String(),
+		Interval:           setting.Interval,
+		Range:              setting.Range,
+		InspectionSlaHours: setting.InspectionSlaHours,
+		InspectionAssign:   string(setting.InspectionAssign),
+		Timezone:           setting.Timezone,
+		Geofence:           geofences,
 	}
 
 	if r.CacheEnabled {
@@ -95,7 +129,7 @@ func (r *app) AppInfo(ctx context.Context) (*dto.AppInfo, error) {
 }
 
 func (r *app) GetPolygon(ctx context.Context) ([]dto.HarbourGeofences, error) {
-	cacheKey := "app_polygon"
+	cacheKey := tenant.CacheKey(ctx, "app_polygon")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -107,11 +141,16 @@ func (r *app) GetPolygon(ctx context.Context) ([]dto.HarbourGeofences, error) {
 		}
 	}
 
-	query := r.Db.Model(&model.AppGeofence{})
+	setting, err := r.currentHarbour(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.Db.WithContext(ctx).Model(&model.AppGeofence{})
 
 	var geofence []model.AppGeofence
 
-	if err := query.Find(&geofence).Error; err != nil {
+	if err := query.Where("harbour_id = ?", setting.ID).Find(&geofence).Error; err != nil {
 		return nil, err
 	}
 
@@ -135,39 +174,16 @@ func (r *app) GetPolygon(ctx context.Context) ([]dto.HarbourGeofences, error) {
 	return polygon, nil
 }
 
-func (r *app) StoreSetting(ctx context.Context, data model.AppSetting) error {
-	tx := r.Db.WithContext(ctx)
-	if err := tx.Model(model.AppSetting{}).Create(&data).Error; err != nil {
-		return err
-	}
-
-	cacheKey := "app_info"
-
-	if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey); err != nil {
-		return nil
-	}
-
-	return nil
-}
-
-func (r *app) FindLatestSetting(ctx context.Context, selectedFields string) (model.AppSetting, error) {
-	var res model.AppSetting
-
-	query := r.Db.WithContext(ctx).Model(model.AppSetting{})
-	query = util.SetSelectFields(query, selectedFields)
-
-	if err := query.Limit(1).Take(&res).Error; err != nil {
-		return model.AppSetting{}, err
-	}
-
-	return res, nil
+// FindLatestSetting returns the settings of the harbour of ctx
+func (r *app) FindLatestSetting(ctx context.Context, selectedFields string) (model.Harbour, error) {
+	return r.currentHarbour(ctx, selectedFields)
 }
 
-func (r *app) UpsertSetting(ctx context.Context, updatedModels *model.AppSetting, updatedField string, query string, args ...interface{}) error {
-	setting := r.Db.WithContext(ctx).Model(&model.AppSetting{})
-	cacheKey := "app_info"
+func (r *app) UpsertSetting(ctx context.Context, updatedModels *model.Harbour, updatedField string, query string, args ...interface{}) error {
+	setting := r.Db.WithContext(ctx).Model(&model.Harbour{})
+	cacheKey := []string{"app_info*", "app_active_harbours"}
 	var count int64
-	if err := r.Db.WithContext(ctx).Model(&model.AppSetting{}).Where(query, args...).Count(&count).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Model(&model.Harbour{}).Where(query, args...).Count(&count).Error; err != nil {
 		return err
 	}
 
@@ -181,8 +197,10 @@ func (r *app) UpsertSetting(ctx context.Context, updatedModels *model.AppSetting
 		}
 	}
 
-	if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey); err != nil {
-		return err
+	for i := range cacheKey {
+		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -194,19 +212,22 @@ func (r *app) StoreGeofence(ctx context.Context, data model.AppGeofence) error {
 		return err
 	}
 
-	cacheKey := "app_polygon"
+	cacheKey := []string{"app_polygon*", "app_info*"}
 
-	if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey); err != nil {
-		return nil
+	for i := range cacheKey {
+		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
+			return nil
+		}
 	}
 
 	return nil
 }
 
-func (r *app) DeleteAllGeofence(ctx context.Context) error {
+// DeleteAllGeofence removes the zone of a harbour before it is stored again
+func (r *app) DeleteAllGeofence(ctx context.Context, harbourID int) error {
 	db := r.Db.WithContext(ctx)
 
-	if err := db.Exec("DELETE FROM app_geofences").Error; err != nil {
+	if err := db.Exec("DELETE FROM app_geofences WHERE harbour_id = ?", harbourID).Error; err != nil {
 		return err
 	}
 
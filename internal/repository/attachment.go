@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type Attachment interface {
+	StoreAttachment(ctx context.Context, attachment *model.Attachment) error
+	AttachmentByID(ctx context.Context, ID int) (*model.Attachment, error)
+	AttachmentList(ctx context.Context, request dto.AttachmentListParam) ([]model.Attachment, error)
+	DeleteAttachment(ctx context.Context, ID int) error
+	OwnerExists(ctx context.Context, ownerType model.AttachmentOwner, ownerID int) (bool, error)
+}
+
+type attachment struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewAttachmentRepository(db *gorm.DB, redisClient *redis.Client) Attachment {
+	return &attachment{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// owners maps every owner type to the table holding its rows
+var owners = map[model.AttachmentOwner]interface{}{
+	model.OwnerInspection:     &model.Inspection{},
+	model.OwnerShip:           &model.Ship{},
+	model.OwnerPairingRequest: &model.PairingRequest{},
+}
+
+// ownerScope limits attachments to the owners belonging to the harbours of ctx
+func ownerScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := tenant.Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where("(owner_type = ? AND owner_id IN (SELECT id FROM ships WHERE harbour_id IN ?)) "+
+			"OR (owner_type = ? AND owner_id IN (SELECT id FROM pairing_requests WHERE harbour_id IN ?)) "+
+			"OR (owner_type = ? AND owner_id IN (SELECT inspections.id FROM inspections JOIN ships ON inspections.ship_id = ships.id WHERE ships.harbour_id IN ?))",
+			model.OwnerShip, ids, model.OwnerPairingRequest, ids, model.OwnerInspection, ids)
+	}
+}
+
+func (r *attachment) StoreAttachment(ctx context.Context, attachment *model.Attachment) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(attachment).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *attachment) AttachmentByID(ctx context.Context, ID int) (*model.Attachment, error) {
+	var attachment model.Attachment
+
+	if err := r.Db.WithContext(ctx).Scopes(ownerScope(ctx)).Where("id = ?", ID).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (r *attachment) AttachmentList(ctx context.Context, request dto.AttachmentListParam) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+
+	query := r.Db.WithContext(ctx).Scopes(ownerScope(ctx)).
+		Where("owner_type = ? AND owner_id = ?", request.OwnerType, request.OwnerID)
+
+	if request.Category != "" {
+		query = query.Where("category = ?", request.Category)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *attachment) DeleteAttachment(ctx context.Context, ID int) error {
+	result := r.Db.WithContext(ctx).Where("id = ?", ID).Delete(&model.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *attachment) OwnerExists(ctx context.Context, ownerType model.AttachmentOwner, ownerID int) (bool, error) {
+	table, ok := owners[ownerType]
+	if !ok {
+		return false, nil
+	}
+
+	var res int64
+	query := r.Db.WithContext(ctx).Model(table)
+	if ownerType == model.OwnerInspection {
+		query = query.Scopes(tenant.ShipScope(ctx, "ship_id"))
+	} else {
+		query = query.Scopes(tenant.Scope(ctx, "harbour_id"))
+	}
+
+	if err := query.Where("id = ?", ownerID).Count(&res).Error; err != nil {
+		return false, err
+	}
+
+	return res > 0, nil
+}
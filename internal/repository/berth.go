@@ -0,0 +1,534 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/tenant"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Berth interface {
+	StoreBerth(ctx context.Context, berth *model.Berth, geofences []model.BerthGeofence) error
+	UpdateBerth(ctx context.Context, berth model.Berth, geofences []model.BerthGeofence) error
+	BerthByID(ctx context.Context, ID int) (*model.Berth, error)
+	BerthCodeTaken(ctx context.Context, harbourID int, code string, exceptID int) (bool, error)
+	BerthList(ctx context.Context, request dto.BerthListParam) ([]dto.BerthResponse, error)
+	BerthCount(ctx context.Context, request dto.BerthListParam) (int64, error)
+	BerthGeofences(ctx context.Context, berthID int) ([]dto.AppGeofence, error)
+	StoreReservation(ctx context.Context, reservation *model.BerthReservation) error
+	AssignReservation(ctx context.Context, reservation model.BerthReservation, berthID int, assignedBy int, note string) error
+	CancelReservation(ctx context.Context, ID int) error
+	ConfirmReservation(ctx context.Context, ID int, confirmedAt time.Time) error
+	ReservationByID(ctx context.Context, ID int) (*model.BerthReservation, error)
+	ArrivingReservations(ctx context.Context, shipID int, arrivalBefore time.Time, departureAfter time.Time) ([]model.BerthReservation, error)
+	ReservationList(ctx context.Context, request dto.ReservationListParam) ([]dto.ReservationResponse, error)
+	ReservationCount(ctx context.Context, request dto.ReservationListParam) (int64, error)
+	ReservationDetail(ctx context.Context, ID int) (*dto.ReservationResponse, error)
+}
+
+type berth struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewBerthRepository(db *gorm.DB, redisClient *redis.Client) Berth {
+	return &berth{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// berthScope filters a query on a table belonging to a berth, column is its berth_id column
+func berthScope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := tenant.Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where(column+" IN (SELECT id FROM berths WHERE berths.harbour_id IN ?)", ids)
+	}
+}
+
+func (r *berth) StoreBerth(ctx context.Context, berth *model.Berth, geofences []model.BerthGeofence) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(berth).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range geofences {
+		geofences[i].BerthID = berth.ID
+	}
+
+	if err := tx.Create(&geofences).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateBerth changes a berth, its zone is replaced unless geofences is nil
+func (r *berth) UpdateBerth(ctx context.Context, berth model.Berth, geofences []model.BerthGeofence) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.Berth{}).Where("id = ?", berth.ID).Updates(map[string]interface{}{
+		"code":       berth.Code,
+		"name":       berth.Name,
+		"zone_type":  berth.ZoneType,
+		"capacity":   berth.Capacity,
+		"max_length": berth.MaxLength,
+		"max_gt":     berth.MaxGT,
+		"is_active":  berth.IsActive,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if geofences != nil {
+		if err := tx.Where("berth_id = ?", berth.ID).Delete(&model.BerthGeofence{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for i := range geofences {
+			geofences[i].BerthID = berth.ID
+		}
+
+		if err := tx.Create(&geofences).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *berth) BerthByID(ctx context.Context, ID int) (*model.Berth, error) {
+	var berth model.Berth
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&berth).Error; err != nil {
+		return nil, err
+	}
+
+	return &berth, nil
+}
+
+func (r *berth) BerthCodeTaken(ctx context.Context, harbourID int, code string, exceptID int) (bool, error) {
+	var res int64
+
+	err := r.Db.WithContext(ctx).Model(&model.Berth{}).
+		Where("harbour_id = ? AND lower(code) = ? AND id <> ?", harbourID, strings.ToLower(code), exceptID).
+		Count(&res).Error
+	if err != nil {
+		return false, err
+	}
+
+	return res > 0, nil
+}
+
+func (r *berth) filterBerth(query *gorm.DB, request dto.BerthListParam) *gorm.DB {
+	if request.ActiveOnly {
+		query = query.Where("berths.is_active = 1")
+	}
+
+	if request.Search != "" {
+		searchLower := "%" + strings.ToLower(request.Search) + "%"
+		query = query.Where("(lower(berths.code) LIKE ? OR lower(berths.name) LIKE ?)", searchLower, searchLower)
+	}
+
+	return query
+}
+
+func (r *berth) BerthList(ctx context.Context, request dto.BerthListParam) ([]dto.BerthResponse, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Berth{}).Scopes(tenant.Scope(ctx, "berths.harbour_id"))
+	query = r.filterBerth(query, request)
+
+	var result []model.Berth
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("berths.is_active DESC, berths.code ASC, berths.id ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.BerthResponse
+	for _, e := range result {
+		res = append(res, berthResponse(e))
+	}
+
+	return res, nil
+}
+
+func (r *berth) BerthCount(ctx context.Context, request dto.BerthListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Berth{}).Scopes(tenant.Scope(ctx, "berths.harbour_id"))
+	query = r.filterBerth(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *berth) BerthGeofences(ctx context.Context, berthID int) ([]dto.AppGeofence, error) {
+	var geofences []model.BerthGeofence
+
+	if err := r.Db.WithContext(ctx).Where("berth_id = ?", berthID).Find(&geofences).Error; err != nil {
+		return nil, err
+	}
+
+	res := []dto.AppGeofence{}
+	for _, e := range geofences {
+		res = append(res, dto.AppGeofence{
+			Long: e.Long,
+			Lat:  e.Lat,
+		})
+	}
+
+	return res, nil
+}
+
+// StoreReservation books the window once the berth and the ship are checked to be free, see checkBookings
+func (r *berth) StoreReservation(ctx context.Context, reservation *model.BerthReservation) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := checkBookings(tx, reservation.BerthID, reservation.ShipID, reservation.ArrivalAt, reservation.DepartureAt, 0); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(reservation).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// AssignReservation approves a requested reservation, possibly on another berth, the bookings are
+// checked again in the same transaction since the berth may have filled up since the request
+func (r *berth) AssignReservation(ctx context.Context, reservation model.BerthReservation, berthID int, assignedBy int, note string) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := checkBookings(tx, berthID, reservation.ShipID, reservation.ArrivalAt, reservation.DepartureAt, reservation.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"berth_id":    berthID,
+		"status":      model.ReservationAssigned,
+		"assigned_by": assignedBy,
+	}
+
+	if note != "" {
+		updates["note"] = note
+	}
+
+	result := tx.Model(&model.BerthReservation{}).
+		Where("id = ? AND status = ?", reservation.ID, model.ReservationRequested).
+		Updates(updates)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *berth) CancelReservation(ctx context.Context, ID int) error {
+	result := r.Db.WithContext(ctx).Model(&model.BerthReservation{}).
+		Where("id = ? AND status IN ?", ID, []model.ReservationStatus{model.ReservationRequested, model.ReservationAssigned}).
+		Update("status", model.ReservationCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *berth) ConfirmReservation(ctx context.Context, ID int, confirmedAt time.Time) error {
+	return r.Db.WithContext(ctx).Model(&model.BerthReservation{}).
+		Where("id = ? AND status = ?", ID, model.ReservationAssigned).
+		Updates(map[string]interface{}{
+			"status":       model.ReservationConfirmed,
+			"confirmed_at": confirmedAt,
+		}).Error
+}
+
+func (r *berth) ReservationByID(ctx context.Context, ID int) (*model.BerthReservation, error) {
+	var reservation model.BerthReservation
+
+	if err := r.Db.WithContext(ctx).Scopes(berthScope(ctx, "berth_id")).Where("id = ?", ID).First(&reservation).Error; err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// checkBookings locks the ship and the berth rows so concurrent bookings of either wait for each
+// other, then makes sure the ship has no open reservation overlapping the window and the berth
+// still has room at every moment of it, requests only take a place on the berth once they are
+// assigned. exceptID leaves the reservation being changed out of the bookings
+func checkBookings(tx *gorm.DB, berthID int, shipID int, start time.Time, end time.Time, exceptID int) error {
+	var ship model.Ship
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", shipID).First(&ship).Error; err != nil {
+		return err
+	}
+
+	var berth model.Berth
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", berthID).First(&berth).Error; err != nil {
+		return err
+	}
+
+	var shipBookings int64
+	err := tx.Model(&model.BerthReservation{}).
+		Where("ship_id = ? AND id <> ? AND status IN ?", shipID, exceptID,
+			[]model.ReservationStatus{model.ReservationRequested, model.ReservationAssigned, model.ReservationConfirmed}).
+		Where("arrival_at < ? AND departure_at > ?", end, start).
+		Count(&shipBookings).Error
+	if err != nil {
+		return err
+	}
+
+	if shipBookings > 0 {
+		return constants.ReservationOverlap
+	}
+
+	var berthBookings []model.BerthReservation
+	err = tx.Select("arrival_at, departure_at").
+		Where("berth_id = ? AND id <> ? AND status IN ?", berthID, exceptID,
+			[]model.ReservationStatus{model.ReservationAssigned, model.ReservationConfirmed}).
+		Where("arrival_at < ? AND departure_at > ?", end, start).
+		Find(&berthBookings).Error
+	if err != nil {
+		return err
+	}
+
+	if peakBookings(berthBookings) >= berth.Capacity {
+		return constants.BerthFull
+	}
+
+	return nil
+}
+
+// peakBookings returns the most bookings moored at the same time, sweeping over the arrivals and
+// departures. A ship departing when another arrives frees its place first
+func peakBookings(bookings []model.BerthReservation) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+
+	events := make([]event, 0, len(bookings)*2)
+	for _, booking := range bookings {
+		events = append(events, event{at: booking.ArrivalAt, delta: 1}, event{at: booking.DepartureAt, delta: -1})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+
+		return events[i].at.Before(events[j].at)
+	})
+
+	peak, moored := 0, 0
+	for _, e := range events {
+		moored += e.delta
+		if moored > peak {
+			peak = moored
+		}
+	}
+
+	return peak
+}
+
+// ArrivingReservations returns the assigned reservations of the ship whose window is open
+func (r *berth) ArrivingReservations(ctx context.Context, shipID int, arrivalBefore time.Time, departureAfter time.Time) ([]model.BerthReservation, error) {
+	var res []model.BerthReservation
+
+	err := r.Db.WithContext(ctx).
+		Where("ship_id = ? AND status = ?", shipID, model.ReservationAssigned).
+		Where("arrival_at <= ? AND departure_at >= ?", arrivalBefore, departureAfter).
+		Order("arrival_at ASC").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *berth) filterReservation(query *gorm.DB, request dto.ReservationListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("berth_reservations.ship_id = ?", request.ShipID)
+	}
+
+	if request.BerthID != 0 {
+		query = query.Where("berth_reservations.berth_id = ?", request.BerthID)
+	}
+
+	if request.Status != "" {
+		query = query.Where("berth_reservations.status = ?", request.Status)
+	}
+
+	if request.Search != "" {
+		searchLower := "%" + strings.ToLower(request.Search) + "%"
+		query = query.Where("(lower(ships.name) LIKE ? OR lower(berths.code) LIKE ? OR lower(berths.name) LIKE ?)",
+			searchLower, searchLower, searchLower)
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(berth_reservations.arrival_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *berth) reservationQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return query.Model(&model.BerthReservation{}).
+		Select("berth_reservations.*, berths.code as berth_code, berths.name as berth_name, ships.name as ship_name").
+		Joins("JOIN berths ON berth_reservations.berth_id = berths.id").
+		Joins("JOIN ships ON berth_reservations.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "berths.harbour_id"))
+}
+
+type reservationRow struct {
+	model.BerthReservation
+	BerthCode string
+	BerthName string
+	ShipName  string
+}
+
+func (r *berth) ReservationList(ctx context.Context, request dto.ReservationListParam) ([]dto.ReservationResponse, error) {
+	query := r.filterReservation(r.reservationQuery(ctx, r.Db.WithContext(ctx)), request)
+
+	var result []reservationRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("berth_reservations.arrival_at DESC, berth_reservations.id DESC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ReservationResponse
+	for _, e := range result {
+		res = append(res, reservationResponse(e))
+	}
+
+	return res, nil
+}
+
+func (r *berth) ReservationCount(ctx context.Context, request dto.ReservationListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.BerthReservation{}).
+		Joins("JOIN berths ON berth_reservations.berth_id = berths.id").
+		Joins("JOIN ships ON berth_reservations.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "berths.harbour_id"))
+	query = r.filterReservation(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *berth) ReservationDetail(ctx context.Context, ID int) (*dto.ReservationResponse, error) {
+	var result reservationRow
+
+	if err := r.reservationQuery(ctx, r.Db.WithContext(ctx)).Where("berth_reservations.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	res := reservationResponse(result)
+
+	return &res, nil
+}
+
+func berthResponse(e model.Berth) dto.BerthResponse {
+	return dto.BerthResponse{
+		ID:        e.ID,
+		HarbourID: e.HarbourID,
+		Code:      e.Code,
+		Name:      e.Name,
+		ZoneType:  e.ZoneType,
+		Capacity:  e.Capacity,
+		MaxLength: e.MaxLength,
+		MaxGT:     e.MaxGT,
+		IsActive:  e.IsActive == 1,
+		CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func reservationResponse(e reservationRow) dto.ReservationResponse {
+	res := dto.ReservationResponse{
+		ID:          e.ID,
+		BerthID:     e.BerthID,
+		BerthCode:   e.BerthCode,
+		BerthName:   e.BerthName,
+		ShipID:      e.ShipID,
+		ShipName:    e.ShipName,
+		Status:      string(e.Status),
+		ArrivalAt:   e.ArrivalAt.Format("2006-01-02 15:04:05"),
+		DepartureAt: e.DepartureAt.Format("2006-01-02 15:04:05"),
+		RequestedBy: e.RequestedBy,
+		AssignedBy:  e.AssignedBy,
+		Note:        e.Note,
+		CreatedAt:   e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if e.ConfirmedAt != nil {
+		res.ConfirmedAt = e.ConfirmedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
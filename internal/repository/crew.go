@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type Crew interface {
+	StoreCrew(ctx context.Context, crew *model.CrewMember) error
+	UpdateCrew(ctx context.Context, crew model.CrewMember) error
+	CrewByID(ctx context.Context, ID int) (*model.CrewMember, error)
+	CrewList(ctx context.Context, request dto.CrewListParam) ([]dto.CrewResponse, error)
+	CrewCount(ctx context.Context, request dto.CrewListParam) (int64, error)
+	ActiveCrew(ctx context.Context, shipID int, IDs []int) ([]model.CrewMember, error)
+	StoreManifest(ctx context.Context, manifest *model.CrewManifest, members []model.CrewManifestMember) error
+	PendingManifest(ctx context.Context, shipID int) (*model.CrewManifest, error)
+	AttachManifest(ctx context.Context, shipID int, checkoutLogID int, departedAt time.Time) (bool, error)
+	ManifestList(ctx context.Context, request dto.ManifestListParam) ([]dto.ManifestResponse, error)
+	ManifestCount(ctx context.Context, request dto.ManifestListParam) (int64, error)
+	ManifestDetail(ctx context.Context, ID int) (*dto.ManifestResponse, error)
+	MissingManifests(ctx context.Context, request dto.MissingManifestParam) ([]dto.MissingManifestResponse, error)
+	MissingManifestCount(ctx context.Context, request dto.MissingManifestParam) (int64, error)
+}
+
+type crew struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewCrewRepository(db *gorm.DB, redisClient *redis.Client) Crew {
+	return &crew{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *crew) StoreCrew(ctx context.Context, crew *model.CrewMember) error {
+	return r.Db.WithContext(ctx).Create(crew).Error
+}
+
+func (r *crew) UpdateCrew(ctx context.Context, crew model.CrewMember) error {
+	result := r.Db.WithContext(ctx).Model(&model.CrewMember{}).Where("id = ?", crew.ID).Updates(map[string]interface{}{
+		"name":      crew.Name,
+		"id_number": crew.IDNumber,
+		"position":  crew.Position,
+		"phone":     crew.Phone,
+		"is_active": crew.IsActive,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *crew) CrewByID(ctx context.Context, ID int) (*model.CrewMember, error) {
+	var crew model.CrewMember
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).First(&crew).Error; err != nil {
+		return nil, err
+	}
+
+	return &crew, nil
+}
+
+func (r *crew) filterCrew(query *gorm.DB, request dto.CrewListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("crew_members.ship_id = ?", request.ShipID)
+	}
+
+	if request.Position != "" {
+		query = query.Where("crew_members.position = ?", request.Position)
+	}
+
+	if request.ActiveOnly {
+		query = query.Where("crew_members.is_active = 1")
+	}
+
+	if request.Search != "" {
+		searchLower := "%" + strings.ToLower(request.Search) + "%"
+		query = query.Where("(lower(crew_members.name) LIKE ? OR lower(crew_members.id_number) LIKE ? OR lower(ships.name) LIKE ?)",
+			searchLower, searchLower, searchLower)
+	}
+
+	return query
+}
+
+type crewRow struct {
+	model.CrewMember
+	ShipName string
+}
+
+func (r *crew) CrewList(ctx context.Context, request dto.CrewListParam) ([]dto.CrewResponse, error) {
+	query := r.Db.WithContext(ctx).Model(&model.CrewMember{}).
+		Select("crew_members.*, ships.name as ship_name").
+		Joins("JOIN ships ON crew_members.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterCrew(query, request)
+
+	var result []crewRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("crew_members.is_active DESC, crew_members.name ASC, crew_members.id ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.CrewResponse
+	for _, e := range result {
+		res = append(res, dto.CrewResponse{
+			ID:        e.ID,
+			ShipID:    e.ShipID,
+			ShipName:  e.ShipName,
+			Name:      e.Name,
+			IDNumber:  e.IDNumber,
+			Position:  string(e.Position),
+			Phone:     e.Phone,
+			IsActive:  e.IsActive == 1,
+			CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *crew) CrewCount(ctx context.Context, request dto.CrewListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.CrewMember{}).
+		Joins("JOIN ships ON crew_members.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterCrew(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// ActiveCrew returns the active crew of the ship among the given ids, unknown ids are left out
+func (r *crew) ActiveCrew(ctx context.Context, shipID int, IDs []int) ([]model.CrewMember, error) {
+	var res []model.CrewMember
+
+	err := r.Db.WithContext(ctx).
+		Where("ship_id = ? AND is_active = 1 AND id IN ?", shipID, IDs).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// StoreManifest stores the manifest of the next departure, a pending manifest of the ship is replaced
+func (r *crew) StoreManifest(ctx context.Context, manifest *model.CrewManifest, members []model.CrewManifestMember) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	pending := tx.Model(&model.CrewManifest{}).Select("id").
+		Where("ship_id = ? AND checkout_log_id IS NULL", manifest.ShipID)
+
+	if err := tx.Where("crew_manifest_id IN (?)", pending).Delete(&model.CrewManifestMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("ship_id = ? AND checkout_log_id IS NULL", manifest.ShipID).Delete(&model.CrewManifest{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(manifest).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range members {
+		members[i].CrewManifestID = manifest.ID
+	}
+
+	if err := tx.Create(&members).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *crew) PendingManifest(ctx context.Context, shipID int) (*model.CrewManifest, error) {
+	var manifest model.CrewManifest
+
+	err := r.Db.WithContext(ctx).
+		Where("ship_id = ? AND checkout_log_id IS NULL", shipID).
+		Order("id DESC").
+		First(&manifest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// AttachManifest links the pending manifest of the ship to its checkout log,
+// it reports false when the ship had no pending manifest
+func (r *crew) AttachManifest(ctx context.Context, shipID int, checkoutLogID int, departedAt time.Time) (bool, error) {
+	result := r.Db.WithContext(ctx).Model(&model.CrewManifest{}).
+		Where("ship_id = ? AND checkout_log_id IS NULL", shipID).
+		Updates(map[string]interface{}{
+			"checkout_log_id": checkoutLogID,
+			"departed_at":     departedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *crew) filterManifest(query *gorm.DB, request dto.ManifestListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("crew_manifests.ship_id = ?", request.ShipID)
+	}
+
+	switch request.Status {
+	case "pending":
+		query = query.Where("crew_manifests.checkout_log_id IS NULL")
+	case "departed":
+		query = query.Where("crew_manifests.checkout_log_id IS NOT NULL")
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(crew_manifests.submitted_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *crew) manifestQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	crewCount := "(SELECT COUNT(*) FROM crew_manifest_members WHERE crew_manifest_members.crew_manifest_id = crew_manifests.id " +
+		"AND crew_manifest_members.deleted_at IS NULL) as crew_count"
+
+	return query.Model(&model.CrewManifest{}).
+		Select("crew_manifests.*, ships.name as ship_name, " + crewCount).
+		Joins("JOIN ships ON crew_manifests.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+}
+
+type manifestRow struct {
+	model.CrewManifest
+	ShipName  string
+	CrewCount int
+}
+
+func (r *crew) ManifestList(ctx context.Context, request dto.ManifestListParam) ([]dto.ManifestResponse, error) {
+	query := r.filterManifest(r.manifestQuery(ctx, r.Db.WithContext(ctx)), request)
+
+	var result []manifestRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("crew_manifests.submitted_at DESC, crew_manifests.id DESC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ManifestResponse
+	for _, e := range result {
+		res = append(res, manifestResponse(e))
+	}
+
+	return res, nil
+}
+
+func (r *crew) ManifestCount(ctx context.Context, request dto.ManifestListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.CrewManifest{}).
+		Joins("JOIN ships ON crew_manifests.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterManifest(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *crew) ManifestDetail(ctx context.Context, ID int) (*dto.ManifestResponse, error) {
+	var result manifestRow
+
+	if err := r.manifestQuery(ctx, r.Db.WithContext(ctx)).Where("crew_manifests.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var members []model.CrewManifestMember
+	if err := r.Db.WithContext(ctx).Where("crew_manifest_id = ?", ID).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	res := manifestResponse(result)
+	for _, e := range members {
+		res.Members = append(res.Members, dto.ManifestMemberResponse{
+			CrewMemberID: e.CrewMemberID,
+			Name:         e.Name,
+			IDNumber:     e.IDNumber,
+			Position:     string(e.Position),
+		})
+	}
+
+	return &res, nil
+}
+
+// missingManifestQuery selects the checkout logs no manifest was attached to
+func (r *crew) missingManifestQuery(ctx context.Context, request dto.MissingManifestParam) *gorm.DB {
+	query := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Joins("LEFT JOIN crew_manifests ON crew_manifests.checkout_log_id = ship_docked_logs.id AND crew_manifests.deleted_at IS NULL").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_docked_logs.status = ? AND crew_manifests.id IS NULL", model.Checkout)
+
+	if request.ShipID != 0 {
+		query = query.Where("ship_docked_logs.ship_id = ?", request.ShipID)
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(ship_docked_logs.created_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *crew) MissingManifests(ctx context.Context, request dto.MissingManifestParam) ([]dto.MissingManifestResponse, error) {
+	var result []struct {
+		LogID           int
+		ShipID          int
+		ShipName        string
+		ResponsibleName string
+		Phone           string
+		CreatedAt       time.Time
+	}
+
+	err := r.missingManifestQuery(ctx, request).
+		Select("ship_docked_logs.id as log_id, ships.id as ship_id, ships.name as ship_name, " +
+			"ships.responsible_name, ships.phone, ship_docked_logs.created_at").
+		Limit(request.Limit).Offset(request.Offset).
+		Order("ship_docked_logs.created_at DESC, ship_docked_logs.id DESC").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.MissingManifestResponse
+	for _, e := range result {
+		res = append(res, dto.MissingManifestResponse{
+			LogID:           e.LogID,
+			ShipID:          e.ShipID,
+			ShipName:        e.ShipName,
+			ResponsibleName: e.ResponsibleName,
+			Phone:           e.Phone,
+			DepartedAt:      e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *crew) MissingManifestCount(ctx context.Context, request dto.MissingManifestParam) (int64, error) {
+	var res int64
+	if err := r.missingManifestQuery(ctx, request).Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func manifestResponse(e manifestRow) dto.ManifestResponse {
+	res := dto.ManifestResponse{
+		ID:            e.ID,
+		ShipID:        e.ShipID,
+		ShipName:      e.ShipName,
+		CheckoutLogID: e.CheckoutLogID,
+		Status:        "pending",
+		SubmittedBy:   e.SubmittedBy,
+		CrewCount:     e.CrewCount,
+		SubmittedAt:   e.SubmittedAt.Format("2006-01-02 15:04:05"),
+		Note:          e.Note,
+	}
+
+	if e.CheckoutLogID != nil {
+		res.Status = "departed"
+	}
+
+	if e.DepartedAt != nil {
+		res.DepartedAt = e.DepartedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
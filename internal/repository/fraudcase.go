@@ -0,0 +1,470 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// activeCaseStatus are the statuses in which new suspicious fixes are still added to a case
+var activeCaseStatus = []model.FraudCaseStatus{model.CaseOpen, model.CaseReviewing, model.CaseEscalated}
+
+type FraudCase interface {
+	AttachFraudFix(ctx context.Context, request dto.FraudFixStore) (int, error)
+	StoreFraudCase(ctx context.Context, fraudCase *model.FraudCase, logIDs []int) error
+	FraudCaseList(ctx context.Context, request dto.FraudCaseListParam) ([]dto.FraudCaseResponse, error)
+	FraudCaseCount(ctx context.Context, request dto.FraudCaseListParam) (int64, error)
+	FraudCaseByID(ctx context.Context, ID int) (*model.FraudCase, error)
+	FraudCaseDetail(ctx context.Context, ID int) (*dto.FraudCaseResponse, error)
+	FraudCaseFixes(ctx context.Context, ID int) ([]dto.ReportShipLocationResponse, error)
+	FraudCaseActivities(ctx context.Context, ID int) ([]dto.FraudCaseActivityResponse, error)
+	UpdateFraudCase(ctx context.Context, ID int, fields map[string]interface{}, activity model.FraudCaseActivity) error
+	StoreFraudCaseActivity(ctx context.Context, activity model.FraudCaseActivity) error
+	CountOpenFraudCase(ctx context.Context) (*dto.FraudCaseCount, error)
+	ShipIDsWithUncasedFraud(ctx context.Context) ([]int, error)
+	ShipFraudFlags(ctx context.Context, ShipID int) ([]model.ShipLocationLog, error)
+}
+
+type fraudCase struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewFraudCaseRepository(db *gorm.DB, redisClient *redis.Client) FraudCase {
+	return &fraudCase{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// AttachFraudFix adds a suspicious fix to the active case of the ship when the previous stored fix
+// belongs to it, otherwise the fix opens a new case. It returns the id of the case.
+func (r *fraudCase) AttachFraudFix(ctx context.Context, request dto.FraudFixStore) (int, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	now := time.Now()
+
+	var current model.FraudCase
+	err := tx.Where("ship_id = ? AND last_log_id = ? AND status IN (?)", request.ShipID, request.PreviousLogID, activeCaseStatus).
+		Order("id DESC").
+		First(&current).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		current = model.FraudCase{
+			ShipID:     request.ShipID,
+			Status:     model.CaseOpen,
+			FirstLogID: request.LogID,
+			LastLogID:  request.LogID,
+			StartedAt:  now,
+			LastSeenAt: now,
+			FixCount:   1,
+			MaxScore:   request.Score,
+			Reasons:    request.Reason,
+		}
+
+		if err := tx.Create(&current).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		activity := model.FraudCaseActivity{
+			FraudCaseID: current.ID,
+			Action:      model.CaseActionOpened,
+			ToStatus:    model.CaseOpen,
+		}
+
+		if err := tx.Create(&activity).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	} else {
+		updateFields := map[string]interface{}{
+			"last_log_id":  request.LogID,
+			"last_seen_at": now,
+			"fix_count":    gorm.Expr("fix_count + 1"),
+			"reasons":      helper.MergeCommaList(current.Reasons, request.Reason),
+		}
+
+		if request.Score > current.MaxScore {
+			updateFields["max_score"] = request.Score
+		}
+
+		if err := tx.Model(&model.FraudCase{}).Where("id = ?", current.ID).Updates(updateFields).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Model(&model.ShipLocationLog{}).Where("id = ?", request.LogID).Update("fraud_case_id", current.ID).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return current.ID, nil
+}
+
+func (r *fraudCase) StoreFraudCase(ctx context.Context, fraudCase *model.FraudCase, logIDs []int) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(fraudCase).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	activity := model.FraudCaseActivity{
+		FraudCaseID: fraudCase.ID,
+		Action:      model.CaseActionOpened,
+		ToStatus:    fraudCase.Status,
+	}
+
+	if err := tx.Create(&activity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&model.ShipLocationLog{}).Where("id IN (?)", logIDs).Update("fraud_case_id", fraudCase.ID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *fraudCase) filterFraudCase(query *gorm.DB, request dto.FraudCaseListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("fraud_cases.ship_id = ?", request.ShipID)
+	}
+
+	if request.AssigneeID != 0 {
+		query = query.Where("fraud_cases.assignee_id = ?", request.AssigneeID)
+	}
+
+	if request.Status != nil && len(request.Status) > 0 && request.Status[0] != "" {
+		query = query.Where("fraud_cases.status IN (?)", request.Status)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(fraud_cases.started_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *fraudCase) FraudCaseList(ctx context.Context, request dto.FraudCaseListParam) ([]dto.FraudCaseResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := tx.Model(&model.FraudCase{}).
+		Select("fraud_cases.*, ships.name as ship_name, users.name as assignee_name").
+		Joins("JOIN ships ON fraud_cases.ship_id = ships.id").
+		Joins("LEFT JOIN users ON fraud_cases.assignee_id = users.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterFraudCase(query, request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("fraud_cases.last_seen_at DESC")
+
+	var result []struct {
+		model.FraudCase
+		ShipName     string
+		AssigneeName *string
+	}
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var cases []dto.FraudCaseResponse
+	for _, e := range result {
+		cases = append(cases, fraudCaseResponse(e.FraudCase, e.ShipName, e.AssigneeName))
+	}
+
+	return cases, nil
+}
+
+func (r *fraudCase) FraudCaseCount(ctx context.Context, request dto.FraudCaseListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.FraudCase{}).
+		Joins("JOIN ships ON fraud_cases.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterFraudCase(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *fraudCase) FraudCaseByID(ctx context.Context, ID int) (*model.FraudCase, error) {
+	var res model.FraudCase
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *fraudCase) FraudCaseDetail(ctx context.Context, ID int) (*dto.FraudCaseResponse, error) {
+	var result struct {
+		model.FraudCase
+		ShipName     string
+		AssigneeName *string
+	}
+
+	err := r.Db.WithContext(ctx).Model(&model.FraudCase{}).
+		Select("fraud_cases.*, ships.name as ship_name, users.name as assignee_name").
+		Joins("JOIN ships ON fraud_cases.ship_id = ships.id").
+		Joins("LEFT JOIN users ON fraud_cases.assignee_id = users.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("fraud_cases.id = ?", ID).
+		Take(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := fraudCaseResponse(result.FraudCase, result.ShipName, result.AssigneeName)
+
+	return &res, nil
+}
+
+func (r *fraudCase) FraudCaseFixes(ctx context.Context, ID int) ([]dto.ReportShipLocationResponse, error) {
+	var result []struct {
+		model.ShipLocationLog
+		ShipName string
+	}
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Select("ship_location_logs.*, ships.name as ship_name").
+		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_location_logs.fraud_case_id = ?", ID).
+		Order("ship_location_logs.created_at ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var fixes []dto.ReportShipLocationResponse
+	for _, e := range result {
+		fixes = append(fixes, dto.ReportShipLocationResponse{
+			LogID:       e.ID,
+			ShipID:      e.ShipID,
+			ShipName:    e.ShipName,
+			Lat:         e.Lat,
+			Long:        e.Long,
+			IsMocked:    e.IsMocked,
+			OnGround:    e.OnGround,
+			FraudScore:  e.FraudScore,
+			FraudReason: e.FraudReason,
+			LogDate:     e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return fixes, nil
+}
+
+func (r *fraudCase) FraudCaseActivities(ctx context.Context, ID int) ([]dto.FraudCaseActivityResponse, error) {
+	var result []struct {
+		model.FraudCaseActivity
+		UserName *string
+	}
+
+	err := r.Db.WithContext(ctx).Model(&model.FraudCaseActivity{}).
+		Select("fraud_case_activities.*, users.name as user_name").
+		Joins("LEFT JOIN users ON fraud_case_activities.user_id = users.id").
+		Where("fraud_case_activities.fraud_case_id = ?", ID).
+		Order("fraud_case_activities.created_at ASC, fraud_case_activities.id ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []dto.FraudCaseActivityResponse
+	for _, e := range result {
+		activity := dto.FraudCaseActivityResponse{
+			ID:         e.ID,
+			UserID:     e.UserID,
+			Action:     string(e.Action),
+			FromStatus: string(e.FromStatus),
+			ToStatus:   string(e.ToStatus),
+			Note:       e.Note,
+			CreatedAt:  e.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if e.UserName != nil {
+			activity.UserName = *e.UserName
+		}
+
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
+
+// UpdateFraudCase changes a case and records the matching activity in the same transaction
+func (r *fraudCase) UpdateFraudCase(ctx context.Context, ID int, fields map[string]interface{}, activity model.FraudCaseActivity) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Model(&model.FraudCase{}).Where("id = ?", ID).Updates(fields).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	activity.FraudCaseID = ID
+	if err := tx.Create(&activity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *fraudCase) StoreFraudCaseActivity(ctx context.Context, activity model.FraudCaseActivity) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(&activity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *fraudCase) CountOpenFraudCase(ctx context.Context) (*dto.FraudCaseCount, error) {
+	var result []struct {
+		Status model.FraudCaseStatus
+		Total  int64
+	}
+
+	err := r.Db.WithContext(ctx).Model(&model.FraudCase{}).
+		Select("status, COUNT(*) as total").
+		Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Where("status IN (?)", activeCaseStatus).
+		Group("status").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res dto.FraudCaseCount
+	for _, e := range result {
+		switch e.Status {
+		case model.CaseOpen:
+			res.Open = e.Total
+		case model.CaseReviewing:
+			res.Reviewing = e.Total
+		case model.CaseEscalated:
+			res.Escalated = e.Total
+		}
+	}
+
+	return &res, nil
+}
+
+func (r *fraudCase) ShipIDsWithUncasedFraud(ctx context.Context) ([]int, error) {
+	var ids []int
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Where("(is_mocked = ? OR is_fraud = ?) AND fraud_case_id IS NULL", 1, 1).
+		Distinct("ship_id").
+		Pluck("ship_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ShipFraudFlags loads only the columns needed to group the fixes of a ship into cases
+func (r *fraudCase) ShipFraudFlags(ctx context.Context, ShipID int) ([]model.ShipLocationLog, error) {
+	var logs []model.ShipLocationLog
+
+	err := r.Db.WithContext(ctx).
+		Select("id, ship_id, is_mocked, is_fraud, fraud_score, fraud_reason, fraud_case_id, created_at").
+		Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Where("ship_id = ?", ShipID).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func fraudCaseResponse(c model.FraudCase, shipName string, assigneeName *string) dto.FraudCaseResponse {
+	res := dto.FraudCaseResponse{
+		ID:         c.ID,
+		ShipID:     c.ShipID,
+		ShipName:   shipName,
+		Status:     string(c.Status),
+		AssigneeID: c.AssigneeID,
+		FixCount:   c.FixCount,
+		MaxScore:   c.MaxScore,
+		Reasons:    []string{},
+		StartedAt:  c.StartedAt.Format("2006-01-02 15:04:05"),
+		LastSeenAt: c.LastSeenAt.Format("2006-01-02 15:04:05"),
+		Sanction:   string(c.Sanction),
+	}
+
+	if assigneeName != nil {
+		res.AssigneeName = *assigneeName
+	}
+
+	if c.Reasons != "" {
+		res.Reasons = strings.Split(c.Reasons, ",")
+	}
+
+	if c.SanctionedAt != nil {
+		res.SanctionedAt = c.SanctionedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if c.ClosedAt != nil {
+		res.ClosedAt = c.ClosedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type Harbour interface {
+	HarbourList(ctx context.Context, request dto.HarbourListParam) ([]dto.HarbourResponse, error)
+	HarbourCount(ctx context.Context, request dto.HarbourListParam) (int64, error)
+	HarbourByID(ctx context.Context, ID int) (*model.Harbour, error)
+	HarbourByCode(ctx context.Context, code int) (*model.Harbour, error)
+	HarbourCodeExists(ctx context.Context, code int, exceptID int) (bool, error)
+	StoreHarbour(ctx context.Context, harbour *model.Harbour) error
+	UserHarbours(ctx context.Context, userID int) ([]int, error)
+	SetUserHarbours(ctx context.Context, userID int, harbourIDs []int) error
+	ShipUserHarbour(ctx context.Context, userID int) (int, error)
+	ActiveHarbours(ctx context.Context) ([]model.Harbour, error)
+}
+
+type harbour struct {
+	Db           *gorm.DB
+	RedisClient  *redis.Client
+	CacheEnabled bool
+}
+
+func NewHarbourRepository(db *gorm.DB, redisClient *redis.Client) Harbour {
+	return &harbour{
+		Db:           db,
+		RedisClient:  redisClient,
+		CacheEnabled: true,
+	}
+}
+
+func (r *harbour) filterHarbour(query *gorm.DB, request dto.HarbourListParam) *gorm.DB {
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(name) LIKE ? OR CAST(code AS varchar) LIKE ?", "%"+searchLower+"%", "%"+searchLower+"%")
+	}
+
+	return query
+}
+
+// HarbourList returns the harbours visible to ctx, admins only see the harbours they are assigned to
+func (r *harbour) HarbourList(ctx context.Context, request dto.HarbourListParam) ([]dto.HarbourResponse, error) {
+	query := r.filterHarbour(r.Db.WithContext(ctx).Model(&model.Harbour{}).Scopes(tenant.Scope(ctx, "id")), request)
+
+	var result []model.Harbour
+	if err := query.Limit(request.Limit).Offset(request.Offset).Order("name ASC").Find(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var res []dto.HarbourResponse
+	for _, e := range result {
+		res = append(res, dto.HarbourResponse{
+			ID:           e.ID,
+			Code:         e.Code,
+			Name:         e.Name,
+			AdminContact: e.AdminContact,
+			Timezone:     e.Timezone,
+			IsActive:     e.IsActive == 1,
+			CreatedAt:    e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return res, nil
+}
+
+func (r *harbour) HarbourCount(ctx context.Context, request dto.HarbourListParam) (int64, error) {
+	query := r.filterHarbour(r.Db.WithContext(ctx).Model(&model.Harbour{}).Scopes(tenant.Scope(ctx, "id")), request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *harbour) HarbourByID(ctx context.Context, ID int) (*model.Harbour, error) {
+	var res model.Harbour
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "id")).Where("id = ?", ID).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// HarbourByCode looks up an active harbour by the code ships pair with, it is not scoped
+// as the pairing and login requests come before a harbour is known
+func (r *harbour) HarbourByCode(ctx context.Context, code int) (*model.Harbour, error) {
+	var res model.Harbour
+
+	if err := r.Db.WithContext(ctx).Where("code = ? AND is_active = ?", code, 1).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// HarbourCodeExists reports whether a harbour other than exceptID uses code, inactive ones included
+func (r *harbour) HarbourCodeExists(ctx context.Context, code int, exceptID int) (bool, error) {
+	var res int64
+
+	if err := r.Db.WithContext(ctx).Model(&model.Harbour{}).Where("code = ? AND id <> ?", code, exceptID).Count(&res).Error; err != nil {
+		return false, err
+	}
+
+	return res > 0, nil
+}
+
+func (r *harbour) StoreHarbour(ctx context.Context, harbour *model.Harbour) error {
+	if err := r.Db.WithContext(ctx).Create(harbour).Error; err != nil {
+		return err
+	}
+
+	// single harbour deployments resolve their settings without a harbour in the request,
+	// those cached settings are stale once a second harbour exists
+	return helper.DeleteRedisKeysByPattern(r.RedisClient, "app_*")
+}
+
+// UserHarbours returns the harbours an admin is assigned to
+func (r *harbour) UserHarbours(ctx context.Context, userID int) ([]int, error) {
+	var res []int
+
+	err := r.Db.WithContext(ctx).Model(&model.UserHarbour{}).
+		Where("user_id = ?", userID).
+		Order("harbour_id ASC").
+		Pluck("harbour_id", &res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetUserHarbours replaces the harbours an admin is assigned to
+func (r *harbour) SetUserHarbours(ctx context.Context, userID int, harbourIDs []int) error {
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserHarbour{}).Error; err != nil {
+			return err
+		}
+
+		if len(harbourIDs) == 0 {
+			return nil
+		}
+
+		var rows []model.UserHarbour
+		for _, harbourID := range harbourIDs {
+			rows = append(rows, model.UserHarbour{UserID: userID, HarbourID: harbourID})
+		}
+
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	cacheKey := "user_list-*"
+
+	if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey); err != nil {
+		return nil
+	}
+
+	return nil
+}
+
+// ShipUserHarbour returns the harbour of the ship a mobile user is paired with
+func (r *harbour) ShipUserHarbour(ctx context.Context, userID int) (int, error) {
+	var ship model.Ship
+
+	if err := r.Db.WithContext(ctx).Select("harbour_id").Where("user_id = ?", userID).First(&ship).Error; err != nil {
+		return 0, err
+	}
+
+	return ship.HarbourID, nil
+}
+
+// ActiveHarbours returns every active harbour of the deployment whatever the scope of ctx,
+// a ship may reach any of them. Every fix outside its home zone looks them up, the list is
+// cached under app_ so storing a harbour or its settings clears it
+func (r *harbour) ActiveHarbours(ctx context.Context) ([]model.Harbour, error) {
+	cacheKey := "app_active_harbours"
+
+	if r.CacheEnabled {
+		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
+		if err == nil {
+			var cachedHarbours []model.Harbour
+			if err := json.Unmarshal([]byte(cachedData), &cachedHarbours); err == nil {
+				return cachedHarbours, nil
+			}
+		}
+	}
+
+	var res []model.Harbour
+
+	if err := r.Db.WithContext(ctx).Where("is_active = ?", 1).Order("id ASC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	if r.CacheEnabled {
+		jsonData, err := json.Marshal(res)
+		if err == nil {
+			r.RedisClient.Set(ctx, cacheKey, jsonData, time.Hour)
+		} else {
+			fmt.Println("Error marshalling data for cache:", err)
+		}
+	}
+
+	return res, nil
+}
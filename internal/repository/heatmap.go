@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type Heatmap interface {
+	EachFix(ctx context.Context, request dto.HeatmapFilter, fn func(dto.HeatmapFix) error) error
+	ReplaceRollups(ctx context.Context, date time.Time, rollups []model.ShipPositionRollup) error
+	LastRollupDate(ctx context.Context) (*time.Time, error)
+	FirstFixDate(ctx context.Context) (*time.Time, error)
+	RollupCells(ctx context.Context, request dto.HeatmapFilter) ([]dto.HeatmapCell, error)
+}
+
+type heatmap struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewHeatmapRepository(db *gorm.DB, redisClient *redis.Client) Heatmap {
+	return &heatmap{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *heatmap) filterHeatmap(query *gorm.DB, request dto.HeatmapFilter, onGroundColumn string) *gorm.DB {
+	if request.ShipType != "" {
+		query = query.Where("ship_details.type = ?", request.ShipType)
+	}
+
+	if request.OnGround != nil {
+		query = query.Where(onGroundColumn+" = ?", *request.OnGround)
+	}
+
+	return query
+}
+
+// EachFix walks the genuine location logs of the range through a database cursor, mocked and
+// fraudulent fixes are left out of the heatmap
+func (r *heatmap) EachFix(ctx context.Context, request dto.HeatmapFilter, fn func(dto.HeatmapFix) error) error {
+	query := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Select("ships.harbour_id, ship_location_logs.ship_id, ship_location_logs.lat, ship_location_logs.long, "+
+			"ship_location_logs.on_ground, ship_location_logs.created_at").
+		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_location_logs.created_at >= ? AND ship_location_logs.created_at < ?", request.Start, request.End).
+		Where("ship_location_logs.is_mocked = 0 AND ship_location_logs.is_fraud = 0")
+
+	query = r.filterHeatmap(query, request, "ship_location_logs.on_ground")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dto.HeatmapFix
+		if err := r.Db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ReplaceRollups swaps the rollups of a day for the given ones so a day can be rolled up again
+func (r *heatmap) ReplaceRollups(ctx context.Context, date time.Time, rollups []model.ShipPositionRollup) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := tx.Unscoped().Scopes(tenant.Scope(ctx, "harbour_id")).Where("date = ?", date.Format("2006-01-02"))
+	if err := query.Delete(&model.ShipPositionRollup{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(rollups) > 0 {
+		if err := tx.CreateInBatches(&rollups, 500).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// LastRollupDate returns the last day rolled up, the worker rolls up every harbour at once so
+// the date is not scoped
+func (r *heatmap) LastRollupDate(ctx context.Context) (*time.Time, error) {
+	var res *time.Time
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipPositionRollup{}).
+		Select("MAX(date)").
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *heatmap) FirstFixDate(ctx context.Context) (*time.Time, error) {
+	var res *time.Time
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Scopes(tenant.ShipScope(ctx, "ship_location_logs.ship_id")).
+		Select("MIN(ship_location_logs.created_at)").
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RollupCells sums the rolled up fixes per ship and cell of the request precision, the cell of a
+// lower precision is the prefix of the stored geohash
+func (r *heatmap) RollupCells(ctx context.Context, request dto.HeatmapFilter) ([]dto.HeatmapCell, error) {
+	var res []dto.HeatmapCell
+
+	query := r.Db.WithContext(ctx).Model(&model.ShipPositionRollup{}).
+		Select("LEFT(ship_position_rollups.geohash, ?) as geohash, ship_position_rollups.ship_id, "+
+			"SUM(ship_position_rollups.fixes) as fixes", request.Precision).
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ship_position_rollups.ship_id").
+		Scopes(tenant.Scope(ctx, "ship_position_rollups.harbour_id")).
+		Where("ship_position_rollups.date >= ? AND ship_position_rollups.date < ?",
+			request.Start.Format("2006-01-02"), request.End.Format("2006-01-02"))
+
+	query = r.filterHeatmap(query, request, "ship_position_rollups.on_ground")
+
+	err := query.Group("1, ship_position_rollups.ship_id").Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,748 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Inspection interface {
+	StoreTemplate(ctx context.Context, template *model.InspectionTemplate, items []model.InspectionTemplateItem) error
+	UpdateTemplate(ctx context.Context, template model.InspectionTemplate, items []model.InspectionTemplateItem) error
+	TemplateByID(ctx context.Context, ID int) (*model.InspectionTemplate, error)
+	TemplateItems(ctx context.Context, templateID int) ([]model.InspectionTemplateItem, error)
+	TemplateList(ctx context.Context, request dto.InspectionTemplateListParam) ([]dto.InspectionTemplateResponse, error)
+	TemplateCount(ctx context.Context, request dto.InspectionTemplateListParam) (int64, error)
+	StoreInspection(ctx context.Context, inspection *model.Inspection, results []model.InspectionResult) error
+	InspectionByDockedLog(ctx context.Context, dockedLogID int) (*model.Inspection, error)
+	InspectionList(ctx context.Context, request dto.InspectionListParam) ([]dto.InspectionResponse, error)
+	InspectionCount(ctx context.Context, request dto.InspectionListParam) (int64, error)
+	InspectionDetail(ctx context.Context, ID int) (*dto.InspectionResponse, error)
+	InspectionResults(ctx context.Context, inspectionID int) ([]dto.InspectionResultResponse, error)
+	StoreTask(ctx context.Context, task *model.InspectionTask, roundRobin bool) error
+	TaskByID(ctx context.Context, ID int) (*model.InspectionTask, error)
+	TaskDetail(ctx context.Context, ID int, now time.Time) (*dto.InspectionTaskResponse, error)
+	AssignTask(ctx context.Context, ID int, assigneeID int, assignedAt time.Time) error
+	TaskList(ctx context.Context, request dto.InspectionTaskListParam, now time.Time) ([]dto.InspectionTaskResponse, error)
+	TaskCount(ctx context.Context, request dto.InspectionTaskListParam, now time.Time) (int64, error)
+	OverdueTasks(ctx context.Context, now time.Time) ([]dto.InspectionTaskResponse, error)
+	MarkOverdueNotified(ctx context.Context, ID int, notifiedAt time.Time) error
+	TaskMetrics(ctx context.Context, startDate string, endDate string, now time.Time) (*dto.InspectionMetricsResponse, error)
+}
+
+// inspectionTaskLock serializes the round robin pick so concurrent check-ins do not get the same inspector
+const inspectionTaskLock = 38001
+
+type inspection struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewInspectionRepository(db *gorm.DB, redisClient *redis.Client) Inspection {
+	return &inspection{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *inspection) StoreTemplate(ctx context.Context, template *model.InspectionTemplate, items []model.InspectionTemplateItem) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(template).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range items {
+		items[i].InspectionTemplateID = template.ID
+	}
+
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTemplate replaces the template items, results already recorded keep their own copy of the old items
+func (r *inspection) UpdateTemplate(ctx context.Context, template model.InspectionTemplate, items []model.InspectionTemplateItem) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.InspectionTemplate{}).Where("id = ?", template.ID).Updates(map[string]interface{}{
+		"name":        template.Name,
+		"description": template.Description,
+		"is_active":   template.IsActive,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Where("inspection_template_id = ?", template.ID).Delete(&model.InspectionTemplateItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range items {
+		items[i].InspectionTemplateID = template.ID
+	}
+
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *inspection) TemplateByID(ctx context.Context, ID int) (*model.InspectionTemplate, error) {
+	var template model.InspectionTemplate
+
+	if err := r.Db.WithContext(ctx).Where("id = ?", ID).First(&template).Error; err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (r *inspection) TemplateItems(ctx context.Context, templateID int) ([]model.InspectionTemplateItem, error) {
+	var items []model.InspectionTemplateItem
+
+	err := r.Db.WithContext(ctx).
+		Where("inspection_template_id = ?", templateID).
+		Order("sort ASC, id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *inspection) filterTemplate(query *gorm.DB, request dto.InspectionTemplateListParam) *gorm.DB {
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(inspection_templates.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	return query
+}
+
+func (r *inspection) TemplateList(ctx context.Context, request dto.InspectionTemplateListParam) ([]dto.InspectionTemplateResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	itemCount := "(SELECT COUNT(*) FROM inspection_template_items WHERE inspection_template_items.inspection_template_id = inspection_templates.id " +
+		"AND inspection_template_items.deleted_at IS NULL) as item_count"
+
+	query := tx.Model(&model.InspectionTemplate{}).Select("inspection_templates.*, " + itemCount)
+	query = r.filterTemplate(query, request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("inspection_templates.created_at DESC")
+
+	var result []struct {
+		model.InspectionTemplate
+		ItemCount int
+	}
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var templates []dto.InspectionTemplateResponse
+	for _, e := range result {
+		templates = append(templates, dto.InspectionTemplateResponse{
+			ID:          e.ID,
+			Name:        e.Name,
+			Description: e.Description,
+			IsActive:    e.IsActive == 1,
+			ItemCount:   e.ItemCount,
+			CreatedAt:   e.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return templates, nil
+}
+
+func (r *inspection) TemplateCount(ctx context.Context, request dto.InspectionTemplateListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.InspectionTemplate{})
+	query = r.filterTemplate(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// StoreInspection records the inspection with its results and marks the docked log as inspected,
+// it returns constants.InspectionExists when the docked log was inspected in the meantime
+func (r *inspection) StoreInspection(ctx context.Context, inspection *model.Inspection, results []model.InspectionResult) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	created := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "ship_docked_log_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(inspection)
+	if created.Error != nil {
+		tx.Rollback()
+		return created.Error
+	}
+
+	if created.RowsAffected == 0 {
+		tx.Rollback()
+		return constants.InspectionExists
+	}
+
+	for i := range results {
+		results[i].InspectionID = inspection.ID
+	}
+
+	if len(results) > 0 {
+		if err := tx.Create(&results).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err := tx.Model(&model.ShipDockedLog{}).
+		Where("id = ?", inspection.ShipDockedLogID).
+		Update("is_inspected", 1).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Model(&model.InspectionTask{}).
+		Where("ship_docked_log_id = ? AND status = ?", inspection.ShipDockedLogID, model.TaskOpen).
+		Updates(map[string]interface{}{
+			"status":        model.TaskDone,
+			"completed_at":  inspection.CompletedAt,
+			"inspection_id": inspection.ID,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	cacheKey := []string{"ship_list-*", "ship_last_update*"}
+
+	for i := range cacheKey {
+		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (r *inspection) InspectionByDockedLog(ctx context.Context, dockedLogID int) (*model.Inspection, error) {
+	var inspection model.Inspection
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("ship_docked_log_id = ?", dockedLogID).First(&inspection).Error; err != nil {
+		return nil, err
+	}
+
+	return &inspection, nil
+}
+
+func (r *inspection) filterInspection(query *gorm.DB, request dto.InspectionListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("inspections.ship_id = ?", request.ShipID)
+	}
+
+	if request.InspectorID != 0 {
+		query = query.Where("inspections.inspector_id = ?", request.InspectorID)
+	}
+
+	if request.Outcome != nil && len(request.Outcome) > 0 && request.Outcome[0] != "" {
+		query = query.Where("inspections.outcome IN (?)", request.Outcome)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(inspections.completed_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *inspection) inspectionQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	failedItems := "(SELECT COUNT(*) FROM inspection_results WHERE inspection_results.inspection_id = inspections.id " +
+		"AND inspection_results.result = 'fail' AND inspection_results.deleted_at IS NULL) as failed_items"
+
+	return query.Model(&model.Inspection{}).
+		Select("inspections.*, ships.name as ship_name, inspection_templates.name as template_name, " +
+			"users.name as inspector_name, ship_docked_logs.created_at as checkin_date, " + failedItems).
+		Joins("JOIN ships ON inspections.ship_id = ships.id").
+		Joins("JOIN ship_docked_logs ON inspections.ship_docked_log_id = ship_docked_logs.id").
+		Joins("LEFT JOIN inspection_templates ON inspections.inspection_template_id = inspection_templates.id").
+		Joins("LEFT JOIN users ON inspections.inspector_id = users.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+}
+
+type inspectionRow struct {
+	model.Inspection
+	ShipName      string
+	TemplateName  *string
+	InspectorName *string
+	CheckinDate   time.Time
+	FailedItems   int
+}
+
+func (r *inspection) InspectionList(ctx context.Context, request dto.InspectionListParam) ([]dto.InspectionResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := r.filterInspection(r.inspectionQuery(ctx, tx), request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("inspections.completed_at DESC")
+
+	var result []inspectionRow
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var inspections []dto.InspectionResponse
+	for _, e := range result {
+		inspections = append(inspections, inspectionResponse(e))
+	}
+
+	return inspections, nil
+}
+
+func (r *inspection) InspectionCount(ctx context.Context, request dto.InspectionListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Inspection{}).
+		Joins("JOIN ships ON inspections.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterInspection(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *inspection) InspectionDetail(ctx context.Context, ID int) (*dto.InspectionResponse, error) {
+	var result inspectionRow
+
+	if err := r.inspectionQuery(ctx, r.Db.WithContext(ctx)).Where("inspections.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	res := inspectionResponse(result)
+
+	return &res, nil
+}
+
+func (r *inspection) InspectionResults(ctx context.Context, inspectionID int) ([]dto.InspectionResultResponse, error) {
+	var result []model.InspectionResult
+
+	if err := r.Db.WithContext(ctx).Where("inspection_id = ?", inspectionID).Order("id ASC").Find(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var results []dto.InspectionResultResponse
+	for _, e := range result {
+		results = append(results, dto.InspectionResultResponse{
+			ID:         e.ID,
+			ItemID:     e.InspectionTemplateItemID,
+			Category:   string(e.Category),
+			Label:      e.Label,
+			IsRequired: e.IsRequired == 1,
+			Result:     string(e.Result),
+			Note:       e.Note,
+		})
+	}
+
+	return results, nil
+}
+
+func inspectionResponse(e inspectionRow) dto.InspectionResponse {
+	res := dto.InspectionResponse{
+		ID:          e.ID,
+		DockedLogID: e.ShipDockedLogID,
+		ShipID:      e.ShipID,
+		ShipName:    e.ShipName,
+		TemplateID:  e.InspectionTemplateID,
+		InspectorID: e.InspectorID,
+		Outcome:     string(e.Outcome),
+		Findings:    e.Findings,
+		FailedItems: e.FailedItems,
+		CheckinDate: e.CheckinDate.Format("2006-01-02 15:04:05"),
+		StartedAt:   e.StartedAt.Format("2006-01-02 15:04:05"),
+		CompletedAt: e.CompletedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if e.TemplateName != nil {
+		res.TemplateName = *e.TemplateName
+	}
+
+	if e.InspectorName != nil {
+		res.InspectorName = *e.InspectorName
+	}
+
+	return res
+}
+
+// StoreTask opens the inspection task of a check-in, with roundRobin the task goes to the admin
+// following the one who received the previous automatic task. A docked log gets one task only.
+func (r *inspection) StoreTask(ctx context.Context, task *model.InspectionTask, roundRobin bool) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", inspectionTaskLock).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var exists int64
+	if err := tx.Model(&model.InspectionTask{}).Where("ship_docked_log_id = ?", task.ShipDockedLogID).Count(&exists).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if exists > 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	if roundRobin {
+		// the rotation runs over the admins of the harbour of the ship
+		harbour := tx.Model(&model.Ship{}).Select("harbour_id").Where("id = ?", task.ShipID)
+		harbourShips := tx.Model(&model.Ship{}).Select("id").Where("harbour_id = (?)", harbour)
+		harbourAdmins := tx.Model(&model.UserHarbour{}).Select("user_id").Where("harbour_id = (?)", harbour)
+
+		var last []int
+		err := tx.Model(&model.InspectionTask{}).
+			Where("assign_mode = ? AND assignee_id IS NOT NULL", model.AssignRoundRobin).
+			Where("ship_id IN (?)", harbourShips).
+			Order("id DESC").Limit(1).
+			Pluck("assignee_id", &last).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		lastID := 0
+		if len(last) > 0 {
+			lastID = last[0]
+		}
+
+		// the admin after the last one, wrapping around to the first admin
+		var next []int
+		err = tx.Model(&model.User{}).
+			Where("role = ? AND id > ?", model.Admin, lastID).
+			Where("id IN (?)", harbourAdmins).
+			Order("id ASC").Limit(1).
+			Pluck("id", &next).Error
+		if err == nil && len(next) == 0 {
+			err = tx.Model(&model.User{}).
+				Where("role = ?", model.Admin).
+				Where("id IN (?)", harbourAdmins).
+				Order("id ASC").Limit(1).
+				Pluck("id", &next).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if len(next) > 0 {
+			assignedAt := task.CheckinAt
+			task.AssigneeID = &next[0]
+			task.AssignedAt = &assignedAt
+		}
+	}
+
+	if err := tx.Create(task).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *inspection) TaskByID(ctx context.Context, ID int) (*model.InspectionTask, error) {
+	var task model.InspectionTask
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).First(&task).Error; err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (r *inspection) TaskDetail(ctx context.Context, ID int, now time.Time) (*dto.InspectionTaskResponse, error) {
+	var result taskRow
+
+	if err := r.taskQuery(ctx, r.Db.WithContext(ctx)).Where("inspection_tasks.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	res := taskResponse(result, now)
+
+	return &res, nil
+}
+
+// AssignTask hands the task to an inspector by hand, the overdue reminder is sent again to the new assignee
+func (r *inspection) AssignTask(ctx context.Context, ID int, assigneeID int, assignedAt time.Time) error {
+	result := r.Db.WithContext(ctx).Model(&model.InspectionTask{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"assignee_id":         assigneeID,
+		"assign_mode":         model.AssignManual,
+		"assigned_at":         assignedAt,
+		"overdue_notified_at": nil,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *inspection) filterTask(query *gorm.DB, request dto.InspectionTaskListParam, now time.Time) *gorm.DB {
+	if request.AssigneeID != 0 {
+		query = query.Where("inspection_tasks.assignee_id = ?", request.AssigneeID)
+	}
+
+	if request.Status != nil && len(request.Status) > 0 && request.Status[0] != "" {
+		query = query.Where("inspection_tasks.status IN (?)", request.Status)
+	}
+
+	if request.Overdue {
+		query = query.Where("inspection_tasks.status = ? AND inspection_tasks.due_at < ?", model.TaskOpen, now)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	return query
+}
+
+func (r *inspection) taskQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return query.Model(&model.InspectionTask{}).
+		Select("inspection_tasks.*, ships.name as ship_name, ships.harbour_id as harbour_id, users.name as assignee_name, users.email as assignee_email").
+		Joins("JOIN ships ON inspection_tasks.ship_id = ships.id").
+		Joins("LEFT JOIN users ON inspection_tasks.assignee_id = users.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+}
+
+type taskRow struct {
+	model.InspectionTask
+	ShipName      string
+	HarbourID     int
+	AssigneeName  *string
+	AssigneeEmail *string
+}
+
+func (r *inspection) TaskList(ctx context.Context, request dto.InspectionTaskListParam, now time.Time) ([]dto.InspectionTaskResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := r.filterTask(r.taskQuery(ctx, tx), request, now)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("inspection_tasks.due_at ASC, inspection_tasks.id ASC")
+
+	var result []taskRow
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var tasks []dto.InspectionTaskResponse
+	for _, e := range result {
+		tasks = append(tasks, taskResponse(e, now))
+	}
+
+	return tasks, nil
+}
+
+func (r *inspection) TaskCount(ctx context.Context, request dto.InspectionTaskListParam, now time.Time) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.InspectionTask{}).
+		Joins("JOIN ships ON inspection_tasks.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterTask(query, request, now)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// OverdueTasks returns the assigned open tasks past their due time whose inspector was not reminded yet
+func (r *inspection) OverdueTasks(ctx context.Context, now time.Time) ([]dto.InspectionTaskResponse, error) {
+	var result []taskRow
+
+	err := r.taskQuery(ctx, r.Db.WithContext(ctx)).
+		Where("inspection_tasks.status = ? AND inspection_tasks.due_at < ?", model.TaskOpen, now).
+		Where("inspection_tasks.assignee_id IS NOT NULL AND inspection_tasks.overdue_notified_at IS NULL").
+		Order("inspection_tasks.due_at ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []dto.InspectionTaskResponse
+	for _, e := range result {
+		tasks = append(tasks, taskResponse(e, now))
+	}
+
+	return tasks, nil
+}
+
+func (r *inspection) MarkOverdueNotified(ctx context.Context, ID int, notifiedAt time.Time) error {
+	return r.Db.WithContext(ctx).Model(&model.InspectionTask{}).
+		Where("id = ?", ID).
+		Update("overdue_notified_at", notifiedAt).Error
+}
+
+// TaskMetrics summarizes the tasks of the check-ins between the dates, time to inspect runs from check-in to completion
+func (r *inspection) TaskMetrics(ctx context.Context, startDate string, endDate string, now time.Time) (*dto.InspectionMetricsResponse, error) {
+	var result struct {
+		Completed       int64
+		CompletedOnTime int64
+		Open            int64
+		Overdue         int64
+		AvgSeconds      float64
+	}
+
+	query := r.Db.WithContext(ctx).Model(&model.InspectionTask{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) as completed, "+
+			"COUNT(*) FILTER (WHERE status = ? AND completed_at <= due_at) as completed_on_time, "+
+			"COUNT(*) FILTER (WHERE status = ?) as open, "+
+			"COUNT(*) FILTER (WHERE status = ? AND due_at < ?) as overdue, "+
+			"COALESCE(AVG(EXTRACT(EPOCH FROM completed_at - checkin_at)) FILTER (WHERE status = ?), 0) as avg_seconds",
+			model.TaskDone, model.TaskDone, model.TaskOpen, model.TaskOpen, now, model.TaskDone).
+		Scopes(tenant.ShipScope(ctx, "ship_id"))
+
+	if startDate != "" && endDate != "" {
+		query = query.Where("DATE(checkin_at) BETWEEN ? AND ?", startDate, endDate)
+	}
+
+	if err := query.Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	res := &dto.InspectionMetricsResponse{
+		Completed:           result.Completed,
+		CompletedOnTime:     result.CompletedOnTime,
+		Open:                result.Open,
+		Overdue:             result.Overdue,
+		AvgTimeToInspectMin: math.Round(result.AvgSeconds/60*10) / 10,
+	}
+
+	if result.Completed > 0 {
+		res.OnTimeRate = math.Round(float64(result.CompletedOnTime)/float64(result.Completed)*1000) / 10
+	}
+
+	return res, nil
+}
+
+func taskResponse(e taskRow, now time.Time) dto.InspectionTaskResponse {
+	res := dto.InspectionTaskResponse{
+		ID:          e.ID,
+		DockedLogID: e.ShipDockedLogID,
+		ShipID:      e.ShipID,
+		ShipName:    e.ShipName,
+		HarbourID:   e.HarbourID,
+		AssignMode:  string(e.AssignMode),
+		Status:      string(e.Status),
+		CheckinAt:   e.CheckinAt.Format("2006-01-02 15:04:05"),
+		DueAt:       e.DueAt.Format("2006-01-02 15:04:05"),
+		IsOverdue:   e.Status == model.TaskOpen && e.DueAt.Before(now),
+	}
+
+	if e.AssigneeID != nil {
+		res.AssigneeID = *e.AssigneeID
+	}
+
+	if e.AssigneeName != nil {
+		res.AssigneeName = *e.AssigneeName
+	}
+
+	if e.AssigneeEmail != nil {
+		res.AssigneeEmail = *e.AssigneeEmail
+	}
+
+	if e.AssignedAt != nil {
+		res.AssignedAt = e.AssignedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if e.CompletedAt != nil {
+		res.CompletedAt = e.CompletedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if e.InspectionID != nil {
+		res.InspectionID = *e.InspectionID
+	}
+
+	return res
+}
@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Invoice interface {
+	TariffList(ctx context.Context) ([]model.PortTariff, error)
+	TariffByID(ctx context.Context, ID int) (*model.PortTariff, error)
+	TariffZoneTaken(ctx context.Context, harbourID int, zoneType string, exceptID int) (bool, error)
+	StoreTariff(ctx context.Context, tariff *model.PortTariff) error
+	UpdateTariff(ctx context.Context, tariff model.PortTariff) error
+	ActiveTariff(ctx context.Context, harbourID int, zoneType string) (*model.PortTariff, error)
+	UninvoicedCheckouts(ctx context.Context, startDate string, endDate string) ([]model.ShipDockedLog, error)
+	StayCheckin(ctx context.Context, shipID int, checkoutLogID int) (*model.ShipDockedLog, error)
+	StayBerth(ctx context.Context, shipID int, arrivedAt time.Time, departedAt time.Time) (*model.Berth, error)
+	InvoiceExists(ctx context.Context, checkoutLogID int) (bool, error)
+	StoreInvoice(ctx context.Context, invoice *model.Invoice) (bool, error)
+	InvoiceByID(ctx context.Context, ID int) (*model.Invoice, error)
+	IssueInvoice(ctx context.Context, ID int, number string, issuedAt time.Time) (bool, error)
+	VoidInvoice(ctx context.Context, ID int, reason string, voidedAt time.Time) (bool, error)
+	RecordPayment(ctx context.Context, payment *model.InvoicePayment) (bool, error)
+	InvoiceList(ctx context.Context, request dto.InvoiceListParam) ([]dto.InvoiceResponse, error)
+	InvoiceCount(ctx context.Context, request dto.InvoiceListParam) (int64, error)
+	InvoiceDetail(ctx context.Context, ID int) (*dto.InvoiceResponse, error)
+}
+
+type invoice struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewInvoiceRepository(db *gorm.DB, redisClient *redis.Client) Invoice {
+	return &invoice{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *invoice) TariffList(ctx context.Context) ([]model.PortTariff, error) {
+	var res []model.PortTariff
+
+	err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).
+		Order("is_active DESC, zone_type ASC, id ASC").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *invoice) TariffByID(ctx context.Context, ID int) (*model.PortTariff, error) {
+	var tariff model.PortTariff
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&tariff).Error; err != nil {
+		return nil, err
+	}
+
+	return &tariff, nil
+}
+
+// TariffZoneTaken reports whether the zone type already has another active tariff in the harbour
+func (r *invoice) TariffZoneTaken(ctx context.Context, harbourID int, zoneType string, exceptID int) (bool, error) {
+	var res int64
+
+	err := r.Db.WithContext(ctx).Model(&model.PortTariff{}).
+		Where("harbour_id = ? AND zone_type = ? AND is_active = 1 AND id <> ?", harbourID, zoneType, exceptID).
+		Count(&res).Error
+	if err != nil {
+		return false, err
+	}
+
+	return res > 0, nil
+}
+
+func (r *invoice) StoreTariff(ctx context.Context, tariff *model.PortTariff) error {
+	return r.Db.WithContext(ctx).Create(tariff).Error
+}
+
+func (r *invoice) UpdateTariff(ctx context.Context, tariff model.PortTariff) error {
+	result := r.Db.WithContext(ctx).Model(&model.PortTariff{}).Where("id = ?", tariff.ID).Updates(map[string]interface{}{
+		"zone_type":   tariff.ZoneType,
+		"name":        tariff.Name,
+		"base_fee":    tariff.BaseFee,
+		"daily_rate":  tariff.DailyRate,
+		"rate_per_gt": tariff.RatePerGT,
+		"is_active":   tariff.IsActive,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *invoice) ActiveTariff(ctx context.Context, harbourID int, zoneType string) (*model.PortTariff, error) {
+	var tariff model.PortTariff
+
+	err := r.Db.WithContext(ctx).
+		Where("harbour_id = ? AND zone_type = ? AND is_active = 1", harbourID, zoneType).
+		Order("id DESC").
+		First(&tariff).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &tariff, nil
+}
+
+// UninvoicedCheckouts returns the checkout logs of the range which are not billed by an invoice
+// yet, a voided invoice no longer bills its stay
+func (r *invoice) UninvoicedCheckouts(ctx context.Context, startDate string, endDate string) ([]model.ShipDockedLog, error) {
+	var res []model.ShipDockedLog
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Select("ship_docked_logs.*").
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_docked_logs.status = ?", model.Checkout).
+		Where("DATE(ship_docked_logs.created_at) BETWEEN ? AND ?", startDate, endDate).
+		Where("NOT EXISTS (SELECT 1 FROM invoices WHERE invoices.checkout_log_id = ship_docked_logs.id " +
+			"AND invoices.status <> 'void' AND invoices.deleted_at IS NULL)").
+		Order("ship_docked_logs.id ASC").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// StayCheckin returns the docked log right before the checkout, it opens the stay when it is a checkin
+func (r *invoice) StayCheckin(ctx context.Context, shipID int, checkoutLogID int) (*model.ShipDockedLog, error) {
+	var log model.ShipDockedLog
+
+	err := r.Db.WithContext(ctx).
+		Where("ship_id = ? AND id < ?", shipID, checkoutLogID).
+		Order("id DESC").
+		First(&log).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &log, nil
+}
+
+// StayBerth returns the berth the ship confirmed during the stay, nil when it moored elsewhere
+func (r *invoice) StayBerth(ctx context.Context, shipID int, arrivedAt time.Time, departedAt time.Time) (*model.Berth, error) {
+	var berth model.Berth
+
+	err := r.Db.WithContext(ctx).Model(&model.Berth{}).
+		Select("berths.*").
+		Joins("JOIN berth_reservations ON berth_reservations.berth_id = berths.id AND berth_reservations.deleted_at IS NULL").
+		Where("berth_reservations.ship_id = ? AND berth_reservations.status = ?", shipID, model.ReservationConfirmed).
+		Where("berth_reservations.confirmed_at BETWEEN ? AND ?", arrivedAt, departedAt).
+		Order("berth_reservations.confirmed_at DESC").
+		First(&berth).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &berth, nil
+}
+
+func (r *invoice) InvoiceExists(ctx context.Context, checkoutLogID int) (bool, error) {
+	var res int64
+
+	err := r.Db.WithContext(ctx).Model(&model.Invoice{}).
+		Where("checkout_log_id = ? AND status <> ?", checkoutLogID, model.InvoiceVoid).
+		Count(&res).Error
+	if err != nil {
+		return false, err
+	}
+
+	return res > 0, nil
+}
+
+// StoreInvoice drafts the invoice of a stay, it reports false when another run already billed
+// the stay, idx_invoices_checkout_billed settles concurrent runs
+func (r *invoice) StoreInvoice(ctx context.Context, invoice *model.Invoice) (bool, error) {
+	result := r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "checkout_log_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status <> 'void' AND deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(invoice)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *invoice) InvoiceByID(ctx context.Context, ID int) (*model.Invoice, error) {
+	var invoice model.Invoice
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&invoice).Error; err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// IssueInvoice numbers a draft invoice, it reports false when the invoice is no longer a draft
+func (r *invoice) IssueInvoice(ctx context.Context, ID int, number string, issuedAt time.Time) (bool, error) {
+	result := r.Db.WithContext(ctx).Model(&model.Invoice{}).
+		Where("id = ? AND status = ?", ID, model.InvoiceDraft).
+		Updates(map[string]interface{}{
+			"number":    number,
+			"status":    model.InvoiceIssued,
+			"issued_at": issuedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// VoidInvoice cancels an invoice nothing was paid on yet
+func (r *invoice) VoidInvoice(ctx context.Context, ID int, reason string, voidedAt time.Time) (bool, error) {
+	result := r.Db.WithContext(ctx).Model(&model.Invoice{}).
+		Where("id = ? AND status IN ? AND paid_amount = 0", ID, []model.InvoiceStatus{model.InvoiceDraft, model.InvoiceIssued}).
+		Updates(map[string]interface{}{
+			"status":      model.InvoiceVoid,
+			"voided_at":   voidedAt,
+			"void_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RecordPayment stores a payment on an issued invoice and marks the invoice paid once it is
+// settled, it reports false when the invoice is not issued or the payment exceeds what is owed
+func (r *invoice) RecordPayment(ctx context.Context, payment *model.InvoicePayment) (bool, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	settled := gorm.Expr("paid_amount + ? >= amount", payment.Amount)
+	result := tx.Model(&model.Invoice{}).
+		Where("id = ? AND status = ? AND paid_amount + ? <= amount", payment.InvoiceID, model.InvoiceIssued, payment.Amount).
+		Updates(map[string]interface{}{
+			"paid_amount": gorm.Expr("paid_amount + ?", payment.Amount),
+			"status":      gorm.Expr("CASE WHEN ? THEN ? ELSE status END", settled, model.InvoicePaid),
+			"paid_at":     gorm.Expr("CASE WHEN ? THEN ? ELSE paid_at END", settled, payment.PaidAt),
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Create(payment).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *invoice) filterInvoice(query *gorm.DB, request dto.InvoiceListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("invoices.ship_id = ?", request.ShipID)
+	}
+
+	if request.Status != "" {
+		query = query.Where("invoices.status = ?", request.Status)
+	}
+
+	if request.Search != "" {
+		searchLower := "%" + strings.ToLower(request.Search) + "%"
+		query = query.Where("(lower(ships.name) LIKE ? OR lower(invoices.number) LIKE ?)", searchLower, searchLower)
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(invoices.departed_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *invoice) invoiceQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return query.Model(&model.Invoice{}).
+		Select("invoices.*, ships.name as ship_name, ships.responsible_name, " +
+			"COALESCE(ship_details.owner_name, '') as owner_name, COALESCE(berths.name, '') as berth_name").
+		Joins("JOIN ships ON invoices.ship_id = ships.id").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id").
+		Joins("LEFT JOIN berths ON invoices.berth_id = berths.id").
+		Scopes(tenant.Scope(ctx, "invoices.harbour_id"))
+}
+
+type invoiceRow struct {
+	model.Invoice
+	ShipName        string
+	ResponsibleName string
+	OwnerName       string
+	BerthName       string
+}
+
+func (r *invoice) InvoiceList(ctx context.Context, request dto.InvoiceListParam) ([]dto.InvoiceResponse, error) {
+	query := r.filterInvoice(r.invoiceQuery(ctx, r.Db.WithContext(ctx)), request)
+
+	var result []invoiceRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("invoices.departed_at DESC, invoices.id DESC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.InvoiceResponse
+	for _, e := range result {
+		res = append(res, invoiceResponse(e))
+	}
+
+	return res, nil
+}
+
+func (r *invoice) InvoiceCount(ctx context.Context, request dto.InvoiceListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Invoice{}).
+		Joins("JOIN ships ON invoices.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "invoices.harbour_id"))
+	query = r.filterInvoice(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *invoice) InvoiceDetail(ctx context.Context, ID int) (*dto.InvoiceResponse, error) {
+	var result invoiceRow
+
+	if err := r.invoiceQuery(ctx, r.Db.WithContext(ctx)).Where("invoices.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var payments []model.InvoicePayment
+	if err := r.Db.WithContext(ctx).Where("invoice_id = ?", ID).Order("paid_at ASC, id ASC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	res := invoiceResponse(result)
+	res.Payments = []dto.InvoicePaymentResponse{}
+	for _, e := range payments {
+		res.Payments = append(res.Payments, dto.InvoicePaymentResponse{
+			ID:         e.ID,
+			Amount:     helper.FromMinorUnits(e.Amount),
+			Method:     e.Method,
+			Reference:  e.Reference,
+			PaidAt:     e.PaidAt.Format("2006-01-02 15:04:05"),
+			RecordedBy: e.RecordedBy,
+		})
+	}
+
+	return &res, nil
+}
+
+func invoiceResponse(e invoiceRow) dto.InvoiceResponse {
+	res := dto.InvoiceResponse{
+		ID:              e.ID,
+		Number:          e.Number,
+		HarbourID:       e.HarbourID,
+		ShipID:          e.ShipID,
+		ShipName:        e.ShipName,
+		ResponsibleName: e.ResponsibleName,
+		OwnerName:       e.OwnerName,
+		BerthID:         e.BerthID,
+		BerthName:       e.BerthName,
+		ZoneType:        e.ZoneType,
+		ArrivedAt:       e.ArrivedAt.Format("2006-01-02 15:04:05"),
+		DepartedAt:      e.DepartedAt.Format("2006-01-02 15:04:05"),
+		Days:            e.Days,
+		GT:              e.GT,
+		BaseFee:         helper.FromMinorUnits(e.BaseFee),
+		DailyRate:       helper.FromMinorUnits(e.DailyRate),
+		RatePerGT:       helper.FromMinorUnits(e.RatePerGT),
+		Amount:          helper.FromMinorUnits(e.Amount),
+		PaidAmount:      helper.FromMinorUnits(e.PaidAmount),
+		Status:          string(e.Status),
+		VoidReason:      e.VoidReason,
+		CreatedAt:       e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if e.Status != model.InvoiceVoid {
+		res.Outstanding = helper.FromMinorUnits(e.Amount - e.PaidAmount)
+	}
+
+	if e.IssuedAt != nil {
+		res.IssuedAt = e.IssuedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if e.PaidAt != nil {
+		res.PaidAt = e.PaidAt.Format("2006-01-02 15:04:05")
+	}
+
+	if e.VoidedAt != nil {
+		res.VoidedAt = e.VoidedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Landing interface {
+	StoreLanding(ctx context.Context, landing *model.Landing, catches []model.LandingCatch) error
+	UpdateLanding(ctx context.Context, landing model.Landing, catches []model.LandingCatch) error
+	LandingByID(ctx context.Context, ID int) (*model.Landing, error)
+	LandingByDockedLog(ctx context.Context, dockedLogID int) (*model.Landing, error)
+	LandingList(ctx context.Context, request dto.LandingListParam) ([]dto.LandingResponse, error)
+	LandingCount(ctx context.Context, request dto.LandingListParam) (int64, error)
+	LandingDetail(ctx context.Context, ID int) (*dto.LandingResponse, error)
+	AggregateByShip(ctx context.Context, request dto.LandingListParam) ([]dto.LandingShipAggregate, error)
+	AggregateBySpecies(ctx context.Context, request dto.LandingListParam) ([]dto.LandingSpeciesAggregate, error)
+	ExportLandings(ctx context.Context, request dto.LandingListParam, fn func(dto.LandingExportRow) error) error
+}
+
+type landing struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewLandingRepository(db *gorm.DB, redisClient *redis.Client) Landing {
+	return &landing{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// StoreLanding records the landing with its catches, it returns constants.LandingExists when
+// a landing of the docked log was stored in the meantime
+func (r *landing) StoreLanding(ctx context.Context, landing *model.Landing, catches []model.LandingCatch) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	created := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "ship_docked_log_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(landing)
+	if created.Error != nil {
+		tx.Rollback()
+		return created.Error
+	}
+
+	if created.RowsAffected == 0 {
+		tx.Rollback()
+		return constants.LandingExists
+	}
+
+	for i := range catches {
+		catches[i].LandingID = landing.ID
+	}
+
+	if err := tx.Create(&catches).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateLanding replaces the landing details and its catches
+func (r *landing) UpdateLanding(ctx context.Context, landing model.Landing, catches []model.LandingCatch) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.Landing{}).Where("id = ?", landing.ID).Updates(map[string]interface{}{
+		"fishing_area": landing.FishingArea,
+		"gear":         landing.Gear,
+		"source":       landing.Source,
+		"submitted_by": landing.SubmittedBy,
+		"landed_at":    landing.LandedAt,
+		"note":         landing.Note,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Where("landing_id = ?", landing.ID).Delete(&model.LandingCatch{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for i := range catches {
+		catches[i].LandingID = landing.ID
+	}
+
+	if err := tx.Create(&catches).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *landing) LandingByID(ctx context.Context, ID int) (*model.Landing, error) {
+	var landing model.Landing
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).First(&landing).Error; err != nil {
+		return nil, err
+	}
+
+	return &landing, nil
+}
+
+func (r *landing) LandingByDockedLog(ctx context.Context, dockedLogID int) (*model.Landing, error) {
+	var landing model.Landing
+
+	if err := r.Db.WithContext(ctx).Where("ship_docked_log_id = ?", dockedLogID).First(&landing).Error; err != nil {
+		return nil, err
+	}
+
+	return &landing, nil
+}
+
+func (r *landing) filterLanding(query *gorm.DB, request dto.LandingListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("landings.ship_id = ?", request.ShipID)
+	}
+
+	if request.Gear != "" {
+		query = query.Where("landings.gear = ?", request.Gear)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(landings.landed_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+// filterSpecies keeps the landings holding the species, the catch rows themselves are not filtered
+func (r *landing) filterSpecies(query *gorm.DB, request dto.LandingListParam) *gorm.DB {
+	if request.Species != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM landing_catches c WHERE c.landing_id = landings.id "+
+			"AND c.species = ? AND c.deleted_at IS NULL)", strings.ToLower(strings.TrimSpace(request.Species)))
+	}
+
+	return query
+}
+
+func (r *landing) landingQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	totalWeight := "(SELECT COALESCE(SUM(landing_catches.weight_kg), 0) FROM landing_catches WHERE landing_catches.landing_id = landings.id " +
+		"AND landing_catches.deleted_at IS NULL) as total_weight_kg"
+
+	return query.Model(&model.Landing{}).
+		Select("landings.*, ships.name as ship_name, " + totalWeight).
+		Joins("JOIN ships ON landings.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+}
+
+type landingRow struct {
+	model.Landing
+	ShipName      string
+	TotalWeightKg float64
+}
+
+func (r *landing) LandingList(ctx context.Context, request dto.LandingListParam) ([]dto.LandingResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := r.filterSpecies(r.filterLanding(r.landingQuery(ctx, tx), request), request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("landings.landed_at DESC, landings.id DESC")
+
+	var result []landingRow
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var landings []dto.LandingResponse
+	for _, e := range result {
+		landings = append(landings, landingResponse(e))
+	}
+
+	return landings, nil
+}
+
+func (r *landing) LandingCount(ctx context.Context, request dto.LandingListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Landing{}).
+		Joins("JOIN ships ON landings.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterSpecies(r.filterLanding(query, request), request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *landing) LandingDetail(ctx context.Context, ID int) (*dto.LandingResponse, error) {
+	var result landingRow
+
+	if err := r.landingQuery(ctx, r.Db.WithContext(ctx)).Where("landings.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var catches []model.LandingCatch
+	if err := r.Db.WithContext(ctx).Where("landing_id = ?", ID).Order("weight_kg DESC, id ASC").Find(&catches).Error; err != nil {
+		return nil, err
+	}
+
+	res := landingResponse(result)
+	for _, e := range catches {
+		res.Catches = append(res.Catches, dto.LandingCatchResponse{
+			ID:       e.ID,
+			Species:  e.Species,
+			WeightKg: e.WeightKg,
+		})
+	}
+
+	return &res, nil
+}
+
+// catchQuery joins every landing with its catch rows, a species filter keeps only the rows of that species
+func (r *landing) catchQuery(ctx context.Context, request dto.LandingListParam) *gorm.DB {
+	query := r.Db.WithContext(ctx).Model(&model.Landing{}).
+		Joins("JOIN ships ON landings.ship_id = ships.id").
+		Joins("JOIN landing_catches ON landing_catches.landing_id = landings.id AND landing_catches.deleted_at IS NULL").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	if request.Species != "" {
+		query = query.Where("landing_catches.species = ?", strings.ToLower(strings.TrimSpace(request.Species)))
+	}
+
+	return r.filterLanding(query, request)
+}
+
+func (r *landing) AggregateByShip(ctx context.Context, request dto.LandingListParam) ([]dto.LandingShipAggregate, error) {
+	res := []dto.LandingShipAggregate{}
+
+	err := r.catchQuery(ctx, request).
+		Select("ships.id as ship_id, ships.name as ship_name, COUNT(DISTINCT landings.id) as landings, " +
+			"COUNT(DISTINCT landing_catches.species) as species, SUM(landing_catches.weight_kg) as total_weight_kg").
+		Group("ships.id, ships.name").
+		Order("total_weight_kg DESC, ships.id ASC").
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *landing) AggregateBySpecies(ctx context.Context, request dto.LandingListParam) ([]dto.LandingSpeciesAggregate, error) {
+	res := []dto.LandingSpeciesAggregate{}
+
+	err := r.catchQuery(ctx, request).
+		Select("landing_catches.species, COUNT(DISTINCT landings.id) as landings, " +
+			"COUNT(DISTINCT landings.ship_id) as ships, SUM(landing_catches.weight_kg) as total_weight_kg").
+		Group("landing_catches.species").
+		Order("total_weight_kg DESC, landing_catches.species ASC").
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ExportLandings walks every catch row of the filtered landings through a database cursor,
+// offset and limit of the request are ignored
+func (r *landing) ExportLandings(ctx context.Context, request dto.LandingListParam, fn func(dto.LandingExportRow) error) error {
+	query := r.catchQuery(ctx, request).
+		Select("landings.id as landing_id, landings.landed_at, ships.id as ship_id, ships.name as ship_name, " +
+			"COALESCE(ship_details.siup, '') as siup, COALESCE(ship_details.bkp, '') as bkp, landings.fishing_area, " +
+			"landings.gear, landing_catches.species, landing_catches.weight_kg, landings.source").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id")
+
+	rows, err := query.Order("landings.landed_at DESC, landings.id DESC, landing_catches.id ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dto.LandingExportRow
+		if err := r.Db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func landingResponse(e landingRow) dto.LandingResponse {
+	return dto.LandingResponse{
+		ID:            e.ID,
+		DockedLogID:   e.ShipDockedLogID,
+		ShipID:        e.ShipID,
+		ShipName:      e.ShipName,
+		FishingArea:   e.FishingArea,
+		Gear:          string(e.Gear),
+		Source:        string(e.Source),
+		SubmittedBy:   e.SubmittedBy,
+		TotalWeightKg: e.TotalWeightKg,
+		LandedAt:      e.LandedAt.Format("2006-01-02 15:04:05"),
+		Note:          e.Note,
+	}
+}
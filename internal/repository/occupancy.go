@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Occupancy interface {
+	OpeningLogs(ctx context.Context, start time.Time) ([]model.ShipDockedLog, error)
+	DockedLogsBetween(ctx context.Context, start time.Time, end time.Time) ([]model.ShipDockedLog, error)
+	CurrentOccupancy(ctx context.Context) (int64, error)
+	ThresholdList(ctx context.Context, activeOnly bool) ([]model.OccupancyThreshold, error)
+	ThresholdByID(ctx context.Context, ID int) (*model.OccupancyThreshold, error)
+	StoreThreshold(ctx context.Context, threshold *model.OccupancyThreshold) error
+	UpdateThreshold(ctx context.Context, threshold model.OccupancyThreshold) error
+	OpenAlerts(ctx context.Context, harbourID int) ([]model.OccupancyAlert, error)
+	StoreAlert(ctx context.Context, alert *model.OccupancyAlert) (bool, error)
+	RaiseAlertPeak(ctx context.Context, ID int, occupancy int) error
+	ResolveAlert(ctx context.Context, ID int, resolvedAt time.Time) error
+	AlertList(ctx context.Context, request dto.OccupancyAlertListParam) ([]dto.OccupancyAlertResponse, error)
+	AlertCount(ctx context.Context, request dto.OccupancyAlertListParam) (int64, error)
+}
+
+type occupancy struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewOccupancyRepository(db *gorm.DB, redisClient *redis.Client) Occupancy {
+	return &occupancy{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// OpeningLogs returns the last docked log of every ship before start, it tells which ships
+// were already inside when the range opens
+func (r *occupancy) OpeningLogs(ctx context.Context, start time.Time) ([]model.ShipDockedLog, error) {
+	var res []model.ShipDockedLog
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Select("DISTINCT ON (ship_docked_logs.ship_id) ship_docked_logs.*").
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_docked_logs.created_at < ?", start).
+		Order("ship_docked_logs.ship_id, ship_docked_logs.created_at DESC, ship_docked_logs.id DESC").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *occupancy) DockedLogsBetween(ctx context.Context, start time.Time, end time.Time) ([]model.ShipDockedLog, error) {
+	var res []model.ShipDockedLog
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Select("ship_docked_logs.*").
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_docked_logs.created_at >= ? AND ship_docked_logs.created_at < ?", start, end).
+		Order("ship_docked_logs.ship_id, ship_docked_logs.created_at ASC, ship_docked_logs.id ASC").
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CurrentOccupancy counts the ships whose last docked log is a checkin
+func (r *occupancy) CurrentOccupancy(ctx context.Context) (int64, error) {
+	lastStatus := "(SELECT ship_docked_logs.status FROM ship_docked_logs WHERE ship_docked_logs.ship_id = ships.id " +
+		"AND ship_docked_logs.deleted_at IS NULL ORDER BY ship_docked_logs.id DESC LIMIT 1)"
+
+	var res int64
+	err := r.Db.WithContext(ctx).Model(&model.Ship{}).
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where(lastStatus+" = ?", model.Checkin).
+		Count(&res).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *occupancy) ThresholdList(ctx context.Context, activeOnly bool) ([]model.OccupancyThreshold, error) {
+	query := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id"))
+	if activeOnly {
+		query = query.Where("is_active = 1")
+	}
+
+	var res []model.OccupancyThreshold
+	if err := query.Order("ships ASC, id ASC").Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *occupancy) ThresholdByID(ctx context.Context, ID int) (*model.OccupancyThreshold, error) {
+	var threshold model.OccupancyThreshold
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&threshold).Error; err != nil {
+		return nil, err
+	}
+
+	return &threshold, nil
+}
+
+func (r *occupancy) StoreThreshold(ctx context.Context, threshold *model.OccupancyThreshold) error {
+	return r.Db.WithContext(ctx).Create(threshold).Error
+}
+
+func (r *occupancy) UpdateThreshold(ctx context.Context, threshold model.OccupancyThreshold) error {
+	result := r.Db.WithContext(ctx).Model(&model.OccupancyThreshold{}).Where("id = ?", threshold.ID).Updates(map[string]interface{}{
+		"name":      threshold.Name,
+		"ships":     threshold.Ships,
+		"is_active": threshold.IsActive,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *occupancy) OpenAlerts(ctx context.Context, harbourID int) ([]model.OccupancyAlert, error) {
+	var res []model.OccupancyAlert
+
+	err := r.Db.WithContext(ctx).
+		Where("harbour_id = ? AND resolved_at IS NULL", harbourID).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// StoreAlert raises an alert, it reports false when a concurrent check already raised the one of the
+// threshold, idx_occupancy_alerts_open keeps a single open alert per threshold
+func (r *occupancy) StoreAlert(ctx context.Context, alert *model.OccupancyAlert) (bool, error) {
+	result := r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "harbour_id"}, {Name: "threshold_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "resolved_at IS NULL AND deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RaiseAlertPeak keeps the highest occupancy seen while the alert is open
+func (r *occupancy) RaiseAlertPeak(ctx context.Context, ID int, occupancy int) error {
+	return r.Db.WithContext(ctx).Model(&model.OccupancyAlert{}).
+		Where("id = ? AND peak_occupancy < ?", ID, occupancy).
+		Update("peak_occupancy", occupancy).Error
+}
+
+func (r *occupancy) ResolveAlert(ctx context.Context, ID int, resolvedAt time.Time) error {
+	return r.Db.WithContext(ctx).Model(&model.OccupancyAlert{}).
+		Where("id = ? AND resolved_at IS NULL", ID).
+		Update("resolved_at", resolvedAt).Error
+}
+
+func (r *occupancy) filterAlert(query *gorm.DB, request dto.OccupancyAlertListParam) *gorm.DB {
+	switch request.Status {
+	case "open":
+		query = query.Where("resolved_at IS NULL")
+	case "resolved":
+		query = query.Where("resolved_at IS NOT NULL")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(raised_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *occupancy) AlertList(ctx context.Context, request dto.OccupancyAlertListParam) ([]dto.OccupancyAlertResponse, error) {
+	query := r.Db.WithContext(ctx).Model(&model.OccupancyAlert{}).Scopes(tenant.Scope(ctx, "harbour_id"))
+	query = r.filterAlert(query, request)
+
+	var result []model.OccupancyAlert
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("raised_at DESC, id DESC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.OccupancyAlertResponse
+	for _, e := range result {
+		alert := dto.OccupancyAlertResponse{
+			ID:            e.ID,
+			ThresholdID:   e.ThresholdID,
+			ThresholdName: e.ThresholdName,
+			Threshold:     e.Threshold,
+			Occupancy:     e.Occupancy,
+			PeakOccupancy: e.PeakOccupancy,
+			Status:        "open",
+			RaisedAt:      e.RaisedAt.Format("2006-01-02 15:04:05"),
+		}
+
+		if e.ResolvedAt != nil {
+			alert.Status = "resolved"
+			alert.ResolvedAt = e.ResolvedAt.Format("2006-01-02 15:04:05")
+		}
+
+		res = append(res, alert)
+	}
+
+	return res, nil
+}
+
+func (r *occupancy) AlertCount(ctx context.Context, request dto.OccupancyAlertListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.OccupancyAlert{}).Scopes(tenant.Scope(ctx, "harbour_id"))
+	query = r.filterAlert(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
@@ -9,6 +9,7 @@ import (
 	"owlharbour-api/internal/model"
 	"owlharbour-api/pkg/constants"
 	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -22,6 +23,7 @@ type PairingRequest interface {
 	UpdatedPairingStatus(ctx context.Context, id int, status string) (*dto.PairingRequestResponse, error)
 	PairingDetailByUsername(ctx context.Context, username string) (*dto.DetailPairingResponse, error)
 	PairingRequestCount(ctx context.Context, status []string) (int64, error)
+	PairingRequestListCount(ctx context.Context, request dto.PairingListParam) (int64, error)
 }
 
 type pairingRequest struct {
@@ -48,7 +50,7 @@ func (r *pairingRequest) PairingRequestCount(ctx context.Context, status []strin
 	hash := sha1.Sum(paramJSON)
 	uniqueString := fmt.Sprintf("%x", hash)
 
-	cacheKey := "pairing_pending_count-" + uniqueString
+	cacheKey := tenant.CacheKey(ctx, "pairing_pending_count-"+uniqueString)
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -62,7 +64,7 @@ func (r *pairingRequest) PairingRequestCount(ctx context.Context, status []strin
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.PairingRequest{})
+	query := tx.Model(&model.PairingRequest{}).Scopes(tenant.Scope(ctx, "harbour_id"))
 
 	query = query.Where("status in (?)", status)
 
@@ -124,6 +126,7 @@ func (r *pairingRequest) StorePairingRequests(ctx context.Context, request dto.P
 		DeviceID:        request.DeviceID,
 		FirebaseToken:   request.FirebaseToken,
 		Status:          "pending",
+		HarbourID:       request.HarbourID,
 	}
 
 	if err := tx.Create(&pairingModel).Error; err != nil {
@@ -157,7 +160,7 @@ func (r *pairingRequest) PairingRequestList(ctx context.Context, request dto.Pai
 	hash := sha1.Sum(paramJSON)
 	uniqueString := fmt.Sprintf("%x", hash)
 
-	cacheKey := "pairing_list-" + uniqueString
+	cacheKey := tenant.CacheKey(ctx, "pairing_list-"+uniqueString)
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -171,16 +174,8 @@ func (r *pairingRequest) PairingRequestList(ctx context.Context, request dto.Pai
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.PairingRequest{})
-
-	if request.Status != nil && request.Status[0] != "" && len(request.Status) > 0 {
-		query = query.Where("status IN (?)", request.Status)
-	}
-
-	if request.Search != "" {
-		query = query.Where("name LIKE ? OR device_id LIKE ? OR phone LIKE ?", "%"+request.Search+"%", "%"+request.Search+"%", "%"+request.Search+"%")
-	}
-
+	query := tx.Model(&model.PairingRequest{}).Scopes(tenant.Scope(ctx, "harbour_id"))
+	query = r.filterPairingRequest(query, request)
 	query = query.Limit(request.Limit).Offset(request.Offset).Order("created_at DESC")
 
 	var pairingRequest []model.PairingRequest
@@ -223,11 +218,69 @@ func (r *pairingRequest) PairingRequestList(ctx context.Context, request dto.Pai
 	return pairingList, nil
 }
 
+func (r *pairingRequest) filterPairingRequest(query *gorm.DB, request dto.PairingListParam) *gorm.DB {
+	if request.Status != nil && request.Status[0] != "" && len(request.Status) > 0 {
+		query = query.Where("status IN (?)", request.Status)
+	}
+
+	if request.Search != "" {
+		query = query.Where("name LIKE ? OR device_id LIKE ? OR phone LIKE ?", "%"+request.Search+"%", "%"+request.Search+"%", "%"+request.Search+"%")
+	}
+
+	return query
+}
+
+// PairingRequestListCount counts the pairing requests matching the list filters, paging is ignored
+func (r *pairingRequest) PairingRequestListCount(ctx context.Context, request dto.PairingListParam) (int64, error) {
+	request.Offset, request.Limit = 0, 0
+
+	paramJSON, err := json.Marshal(request)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 0, err
+	}
+
+	hash := sha1.Sum(paramJSON)
+	uniqueString := fmt.Sprintf("%x", hash)
+
+	// shares the pairing_list- prefix so it is cleared together with the list cache
+	cacheKey := tenant.CacheKey(ctx, "pairing_list-count-"+uniqueString)
+
+	if r.CacheEnabled {
+		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
+		if err == nil {
+			var cachedInfo int64
+			if err := json.Unmarshal([]byte(cachedData), &cachedInfo); err == nil {
+				return cachedInfo, nil
+			}
+		}
+	}
+
+	query := r.Db.WithContext(ctx).Model(&model.PairingRequest{}).Scopes(tenant.Scope(ctx, "harbour_id"))
+	query = r.filterPairingRequest(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	if r.CacheEnabled {
+		jsonData, err := json.Marshal(res)
+		if err == nil {
+			r.RedisClient.Set(ctx, cacheKey, jsonData, time.Hour)
+		} else {
+			fmt.Println("Error marshalling data for cache:", err)
+		}
+	}
+
+	return res, nil
+}
+
 func (r *pairingRequest) UpdatedPairingStatus(ctx context.Context, id int, status string) (*dto.PairingRequestResponse, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	var pairing model.PairingRequest
-	err := tx.First(&pairing, id).Error
+	err := tx.Scopes(tenant.Scope(ctx, "harbour_id")).First(&pairing, id).Error
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -258,6 +311,7 @@ func (r *pairingRequest) UpdatedPairingStatus(ctx context.Context, id int, statu
 		DeviceID:        pairing.DeviceID,
 		FirebaseToken:   pairing.FirebaseToken,
 		Status:          status,
+		HarbourID:       pairing.HarbourID,
 		CreatedAt:       pairing.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
 
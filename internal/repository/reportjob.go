@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type ReportJob interface {
+	StoreReportJob(ctx context.Context, job *model.ReportJob) error
+	UpdateReportJob(ctx context.Context, ID int, fields map[string]interface{}) error
+	DeleteReportJob(ctx context.Context, ID int) error
+	ReportJobByID(ctx context.Context, ID int) (*model.ReportJob, error)
+	ReportJobDetail(ctx context.Context, ID int) (*dto.ReportJobResponse, error)
+	ReportJobList(ctx context.Context, request dto.ReportJobListParam) ([]dto.ReportJobResponse, error)
+	ReportJobCount(ctx context.Context, request dto.ReportJobListParam) (int64, error)
+	DueReportJobs(ctx context.Context, now time.Time) ([]model.ReportJob, error)
+	ClaimReportJob(ctx context.Context, job model.ReportJob, nextRunAt time.Time, run *model.ReportJobRun) (bool, error)
+	DueRetryRuns(ctx context.Context, now time.Time) ([]model.ReportJobRun, error)
+	ClaimRetryRun(ctx context.Context, run *model.ReportJobRun) (bool, error)
+	FinishReportJobRun(ctx context.Context, ID int, fields map[string]interface{}) error
+	ReportJobRunList(ctx context.Context, request dto.ReportJobRunListParam) ([]dto.ReportJobRunResponse, error)
+	ReportJobRunCount(ctx context.Context, request dto.ReportJobRunListParam) (int64, error)
+}
+
+type reportJob struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewReportJobRepository(db *gorm.DB, redisClient *redis.Client) ReportJob {
+	return &reportJob{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *reportJob) StoreReportJob(ctx context.Context, job *model.ReportJob) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Create(job).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportJob) UpdateReportJob(ctx context.Context, ID int, fields map[string]interface{}) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.ReportJob{}).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).Updates(fields)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportJob) DeleteReportJob(ctx context.Context, ID int) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).Delete(&model.ReportJob{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportJob) ReportJobByID(ctx context.Context, ID int) (*model.ReportJob, error) {
+	var job model.ReportJob
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&job).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *reportJob) ReportJobDetail(ctx context.Context, ID int) (*dto.ReportJobResponse, error) {
+	job, err := r.ReportJobByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := reportJobResponse(*job)
+
+	var lastRun model.ReportJobRun
+	if err := r.Db.WithContext(ctx).Where("report_job_id = ?", ID).Order("id DESC").First(&lastRun).Error; err == nil {
+		res.LastStatus = string(lastRun.Status)
+	}
+
+	return &res, nil
+}
+
+func (r *reportJob) filterReportJob(query *gorm.DB, request dto.ReportJobListParam) *gorm.DB {
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(report_jobs.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.Report != "" {
+		query = query.Where("report_jobs.report = ?", request.Report)
+	}
+
+	return query
+}
+
+func (r *reportJob) ReportJobList(ctx context.Context, request dto.ReportJobListParam) ([]dto.ReportJobResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	// the status of the latest run is shown next to every job
+	lastStatus := "(SELECT status FROM report_job_runs WHERE report_job_runs.report_job_id = report_jobs.id " +
+		"AND report_job_runs.deleted_at IS NULL ORDER BY report_job_runs.id DESC LIMIT 1) as last_status"
+
+	query := tx.Model(&model.ReportJob{}).Select("report_jobs.*, " + lastStatus).Scopes(tenant.Scope(ctx, "report_jobs.harbour_id"))
+	query = r.filterReportJob(query, request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("report_jobs.created_at DESC")
+
+	var result []struct {
+		model.ReportJob
+		LastStatus *string
+	}
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var jobs []dto.ReportJobResponse
+	for _, e := range result {
+		job := reportJobResponse(e.ReportJob)
+		if e.LastStatus != nil {
+			job.LastStatus = *e.LastStatus
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (r *reportJob) ReportJobCount(ctx context.Context, request dto.ReportJobListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ReportJob{}).Scopes(tenant.Scope(ctx, "harbour_id"))
+	query = r.filterReportJob(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *reportJob) DueReportJobs(ctx context.Context, now time.Time) ([]model.ReportJob, error) {
+	var jobs []model.ReportJob
+
+	err := r.Db.WithContext(ctx).
+		Where("is_active = ? AND next_run_at <= ?", 1, now).
+		Order("next_run_at ASC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// ClaimReportJob moves the job to its next run and opens the run record. The update only matches
+// while next_run_at is unchanged, so a job is run once even with several scheduler instances.
+func (r *reportJob) ClaimReportJob(ctx context.Context, job model.ReportJob, nextRunAt time.Time, run *model.ReportJobRun) (bool, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.ReportJob{}).
+		Where("id = ? AND next_run_at = ?", job.ID, job.NextRunAt).
+		Updates(map[string]interface{}{
+			"next_run_at": nextRunAt,
+			"last_run_at": time.Now(),
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Create(run).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *reportJob) DueRetryRuns(ctx context.Context, now time.Time) ([]model.ReportJobRun, error) {
+	var runs []model.ReportJobRun
+
+	err := r.Db.WithContext(ctx).
+		Where("status = ? AND next_retry_at <= ?", model.JobRunRetrying, now).
+		Order("next_retry_at ASC").
+		Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// ClaimRetryRun marks a waiting run as running again, false when another scheduler took it first
+func (r *reportJob) ClaimRetryRun(ctx context.Context, run *model.ReportJobRun) (bool, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.ReportJobRun{}).
+		Where("id = ? AND status = ?", run.ID, model.JobRunRetrying).
+		Updates(map[string]interface{}{
+			"status":        model.JobRunRunning,
+			"attempt":       gorm.Expr("attempt + 1"),
+			"next_retry_at": nil,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	run.Status = model.JobRunRunning
+	run.Attempt++
+
+	return true, nil
+}
+
+func (r *reportJob) FinishReportJobRun(ctx context.Context, ID int, fields map[string]interface{}) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Model(&model.ReportJobRun{}).Where("id = ?", ID).Updates(fields).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportJob) filterReportJobRun(query *gorm.DB, request dto.ReportJobRunListParam) *gorm.DB {
+	if request.JobID != 0 {
+		query = query.Where("report_job_id = ?", request.JobID)
+	}
+
+	if request.Status != nil && len(request.Status) > 0 && request.Status[0] != "" {
+		query = query.Where("status IN (?)", request.Status)
+	}
+
+	return query
+}
+
+// runScope limits runs to the jobs of the harbours of ctx
+func (r *reportJob) runScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := tenant.Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where("report_job_id IN (SELECT id FROM report_jobs WHERE harbour_id IN ?)", ids)
+	}
+}
+
+func (r *reportJob) ReportJobRunList(ctx context.Context, request dto.ReportJobRunListParam) ([]dto.ReportJobRunResponse, error) {
+	var result []model.ReportJobRun
+
+	query := r.Db.WithContext(ctx).Model(&model.ReportJobRun{}).Scopes(r.runScope(ctx))
+	query = r.filterReportJobRun(query, request)
+
+	if err := query.Limit(request.Limit).Offset(request.Offset).Order("id DESC").Find(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var runs []dto.ReportJobRunResponse
+	for _, e := range result {
+		run := dto.ReportJobRunResponse{
+			ID:          e.ID,
+			JobID:       e.ReportJobID,
+			Status:      string(e.Status),
+			Attempt:     e.Attempt,
+			PeriodStart: e.PeriodStart,
+			PeriodEnd:   e.PeriodEnd,
+			ScheduledAt: e.ScheduledAt.Format("2006-01-02 15:04:05"),
+			Error:       e.Error,
+		}
+		if e.FinishedAt != nil {
+			run.FinishedAt = e.FinishedAt.Format("2006-01-02 15:04:05")
+		}
+		if e.NextRetryAt != nil {
+			run.NextRetryAt = e.NextRetryAt.Format("2006-01-02 15:04:05")
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+func (r *reportJob) ReportJobRunCount(ctx context.Context, request dto.ReportJobRunListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ReportJobRun{}).Scopes(r.runScope(ctx))
+	query = r.filterReportJobRun(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func reportJobResponse(job model.ReportJob) dto.ReportJobResponse {
+	res := dto.ReportJobResponse{
+		ID:         job.ID,
+		Name:       job.Name,
+		Report:     string(job.Report),
+		Frequency:  string(job.Frequency),
+		Format:     job.Format,
+		Recipients: strings.Split(job.Recipients, ","),
+		RunTime:    job.RunTime,
+		Weekday:    job.Weekday,
+		MonthDay:   job.MonthDay,
+		MaxRetry:   job.MaxRetry,
+		IsActive:   job.IsActive == 1,
+		NextRunAt:  job.NextRunAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if job.LastRunAt != nil {
+		res.LastRunAt = job.LastRunAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
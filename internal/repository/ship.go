@@ -8,6 +8,8 @@ import (
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/model"
 	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/pagination"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"strings"
 	"time"
@@ -17,26 +19,46 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// reportShipDetailColumns are the ship_details columns attached to every exported report row
+const reportShipDetailColumns = "COALESCE(ship_details.type, '') as type, COALESCE(ship_details.dimension, '') as dimension, " +
+	"COALESCE(ship_details.harbour, '') as harbour, COALESCE(ship_details.siup, '') as siup, COALESCE(ship_details.bkp, '') as bkp, " +
+	"COALESCE(ship_details.selar_mark, '') as selar_mark, COALESCE(ship_details.gt, '') as gt, COALESCE(ship_details.owner_name, '') as owner_name"
+
 type Ship interface {
 	StoreNewShip(ctx context.Context, request dto.PairingToNewShip) error
 	ShipList(ctx context.Context, request dto.ShipListParam) ([]dto.ShipResponse, error)
+	ShipListCount(ctx context.Context, request dto.ShipListParam) (int64, error)
 	ShipByDevice(ctx context.Context, DeviceID string) (*dto.ShipMobileDetailResponse, error)
 	ShipByAuth(ctx context.Context, authUser model.User) (*dto.ShipMobileDetailResponse, error)
 	ShipByID(ctx context.Context, ShipID int) (*model.Ship, error)
 	GetLastDockedLog(ctx context.Context, ShipID int) (*dto.ShipDockedLog, error)
-	StoreDockedLog(ctx context.Context, request dto.ShipDockedLogStore) error
-	StoreLocationLog(ctx context.Context, request dto.ShipLocationLogStore) error
+	StoreDockedLog(ctx context.Context, request dto.ShipDockedLogStore) (int, error)
+	StoreLocationLog(ctx context.Context, request dto.ShipLocationLogStore) (int, error)
+	GetLastLocationLog(ctx context.Context, ShipID int) (*model.ShipLocationLog, error)
+	FrozenPositionSince(ctx context.Context, ShipID int, lat string, long string) (*time.Time, error)
+	RecordReportingStat(ctx context.Context, request dto.ShipReportingStatStore) error
+	ShipReportingCompliance(ctx context.Context, request dto.ShipReportingComplianceParam) ([]dto.ShipReportingComplianceResponse, error)
 	UpdateShip(ctx context.Context, request model.Ship) error
 	UpdateShipDetail(ctx context.Context, request dto.ShipAddonDetailRequest) error
-	ShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.DockLogsShip, error)
-	ShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.LocationLogsShip, error)
+	ShipImportIndex(ctx context.Context) ([]dto.ShipImportIndex, error)
+	ImportShip(ctx context.Context, row dto.ShipImportRow) (int, error)
+	ShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.DockLogsShip, string, error)
+	ShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.LocationLogsShip, string, error)
+	CountShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) (int64, error)
+	CountShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) (int64, error)
+	ShipTrackLogs(ctx context.Context, ShipID int, start time.Time, end time.Time) ([]model.ShipLocationLog, error)
 	ShipAddonDetail(ctx context.Context, ShipID int) (dto.ShipAddonDetailResponse, error)
 	CountShip(ctx context.Context) (int64, error)
 	CountStatistic(ctx context.Context) ([]int64, error)
+	StatisticSeries(ctx context.Context, request dto.DashboardSeriesFilter) ([]dto.DashboardSeriesRow, error)
 	LastUpdated(ctx context.Context) (time.Time, error)
 	ShipInBatch(ctx context.Context, start int, end int) (*[]model.Ship, bool, error)
-	ReportShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, error)
-	ReportShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, error)
+	ReportShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, string, error)
+	ReportShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, string, error)
+	ReportShipDockingCount(ctx context.Context, request dto.ReportShipDockedParam) (int64, error)
+	ReportShipFraudCount(ctx context.Context, request dto.ReportShipLocationParam) (int64, error)
+	ExportShipDocking(ctx context.Context, request dto.ReportShipDockedParam, fn func(dto.ReportShipDockingExportRow) error) error
+	ExportShipFraud(ctx context.Context, request dto.ReportShipLocationParam, fn func(dto.ReportShipFraudExportRow) error) error
 	CountShipByTerrain(ctx context.Context, onGround int) (int64, error)
 	CountShipByStatus(ctx context.Context, startDate string, endDate string, status string) (int64, error)
 	CountShipFraud(ctx context.Context, startDate string, endDate string) (int64, error)
@@ -45,6 +67,7 @@ type Ship interface {
 	UpdateShipDeviceID(ctx context.Context, deviceID string, user_id int) error
 	UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupRequest, id int, data model.ShipDockedLog) error
 	NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipParam) ([]dto.NeedCheckupShipResponse, error)
+	NeedCheckupShipCount(ctx context.Context, request dto.NeedCheckupShipParam) (int64, error)
 	LastestDockedShip(ctx context.Context, limit int) ([]dto.DashboardLastDockedShipResponse, error)
 }
 
@@ -67,7 +90,8 @@ func (r *ship) LastestDockedShip(ctx context.Context, limit int) ([]dto.Dashboar
 
 	query := tx.Model(&model.ShipDockedLog{}).
 		Select("ship_docked_logs.*, ships.name as ship_name, ships.id as ship_id, ships.responsible_name as responsible_name, ships.phone as phone").
-		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id")
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
 	limitParam := 10
 	if limit != 0 {
@@ -114,22 +138,24 @@ func (r *ship) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipP
 	tx := r.Db.WithContext(ctx).Begin()
 
 	query := tx.Model(&model.ShipDockedLog{}).
-		Select("ship_docked_logs.*, ships.name as ship_name, ships.id as ship_id").
-		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id")
-
-	if request.Search != "" {
-		searchLower := strings.ToLower(request.Search)
-		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
-	}
-
-	query = query.Where("(is_inspected = ? OR is_reported = ?) and ship_docked_logs.status = ?", 0, 0, "checkin").
+		Select("ship_docked_logs.*, ships.name as ship_name, ships.id as ship_id, " +
+			"inspection_tasks.id as task_id, inspection_tasks.due_at, users.name as assignee_name").
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Joins("LEFT JOIN inspection_tasks ON inspection_tasks.ship_docked_log_id = ship_docked_logs.id AND inspection_tasks.deleted_at IS NULL").
+		Joins("LEFT JOIN users ON inspection_tasks.assignee_id = users.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterNeedCheckup(query, request).
 		Limit(request.Limit).
 		Offset(request.Offset).
 		Order("ship_docked_logs.created_at DESC")
 
 	var result []struct {
 		model.ShipDockedLog
-		ShipName string `json:"ship_name"`
+		ShipName     string `json:"ship_name"`
+		TaskID       *int
+		DueAt        *time.Time
+		AssigneeName *string
 	}
 
 	if err := query.Find(&result).Error; err != nil {
@@ -137,9 +163,11 @@ func (r *ship) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipP
 		return nil, err
 	}
 
+	now := time.Now()
+
 	var shipDock []dto.NeedCheckupShipResponse
 	for _, e := range result {
-		shipDock = append(shipDock, dto.NeedCheckupShipResponse{
+		checkup := dto.NeedCheckupShipResponse{
 			LogID:       e.ID,
 			ShipName:    e.ShipName,
 			Lat:         e.Lat,
@@ -147,7 +175,22 @@ func (r *ship) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipP
 			CheckinDate: e.CreatedAt.Format("2006-01-02 15:04:05"),
 			IsInspected: e.IsInspected,
 			IsReported:  e.IsReported,
-		})
+		}
+
+		if e.TaskID != nil {
+			checkup.TaskID = *e.TaskID
+		}
+
+		if e.DueAt != nil {
+			checkup.DueAt = e.DueAt.Format("2006-01-02 15:04:05")
+			checkup.IsOverdue = e.IsInspected == 0 && e.DueAt.Before(now)
+		}
+
+		if e.AssigneeName != nil {
+			checkup.AssigneeName = *e.AssigneeName
+		}
+
+		shipDock = append(shipDock, checkup)
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -158,6 +201,34 @@ func (r *ship) NeedCheckupShip(ctx context.Context, request dto.NeedCheckupShipP
 	return shipDock, nil
 }
 
+func (r *ship) filterNeedCheckup(query *gorm.DB, request dto.NeedCheckupShipParam) *gorm.DB {
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.AssigneeID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM inspection_tasks t WHERE t.ship_docked_log_id = ship_docked_logs.id "+
+			"AND t.assignee_id = ? AND t.deleted_at IS NULL)", request.AssigneeID)
+	}
+
+	return query.Where("(is_inspected = ? OR is_reported = ?) and ship_docked_logs.status = ?", 0, 0, "checkin")
+}
+
+func (r *ship) NeedCheckupShipCount(ctx context.Context, request dto.NeedCheckupShipParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterNeedCheckup(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
 func (r *ship) UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupRequest, id int, data model.ShipDockedLog) error {
 	tx := r.Db.WithContext(ctx).Begin()
 
@@ -182,12 +253,25 @@ func (r *ship) UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupReq
 		return err
 	}
 
+	if isInspectedInt == 1 {
+		err := tx.Model(&model.InspectionTask{}).
+			Where("ship_docked_log_id = ? AND status = ?", id, model.TaskOpen).
+			Updates(map[string]interface{}{
+				"status":       model.TaskDone,
+				"completed_at": time.Now(),
+			}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	cacheKey := []string{"ship_list-*", "ship_last_update"}
+	cacheKey := []string{"ship_list-*", "ship_last_update*"}
 
 	for i := range cacheKey {
 		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
@@ -201,7 +285,7 @@ func (r *ship) UpdateShipCheckup(ctx context.Context, request dto.ShipCheckupReq
 func (r *ship) FindOneDockedLog(ctx context.Context, selectedFields string, query string, args ...any) (model.ShipDockedLog, error) {
 	var res model.ShipDockedLog
 
-	db := r.Db.WithContext(ctx).Model(model.ShipDockedLog{})
+	db := r.Db.WithContext(ctx).Model(model.ShipDockedLog{}).Scopes(tenant.ShipScope(ctx, "ship_docked_logs.ship_id"))
 	db = util.SetSelectFields(db, selectedFields)
 
 	if err := db.Where(query, args...).Take(&res).Error; err != nil {
@@ -228,7 +312,7 @@ func (r *ship) UpdateShipDeviceID(ctx context.Context, deviceID string, user_id
 		return err
 	}
 
-	cacheKey := []string{"ship_list-*", "ship_last_update"}
+	cacheKey := []string{"ship_list-*", "ship_last_update*"}
 
 	for i := range cacheKey {
 		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
@@ -242,7 +326,7 @@ func (r *ship) UpdateShipDeviceID(ctx context.Context, deviceID string, user_id
 func (r *ship) FindOne(ctx context.Context, selectedFields string, query string, args ...any) (model.Ship, error) {
 	var res model.Ship
 
-	db := r.Db.WithContext(ctx).Model(model.Ship{})
+	db := r.Db.WithContext(ctx).Model(model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 	db = util.SetSelectFields(db, selectedFields)
 
 	if err := db.Where(query, args...).Take(&res).Error; err != nil {
@@ -255,7 +339,7 @@ func (r *ship) FindOne(ctx context.Context, selectedFields string, query string,
 func (r *ship) CountShipFraud(ctx context.Context, startDate string, endDate string) (int64, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.ShipLocationLog{})
+	query := tx.Model(&model.ShipLocationLog{}).Scopes(tenant.ShipScope(ctx, "ship_location_logs.ship_id"))
 
 	var res int64
 
@@ -263,7 +347,7 @@ func (r *ship) CountShipFraud(ctx context.Context, startDate string, endDate str
 		query = query.Where("created_at BETWEEN ? AND ?", startDate, endDate)
 	}
 
-	query.Where("is_mocked = ?", 1).Count(&res)
+	query.Where("(is_mocked = ? OR is_fraud = ?)", 1, 1).Count(&res)
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
@@ -276,7 +360,7 @@ func (r *ship) CountShipFraud(ctx context.Context, startDate string, endDate str
 func (r *ship) CountShipByStatus(ctx context.Context, startDate string, endDate string, status string) (int64, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.Ship{})
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
 	var res int64
 
@@ -297,7 +381,7 @@ func (r *ship) CountShipByStatus(ctx context.Context, startDate string, endDate
 func (r *ship) CountShipByTerrain(ctx context.Context, onGround int) (int64, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.Ship{})
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
 	var res int64
 
@@ -320,6 +404,7 @@ func (r *ship) StoreNewShip(ctx context.Context, request dto.PairingToNewShip) e
 		FirebaseToken:   request.FirebaseToken,
 		Status:          "out of scope",
 		UserID:          request.UserID,
+		HarbourID:       request.HarbourID,
 	}
 
 	tx := r.Db.WithContext(ctx).Begin()
@@ -334,7 +419,7 @@ func (r *ship) StoreNewShip(ctx context.Context, request dto.PairingToNewShip) e
 		return err
 	}
 
-	cacheKey := []string{"ship_list-*", "ship_count"}
+	cacheKey := []string{"ship_list-*", "ship_count*"}
 
 	for i := range cacheKey {
 		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
@@ -355,7 +440,7 @@ func (r *ship) ShipList(ctx context.Context, request dto.ShipListParam) ([]dto.S
 	hash := sha1.Sum(paramJSON)
 	uniqueString := fmt.Sprintf("%x", hash)
 
-	cacheKey := "ship_list-" + uniqueString
+	cacheKey := tenant.CacheKey(ctx, "ship_list-"+uniqueString)
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -369,17 +454,8 @@ func (r *ship) ShipList(ctx context.Context, request dto.ShipListParam) ([]dto.S
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.Ship{})
-
-	if request.Status != nil && request.Status[0] != "" && len(request.Status) > 0 {
-		query = query.Where("status IN (?)", request.Status)
-	}
-
-	if request.Search != "" {
-		searchLower := strings.ToLower(request.Search)
-		query = query.Where("lower(name) LIKE ? OR lower(device_id) LIKE ? OR lower(phone) LIKE ?", "%"+searchLower+"%", "%"+searchLower+"%", "%"+searchLower+"%")
-	}
-
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterShipList(query, request)
 	query = query.Limit(request.Limit).Offset(request.Offset).Order("created_at DESC")
 
 	var ship []model.Ship
@@ -419,6 +495,65 @@ func (r *ship) ShipList(ctx context.Context, request dto.ShipListParam) ([]dto.S
 	return shipList, nil
 }
 
+func (r *ship) filterShipList(query *gorm.DB, request dto.ShipListParam) *gorm.DB {
+	if request.Status != nil && request.Status[0] != "" && len(request.Status) > 0 {
+		query = query.Where("status IN (?)", request.Status)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(name) LIKE ? OR lower(device_id) LIKE ? OR lower(phone) LIKE ?", "%"+searchLower+"%", "%"+searchLower+"%", "%"+searchLower+"%")
+	}
+
+	return query
+}
+
+// ShipListCount counts the ships matching the list filters, paging is ignored
+func (r *ship) ShipListCount(ctx context.Context, request dto.ShipListParam) (int64, error) {
+	request.Offset, request.Limit = 0, 0
+
+	paramJSON, err := json.Marshal(request)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 0, err
+	}
+
+	hash := sha1.Sum(paramJSON)
+	uniqueString := fmt.Sprintf("%x", hash)
+
+	// shares the ship_list- prefix so it is cleared together with the list cache
+	cacheKey := tenant.CacheKey(ctx, "ship_list-count-"+uniqueString)
+
+	if r.CacheEnabled {
+		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
+		if err == nil {
+			var cachedInfo int64
+			if err := json.Unmarshal([]byte(cachedData), &cachedInfo); err == nil {
+				return cachedInfo, nil
+			}
+		}
+	}
+
+	query := r.Db.WithContext(ctx).Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterShipList(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	if r.CacheEnabled {
+		jsonData, err := json.Marshal(res)
+		if err == nil {
+			r.RedisClient.Set(ctx, cacheKey, jsonData, time.Hour)
+		} else {
+			fmt.Println("Error marshalling data for cache:", err)
+		}
+	}
+
+	return res, nil
+}
+
 func (r *ship) ShipByDevice(ctx context.Context, DeviceID string) (*dto.ShipMobileDetailResponse, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
@@ -440,6 +575,8 @@ func (r *ship) ShipByDevice(ctx context.Context, DeviceID string) (*dto.ShipMobi
 		Status:          string(ship.Status),
 		OnGround:        ship.OnGround,
 		CreatedAt:       ship.CreatedAt.Format("2006-01-02 15:04:05"),
+		LastReportedAt:  ship.LastReportedAt,
+		HarbourID:       ship.HarbourID,
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -471,6 +608,7 @@ func (r *ship) ShipByAuth(ctx context.Context, authUser model.User) (*dto.ShipMo
 		Status:          string(ship.Status),
 		OnGround:        ship.OnGround,
 		CreatedAt:       ship.CreatedAt.Format("2006-01-02 15:04:05"),
+		HarbourID:       ship.HarbourID,
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -507,7 +645,7 @@ func (r *ship) GetLastDockedLog(ctx context.Context, ShipID int) (*dto.ShipDocke
 	return &logDock, nil
 }
 
-func (r *ship) StoreDockedLog(ctx context.Context, request dto.ShipDockedLogStore) error {
+func (r *ship) StoreDockedLog(ctx context.Context, request dto.ShipDockedLogStore) (int, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	dockedModel := model.ShipDockedLog{
@@ -521,56 +659,212 @@ func (r *ship) StoreDockedLog(ctx context.Context, request dto.ShipDockedLogStor
 
 	if err := tx.Create(&dockedModel).Error; err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
-	cacheKey := "ship_statistic_count"
+	cacheKey := "ship_statistic_count*"
 
 	if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey); err != nil {
-		return nil
+		return dockedModel.ID, nil
 	}
 
-	return nil
+	return dockedModel.ID, nil
 }
 
-func (r *ship) StoreLocationLog(ctx context.Context, request dto.ShipLocationLogStore) error {
+func (r *ship) StoreLocationLog(ctx context.Context, request dto.ShipLocationLogStore) (int, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	locationModel := model.ShipLocationLog{
-		ShipID:   request.ShipID,
-		Long:     request.Long,
-		Lat:      request.Lat,
-		DegNorth: request.DegNorth,
-		OnGround: request.OnGround,
-		IsMocked: request.IsMocked,
+		ShipID:          request.ShipID,
+		Long:            request.Long,
+		Lat:             request.Lat,
+		DegNorth:        request.DegNorth,
+		OnGround:        request.OnGround,
+		IsMocked:        request.IsMocked,
+		Speed:           request.Speed,
+		HarbourDistance: request.HarbourDistance,
+		IsFraud:         request.IsFraud,
+		FraudScore:      request.FraudScore,
+		FraudReason:     request.FraudReason,
+		Status:          model.ShipStatus(request.Status),
+		FixedAt:         request.FixedAt,
 	}
 
 	if err := tx.Create(&locationModel).Error; err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
+		return 0, err
+	}
+
+	return locationModel.ID, nil
+}
+
+func (r *ship) GetLastLocationLog(ctx context.Context, ShipID int) (*model.ShipLocationLog, error) {
+	var log model.ShipLocationLog
+
+	err := r.Db.WithContext(ctx).Where("ship_id = ?", ShipID).Order("created_at DESC, id DESC").First(&log).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &log, nil
+}
+
+// FrozenPositionSince returns when the ship started reporting exactly the given coordinates without
+// interruption, nil when its last stored fix was somewhere else
+func (r *ship) FrozenPositionSince(ctx context.Context, ShipID int, lat string, long string) (*time.Time, error) {
+	var since *time.Time
+
+	db := r.Db.WithContext(ctx)
+
+	lastMoved := db.Model(&model.ShipLocationLog{}).
+		Select("COALESCE(MAX(created_at), '-infinity')").
+		Where("ship_id = ? AND (lat <> ? OR long <> ?)", ShipID, lat, long)
+
+	err := db.Model(&model.ShipLocationLog{}).
+		Select("MIN(created_at)").
+		Where("ship_id = ? AND lat = ? AND long = ? AND created_at > (?)", ShipID, lat, long, lastMoved).
+		Scan(&since).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return since, nil
+}
+
+func (r *ship) RecordReportingStat(ctx context.Context, request dto.ShipReportingStatStore) error {
+	now := time.Now()
+
+	stat := model.ShipReportingStat{
+		ShipID:      request.ShipID,
+		Date:        time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		Received:    1,
+		SumInterval: request.Interval,
+		MaxGap:      request.Interval,
+	}
+
+	if request.Stored {
+		stat.Stored = 1
+	} else {
+		stat.Merged = 1
+	}
+
+	if request.Gap {
+		stat.Gaps = 1
+	}
+
+	err := r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ship_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"received":     gorm.Expr("ship_reporting_stats.received + ?", stat.Received),
+			"stored":       gorm.Expr("ship_reporting_stats.stored + ?", stat.Stored),
+			"merged":       gorm.Expr("ship_reporting_stats.merged + ?", stat.Merged),
+			"gaps":         gorm.Expr("ship_reporting_stats.gaps + ?", stat.Gaps),
+			"sum_interval": gorm.Expr("ship_reporting_stats.sum_interval + ?", stat.SumInterval),
+			"max_gap":      gorm.Expr("GREATEST(ship_reporting_stats.max_gap, ?)", stat.MaxGap),
+			"updated_at":   now,
+		}),
+	}).Create(&stat).Error
+	if err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (r *ship) ShipReportingCompliance(ctx context.Context, request dto.ShipReportingComplianceParam) ([]dto.ShipReportingComplianceResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := tx.Model(&model.ShipReportingStat{}).
+		Select(`ships.id as ship_id, ships.name as ship_name,
+			SUM(ship_reporting_stats.received) as received,
+			SUM(ship_reporting_stats.stored) as stored,
+			SUM(ship_reporting_stats.merged) as merged,
+			SUM(ship_reporting_stats.gaps) as gaps,
+			MAX(ship_reporting_stats.max_gap) as max_gap,
+			SUM(ship_reporting_stats.sum_interval) as sum_interval`).
+		Joins("JOIN ships ON ship_reporting_stats.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	if request.ShipID != 0 {
+		query = query.Where("ship_reporting_stats.ship_id = ?", request.ShipID)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("ship_reporting_stats.date BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	query = query.Group("ships.id, ships.name").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Order("ships.name ASC")
+
+	var result []struct {
+		ShipID      int
+		ShipName    string
+		Received    int
+		Stored      int
+		Merged      int
+		Gaps        int
+		MaxGap      int64
+		SumInterval int64
+	}
+
+	if err := query.Scan(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var compliance []dto.ShipReportingComplianceResponse
+	for _, e := range result {
+		compliance = append(compliance, dto.ShipReportingComplianceResponse{
+			ShipID:   e.ShipID,
+			ShipName: e.ShipName,
+			Received: e.Received,
+			Stored:   e.Stored,
+			Merged:   e.Merged,
+			Gaps:     e.Gaps,
+			MaxGap:   e.MaxGap,
+			AverageInterval: func() float64 {
+				if e.Received == 0 {
+					return 0
+				}
+				return float64(e.SumInterval) / float64(e.Received)
+			}(),
+		})
+	}
+
+	return compliance, nil
+}
+
 func (r *ship) UpdateShip(ctx context.Context, request model.Ship) error {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	updateFields := map[string]interface{}{
-		"status":       model.ShipStatus(request.Status),
-		"current_lat":  request.CurrentLat,
-		"current_long": request.CurrentLong,
-		"deg_north":    request.DegNorth,
+		"status":           model.ShipStatus(request.Status),
+		"current_lat":      request.CurrentLat,
+		"current_long":     request.CurrentLong,
+		"deg_north":        request.DegNorth,
+		"last_reported_at": request.LastReportedAt,
 		"on_ground": func() int {
 			if request.OnGround == 1 {
 				return 1
@@ -589,7 +883,7 @@ func (r *ship) UpdateShip(ctx context.Context, request model.Ship) error {
 		return err
 	}
 
-	cacheKey := []string{"ship_list-*", "ship_last_update"}
+	cacheKey := []string{"ship_list-*", "ship_last_update*"}
 
 	for i := range cacheKey {
 		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
@@ -604,7 +898,13 @@ func (r *ship) UpdateShipDetail(ctx context.Context, request dto.ShipAddonDetail
 	tx := r.Db.WithContext(ctx).Begin()
 
 	var existingShip model.Ship
-	if err := tx.Where("id = ?", request.ShipID).First(&existingShip).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", request.ShipID).First(&existingShip).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	before, err := r.currentShipDetail(tx, request.ShipID)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -629,6 +929,11 @@ func (r *ship) UpdateShipDetail(ctx context.Context, request dto.ShipAddonDetail
 		return err
 	}
 
+	if err := recordShipDetailVersion(tx, before, request.ShipID, model.DetailSourceManual, request.ChangedBy, existingShip.CreatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		return err
@@ -637,11 +942,161 @@ func (r *ship) UpdateShipDetail(ctx context.Context, request dto.ShipAddonDetail
 	return nil
 }
 
+// ShipImportIndex returns the name and SIUP of every ship, used to match import rows to existing ships
+func (r *ship) ShipImportIndex(ctx context.Context) ([]dto.ShipImportIndex, error) {
+	var res []dto.ShipImportIndex
+
+	err := r.Db.WithContext(ctx).Model(&model.Ship{}).
+		Select("ships.id, ships.name, COALESCE(ship_details.siup, '') as siup").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ImportShip creates the ship of an import row when row.ShipID is 0 and updates it otherwise,
+// empty cells keep the stored value of an existing ship. It returns the id of the ship.
+func (r *ship) ImportShip(ctx context.Context, row dto.ShipImportRow) (int, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	shipModel := model.Ship{
+		Name:            row.Name,
+		Phone:           row.Phone,
+		ResponsibleName: row.ResponsibleName,
+		Status:          "out of scope",
+		HarbourID:       row.HarbourID,
+	}
+
+	detail := model.ShipDetail{
+		Type:      model.ShipType(row.Type),
+		Dimension: row.Dimension,
+		Harbour:   row.Harbour,
+		SIUP:      row.SIUP,
+		BKP:       row.BKP,
+		SelarMark: row.SelarMark,
+		GT:        row.GT,
+		OwnerName: row.OwnerName,
+	}
+
+	if row.ShipID == 0 {
+		if err := tx.Create(&shipModel).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		detail.ShipID = shipModel.ID
+		if err := tx.Create(&detail).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := recordShipDetailVersion(tx, nil, shipModel.ID, model.DetailSourceImport, row.ChangedBy, shipModel.CreatedAt); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	} else {
+		shipModel.ID = row.ShipID
+
+		var existingShip model.Ship
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", row.ShipID).First(&existingShip).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		before, err := r.currentShipDetail(tx, row.ShipID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		shipFields := nonEmptyFields(map[string]string{
+			"name":             row.Name,
+			"phone":            row.Phone,
+			"responsible_name": row.ResponsibleName,
+		})
+		if err := tx.Model(&model.Ship{}).Where("id = ?", row.ShipID).Updates(shipFields).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		detailFields := nonEmptyFields(map[string]string{
+			"type":       row.Type,
+			"dimension":  row.Dimension,
+			"harbour":    row.Harbour,
+			"siup":       row.SIUP,
+			"bkp":        row.BKP,
+			"selar_mark": row.SelarMark,
+			"gt":         row.GT,
+			"owner_name": row.OwnerName,
+		})
+
+		detail.ShipID = row.ShipID
+		onConflict := clause.OnConflict{Columns: []clause.Column{{Name: "ship_id"}}, DoNothing: true}
+		if len(detailFields) > 0 {
+			onConflict = clause.OnConflict{Columns: []clause.Column{{Name: "ship_id"}}, DoUpdates: clause.Assignments(detailFields)}
+		}
+
+		if err := tx.Clauses(onConflict).Create(&detail).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := recordShipDetailVersion(tx, before, row.ShipID, model.DetailSourceImport, row.ChangedBy, existingShip.CreatedAt); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	cacheKey := []string{"ship_list-*", "ship_count*"}
+
+	for i := range cacheKey {
+		if err := helper.DeleteRedisKeysByPattern(r.RedisClient, cacheKey[i]); err != nil {
+			return shipModel.ID, nil
+		}
+	}
+
+	return shipModel.ID, nil
+}
+
+// currentShipDetail returns the current details of a ship within tx, nil when it has none yet
+func (r *ship) currentShipDetail(tx *gorm.DB, ShipID int) (*model.ShipDetail, error) {
+	var detail model.ShipDetail
+	err := tx.Where("ship_id = ?", ShipID).First(&detail).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &detail, nil
+}
+
+func nonEmptyFields(fields map[string]string) map[string]interface{} {
+	res := map[string]interface{}{}
+	for column, value := range fields {
+		if value != "" {
+			res[column] = value
+		}
+	}
+
+	return res
+}
+
 func (r *ship) ShipByID(ctx context.Context, ShipID int) (*model.Ship, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	var ship model.Ship
-	err := tx.Where("id = ?", ShipID).First(&ship).Error
+	err := tx.Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ShipID).First(&ship).Error
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -655,32 +1110,49 @@ func (r *ship) ShipByID(ctx context.Context, ShipID int) (*model.Ship, error) {
 	return &ship, nil
 }
 
-func (r *ship) ShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.DockLogsShip, error) {
-	tx := r.Db.WithContext(ctx).Begin()
-
-	var logs []model.ShipDockedLog
-	query := tx.Where("ship_id = ?", ShipID).Order("created_at DESC")
+func (r *ship) filterShipDockedLogs(query *gorm.DB, ShipID int, request *dto.ShipLogParam) *gorm.DB {
+	query = query.Where("ship_id = ?", ShipID)
 
 	if request.StartDate != "" && request.EndDate != "" {
 		query = query.Where("created_at BETWEEN ? AND ?", request.StartDate, request.EndDate)
 	}
 
+	return query
+}
+
+// ShipDockedLogs returns a page of the ship docking logs and the cursor of the next page, empty on the last page
+func (r *ship) ShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.DockLogsShip, string, error) {
+	cursor, err := pagination.DecodeCursor(request.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	limit := 10
 	if request.Limit != 0 {
 		limit = request.Limit
 	}
 
-	query.Limit(limit).Offset(request.Offset)
+	tx := r.Db.WithContext(ctx).Begin()
 
-	err := query.Find(&logs).Error
-	if err != nil {
+	var logs []model.ShipDockedLog
+	query := r.filterShipDockedLogs(tx.Model(&model.ShipDockedLog{}), ShipID, request).
+		Scopes(tenant.ShipScope(ctx, "ship_docked_logs.ship_id"), pagination.Keyset("ship_docked_logs", cursor, request.Offset)).
+		Limit(pagination.Limit(limit))
+
+	if err := query.Find(&logs).Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+		nextCursor = pagination.EncodeCursor(logs[limit-1].CreatedAt, logs[limit-1].ID)
 	}
 
 	var logDock []dto.DockLogsShip
@@ -694,58 +1166,126 @@ func (r *ship) ShipDockedLogs(ctx context.Context, ShipID int, request *dto.Ship
 		})
 	}
 
-	return logDock, nil
+	return logDock, nextCursor, nil
 }
 
-func (r *ship) ShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.LocationLogsShip, error) {
-	tx := r.Db.WithContext(ctx).Begin()
+func (r *ship) CountShipDockedLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) (int64, error) {
+	query := r.filterShipDockedLogs(r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}), ShipID, request).
+		Scopes(tenant.ShipScope(ctx, "ship_docked_logs.ship_id"))
 
-	var logs []model.ShipLocationLog
-	query := tx.Where("ship_id = ?", ShipID).Order("created_at DESC")
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *ship) filterShipLocationLogs(query *gorm.DB, ShipID int, request *dto.ShipLogParam) *gorm.DB {
+	query = query.Where("ship_id = ?", ShipID)
 
 	if request.StartDate != "" && request.EndDate != "" {
 		query = query.Where("created_at BETWEEN ? AND (?::DATE + INTERVAL '1 DAY')", request.StartDate, request.EndDate)
 	}
 
+	return query
+}
+
+// ShipLocationLogs returns a page of the ship location logs and the cursor of the next page, empty on the last page
+func (r *ship) ShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) ([]dto.LocationLogsShip, string, error) {
+	cursor, err := pagination.DecodeCursor(request.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	limit := 10
 	if request.Limit != 0 {
 		limit = request.Limit
 	}
 
-	query.Limit(limit).Offset(request.Offset)
+	tx := r.Db.WithContext(ctx).Begin()
 
-	err := query.Find(&logs).Error
-	if err != nil {
+	var logs []model.ShipLocationLog
+	query := r.filterShipLocationLogs(tx.Model(&model.ShipLocationLog{}), ShipID, request).
+		Scopes(tenant.ShipScope(ctx, "ship_location_logs.ship_id"), pagination.Keyset("ship_location_logs", cursor, request.Offset)).
+		Limit(pagination.Limit(limit))
+
+	if err := query.Find(&logs).Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+		nextCursor = pagination.EncodeCursor(logs[limit-1].CreatedAt, logs[limit-1].ID)
 	}
 
 	var logDock []dto.LocationLogsShip
 	for _, log := range logs {
 		logDock = append(logDock, dto.LocationLogsShip{
-			LogID:     log.ID,
-			Long:      log.Long,
-			Lat:       log.Lat,
-			IsMocked:  log.IsMocked,
-			OnGround:  log.OnGround,
-			DegNorth:  log.DegNorth,
-			CreatedAt: log.CreatedAt.Format("2006-01-02 15:04:05"),
+			LogID:           log.ID,
+			Long:            log.Long,
+			Lat:             log.Lat,
+			IsMocked:        log.IsMocked,
+			OnGround:        log.OnGround,
+			DegNorth:        log.DegNorth,
+			Speed:           log.Speed,
+			HarbourDistance: log.HarbourDistance,
+			FraudScore:      log.FraudScore,
+			FraudReason:     log.FraudReason,
+			CreatedAt:       log.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
 
-	return logDock, nil
+	return logDock, nextCursor, nil
+}
+
+func (r *ship) CountShipLocationLogs(ctx context.Context, ShipID int, request *dto.ShipLogParam) (int64, error) {
+	query := r.filterShipLocationLogs(r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}), ShipID, request).
+		Scopes(tenant.ShipScope(ctx, "ship_location_logs.ship_id"))
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// ShipTrackLogs returns the location logs of the ship in [start, end)
+func (r *ship) ShipTrackLogs(ctx context.Context, ShipID int, start time.Time, end time.Time) ([]model.ShipLocationLog, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	var logs []model.ShipLocationLog
+	query := tx.Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Where("ship_id = ?", ShipID).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Order("created_at ASC, id ASC")
+
+	if err := query.Find(&logs).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return logs, nil
 }
 
 func (r *ship) ShipAddonDetail(ctx context.Context, ShipID int) (dto.ShipAddonDetailResponse, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	var detail model.ShipDetail
-	if err := tx.Where("ship_id = ?", ShipID).First(&detail).Error; err != nil {
+	if err := tx.Scopes(tenant.ShipScope(ctx, "ship_id")).Where("ship_id = ?", ShipID).First(&detail).Error; err != nil {
 		tx.Rollback()
 		return dto.ShipAddonDetailResponse{}, err
 	}
@@ -770,7 +1310,7 @@ func (r *ship) ShipAddonDetail(ctx context.Context, ShipID int) (dto.ShipAddonDe
 }
 
 func (r *ship) CountShip(ctx context.Context) (int64, error) {
-	cacheKey := "ship_count"
+	cacheKey := tenant.CacheKey(ctx, "ship_count")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -784,7 +1324,7 @@ func (r *ship) CountShip(ctx context.Context) (int64, error) {
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.Ship{})
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
 	var totalShip int64
 
@@ -811,7 +1351,7 @@ func (r *ship) CountShip(ctx context.Context) (int64, error) {
 }
 
 func (r *ship) CountStatistic(ctx context.Context) ([]int64, error) {
-	cacheKey := "ship_statistic_count"
+	cacheKey := tenant.CacheKey(ctx, "ship_statistic_count")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -826,19 +1366,19 @@ func (r *ship) CountStatistic(ctx context.Context) ([]int64, error) {
 	tx := r.Db.WithContext(ctx).Begin()
 
 	var totalCheckin int64
-	if err := tx.Model(&model.ShipDockedLog{}).Where("status = ?", "checkin").Count(&totalCheckin).Error; err != nil {
+	if err := tx.Model(&model.ShipDockedLog{}).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("status = ?", "checkin").Count(&totalCheckin).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	var totalCheckout int64
-	if err := tx.Model(&model.ShipDockedLog{}).Where("status = ?", "checkout").Count(&totalCheckout).Error; err != nil {
+	if err := tx.Model(&model.ShipDockedLog{}).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("status = ?", "checkout").Count(&totalCheckout).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	var totalFraud int64
-	if err := tx.Model(&model.ShipLocationLog{}).Where("is_mocked = ?", 1).Count(&totalFraud).Error; err != nil {
+	if err := tx.Model(&model.ShipLocationLog{}).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("(is_mocked = ? OR is_fraud = ?)", 1, 1).Count(&totalFraud).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -861,8 +1401,48 @@ func (r *ship) CountStatistic(ctx context.Context) ([]int64, error) {
 	return []int64{totalCheckin, totalCheckout, totalFraud}, nil
 }
 
+// StatisticSeries counts the docked and location logs of the period per bucket of the harbour
+// timezone in one grouped query, the grouping set () adds the row of the whole period so its
+// active ships are distinct over the period and not summed over the buckets
+func (r *ship) StatisticSeries(ctx context.Context, request dto.DashboardSeriesFilter) ([]dto.DashboardSeriesRow, error) {
+	var res []dto.DashboardSeriesRow
+
+	docked := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Select("'docked' AS kind, ship_docked_logs.ship_id, CAST(ship_docked_logs.status AS TEXT) AS status, "+
+			"0 AS is_mocked, 0 AS is_fraud, date_trunc(?, ship_docked_logs.created_at AT TIME ZONE ?) AS bucket",
+			request.Granularity, request.Timezone).
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_docked_logs.created_at >= ? AND ship_docked_logs.created_at < ?", request.Start, request.End)
+
+	location := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Select("'location' AS kind, ship_location_logs.ship_id, CAST(ship_location_logs.status AS TEXT) AS status, "+
+			"ship_location_logs.is_mocked, ship_location_logs.is_fraud, date_trunc(?, ship_location_logs.created_at AT TIME ZONE ?) AS bucket",
+			request.Granularity, request.Timezone).
+		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("ship_location_logs.created_at >= ? AND ship_location_logs.created_at < ?", request.Start, request.End)
+
+	err := r.Db.WithContext(ctx).Raw(`SELECT logs.bucket, GROUPING(logs.bucket) AS is_total,
+			COUNT(*) FILTER (WHERE logs.kind = 'docked' AND logs.status = ?) AS checkin,
+			COUNT(*) FILTER (WHERE logs.kind = 'docked' AND logs.status = ?) AS checkout,
+			COUNT(DISTINCT logs.ship_id) FILTER (WHERE logs.kind = 'location' AND logs.status = ?) AS out_of_scope,
+			COUNT(*) FILTER (WHERE logs.kind = 'location' AND (logs.is_mocked = 1 OR logs.is_fraud = 1)) AS fraud,
+			COUNT(DISTINCT logs.ship_id) FILTER (WHERE logs.kind = 'location') AS active_ships
+		FROM (? UNION ALL ?) AS logs
+		GROUP BY GROUPING SETS ((logs.bucket), ())
+		ORDER BY is_total DESC, logs.bucket ASC`,
+		model.Checkin, model.Checkout, model.OutOfScope, docked, location).
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (r *ship) LastUpdated(ctx context.Context) (time.Time, error) {
-	cacheKey := "ship_last_update"
+	cacheKey := tenant.CacheKey(ctx, "ship_last_update")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -878,7 +1458,7 @@ func (r *ship) LastUpdated(ctx context.Context) (time.Time, error) {
 
 	var maxUpdatedAt time.Time
 
-	query := tx.Model(&model.Ship{}).Select("MAX(updated_at)").Row()
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id")).Select("MAX(updated_at)").Row()
 	if err := query.Scan(&maxUpdatedAt); err != nil {
 		tx.Rollback()
 		return time.Time{}, err
@@ -915,7 +1495,7 @@ func (r *ship) ShipInBatch(ctx context.Context, start int, end int) (*[]model.Sh
 		OnGroundLogs  int       `json:"log_onground" gorm:"column:log_onground"`
 	}
 
-	cacheKey := "ship_highest_current_update"
+	cacheKey := tenant.CacheKey(ctx, "ship_highest_current_update")
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -925,7 +1505,7 @@ func (r *ship) ShipInBatch(ctx context.Context, start int, end int) (*[]model.Sh
 				if lastUpdate != cachedInfo {
 					tx := r.Db.WithContext(ctx).Begin()
 
-					query := tx.Model(&model.Ship{})
+					query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
 					query = query.Select("ships.*, slr.long as log_long, slr.lat as log_lat, slr.created_at as log_created, slr.on_ground as log_onground")
 					query = query.Joins(`
@@ -986,7 +1566,7 @@ func (r *ship) ShipInBatch(ctx context.Context, start int, end int) (*[]model.Sh
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.Ship{})
+	query := tx.Model(&model.Ship{}).Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 	query = query.Select("ships.*, slr.long as log_long, slr.lat as log_lat, slr.created_at as log_created, slr.on_ground as log_onground")
 	query = query.Joins(`
 		JOIN ship_location_logs AS slr
@@ -1028,24 +1608,35 @@ func (r *ship) ShipInBatch(ctx context.Context, start int, end int) (*[]model.Sh
 	return &ships, false, nil
 }
 
-func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, error) {
+// ReportShipDocking returns a page of the docking report and the cursor of the next page, empty on the last page
+func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDockedParam) ([]dto.ReportShipDockingResponse, string, error) {
+	cursor, err := pagination.DecodeCursor(request.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	paramJSON, err := json.Marshal(request)
 	if err != nil {
 		fmt.Println("Error:", err)
-		return nil, err
+		return nil, "", err
 	}
 
 	hash := sha1.Sum(paramJSON)
 	uniqueString := fmt.Sprintf("%x", hash)
 
-	cacheKey := "report_ship_list-" + uniqueString
+	cacheKey := tenant.CacheKey(ctx, "report_ship_list-"+uniqueString)
+
+	type reportPage struct {
+		Data       []dto.ReportShipDockingResponse
+		NextCursor string
+	}
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
 		if err == nil {
-			var cachedInfo []dto.ReportShipDockingResponse
+			var cachedInfo reportPage
 			if err := json.Unmarshal([]byte(cachedData), &cachedInfo); err == nil {
-				return cachedInfo, nil
+				return cachedInfo.Data, cachedInfo.NextCursor, nil
 			}
 		}
 	}
@@ -1054,22 +1645,11 @@ func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDock
 
 	query := tx.Model(&model.ShipDockedLog{}).
 		Select("ship_docked_logs.*, ships.name as ship_name, ships.id as ship_id").
-		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id")
-
-	if request.LogType != nil && request.LogType[0] != "" && len(request.LogType) > 0 {
-		query = query.Where("ship_docked_logs.status IN (?)", request.LogType)
-	}
-
-	if request.Search != "" {
-		searchLower := strings.ToLower(request.Search)
-		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
-	}
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
-	if request.StartDate != "" && request.EndDate != "" {
-		query = query.Where("DATE(ship_docked_logs.created_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
-	}
-
-	query = query.Limit(request.Limit).Offset(request.Offset).Order("ship_docked_logs.created_at DESC")
+	query = r.filterReportDocking(query, request)
+	query = query.Scopes(pagination.Keyset("ship_docked_logs", cursor, request.Offset)).Limit(pagination.Limit(request.Limit))
 
 	var result []struct {
 		model.ShipDockedLog
@@ -1078,7 +1658,14 @@ func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDock
 
 	if err := query.Find(&result).Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if request.Limit > 0 && len(result) > request.Limit {
+		result = result[:request.Limit]
+		last := result[request.Limit-1]
+		nextCursor = pagination.EncodeCursor(last.CreatedAt, last.ID)
 	}
 
 	var shipDock []dto.ReportShipDockingResponse
@@ -1096,11 +1683,11 @@ func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDock
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
 	}
 
 	if r.CacheEnabled {
-		jsonData, err := json.Marshal(shipDock)
+		jsonData, err := json.Marshal(reportPage{Data: shipDock, NextCursor: nextCursor})
 		if err == nil {
 			r.RedisClient.Set(ctx, cacheKey, jsonData, time.Hour)
 		} else {
@@ -1108,27 +1695,39 @@ func (r *ship) ReportShipDocking(ctx context.Context, request dto.ReportShipDock
 		}
 	}
 
-	return shipDock, nil
+	return shipDock, nextCursor, nil
+}
+
+func (r *ship) ReportShipDockingCount(ctx context.Context, request dto.ReportShipDockedParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterReportDocking(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
 }
 
-func (r *ship) ReportShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, error) {
+// ReportShipFraud returns a page of the fraud report and the cursor of the next page, empty on the last page
+func (r *ship) ReportShipFraud(ctx context.Context, request dto.ReportShipLocationParam) ([]dto.ReportShipLocationResponse, string, error) {
+	cursor, err := pagination.DecodeCursor(request.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	tx := r.Db.WithContext(ctx).Begin()
 
 	query := tx.Model(&model.ShipLocationLog{}).
 		Select("ship_location_logs.*, ships.name as ship_name, ships.id as ship_id").
 		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
-		Where("is_mocked = 1")
-
-	if request.Search != "" {
-		searchLower := strings.ToLower(request.Search)
-		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
-	}
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
 
-	if request.StartDate != "" && request.EndDate != "" {
-		query = query.Where("DATE(ship_location_logs.created_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
-	}
-
-	query = query.Limit(request.Limit).Offset(request.Offset).Order("ship_location_logs.created_at DESC")
+	query = r.filterReportFraud(query, request)
+	query = query.Scopes(pagination.Keyset("ship_location_logs", cursor, request.Offset)).Limit(pagination.Limit(request.Limit))
 
 	var result []struct {
 		model.ShipLocationLog
@@ -1137,27 +1736,151 @@ func (r *ship) ReportShipFraud(ctx context.Context, request dto.ReportShipLocati
 
 	if err := query.Find(&result).Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if request.Limit > 0 && len(result) > request.Limit {
+		result = result[:request.Limit]
+		last := result[request.Limit-1]
+		nextCursor = pagination.EncodeCursor(last.CreatedAt, last.ID)
 	}
 
 	var shipDock []dto.ReportShipLocationResponse
 	for _, e := range result {
 		shipDock = append(shipDock, dto.ReportShipLocationResponse{
-			LogID:    e.ID,
-			ShipID:   e.ShipID,
-			ShipName: e.ShipName,
-			Lat:      e.Lat,
-			Long:     e.Long,
-			IsMocked: e.IsMocked,
-			OnGround: e.OnGround,
-			LogDate:  e.CreatedAt.Format("2006-01-02 15:04:05"),
+			LogID:       e.ID,
+			ShipID:      e.ShipID,
+			ShipName:    e.ShipName,
+			Lat:         e.Lat,
+			Long:        e.Long,
+			IsMocked:    e.IsMocked,
+			OnGround:    e.OnGround,
+			FraudScore:  e.FraudScore,
+			FraudReason: e.FraudReason,
+			LogDate:     e.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, "", err
 	}
 
-	return shipDock, nil
+	return shipDock, nextCursor, nil
+}
+
+func (r *ship) ReportShipFraudCount(ctx context.Context, request dto.ReportShipLocationParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterReportFraud(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *ship) filterReportDocking(query *gorm.DB, request dto.ReportShipDockedParam) *gorm.DB {
+	if request.LogType != nil && request.LogType[0] != "" && len(request.LogType) > 0 {
+		query = query.Where("ship_docked_logs.status IN (?)", request.LogType)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(ship_docked_logs.created_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *ship) filterReportFraud(query *gorm.DB, request dto.ReportShipLocationParam) *gorm.DB {
+	query = query.Where("(ship_location_logs.is_mocked = 1 OR ship_location_logs.is_fraud = 1)")
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.Reason != "" {
+		query = query.Where("ship_location_logs.fraud_reason LIKE ?", "%"+request.Reason+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(ship_location_logs.created_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+// ExportShipDocking walks the whole filtered docking report row by row through a database cursor,
+// offset and limit of the request are ignored
+func (r *ship) ExportShipDocking(ctx context.Context, request dto.ReportShipDockedParam, fn func(dto.ReportShipDockingExportRow) error) error {
+	query := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Select("ship_docked_logs.id as log_id, ship_docked_logs.created_at as log_date, ship_docked_logs.status, " +
+			"ship_docked_logs.lat, ship_docked_logs.long, ships.id as ship_id, ships.name as ship_name, " + reportShipDetailColumns).
+		Joins("JOIN ships ON ship_docked_logs.ship_id = ships.id").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterReportDocking(query, request)
+
+	rows, err := query.Order("ship_docked_logs.created_at DESC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dto.ReportShipDockingExportRow
+		if err := r.Db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ExportShipFraud walks the whole filtered fraud report row by row through a database cursor,
+// offset and limit of the request are ignored
+func (r *ship) ExportShipFraud(ctx context.Context, request dto.ReportShipLocationParam, fn func(dto.ReportShipFraudExportRow) error) error {
+	query := r.Db.WithContext(ctx).Model(&model.ShipLocationLog{}).
+		Select("ship_location_logs.id as log_id, ship_location_logs.created_at as log_date, ship_location_logs.lat, " +
+			"ship_location_logs.long, ship_location_logs.is_mocked, ship_location_logs.on_ground, ship_location_logs.fraud_score, " +
+			"ship_location_logs.fraud_reason, ships.id as ship_id, ships.name as ship_name, " + reportShipDetailColumns).
+		Joins("JOIN ships ON ship_location_logs.ship_id = ships.id").
+		Joins("LEFT JOIN ship_details ON ship_details.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterReportFraud(query, request)
+
+	rows, err := query.Order("ship_location_logs.created_at DESC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dto.ReportShipFraudExportRow
+		if err := r.Db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type ShipDetailHistory interface {
+	HistoryList(ctx context.Context, ShipID int, request dto.ShipDetailHistoryParam) ([]dto.ShipDetailHistoryResponse, error)
+	HistoryCount(ctx context.Context, ShipID int) (int64, error)
+	DetailAsOf(ctx context.Context, ShipID int, at time.Time) (*dto.ShipDetailAsOfResponse, error)
+}
+
+type shipDetailHistory struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewShipDetailHistoryRepository(db *gorm.DB, redisClient *redis.Client) ShipDetailHistory {
+	return &shipDetailHistory{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+type shipDetailHistoryRow struct {
+	model.ShipDetailHistory
+	ChangedByName string
+}
+
+func (r *shipDetailHistory) HistoryList(ctx context.Context, ShipID int, request dto.ShipDetailHistoryParam) ([]dto.ShipDetailHistoryResponse, error) {
+	var rows []shipDetailHistoryRow
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDetailHistory{}).
+		Select("ship_detail_histories.*, COALESCE(users.name, '') as changed_by_name").
+		Joins("LEFT JOIN users ON users.id = ship_detail_histories.changed_by").
+		Scopes(tenant.ShipScope(ctx, "ship_detail_histories.ship_id")).
+		Where("ship_detail_histories.ship_id = ?", ShipID).
+		Order("ship_detail_histories.version DESC").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipDetailHistoryResponse
+	for _, row := range rows {
+		item := dto.ShipDetailHistoryResponse{
+			ID:            row.ID,
+			ShipID:        row.ShipID,
+			Version:       row.Version,
+			Detail:        shipDetailHistoryDetail(row.ShipDetailHistory),
+			Changes:       map[string]dto.ShipDetailChange{},
+			Source:        string(row.Source),
+			ChangedBy:     row.ChangedBy,
+			ChangedByName: row.ChangedByName,
+			ValidFrom:     row.ValidFrom.Format("2006-01-02 15:04:05"),
+			CreatedAt:     row.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+
+		if row.Changes != "" {
+			json.Unmarshal([]byte(row.Changes), &item.Changes)
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (r *shipDetailHistory) HistoryCount(ctx context.Context, ShipID int) (int64, error) {
+	var res int64
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDetailHistory{}).
+		Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Where("ship_id = ?", ShipID).
+		Count(&res).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// DetailAsOf returns the version of the ship details that was valid at the given time.
+// Ships whose details were never changed since history is kept fall back to the current
+// details, it returns gorm.ErrRecordNotFound when the ship had no details yet.
+func (r *shipDetailHistory) DetailAsOf(ctx context.Context, ShipID int, at time.Time) (*dto.ShipDetailAsOfResponse, error) {
+	db := r.Db.WithContext(ctx)
+
+	res := &dto.ShipDetailAsOfResponse{
+		ShipID: ShipID,
+		AsOf:   at.Format("2006-01-02 15:04:05"),
+	}
+
+	var version model.ShipDetailHistory
+	err := db.Scopes(tenant.ShipScope(ctx, "ship_id")).Where("ship_id = ? AND valid_from <= ?", ShipID, at).Order("version DESC").First(&version).Error
+	if err == nil {
+		res.Version = version.Version
+		res.Detail = shipDetailHistoryDetail(version)
+		return res, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	var versions int64
+	if err := db.Model(&model.ShipDetailHistory{}).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("ship_id = ?", ShipID).Count(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	if versions > 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var ship model.Ship
+	if err := db.Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ? AND created_at <= ?", ShipID, at).First(&ship).Error; err != nil {
+		return nil, err
+	}
+
+	var detail model.ShipDetail
+	if err := db.Scopes(tenant.ShipScope(ctx, "ship_id")).Where("ship_id = ?", ShipID).First(&detail).Error; err != nil {
+		return nil, err
+	}
+
+	res.Detail = dto.ShipAddonDetailResponse{
+		Type:      string(detail.Type),
+		Dimension: detail.Dimension,
+		Harbour:   detail.Harbour,
+		SIUP:      detail.SIUP,
+		BKP:       detail.BKP,
+		SelarMark: detail.SelarMark,
+		GT:        detail.GT,
+		OwnerName: detail.OwnerName,
+	}
+
+	return res, nil
+}
+
+func shipDetailHistoryDetail(e model.ShipDetailHistory) dto.ShipAddonDetailResponse {
+	return dto.ShipAddonDetailResponse{
+		Type:      string(e.Type),
+		Dimension: e.Dimension,
+		Harbour:   e.Harbour,
+		SIUP:      e.SIUP,
+		BKP:       e.BKP,
+		SelarMark: e.SelarMark,
+		GT:        e.GT,
+		OwnerName: e.OwnerName,
+	}
+}
+
+// shipDetailFields lists the versioned columns of a ship_details row
+func shipDetailFields(e model.ShipDetail) map[string]string {
+	return map[string]string{
+		"type":       string(e.Type),
+		"dimension":  e.Dimension,
+		"harbour":    e.Harbour,
+		"siup":       e.SIUP,
+		"bkp":        e.BKP,
+		"selar_mark": e.SelarMark,
+		"gt":         e.GT,
+		"owner_name": e.OwnerName,
+	}
+}
+
+// recordShipDetailVersion stores the details of the ship, as saved within tx, as a new version
+// when they differ from before. before is nil when the ship had no details. The first change of
+// details stored before history was kept also records them as a baseline valid since baselineFrom.
+// The caller must hold a lock on the ship row so concurrent updates don't share a version number.
+func recordShipDetailVersion(tx *gorm.DB, before *model.ShipDetail, ShipID int, source model.ShipDetailSource, changedBy int, baselineFrom time.Time) error {
+	var after model.ShipDetail
+	if err := tx.Where("ship_id = ?", ShipID).First(&after).Error; err != nil {
+		return err
+	}
+
+	previous := map[string]string{}
+	if before != nil {
+		previous = shipDetailFields(*before)
+	}
+
+	changes := map[string]dto.ShipDetailChange{}
+	for column, value := range shipDetailFields(after) {
+		if previous[column] != value {
+			changes[column] = dto.ShipDetailChange{From: previous[column], To: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var version int
+	if err := tx.Model(&model.ShipDetailHistory{}).Where("ship_id = ?", ShipID).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return err
+	}
+
+	if version == 0 && before != nil {
+		version = 1
+		baseline := shipDetailVersion(*before, version)
+		baseline.Source = model.DetailSourceBaseline
+		baseline.ValidFrom = baselineFrom
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	current := shipDetailVersion(after, version+1)
+	current.Changes = string(encoded)
+	current.Source = source
+	current.ValidFrom = time.Now()
+	if changedBy > 0 {
+		current.ChangedBy = &changedBy
+	}
+
+	return tx.Create(&current).Error
+}
+
+func shipDetailVersion(e model.ShipDetail, version int) model.ShipDetailHistory {
+	return model.ShipDetailHistory{
+		ShipID:    e.ShipID,
+		Version:   version,
+		Type:      e.Type,
+		Dimension: e.Dimension,
+		Harbour:   e.Harbour,
+		SIUP:      e.SIUP,
+		BKP:       e.BKP,
+		SelarMark: e.SelarMark,
+		GT:        e.GT,
+		OwnerName: e.OwnerName,
+	}
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type ShipDocument interface {
+	StoreDocument(ctx context.Context, document *model.ShipDocument) error
+	UpdateDocument(ctx context.Context, document model.ShipDocument) error
+	DeleteDocument(ctx context.Context, ID int) error
+	DocumentByID(ctx context.Context, ID int) (*model.ShipDocument, error)
+	DocumentList(ctx context.Context, request dto.ShipDocumentListParam, now time.Time) ([]dto.ShipDocumentResponse, error)
+	DocumentCount(ctx context.Context, request dto.ShipDocumentListParam, now time.Time) (int64, error)
+	DueReminders(ctx context.Context, days int, now time.Time) ([]dto.ShipDocumentResponse, error)
+	MarkReminded(ctx context.Context, ID int, days int, now time.Time) error
+	ExpiredDocuments(ctx context.Context, shipID int, types []model.DocumentType, now time.Time) ([]dto.ShipDocumentResponse, error)
+}
+
+type shipDocument struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewShipDocumentRepository(db *gorm.DB, redisClient *redis.Client) ShipDocument {
+	return &shipDocument{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *shipDocument) StoreDocument(ctx context.Context, document *model.ShipDocument) error {
+	return r.Db.WithContext(ctx).Create(document).Error
+}
+
+// UpdateDocument saves the document details, the reminder state is stored as given
+// so a renewed expiry can start its reminders over
+func (r *shipDocument) UpdateDocument(ctx context.Context, document model.ShipDocument) error {
+	result := r.Db.WithContext(ctx).Model(&model.ShipDocument{}).Where("id = ?", document.ID).Updates(map[string]interface{}{
+		"type":          document.Type,
+		"number":        document.Number,
+		"issuer":        document.Issuer,
+		"issued_at":     document.IssuedAt,
+		"expires_at":    document.ExpiresAt,
+		"attachment_id": document.AttachmentID,
+		"reminded_days": document.RemindedDays,
+		"reminded_at":   document.RemindedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *shipDocument) DeleteDocument(ctx context.Context, ID int) error {
+	result := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).Delete(&model.ShipDocument{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *shipDocument) DocumentByID(ctx context.Context, ID int) (*model.ShipDocument, error) {
+	var document model.ShipDocument
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.ShipScope(ctx, "ship_id")).Where("id = ?", ID).First(&document).Error; err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// filterDocument compares expiry dates with the date of now, documents without expiry are always valid
+func (r *shipDocument) filterDocument(query *gorm.DB, request dto.ShipDocumentListParam, now time.Time) *gorm.DB {
+	today := now.Format("2006-01-02")
+	limit := now.AddDate(0, 0, request.ExpiringDays).Format("2006-01-02")
+
+	if request.ShipID != 0 {
+		query = query.Where("ship_documents.ship_id = ?", request.ShipID)
+	}
+
+	if request.Type != "" {
+		query = query.Where("ship_documents.type = ?", request.Type)
+	}
+
+	switch request.Status {
+	case "valid":
+		query = query.Where("(ship_documents.expires_at IS NULL OR ship_documents.expires_at > ?)", limit)
+	case "expiring":
+		query = query.Where("ship_documents.expires_at BETWEEN ? AND ?", today, limit)
+	case "expired":
+		query = query.Where("ship_documents.expires_at < ?", today)
+	}
+
+	if request.Search != "" {
+		searchLower := "%" + strings.ToLower(request.Search) + "%"
+		query = query.Where("(lower(ships.name) LIKE ? OR lower(ship_documents.number) LIKE ?)", searchLower, searchLower)
+	}
+
+	return query
+}
+
+type shipDocumentRow struct {
+	model.ShipDocument
+	ShipName      string
+	FirebaseToken string
+	HarbourID     int
+}
+
+func (r *shipDocument) documentQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return query.Model(&model.ShipDocument{}).
+		Select("ship_documents.*, ships.name as ship_name, ships.firebase_token, ships.harbour_id").
+		Joins("JOIN ships ON ship_documents.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+}
+
+func (r *shipDocument) DocumentList(ctx context.Context, request dto.ShipDocumentListParam, now time.Time) ([]dto.ShipDocumentResponse, error) {
+	query := r.filterDocument(r.documentQuery(ctx, r.Db.WithContext(ctx)), request, now)
+
+	var result []shipDocumentRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("ship_documents.expires_at ASC NULLS LAST, ship_documents.id ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipDocumentResponse
+	for _, e := range result {
+		res = append(res, shipDocumentResponse(e, request.ExpiringDays, now))
+	}
+
+	return res, nil
+}
+
+func (r *shipDocument) DocumentCount(ctx context.Context, request dto.ShipDocumentListParam, now time.Time) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ShipDocument{}).
+		Joins("JOIN ships ON ship_documents.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+	query = r.filterDocument(query, request, now)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// DueReminders returns the documents expiring within days, expired ones included, whose last reminder was not sent yet
+func (r *shipDocument) DueReminders(ctx context.Context, days int, now time.Time) ([]dto.ShipDocumentResponse, error) {
+	var result []shipDocumentRow
+
+	err := r.documentQuery(ctx, r.Db.WithContext(ctx)).
+		Where("ship_documents.expires_at <= ?", now.AddDate(0, 0, days).Format("2006-01-02")).
+		Where("(ship_documents.reminded_days IS NULL OR ship_documents.reminded_days > 0)").
+		Order("ship_documents.expires_at ASC, ship_documents.id ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipDocumentResponse
+	for _, e := range result {
+		res = append(res, shipDocumentResponse(e, days, now))
+	}
+
+	return res, nil
+}
+
+func (r *shipDocument) MarkReminded(ctx context.Context, ID int, days int, now time.Time) error {
+	return r.Db.WithContext(ctx).Model(&model.ShipDocument{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"reminded_days": days,
+		"reminded_at":   now,
+	}).Error
+}
+
+// ExpiredDocuments returns the documents of the given types which expired before the date of now
+func (r *shipDocument) ExpiredDocuments(ctx context.Context, shipID int, types []model.DocumentType, now time.Time) ([]dto.ShipDocumentResponse, error) {
+	var result []shipDocumentRow
+
+	err := r.documentQuery(ctx, r.Db.WithContext(ctx)).
+		Where("ship_documents.ship_id = ? AND ship_documents.type IN ? AND ship_documents.expires_at < ?", shipID, types, now.Format("2006-01-02")).
+		Order("ship_documents.expires_at ASC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipDocumentResponse
+	for _, e := range result {
+		res = append(res, shipDocumentResponse(e, 0, now))
+	}
+
+	return res, nil
+}
+
+// documentDaysLeft counts the calendar days from the date of now to the expiry date
+func documentDaysLeft(expiresAt time.Time, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(expiry.Sub(today).Hours() / 24)
+}
+
+func shipDocumentResponse(e shipDocumentRow, expiringDays int, now time.Time) dto.ShipDocumentResponse {
+	res := dto.ShipDocumentResponse{
+		ID:            e.ID,
+		ShipID:        e.ShipID,
+		ShipName:      e.ShipName,
+		Type:          string(e.Type),
+		Number:        e.Number,
+		Issuer:        e.Issuer,
+		IssuedAt:      e.IssuedAt.Format("2006-01-02"),
+		Status:        "valid",
+		AttachmentID:  e.AttachmentID,
+		RemindedDays:  e.RemindedDays,
+		CreatedAt:     e.CreatedAt.Format("2006-01-02 15:04:05"),
+		FirebaseToken: e.FirebaseToken,
+		HarbourID:     e.HarbourID,
+	}
+
+	if e.ExpiresAt != nil {
+		daysLeft := documentDaysLeft(*e.ExpiresAt, now)
+		res.ExpiresAt = e.ExpiresAt.Format("2006-01-02")
+		res.DaysLeft = &daysLeft
+
+		if daysLeft < 0 {
+			res.Status = "expired"
+		} else if daysLeft <= expiringDays {
+			res.Status = "expiring"
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShipImport interface {
+	StoreImport(ctx context.Context, shipImport *model.ShipImport) error
+	ImportByID(ctx context.Context, ID int) (*model.ShipImport, error)
+	ImportDetail(ctx context.Context, ID int) (*dto.ShipImportResponse, error)
+	UpdateImport(ctx context.Context, shipImport model.ShipImport) error
+	ClaimImport(ctx context.Context, staleBefore time.Time) (*model.ShipImport, error)
+	QueueCommit(ctx context.Context, ID int, syncRows int) (bool, error)
+	ImportList(ctx context.Context, request dto.ShipImportListParam) ([]dto.ShipImportResponse, error)
+	ImportCount(ctx context.Context, request dto.ShipImportListParam) (int64, error)
+}
+
+type shipImport struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewShipImportRepository(db *gorm.DB, redisClient *redis.Client) ShipImport {
+	return &shipImport{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *shipImport) StoreImport(ctx context.Context, shipImport *model.ShipImport) error {
+	return r.Db.WithContext(ctx).Create(shipImport).Error
+}
+
+func (r *shipImport) ImportByID(ctx context.Context, ID int) (*model.ShipImport, error) {
+	var res model.ShipImport
+
+	if err := r.Db.WithContext(ctx).Scopes(tenant.Scope(ctx, "harbour_id")).Where("id = ?", ID).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *shipImport) ImportDetail(ctx context.Context, ID int) (*dto.ShipImportResponse, error) {
+	res, err := r.ImportByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := shipImportResponse(*res)
+
+	return &detail, nil
+}
+
+// UpdateImport saves the status, progress and results of an import
+func (r *shipImport) UpdateImport(ctx context.Context, shipImport model.ShipImport) error {
+	return r.Db.WithContext(ctx).Model(&model.ShipImport{}).Where("id = ?", shipImport.ID).Updates(map[string]interface{}{
+		"status":         shipImport.Status,
+		"dry_run":        shipImport.DryRun,
+		"total_rows":     shipImport.TotalRows,
+		"processed_rows": shipImport.ProcessedRows,
+		"created_rows":   shipImport.CreatedRows,
+		"updated_rows":   shipImport.UpdatedRows,
+		"failed_rows":    shipImport.FailedRows,
+		"errors":         shipImport.Errors,
+		"error":          shipImport.Error,
+		"started_at":     shipImport.StartedAt,
+		"finished_at":    shipImport.FinishedAt,
+	}).Error
+}
+
+// ClaimImport marks the oldest queued import as running and returns it, concurrent workers
+// skip imports claimed by another one. A running import without progress since staleBefore was
+// left by a crashed worker and is claimed again. It returns gorm.ErrRecordNotFound when nothing is queued.
+func (r *shipImport) ClaimImport(ctx context.Context, staleBefore time.Time) (*model.ShipImport, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	var res model.ShipImport
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? OR (status = ? AND updated_at < ?)", model.ImportQueued, model.ImportRunning, staleBefore).
+		Order("id ASC").
+		First(&res).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the claim bumps updated_at so the import is not stale for the other workers
+	if err := tx.Model(&model.ShipImport{}).Where("id = ?", res.ID).Update("status", model.ImportRunning).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	res.Status = model.ImportRunning
+
+	return &res, nil
+}
+
+// QueueCommit turns a validated dry run into a commit of the same file, imports of at most
+// syncRows rows are marked running at once for the request to commit them so the worker never
+// claims them. It reports false when the import is not waiting for a commit
+func (r *shipImport) QueueCommit(ctx context.Context, ID int, syncRows int) (bool, error) {
+	result := r.Db.WithContext(ctx).Model(&model.ShipImport{}).
+		Scopes(tenant.Scope(ctx, "harbour_id")).
+		Where("id = ? AND status = ?", ID, model.ImportValidated).
+		Updates(map[string]interface{}{
+			"status":         gorm.Expr("CASE WHEN total_rows <= ? THEN ? ELSE ? END", syncRows, model.ImportRunning, model.ImportQueued),
+			"dry_run":        0,
+			"processed_rows": 0,
+			"created_rows":   0,
+			"updated_rows":   0,
+			"failed_rows":    0,
+			"errors":         "",
+			"started_at":     nil,
+			"finished_at":    nil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *shipImport) filterImport(query *gorm.DB, request dto.ShipImportListParam) *gorm.DB {
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	return query
+}
+
+func (r *shipImport) ImportList(ctx context.Context, request dto.ShipImportListParam) ([]dto.ShipImportResponse, error) {
+	query := r.filterImport(r.Db.WithContext(ctx).Model(&model.ShipImport{}).Scopes(tenant.Scope(ctx, "harbour_id")), request)
+
+	var result []model.ShipImport
+	if err := query.Limit(request.Limit).Offset(request.Offset).Order("id DESC").Find(&result).Error; err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipImportResponse
+	for _, e := range result {
+		item := shipImportResponse(e)
+		item.Errors = nil
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (r *shipImport) ImportCount(ctx context.Context, request dto.ShipImportListParam) (int64, error) {
+	query := r.filterImport(r.Db.WithContext(ctx).Model(&model.ShipImport{}).Scopes(tenant.Scope(ctx, "harbour_id")), request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// shipImportResponse builds the response of an import, the row errors are decoded from their json column
+func shipImportResponse(e model.ShipImport) dto.ShipImportResponse {
+	res := dto.ShipImportResponse{
+		ID:            e.ID,
+		FileName:      e.FileName,
+		Status:        string(e.Status),
+		DryRun:        e.DryRun == 1,
+		TotalRows:     e.TotalRows,
+		ProcessedRows: e.ProcessedRows,
+		CreatedRows:   e.CreatedRows,
+		UpdatedRows:   e.UpdatedRows,
+		FailedRows:    e.FailedRows,
+		Error:         e.Error,
+		CreatedBy:     e.CreatedBy,
+		CreatedAt:     e.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if e.TotalRows > 0 {
+		res.Progress = float64(e.ProcessedRows*10000/e.TotalRows) / 100
+	}
+
+	if e.Errors != "" {
+		json.Unmarshal([]byte(e.Errors), &res.Errors)
+	}
+
+	if e.StartedAt != nil {
+		res.StartedAt = e.StartedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if e.FinishedAt != nil {
+		res.FinishedAt = e.FinishedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/tenant"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type ShipVisit interface {
+	OngoingVisit(ctx context.Context, shipID int) (*model.ShipVisit, error)
+	StoreVisit(ctx context.Context, visit *model.ShipVisit) error
+	DepartVisit(ctx context.Context, ID int, departedAt time.Time) error
+	VisitList(ctx context.Context, request dto.ShipVisitListParam) ([]dto.ShipVisitResponse, error)
+	VisitCount(ctx context.Context, request dto.ShipVisitListParam) (int64, error)
+	VisitDetail(ctx context.Context, ID int) (*dto.ShipVisitResponse, error)
+}
+
+type shipVisit struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewShipVisitRepository(db *gorm.DB, redisClient *redis.Client) ShipVisit {
+	return &shipVisit{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+// visitScope shows a visit to the harbour visited as well as to the home harbour of the ship
+func visitScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := tenant.Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where("(ship_visits.harbour_id IN ? OR ship_visits.home_harbour_id IN ?)", ids, ids)
+	}
+}
+
+// OngoingVisit returns the visit the ship has not departed from yet, nil when it is not visiting
+func (r *shipVisit) OngoingVisit(ctx context.Context, shipID int) (*model.ShipVisit, error) {
+	var visit model.ShipVisit
+
+	err := r.Db.WithContext(ctx).Scopes(visitScope(ctx)).
+		Where("ship_id = ? AND status = ?", shipID, model.VisitOngoing).
+		Order("arrived_at DESC").
+		First(&visit).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &visit, nil
+}
+
+func (r *shipVisit) StoreVisit(ctx context.Context, visit *model.ShipVisit) error {
+	return r.Db.WithContext(ctx).Create(visit).Error
+}
+
+func (r *shipVisit) DepartVisit(ctx context.Context, ID int, departedAt time.Time) error {
+	return r.Db.WithContext(ctx).Model(&model.ShipVisit{}).
+		Where("id = ? AND status = ?", ID, model.VisitOngoing).
+		Updates(map[string]interface{}{
+			"status":      model.VisitDeparted,
+			"departed_at": departedAt,
+		}).Error
+}
+
+func (r *shipVisit) filterVisit(ctx context.Context, query *gorm.DB, request dto.ShipVisitListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("ship_visits.ship_id = ?", request.ShipID)
+	}
+
+	if request.Status != "" {
+		query = query.Where("ship_visits.status = ?", request.Status)
+	}
+
+	if ids, ok := tenant.Harbours(ctx); ok {
+		switch request.Direction {
+		case "incoming":
+			query = query.Where("ship_visits.harbour_id IN ?", ids)
+		case "outgoing":
+			query = query.Where("ship_visits.home_harbour_id IN ?", ids)
+		}
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(ship_visits.arrived_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *shipVisit) visitQuery(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return query.Model(&model.ShipVisit{}).
+		Select("ship_visits.*, ships.name as ship_name, ships.responsible_name, ships.phone, " +
+			"home.name as home_harbour_name, visited.name as harbour_name").
+		Joins("JOIN ships ON ship_visits.ship_id = ships.id").
+		Joins("LEFT JOIN harbours home ON ship_visits.home_harbour_id = home.id").
+		Joins("LEFT JOIN harbours visited ON ship_visits.harbour_id = visited.id").
+		Scopes(visitScope(ctx))
+}
+
+type visitRow struct {
+	model.ShipVisit
+	ShipName        string
+	ResponsibleName string
+	Phone           string
+	HomeHarbourName string
+	HarbourName     string
+}
+
+func (r *shipVisit) VisitList(ctx context.Context, request dto.ShipVisitListParam) ([]dto.ShipVisitResponse, error) {
+	query := r.filterVisit(ctx, r.visitQuery(ctx, r.Db.WithContext(ctx)), request)
+
+	var result []visitRow
+	err := query.Limit(request.Limit).Offset(request.Offset).
+		Order("ship_visits.arrived_at DESC, ship_visits.id DESC").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.ShipVisitResponse
+	for _, e := range result {
+		res = append(res, visitResponse(e))
+	}
+
+	return res, nil
+}
+
+func (r *shipVisit) VisitCount(ctx context.Context, request dto.ShipVisitListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.ShipVisit{}).
+		Joins("JOIN ships ON ship_visits.ship_id = ships.id").
+		Scopes(visitScope(ctx))
+	query = r.filterVisit(ctx, query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *shipVisit) VisitDetail(ctx context.Context, ID int) (*dto.ShipVisitResponse, error) {
+	var result visitRow
+
+	if err := r.visitQuery(ctx, r.Db.WithContext(ctx)).Where("ship_visits.id = ?", ID).First(&result).Error; err != nil {
+		return nil, err
+	}
+
+	res := visitResponse(result)
+
+	return &res, nil
+}
+
+func visitResponse(e visitRow) dto.ShipVisitResponse {
+	res := dto.ShipVisitResponse{
+		ID:              e.ID,
+		ShipID:          e.ShipID,
+		ShipName:        e.ShipName,
+		ResponsibleName: e.ResponsibleName,
+		Phone:           e.Phone,
+		HomeHarbourID:   e.HomeHarbourID,
+		HomeHarbourName: e.HomeHarbourName,
+		HarbourID:       e.HarbourID,
+		HarbourName:     e.HarbourName,
+		Status:          string(e.Status),
+		Lat:             e.Lat,
+		Long:            e.Long,
+		ArrivedAt:       e.ArrivedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if e.DepartedAt != nil {
+		res.DepartedAt = e.DepartedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return res
+}
@@ -8,6 +8,7 @@ import (
 	"owlharbour-api/internal/dto"
 	"owlharbour-api/internal/model"
 	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
 	"owlharbour-api/pkg/util"
 	"strings"
 	"time"
@@ -18,13 +19,16 @@ import (
 
 type User interface {
 	GetAll(ctx context.Context, request dto.UserListParam) ([]dto.AllUser, error)
+	Count(ctx context.Context, request dto.UserListParam) (int64, error)
 	Find(ctx context.Context, queries []string, argsSlice ...[]interface{}) (model.User, error)
 	Store(ctx context.Context, data model.User) error
 	FindOne(ctx context.Context, selectedFields string, query string, args ...any) (model.User, error)
+	FindScoped(ctx context.Context, selectedFields string, ID int) (model.User, error)
 	UpdateOne(ctx context.Context, updatedModels *dto.PayloadUpdateUser, updatedField string, query string, args ...interface{}) error
 	UpdateJwtToken(ctx context.Context, updatedModels *dto.PayloadUpdateJwtToken, updatedField string, query string, args ...interface{}) error
 	DeleteOne(ctx context.Context, query string, args ...interface{}) error
 	RemoveJwtToken(ctx context.Context, updatedModels *dto.PayloadUpdateJwtToken, updatedField string, query string, args ...interface{}) error
+	AdminEmails(ctx context.Context) ([]string, error)
 }
 
 type user struct {
@@ -51,7 +55,7 @@ func (r *user) GetAll(ctx context.Context, request dto.UserListParam) ([]dto.All
 	hash := sha1.Sum(paramJSON)
 	uniqueString := fmt.Sprintf("%x", hash)
 
-	cacheKey := "user_list-" + uniqueString
+	cacheKey := tenant.CacheKey(ctx, "user_list-"+uniqueString)
 
 	if r.CacheEnabled {
 		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
@@ -65,13 +69,8 @@ func (r *user) GetAll(ctx context.Context, request dto.UserListParam) ([]dto.All
 
 	tx := r.Db.WithContext(ctx).Begin()
 
-	query := tx.Model(&model.User{})
-
-	if request.Search != "" {
-		searchLower := strings.ToLower(request.Search)
-		query = query.Where("lower(name) LIKE ? OR lower(email) LIKE ?", "%"+searchLower+"%", "%"+searchLower+"%")
-	}
-
+	query := tx.Model(&model.User{}).Scopes(r.harbourUsers(ctx))
+	query = r.filterUser(query, request)
 	query = query.Limit(request.Limit).Offset(request.Offset).Order("created_at DESC")
 
 	var res []model.User
@@ -126,6 +125,75 @@ func (r *user) GetAll(ctx context.Context, request dto.UserListParam) ([]dto.All
 	return AllUser, nil
 }
 
+func (r *user) filterUser(query *gorm.DB, request dto.UserListParam) *gorm.DB {
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(name) LIKE ? OR lower(email) LIKE ?", "%"+searchLower+"%", "%"+searchLower+"%")
+	}
+
+	return query
+}
+
+// harbourUsers limits users to the admins assigned to the harbours of ctx and the mobile users
+// of their ships, superadmins are only visible to an unscoped ctx
+func (r *user) harbourUsers(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := tenant.Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where("users.id IN (SELECT user_id FROM user_harbours WHERE harbour_id IN ?) "+
+			"OR users.id IN (SELECT user_id FROM ships WHERE harbour_id IN ? AND deleted_at IS NULL)", ids, ids)
+	}
+}
+
+// Count counts the users matching the list filters, paging is ignored
+func (r *user) Count(ctx context.Context, request dto.UserListParam) (int64, error) {
+	request.Offset, request.Limit = 0, 0
+
+	paramJSON, err := json.Marshal(request)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 0, err
+	}
+
+	hash := sha1.Sum(paramJSON)
+	uniqueString := fmt.Sprintf("%x", hash)
+
+	// shares the user_list- prefix so it is cleared together with the list cache
+	cacheKey := tenant.CacheKey(ctx, "user_list-count-"+uniqueString)
+
+	if r.CacheEnabled {
+		cachedData, err := r.RedisClient.Get(ctx, cacheKey).Result()
+		if err == nil {
+			var cachedInfo int64
+			if err := json.Unmarshal([]byte(cachedData), &cachedInfo); err == nil {
+				return cachedInfo, nil
+			}
+		}
+	}
+
+	query := r.Db.WithContext(ctx).Model(&model.User{}).Scopes(r.harbourUsers(ctx))
+	query = r.filterUser(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	if r.CacheEnabled {
+		jsonData, err := json.Marshal(res)
+		if err == nil {
+			r.RedisClient.Set(ctx, cacheKey, jsonData, time.Hour)
+		} else {
+			fmt.Println("Error marshalling data for cache:", err)
+		}
+	}
+
+	return res, nil
+}
+
 func (r *user) Store(ctx context.Context, data model.User) error {
 	tx := r.Db.WithContext(ctx)
 	if err := tx.Model(model.User{}).Create(&data).Error; err != nil {
@@ -154,6 +222,20 @@ func (r *user) FindOne(ctx context.Context, selectedFields string, query string,
 	return res, nil
 }
 
+// FindScoped finds a user by id among the users visible to the harbours of ctx
+func (r *user) FindScoped(ctx context.Context, selectedFields string, ID int) (model.User, error) {
+	var res model.User
+
+	db := r.Db.WithContext(ctx).Model(model.User{}).Scopes(r.harbourUsers(ctx))
+	db = util.SetSelectFields(db, selectedFields)
+
+	if err := db.Where("users.id = ?", ID).Take(&res).Error; err != nil {
+		return model.User{}, err
+	}
+
+	return res, nil
+}
+
 func (r *user) Find(ctx context.Context, queries []string, argsSlice ...[]interface{}) (model.User, error) {
 	var res model.User
 
@@ -235,3 +317,24 @@ func (r *user) DeleteOne(ctx context.Context, query string, args ...interface{})
 
 	return nil
 }
+
+// AdminEmails returns the verified email addresses of the superadmin accounts and the admins
+// of the harbours of ctx, every admin when ctx is not scoped
+func (r *user) AdminEmails(ctx context.Context) ([]string, error) {
+	var res []string
+
+	query := r.Db.WithContext(ctx).Model(&model.User{}).
+		Where("role IN ? AND email <> '' AND email_verified_at IS NOT NULL", []model.RoleType{model.SuperAdmin, model.Admin})
+
+	if ids, ok := tenant.Harbours(ctx); ok {
+		query = query.Where("role = ? OR id IN (SELECT user_id FROM user_harbours WHERE harbour_id IN ?)", model.SuperAdmin, ids)
+	}
+
+	err := query.Order("id ASC").
+		Pluck("email", &res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
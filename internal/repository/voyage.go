@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"owlharbour-api/internal/dto"
+	"owlharbour-api/internal/model"
+	"owlharbour-api/pkg/helper"
+	"owlharbour-api/pkg/tenant"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Voyage interface {
+	StartVoyage(ctx context.Context, ShipID int, checkoutLogID int, lat string, long string) error
+	UpdateVoyageProgress(ctx context.Context, request dto.VoyageProgress) error
+	CompleteVoyage(ctx context.Context, ShipID int, checkinLogID int) error
+	VoyageList(ctx context.Context, request dto.VoyageListParam) ([]dto.VoyageResponse, error)
+	VoyageCount(ctx context.Context, request dto.VoyageListParam) (int64, error)
+	VoyageByID(ctx context.Context, ID int) (*dto.VoyageResponse, error)
+	DockedLogsByShip(ctx context.Context, ShipID int) ([]model.ShipDockedLog, error)
+	LocationLogsBetween(ctx context.Context, ShipID int, start time.Time, end time.Time) ([]model.ShipLocationLog, error)
+	ReplaceShipVoyages(ctx context.Context, ShipID int, voyages []model.Voyage) error
+	ShipIDsWithDockedLogs(ctx context.Context) ([]int, error)
+}
+
+type voyage struct {
+	Db          *gorm.DB
+	RedisClient *redis.Client
+}
+
+func NewVoyageRepository(db *gorm.DB, redisClient *redis.Client) Voyage {
+	return &voyage{
+		Db:          db,
+		RedisClient: redisClient,
+	}
+}
+
+func (r *voyage) StartVoyage(ctx context.Context, ShipID int, checkoutLogID int, lat string, long string) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	voyageModel := model.Voyage{
+		ShipID:        ShipID,
+		CheckoutLogID: checkoutLogID,
+		Status:        model.VoyageOngoing,
+		DepartedAt:    time.Now(),
+		LastLat:       lat,
+		LastLong:      long,
+	}
+
+	if err := tx.Create(&voyageModel).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateVoyageProgress extends the ongoing voyage of the ship with the leg to the fix, the voyage
+// row is locked so concurrent fixes measure their legs one after the other and the totals are
+// incremented in the statement
+func (r *voyage) UpdateVoyageProgress(ctx context.Context, request dto.VoyageProgress) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	var current model.Voyage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ship_id = ? AND status = ?", request.ShipID, model.VoyageOngoing).Order("departed_at DESC").First(&current).Error
+	if err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			// the ship is not on a voyage, nothing to update
+			return nil
+		}
+		return err
+	}
+
+	var leg float64
+	lastLat, errLat := strconv.ParseFloat(current.LastLat, 64)
+	lastLong, errLong := strconv.ParseFloat(current.LastLong, 64)
+	lat, errNewLat := strconv.ParseFloat(request.Lat, 64)
+	long, errNewLong := strconv.ParseFloat(request.Long, 64)
+	if errLat == nil && errLong == nil && errNewLat == nil && errNewLong == nil {
+		leg = helper.Haversine(helper.GeoPoint{Lat: lastLat, Long: lastLong}, helper.GeoPoint{Lat: lat, Long: long})
+	}
+
+	updateFields := map[string]interface{}{
+		"distance":     gorm.Expr("distance + ?", leg),
+		"max_distance": gorm.Expr("GREATEST(max_distance, ?)", request.HarbourDistance),
+		"last_lat":     request.Lat,
+		"last_long":    request.Long,
+	}
+
+	if request.IsFraud == 1 {
+		updateFields["fraud_count"] = gorm.Expr("fraud_count + 1")
+	}
+
+	if err := tx.Model(&model.Voyage{}).Where("id = ?", current.ID).Updates(updateFields).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *voyage) CompleteVoyage(ctx context.Context, ShipID int, checkinLogID int) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	// locked like the progress updates, a concurrent fix extends the voyage before or not at all
+	var current model.Voyage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ship_id = ? AND status = ?", ShipID, model.VoyageOngoing).Order("departed_at DESC").First(&current).Error
+	if err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	arrivedAt := time.Now()
+
+	updateFields := map[string]interface{}{
+		"status":         model.VoyageCompleted,
+		"checkin_log_id": checkinLogID,
+		"arrived_at":     arrivedAt,
+		"duration":       int64(arrivedAt.Sub(current.DepartedAt).Seconds()),
+	}
+
+	if err := tx.Model(&model.Voyage{}).Where("id = ?", current.ID).Updates(updateFields).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *voyage) filterVoyage(query *gorm.DB, request dto.VoyageListParam) *gorm.DB {
+	if request.ShipID != 0 {
+		query = query.Where("voyages.ship_id = ?", request.ShipID)
+	}
+
+	if request.Status != nil && len(request.Status) > 0 && request.Status[0] != "" {
+		query = query.Where("voyages.status IN (?)", request.Status)
+	}
+
+	if request.Search != "" {
+		searchLower := strings.ToLower(request.Search)
+		query = query.Where("lower(ships.name) LIKE ?", "%"+searchLower+"%")
+	}
+
+	if request.StartDate != "" && request.EndDate != "" {
+		query = query.Where("DATE(voyages.departed_at) BETWEEN ? AND ?", request.StartDate, request.EndDate)
+	}
+
+	return query
+}
+
+func (r *voyage) VoyageList(ctx context.Context, request dto.VoyageListParam) ([]dto.VoyageResponse, error) {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	query := tx.Model(&model.Voyage{}).
+		Select("voyages.*, ships.name as ship_name").
+		Joins("JOIN ships ON voyages.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterVoyage(query, request)
+	query = query.Limit(request.Limit).Offset(request.Offset).Order("voyages.departed_at DESC")
+
+	var result []struct {
+		model.Voyage
+		ShipName string `json:"ship_name"`
+	}
+
+	if err := query.Find(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var voyages []dto.VoyageResponse
+	for _, e := range result {
+		voyages = append(voyages, voyageResponse(e.Voyage, e.ShipName))
+	}
+
+	return voyages, nil
+}
+
+func (r *voyage) VoyageCount(ctx context.Context, request dto.VoyageListParam) (int64, error) {
+	query := r.Db.WithContext(ctx).Model(&model.Voyage{}).
+		Joins("JOIN ships ON voyages.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id"))
+
+	query = r.filterVoyage(query, request)
+
+	var res int64
+	if err := query.Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (r *voyage) VoyageByID(ctx context.Context, ID int) (*dto.VoyageResponse, error) {
+	var result struct {
+		model.Voyage
+		ShipName string `json:"ship_name"`
+	}
+
+	err := r.Db.WithContext(ctx).Model(&model.Voyage{}).
+		Select("voyages.*, ships.name as ship_name").
+		Joins("JOIN ships ON voyages.ship_id = ships.id").
+		Scopes(tenant.Scope(ctx, "ships.harbour_id")).
+		Where("voyages.id = ?", ID).
+		Take(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := voyageResponse(result.Voyage, result.ShipName)
+
+	return &res, nil
+}
+
+func (r *voyage) DockedLogsByShip(ctx context.Context, ShipID int) ([]model.ShipDockedLog, error) {
+	var logs []model.ShipDockedLog
+
+	if err := r.Db.WithContext(ctx).Where("ship_id = ?", ShipID).Order("created_at ASC, id ASC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (r *voyage) LocationLogsBetween(ctx context.Context, ShipID int, start time.Time, end time.Time) ([]model.ShipLocationLog, error) {
+	var logs []model.ShipLocationLog
+
+	err := r.Db.WithContext(ctx).
+		Where("ship_id = ? AND created_at BETWEEN ? AND ?", ShipID, start, end).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (r *voyage) ReplaceShipVoyages(ctx context.Context, ShipID int, voyages []model.Voyage) error {
+	tx := r.Db.WithContext(ctx).Begin()
+
+	if err := tx.Unscoped().Where("ship_id = ?", ShipID).Delete(&model.Voyage{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(voyages) > 0 {
+		if err := tx.CreateInBatches(&voyages, 100).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *voyage) ShipIDsWithDockedLogs(ctx context.Context) ([]int, error) {
+	var ids []int
+
+	err := r.Db.WithContext(ctx).Model(&model.ShipDockedLog{}).
+		Scopes(tenant.ShipScope(ctx, "ship_id")).
+		Distinct("ship_id").
+		Pluck("ship_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func voyageResponse(v model.Voyage, shipName string) dto.VoyageResponse {
+	res := dto.VoyageResponse{
+		ID:            v.ID,
+		ShipID:        v.ShipID,
+		ShipName:      shipName,
+		Status:        string(v.Status),
+		CheckoutLogID: v.CheckoutLogID,
+		CheckinLogID:  v.CheckinLogID,
+		DepartedAt:    v.DepartedAt.Format("2006-01-02 15:04:05"),
+		Duration:      v.Duration,
+		Distance:      v.Distance,
+		MaxDistance:   v.MaxDistance,
+		FraudCount:    v.FraudCount,
+	}
+
+	if v.ArrivedAt != nil {
+		res.ArrivedAt = v.ArrivedAt.Format("2006-01-02 15:04:05")
+	} else {
+		// ongoing voyage, report the elapsed time so far
+		res.Duration = int64(time.Since(v.DepartedAt).Seconds())
+	}
+
+	return res
+}
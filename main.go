@@ -7,6 +7,10 @@ import (
 	"owlharbour-api/database"
 	"owlharbour-api/database/migration"
 	"owlharbour-api/database/seeder"
+	"owlharbour-api/internal/app/document"
+	"owlharbour-api/internal/app/heatmap"
+	"owlharbour-api/internal/app/inspection"
+	"owlharbour-api/internal/app/scheduler"
 	"owlharbour-api/internal/app/ship"
 	"owlharbour-api/internal/factory"
 	"owlharbour-api/internal/http"
@@ -64,6 +68,31 @@ func main() {
 			ship.NewHandler(f).WorkerRecordLog(ctx)
 		}
 
+		if c == "import" {
+			ctx := context.Background()
+			ship.NewHandler(f).WorkerImport(ctx)
+		}
+
+		if c == "scheduler" {
+			ctx := context.Background()
+			scheduler.NewHandler(f).WorkerSchedule(ctx)
+		}
+
+		if c == "inspection" {
+			ctx := context.Background()
+			inspection.NewHandler(f).WorkerOverdue(ctx)
+		}
+
+		if c == "document" {
+			ctx := context.Background()
+			document.NewHandler(f).WorkerExpiry(ctx)
+		}
+
+		if c == "heatmap" {
+			ctx := context.Background()
+			heatmap.NewHandler(f).WorkerRollup(ctx)
+		}
+
 		return
 	}
 
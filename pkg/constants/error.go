@@ -31,4 +31,111 @@ var (
 
 	NotFoundDataAppSetting = errors.New("Data app setting not found!")
 	ErrorUpdateAppSetting  = errors.New("Error update app setting")
+
+	InvalidFraudCaseStatus     = errors.New("Invalid fraud case status")
+	InvalidFraudCaseTransition = errors.New("Fraud case can not move to the requested status")
+	InvalidSanctionType        = errors.New("Invalid sanction type")
+	FraudCaseClosed            = errors.New("Fraud case is already closed")
+	InvalidCaseAssignee        = errors.New("Fraud case assignee must be an admin of the harbour of the ship")
+
+	InvalidReportKind    = errors.New("Invalid report, use docking, fraud or activity")
+	InvalidReportFormat  = errors.New("Format is not available for this report")
+	InvalidJobFrequency  = errors.New("Invalid frequency, use daily, weekly or monthly")
+	InvalidJobRunTime    = errors.New("Invalid run time, use HH:MM")
+	InvalidJobRecipients = errors.New("Recipients must be valid email addresses")
+	InvalidJobSchedule   = errors.New("Weekday must be between 0 and 6, month day between 1 and 28")
+	InvalidJobMaxRetry   = errors.New("Max retry cannot be negative, use 0 to never retry")
+
+	InvalidCursor = errors.New("Invalid cursor, use the next_cursor of the previous page")
+
+	InspectionNotCheckin       = errors.New("Inspection can only be recorded against a check-in log")
+	InspectionExists           = errors.New("Docked log is already inspected")
+	InspectionTemplateInactive = errors.New("Inspection template is not active")
+	InspectionTemplateEmpty    = errors.New("Inspection template needs at least one checklist item")
+	InvalidChecklistCategory   = errors.New("Invalid checklist category, use safety_gear, license, catch or other")
+	InvalidChecklistItem       = errors.New("Checklist item does not belong to the inspection template")
+	InvalidChecklistResult     = errors.New("Invalid checklist result, use pass, fail or na")
+	ChecklistIncomplete        = errors.New("Every required checklist item needs a result")
+	InvalidInspectionOutcome   = errors.New("Invalid outcome, use passed, conditional or failed")
+	InspectionOutcomeLenient   = errors.New("Outcome cannot be more lenient than the checklist results")
+	InvalidInspectionTime      = errors.New("Invalid started_at, use YYYY-MM-DD HH:MM:SS")
+	InvalidAssignMode          = errors.New("Invalid inspection assignment, use round_robin or manual")
+	InvalidInspectionSla       = errors.New("Inspection SLA must be a positive number of hours")
+	InvalidTimezone            = errors.New("Invalid timezone, use an IANA zone such as Asia/Jakarta")
+	InvalidInspector           = errors.New("Inspector must be an admin user")
+	InspectionTaskDone         = errors.New("Inspection task is already done")
+
+	LandingNotFishingVessel = errors.New("Landings can only be recorded for kapal tangkap")
+	LandingNotCheckin       = errors.New("Landing must belong to a check-in log of the ship")
+	LandingExists           = errors.New("Docked log already has a landing")
+	InvalidFishingGear      = errors.New("Invalid gear, use gillnet, longline, purse_seine, trawl, handline, pole_and_line, trap or other")
+	InvalidCatchWeight      = errors.New("Catch weight must be more than 0 and at most 500000 kg")
+	InvalidCatchSpecies     = errors.New("Species of a catch must not be empty")
+	DuplicateCatchSpecies   = errors.New("Every species can only be listed once per landing")
+	InvalidLandingTime      = errors.New("Invalid landed_at, use YYYY-MM-DD HH:MM:SS between check-in and now")
+
+	InvalidCrewPosition    = errors.New("Invalid position, use captain, officer, engineer, deckhand, cook or other")
+	CrewNotOnShip          = errors.New("Crew member is not an active crew of the ship")
+	DuplicateCrewMember    = errors.New("Every crew member can only be listed once per manifest")
+	ManifestWithoutCaptain = errors.New("Manifest needs a captain on board")
+	ManifestNotCheckin     = errors.New("Manifest can only be submitted while the ship is checked in")
+
+	InvalidDocumentType        = errors.New("Invalid document type, use siup, bkp, selar_mark, seaworthiness or other")
+	InvalidDocumentDate        = errors.New("Invalid document date, use YYYY-MM-DD with the expiry after the issue date")
+	DocumentAttachmentMismatch = errors.New("Document attachment must be uploaded for the same ship")
+
+	InvalidImportFormat = errors.New("Invalid import file, use csv or xlsx")
+	ImportMissingColumn = errors.New("Import file needs a header row with a name column")
+	ImportTooManyRows   = errors.New("Import file exceeds the maximum number of rows")
+	ImportNotValidated  = errors.New("Import must finish its dry run before it can be committed")
+
+	InvalidBerthGeofence  = errors.New("Berth zone needs at least 3 points")
+	BerthCodeTaken        = errors.New("Berth code is already used in this harbour")
+	BerthInactive         = errors.New("Berth is not active")
+	BerthOtherHarbour     = errors.New("Berth belongs to another harbour than the ship")
+	InvalidBerthWindow    = errors.New("Invalid arrival window, use YYYY-MM-DD HH:MM:SS with the departure after the arrival")
+	BerthFull             = errors.New("Berth is fully booked for the arrival window")
+	BerthShipTooLong      = errors.New("Ship is longer than the berth allows")
+	BerthShipTooHeavy     = errors.New("Ship gross tonnage exceeds the berth limit")
+	BerthShipSizeUnknown  = errors.New("Fill in the dimension and GT of the ship to check the berth limits")
+	ReservationOverlap    = errors.New("Ship already has a reservation in the arrival window")
+	ReservationNotPending = errors.New("Reservation is already confirmed or cancelled")
+
+	TariffNotFound       = errors.New("No active port tariff for the zone type of the stay")
+	TariffZoneTaken      = errors.New("Zone type already has an active tariff in this harbour")
+	StayNotCompleted     = errors.New("Stay needs a check-in before the check-out")
+	InvoiceNotDraft      = errors.New("Only a draft invoice can be issued")
+	InvoiceNotIssued     = errors.New("Payments can only be recorded on an issued invoice")
+	InvoiceCannotVoid    = errors.New("Only draft or issued invoices without payments can be voided")
+	InvalidPaymentAmount = errors.New("Payment amount must be more than 0 and at most the outstanding amount")
+	InvalidPaymentTime   = errors.New("Invalid paid_at, use YYYY-MM-DD HH:MM:SS not later than now")
+
+	InvalidOccupancyInterval = errors.New("Invalid interval, use hour or day")
+	InvalidOccupancyRange    = errors.New("Invalid range, use YYYY-MM-DD dates up to 31 days by hour or 366 days by day")
+
+	InvalidHeatmapRange     = errors.New("Invalid range, use YYYY-MM-DD dates up to 366 days")
+	InvalidHeatmapPrecision = errors.New("Invalid precision, use 3 to 7")
+	InvalidHeatmapFilter    = errors.New("Invalid filter, ship_type must be kapal angkut or kapal tangkap and on_ground 0 or 1")
+
+	InvalidStatisticGranularity = errors.New("Invalid granularity, use hour, day, week or month")
+	InvalidStatisticRange       = errors.New("Invalid period, use YYYY-MM-DD dates up to 31 days by hour, 366 by day, 730 by week or 1830 by month")
+
+	InvalidAsOfDate = errors.New("Invalid date, use YYYY-MM-DD or YYYY-MM-DD HH:MM:SS")
+
+	InvalidTrackRange = errors.New("Invalid range, use YYYY-MM-DD dates up to 31 days")
+
+	HarbourRequired  = errors.New("Select a harbour with the X-Harbour-Code header")
+	HarbourForbidden = errors.New("You don't have access to this harbour")
+	HarbourCodeTaken = errors.New("Harbour code is already used by another harbour")
+	InvalidHarbour   = errors.New("Invalid harbour code, harbour not found or inactive")
+
+	InvalidAttachmentOwner    = errors.New("Invalid owner type, use inspection, ship or pairing_request")
+	InvalidAttachmentCategory = errors.New("Category is not available for this owner type")
+	AttachmentOwnerNotFound   = errors.New("Attachment owner not found")
+	AttachmentTooLarge        = errors.New("File exceeds the maximum upload size")
+	AttachmentTypeNotAllowed  = errors.New("File type is not allowed for this category")
+	InvalidStorageKey         = errors.New("Invalid storage key")
+	StorageObjectNotFound     = errors.New("File not found in storage")
+	InvalidSignature          = errors.New("Invalid download signature")
+	SignedURLExpired          = errors.New("Download link has expired")
 )
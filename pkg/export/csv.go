@@ -0,0 +1,33 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) Writer {
+	// UTF-8 byte order mark so spreadsheet applications detect the encoding of ship names
+	w.Write([]byte{0xEF, 0xBB, 0xBF})
+
+	return &csvWriter{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (c *csvWriter) Write(row []string) error {
+	cells := make([]string, len(row))
+	for i, value := range row {
+		cells[i] = escapeCell(value)
+	}
+
+	return c.writer.Write(cells)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Writer writes a table row by row, nothing but the current row is kept in memory.
+// Close must be called once after the last row to finish the document.
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// escapeCell quotes a value a spreadsheet application would run as a formula, ship names and
+// notes are typed by users. Numbers such as negative coordinates are left as they are
+func escapeCell(value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+
+		return "'" + value
+	}
+
+	return value
+}
+
+// ContentType returns the mime type of a format and whether the format is supported,
+// response headers must be set before NewWriter since writers start writing immediately
+func ContentType(format string) (string, bool) {
+	contentType, ok := contentTypes[format]
+	return contentType, ok
+}
+
+// NewWriter returns the table writer of the given format, sheet is only used by formats with named sheets
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet)
+	}
+
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
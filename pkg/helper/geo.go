@@ -0,0 +1,114 @@
+package helper
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	EarthRadius           = 6371008.8 // mean earth radius in metres
+	MetersPerSecondToKnot = 1.943844
+)
+
+type GeoPoint struct {
+	Lat  float64
+	Long float64
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// Haversine returns the great-circle distance between two points in metres
+func Haversine(a, b GeoPoint) float64 {
+	lat1 := toRadians(a.Lat)
+	lat2 := toRadians(b.Lat)
+	dLat := lat2 - lat1
+	dLong := toRadians(b.Long - a.Long)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bearing returns the initial course from a to b in degrees clockwise from true north (0 - 360)
+func Bearing(a, b GeoPoint) float64 {
+	lat1 := toRadians(a.Lat)
+	lat2 := toRadians(b.Lat)
+	dLong := toRadians(b.Long - a.Long)
+
+	y := math.Sin(dLong) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLong)
+
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+// SpeedOverGround returns the speed in knots needed to travel from a to b in the elapsed time
+func SpeedOverGround(a, b GeoPoint, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return Haversine(a, b) / elapsed.Seconds() * MetersPerSecondToKnot
+}
+
+// DistanceToPolygonEdge returns the distance in metres from p to the closest edge of a polygon
+// given as [lat, long] pairs, regardless of p being inside or outside
+func DistanceToPolygonEdge(p GeoPoint, polygon [][2]float64) float64 {
+	if len(polygon) == 0 {
+		return 0
+	}
+
+	if len(polygon) == 1 {
+		return Haversine(p, GeoPoint{Lat: polygon[0][0], Long: polygon[0][1]})
+	}
+
+	closest := math.MaxFloat64
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		start := GeoPoint{Lat: polygon[j][0], Long: polygon[j][1]}
+		end := GeoPoint{Lat: polygon[i][0], Long: polygon[i][1]}
+
+		if d := perpendicularDistance(p, start, end); d < closest {
+			closest = d
+		}
+	}
+
+	return closest
+}
+
+// DistanceFromHarbour is zero for a point inside the harbour polygon and the distance
+// to the nearest polygon edge otherwise
+func DistanceFromHarbour(p GeoPoint, polygon [][2]float64) float64 {
+	if StatusCheck([2]float64{p.Lat, p.Long}, polygon) {
+		return 0
+	}
+
+	return DistanceToPolygonEdge(p, polygon)
+}
+
+// perpendicularDistance returns the distance in metres from p to the segment start-end,
+// the points are projected on a local equirectangular plane around start which is
+// accurate enough for harbour sized geometry and ship track segments
+func perpendicularDistance(p, start, end GeoPoint) float64 {
+	cosLat := math.Cos(toRadians(start.Lat))
+
+	x := toRadians(p.Long-start.Long) * cosLat * EarthRadius
+	y := toRadians(p.Lat-start.Lat) * EarthRadius
+	ex := toRadians(end.Long-start.Long) * cosLat * EarthRadius
+	ey := toRadians(end.Lat-start.Lat) * EarthRadius
+
+	length := ex*ex + ey*ey
+	if length == 0 {
+		return math.Hypot(x, y)
+	}
+
+	t := (x*ex + y*ey) / length
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(x-t*ex, y-t*ey)
+}
@@ -0,0 +1,81 @@
+package helper
+
+import "strings"
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashEncode returns the geohash of a point with the given number of characters, every
+// prefix of the result is the geohash of the same point at a lower precision
+func GeohashEncode(lat float64, long float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLong, maxLong := -180.0, 180.0
+
+	var hash strings.Builder
+	bit, ch, even := 0, 0, true
+	for hash.Len() < precision {
+		if even {
+			mid := (minLong + maxLong) / 2
+			if long >= mid {
+				ch = ch<<1 | 1
+				minLong = mid
+			} else {
+				ch = ch << 1
+				maxLong = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch = ch << 1
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		bit++
+		if bit == 5 {
+			hash.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// GeohashBounds returns the south west and north east corners of a geohash cell, ok is false
+// when hash holds a character outside the geohash alphabet
+func GeohashBounds(hash string) (southWest GeoPoint, northEast GeoPoint, ok bool) {
+	minLat, maxLat := -90.0, 90.0
+	minLong, maxLong := -180.0, 180.0
+
+	even := true
+	for _, c := range hash {
+		index := strings.IndexRune(geohashBase32, c)
+		if index < 0 {
+			return GeoPoint{}, GeoPoint{}, false
+		}
+
+		for mask := 16; mask > 0; mask >>= 1 {
+			if even {
+				mid := (minLong + maxLong) / 2
+				if index&mask != 0 {
+					minLong = mid
+				} else {
+					maxLong = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if index&mask != 0 {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return GeoPoint{Lat: minLat, Long: minLong}, GeoPoint{Lat: maxLat, Long: maxLong}, true
+}
@@ -1,20 +1,40 @@
 package helper
 
 import (
+	"io"
 	"owlharbour-api/pkg/util"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 )
 
-func SendMail(to, subject, body string) error {
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// SendMail sends a html mail, to may hold several comma separated recipients
+func SendMail(to, subject, body string, attachments ...MailAttachment) error {
 	from := util.GetEnv("MAIL_USERNAME", "fallback")
 	password := util.GetEnv("MAIL_PASSWORD", "fallback")
 	msg := gomail.NewMessage()
 	msg.SetHeader("From", from)
-	msg.SetHeader("To", to)
+	msg.SetHeader("To", splitRecipients(to)...)
 	msg.SetHeader("Subject", subject)
 	msg.SetBody("text/html", body)
 
+	for _, attachment := range attachments {
+		data := attachment.Data
+		msg.Attach(attachment.Filename,
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}),
+		)
+	}
+
 	d := gomail.NewDialer("smtp.gmail.com", 587, from, password)
 
 	if err := d.DialAndSend(msg); err != nil {
@@ -23,3 +43,14 @@ func SendMail(to, subject, body string) error {
 
 	return nil
 }
+
+func splitRecipients(to string) []string {
+	var recipients []string
+	for _, address := range strings.Split(to, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+
+	return recipients
+}
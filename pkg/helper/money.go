@@ -0,0 +1,14 @@
+package helper
+
+import "math"
+
+// ToMinorUnits converts a decimal amount of the API into the cents it is stored as, money is
+// kept in integer minor units so sums and comparisons stay exact
+func ToMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromMinorUnits converts stored cents back into the decimal amount of the API
+func FromMinorUnits(amount int64) float64 {
+	return float64(amount) / 100
+}
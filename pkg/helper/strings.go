@@ -0,0 +1,19 @@
+package helper
+
+import "strings"
+
+// MergeCommaList appends the values of a comma separated list to another one, skipping
+// empty values and values that are already present
+func MergeCommaList(current string, values string) string {
+	var merged []string
+	seen := map[string]bool{}
+	for _, v := range strings.Split(current+","+values, ",") {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		merged = append(merged, v)
+	}
+
+	return strings.Join(merged, ",")
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"owlharbour-api/pkg/util"
+	"time"
+)
+
+// DefaultTimezone is the zone given to harbours saved without one, APP_TIMEZONE of the deployment
+func DefaultTimezone() string {
+	name := util.GetEnv("APP_TIMEZONE", "UTC")
+	if name == "" {
+		return "UTC"
+	}
+
+	return name
+}
+
+// HarbourLocation loads the zone a harbour buckets its days and hours in, every time series of a
+// harbour uses it so the charts agree with each other
+func HarbourLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone()
+	}
+
+	return time.LoadLocation(name)
+}
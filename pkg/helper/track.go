@@ -0,0 +1,50 @@
+package helper
+
+// DouglasPeucker simplifies a polyline and returns the indexes of the points to keep,
+// tolerance is the maximum allowed deviation in metres
+func DouglasPeucker(points []GeoPoint, tolerance float64) []int {
+	if len(points) <= 2 || tolerance <= 0 {
+		keep := make([]int, len(points))
+		for i := range points {
+			keep[i] = i
+		}
+		return keep
+	}
+
+	marked := make([]bool, len(points))
+	marked[0] = true
+	marked[len(points)-1] = true
+
+	// iterative stack instead of recursion, long tracks can contain thousands of fixes
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		segment := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		first, last := segment[0], segment[1]
+		maxDistance := 0.0
+		index := -1
+
+		for i := first + 1; i < last; i++ {
+			d := perpendicularDistance(points[i], points[first], points[last])
+			if d > maxDistance {
+				maxDistance = d
+				index = i
+			}
+		}
+
+		if index != -1 && maxDistance > tolerance {
+			marked[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	var keep []int
+	for i, m := range marked {
+		if m {
+			keep = append(keep, i)
+		}
+	}
+
+	return keep
+}
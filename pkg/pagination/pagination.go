@@ -0,0 +1,83 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"owlharbour-api/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// Cursor points at the last row of a page ordered by (created_at, id) descending
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// EncodeCursor returns the opaque cursor of a row, the timestamp keeps its full precision
+// so rows created within the same second are not skipped
+func EncodeCursor(createdAt time.Time, ID int) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor from EncodeCursor, an empty value means the first page
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, constants.InvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, constants.InvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, constants.InvalidCursor
+	}
+
+	ID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, constants.InvalidCursor
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: ID}, nil
+}
+
+// Keyset orders the query newest first and continues after the cursor row. The offset is
+// only used without a cursor, so clients paging by offset keep working.
+func Keyset(table string, cursor *Cursor, offset int) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		if cursor != nil {
+			query = query.Where("("+table+".created_at, "+table+".id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		} else {
+			query = query.Offset(offset)
+		}
+
+		return query.Order(table + ".created_at DESC").Order(table + ".id DESC")
+	}
+}
+
+// Limit is the number of rows to fetch for a keyset page, one more than the page size
+// tells whether another page follows without counting
+func Limit(limit int) int {
+	if limit < 0 {
+		return limit
+	}
+
+	return limit + 1
+}
+
+// HasMore reports whether rows follow an offset page of count rows
+func HasMore(offset int, count int, filteredTotal int64) bool {
+	return int64(offset+count) < filteredTotal
+}
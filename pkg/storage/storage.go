@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"owlharbour-api/pkg/constants"
+	"owlharbour-api/pkg/util"
+	"strconv"
+	"time"
+)
+
+// Storage keeps the uploaded files, keys are slash separated paths like inspection/12/photo.jpg
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// signingKey signs the local download links, the attachment file route trusts nothing else
+var signingKey []byte
+
+// NewStorage returns the driver chosen by STORAGE_DRIVER, local unless it is s3. It stops the
+// process without STORAGE_SIGNING_KEY since any download link could be forged
+func NewStorage() Storage {
+	key := util.GetEnv("STORAGE_SIGNING_KEY", "")
+	if key == "" {
+		log.Fatal("STORAGE_SIGNING_KEY is required to sign attachment download links")
+	}
+	signingKey = []byte(key)
+
+	if util.GetEnv("STORAGE_DRIVER", "local") == "s3" {
+		return NewS3Storage(S3Config{
+			Endpoint:  util.GetEnv("STORAGE_S3_ENDPOINT", "http://localhost:9000"),
+			Region:    util.GetEnv("STORAGE_S3_REGION", "us-east-1"),
+			Bucket:    util.GetEnv("STORAGE_S3_BUCKET", "owlharbour"),
+			AccessKey: util.GetEnv("STORAGE_S3_ACCESS_KEY", ""),
+			SecretKey: util.GetEnv("STORAGE_S3_SECRET_KEY", ""),
+			PathStyle: util.GetEnv("STORAGE_S3_PATH_STYLE", "true") == "true",
+		})
+	}
+
+	baseURL := "http://" + util.GetEnv("APP_URL", "127.0.0.1") + ":" + util.GetEnv("APP_PORT", "8081") + "/api/v1/attachment/file"
+
+	return NewLocalStorage(
+		util.GetEnv("STORAGE_LOCAL_PATH", "storage/attachments"),
+		util.GetEnv("STORAGE_PUBLIC_URL", baseURL),
+	)
+}
+
+// Sign returns the signature of a local download link, the key and the expiry are both covered
+func Sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(key + "|" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a link created by Sign and rejects it once it has expired
+func VerifySignature(key string, expires string, signature string) error {
+	if len(signingKey) == 0 {
+		return constants.InvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return constants.InvalidSignature
+	}
+
+	if !hmac.Equal([]byte(Sign(key, expiresAt)), []byte(signature)) {
+		return constants.InvalidSignature
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return constants.SignedURLExpired
+	}
+
+	return nil
+}
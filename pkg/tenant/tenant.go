@@ -0,0 +1,90 @@
+package tenant
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type harboursKey struct{}
+
+// WithHarbours scopes ctx to the given harbours, repository queries made with the returned
+// context only see rows of those harbours. Without ids nothing is visible.
+func WithHarbours(ctx context.Context, ids ...int) context.Context {
+	scoped := make([]int, len(ids))
+	copy(scoped, ids)
+	sort.Ints(scoped)
+
+	return context.WithValue(ctx, harboursKey{}, scoped)
+}
+
+// Harbours returns the harbours ctx is scoped to, ok is false when ctx is not scoped
+// and sees every harbour, as for superadmins and background workers
+func Harbours(ctx context.Context) ([]int, bool) {
+	ids, ok := ctx.Value(harboursKey{}).([]int)
+	return ids, ok
+}
+
+// Harbour returns the harbour of ctx when it is scoped to exactly one
+func Harbour(ctx context.Context) (int, bool) {
+	ids, ok := Harbours(ctx)
+	if !ok || len(ids) != 1 {
+		return 0, false
+	}
+
+	return ids[0], true
+}
+
+// Detach returns a background context keeping the harbour scope of ctx, for work which
+// outlives the request ctx belongs to
+func Detach(ctx context.Context) context.Context {
+	ids, ok := Harbours(ctx)
+	if !ok {
+		return context.Background()
+	}
+
+	return WithHarbours(context.Background(), ids...)
+}
+
+// Scope filters a query on a table owned by a harbour, column is its harbour_id column
+func Scope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where(column+" IN ?", ids)
+	}
+}
+
+// ShipScope filters a query on a table belonging to a ship, column is its ship_id column
+func ShipScope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		ids, ok := Harbours(ctx)
+		if !ok {
+			return query
+		}
+
+		return query.Where(column+" IN (SELECT id FROM ships WHERE ships.harbour_id IN ?)", ids)
+	}
+}
+
+// CacheKey suffixes a cache key with the harbours of ctx so scoped results are cached apart,
+// invalidating by the key followed by * clears every harbour
+func CacheKey(ctx context.Context, key string) string {
+	ids, ok := Harbours(ctx)
+	if !ok {
+		return key + "-all"
+	}
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+
+	return key + "-h" + strings.Join(parts, ",")
+}